@@ -19,4 +19,5 @@ go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
 
 protoc -I . -I ./third_party --go_out=. --go_opt=paths=source_relative plugins/*.proto
 protoc -I . -I ./third_party --go_out=. --go_opt=paths=source_relative surface/*.proto
-protoc -I . -I ./third_party --go_out=. --go_opt=paths=source_relative metrics/*.proto
\ No newline at end of file
+protoc -I . -I ./third_party --go_out=. --go_opt=paths=source_relative metrics/*.proto
+protoc -I . -I ./third_party --go_out=. --go_opt=paths=source_relative --go_opt=Mextensions/extension.proto=github.com/google/gnostic-models/extensions extensions/batch.proto extensions/capabilities.proto
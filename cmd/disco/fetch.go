@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/gnostic/compiler"
+	discovery "github.com/google/gnostic/discovery"
+)
+
+// defaultFetchWorkers is the number of documents that are downloaded at once by --fetch-all.
+const defaultFetchWorkers = 8
+
+// A fetchFailure is an API whose discovery document could not be downloaded or written.
+type fetchFailure struct {
+	api *discovery.API
+	err error
+}
+
+func (f *fetchFailure) Error() string {
+	return fmt.Sprintf("%s/%s: %v", f.api.Name, f.api.Version, f.err)
+}
+
+// documentFilename returns the name of the file that holds the discovery document of an API,
+// like the name written by --raw. Names and versions are lowercased, and characters that
+// aren't letters, digits, dots, or dashes are replaced with underscores.
+func documentFilename(api *discovery.API) string {
+	return "disco-" + normalizeName(api.Name) + "-" + normalizeName(api.Version) + ".json"
+}
+
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// fetchAll reads the list of APIs from the apis/list service at listURL and downloads the
+// documents of the APIs that pass the filters to dir, which is created if necessary.
+// It returns the number of documents that it tried to download and the failures.
+func fetchAll(ctx context.Context, listURL string, nameFilter *regexp.Regexp, preferredOnly bool, dir string, workers int) (int, []*fetchFailure, error) {
+	bytes, err := compiler.FetchFileContext(ctx, listURL)
+	if err != nil {
+		return 0, nil, err
+	}
+	listResponse, err := discovery.ParseList(bytes)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return 0, nil, err
+	}
+	apis := listResponse.FilterAPIs(nameFilter, preferredOnly)
+	return len(apis), fetchDocuments(ctx, apis, dir, workers), nil
+}
+
+// fetchDocuments downloads the discovery documents of apis with a pool of workers and
+// writes them to dir. Failures don't stop the other downloads; they are returned in
+// the order of apis.
+func fetchDocuments(ctx context.Context, apis []*discovery.API, dir string, workers int) []*fetchFailure {
+	if workers < 1 {
+		workers = 1
+	}
+	// Each document is read once, so there is no reason to keep it in a cache.
+	ctx = compiler.WithoutCaching(ctx)
+	errs := make([]error, len(apis))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = fetchDocument(ctx, apis[i], dir)
+			}
+		}()
+	}
+	for i := range apis {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	failures := make([]*fetchFailure, 0)
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, &fetchFailure{api: apis[i], err: err})
+		}
+	}
+	return failures
+}
+
+func fetchDocument(ctx context.Context, api *discovery.API, dir string) error {
+	bytes, err := discovery.FetchDocumentBytesContext(ctx, api.DiscoveryRestURL)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, documentFilename(api)), bytes, 0644)
+}
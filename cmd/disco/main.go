@@ -15,10 +15,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/docopt/docopt-go"
@@ -32,14 +35,24 @@ func main() {
 	usage := `
 Usage:
 	disco help
-	disco list [--raw]
-	disco get [<api>] [<version>] [--raw] [--openapi2] [--openapi3] [--features] [--schemas] [--all]
+	disco list [--raw] [--name-filter=<regex>] [--preferred-only]
+	disco get [<api>] [<version>] [--raw] [--openapi2] [--openapi3] [--features] [--schemas] [--all] [--name-filter=<regex>] [--preferred-only]
+	disco get --fetch-all --out-dir=<dir> [--name-filter=<regex>] [--preferred-only] [--workers=<n>]
 	disco <file> [--openapi2] [--openapi3] [--features] [--schemas]
 	`
 	arguments, err := docopt.Parse(usage, nil, false, "Disco 1.0", false)
 	if err != nil {
 		log.Fatalf("%+v", err)
 	}
+	// Filter the listed APIs.
+	var nameFilter *regexp.Regexp
+	if arguments["--name-filter"] != nil {
+		nameFilter, err = regexp.Compile(arguments["--name-filter"].(string))
+		if err != nil {
+			log.Fatalf("Invalid --name-filter: %+v", err)
+		}
+	}
+	preferredOnly := arguments["--preferred-only"].(bool)
 
 	// Help.
 	if arguments["help"].(bool) {
@@ -65,12 +78,36 @@ Usage:
 				log.Fatalf("%+v", err)
 			}
 			// List the APIs.
-			for _, api := range listResponse.APIs {
+			for _, api := range listResponse.FilterAPIs(nameFilter, preferredOnly) {
 				fmt.Printf("%s %s\n", api.Name, api.Version)
 			}
 		}
 	}
 
+	// Download the descriptions of all matching APIs.
+	if arguments["--fetch-all"].(bool) {
+		workers := defaultFetchWorkers
+		if arguments["--workers"] != nil {
+			workers, err = strconv.Atoi(arguments["--workers"].(string))
+			if err != nil || workers < 1 {
+				log.Fatalf("Invalid --workers: %s", arguments["--workers"])
+			}
+		}
+		dir := arguments["--out-dir"].(string)
+		count, failures, err := fetchAll(context.Background(), discovery.APIsListServiceURL, nameFilter, preferredOnly, dir, workers)
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		for _, failure := range failures {
+			log.Printf("%s", failure.Error())
+		}
+		log.Printf("Fetched %d of %d documents to %s", count-len(failures), count, dir)
+		if len(failures) > 0 {
+			log.Fatalf("%d documents could not be fetched", len(failures))
+		}
+		return
+	}
+
 	// Get an API description.
 	if arguments["get"].(bool) {
 		// Read the list of APIs from the apis/list service.
@@ -86,7 +123,7 @@ Usage:
 				!arguments["--schemas"].(bool) {
 				log.Fatalf("Please specify an output option.")
 			}
-			for _, api := range listResponse.APIs {
+			for _, api := range listResponse.FilterAPIs(nameFilter, preferredOnly) {
 				log.Printf("%s/%s", api.Name, api.Version)
 				// Fetch the discovery description of the API.
 				bytes, err := discovery.FetchDocumentBytes(api.DiscoveryRestURL)
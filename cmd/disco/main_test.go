@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	discovery "github.com/google/gnostic/discovery"
+)
+
+// newDirectoryServer serves an apis/list response and the documents that it lists.
+// The document of broken/v1 is missing.
+func newDirectoryServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	list := &discovery.List{Kind: "discovery#directoryList", DiscoveryVersion: "v1"}
+	for _, api := range []struct {
+		name      string
+		version   string
+		preferred bool
+	}{
+		{"pubsub", "v1beta2", false},
+		{"pubsub", "v1", true},
+		{"pubsublite", "v1", true},
+		{"Storage", "v1:alpha", true},
+		{"broken", "v1", true},
+	} {
+		path := "/apis/" + api.name + "/" + api.version + "/rest"
+		list.APIs = append(list.APIs, &discovery.API{
+			Name:             api.name,
+			Version:          api.version,
+			Preferred:        api.preferred,
+			DiscoveryRestURL: server.URL + path,
+		})
+		if api.name != "broken" {
+			document := `{"name": "` + api.name + `", "version": "` + api.version + `"}`
+			mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(document))
+			})
+		}
+	}
+	mux.HandleFunc("/apis", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(list)
+	})
+	return server
+}
+
+// readDirectory returns the names of the files in a directory and checks their contents.
+func readDirectory(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), `{"name": `) {
+			t.Errorf("unexpected contents of %s: %s", entry.Name(), data)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFetchAll(t *testing.T) {
+	server := newDirectoryServer(t)
+	for _, test := range []struct {
+		name          string
+		nameFilter    *regexp.Regexp
+		preferredOnly bool
+		count         int
+		files         []string
+		failures      []string
+	}{
+		{
+			name:     "all",
+			count:    5,
+			files:    []string{"disco-pubsub-v1.json", "disco-pubsub-v1beta2.json", "disco-pubsublite-v1.json", "disco-storage-v1_alpha.json"},
+			failures: []string{"broken/v1"},
+		},
+		{
+			name:          "preferred",
+			nameFilter:    regexp.MustCompile("^pubsub"),
+			preferredOnly: true,
+			count:         2,
+			files:         []string{"disco-pubsub-v1.json", "disco-pubsublite-v1.json"},
+		},
+		{
+			name:       "name",
+			nameFilter: regexp.MustCompile("^pubsub$"),
+			count:      2,
+			files:      []string{"disco-pubsub-v1.json", "disco-pubsub-v1beta2.json"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// The output directory is created if necessary.
+			dir := filepath.Join(t.TempDir(), "out")
+			count, failures, err := fetchAll(context.Background(), server.URL+"/apis", test.nameFilter, test.preferredOnly, dir, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != test.count {
+				t.Errorf("expected %d documents, got %d", test.count, count)
+			}
+			files := readDirectory(t, dir)
+			if strings.Join(files, " ") != strings.Join(test.files, " ") {
+				t.Errorf("unexpected files %v (expected %v)", files, test.files)
+			}
+			if len(failures) != len(test.failures) {
+				t.Fatalf("unexpected failures %v (expected %v)", failures, test.failures)
+			}
+			for i, failure := range failures {
+				if !strings.HasPrefix(failure.Error(), test.failures[i]+": ") {
+					t.Errorf("unexpected failure %q (expected one for %s)", failure.Error(), test.failures[i])
+				}
+			}
+		})
+	}
+	if _, _, err := fetchAll(context.Background(), server.URL+"/missing", nil, false, t.TempDir(), 2); err == nil {
+		t.Errorf("expected an error for a missing apis/list service")
+	}
+}
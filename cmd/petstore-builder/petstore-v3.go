@@ -60,6 +60,10 @@ func buildDocumentV3() *v3.Document {
 						},
 					},
 					Responses: &v3.Responses{
+						// the default response is written after the 200 response, like in the example
+						SpecificationExtension: []*v3.NamedAny{
+							&v3.NamedAny{Name: "x-gnostic-default-position", Value: &v3.Any{Yaml: "1\n"}},
+						},
 						Default: &v3.ResponseOrReference{
 							Oneof: &v3.ResponseOrReference_Response{
 								Response: &v3.Response{
@@ -139,6 +143,10 @@ func buildDocumentV3() *v3.Document {
 					OperationId: "createPets",
 					Tags:        []string{"pets"},
 					Responses: &v3.Responses{
+						// the default response is written after the 200 response, like in the example
+						SpecificationExtension: []*v3.NamedAny{
+							&v3.NamedAny{Name: "x-gnostic-default-position", Value: &v3.Any{Yaml: "1\n"}},
+						},
 						Default: &v3.ResponseOrReference{
 							Oneof: &v3.ResponseOrReference_Response{
 								Response: &v3.Response{
@@ -204,6 +212,10 @@ func buildDocumentV3() *v3.Document {
 						},
 					},
 					Responses: &v3.Responses{
+						// the default response is written after the 200 response, like in the example
+						SpecificationExtension: []*v3.NamedAny{
+							&v3.NamedAny{Name: "x-gnostic-default-position", Value: &v3.Any{Yaml: "1\n"}},
+						},
 						Default: &v3.ResponseOrReference{
 							Oneof: &v3.ResponseOrReference_Response{
 								Response: &v3.Response{
@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+// resolveAnyValues writes the values of the openapi.v3.Any messages of a message and the
+// messages that it contains as YAML. Only the yaml of an Any is written to documents, so an
+// annotation that sets its value instead, like
+//
+//	value: { value: { [type.googleapis.com/google.protobuf.Int64Value]: { value: 100 } } }
+//
+// would be written as null.
+func resolveAnyValues(m protoreflect.Message) {
+	if a, ok := m.Interface().(*v3.Any); ok {
+		if a.Yaml == "" && a.Value != nil {
+			text, err := yamlForAnyValue(a.Value)
+			if err != nil {
+				log.Printf("the value of an extension or example is written as null: %v", err)
+				return
+			}
+			a.Yaml = text
+		}
+		return
+	}
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Message() == nil || field.IsMap() || !m.Has(field) {
+			continue
+		}
+		if !field.IsList() {
+			resolveAnyValues(m.Get(field).Message())
+			continue
+		}
+		list := m.Get(field).List()
+		for j := 0; j < list.Len(); j++ {
+			resolveAnyValues(list.Get(j).Message())
+		}
+	}
+}
+
+// yamlForAnyValue returns the YAML of a wrapper, like google.protobuf.Int64Value, or of a
+// google.protobuf.Struct, Value or ListValue. Numbers and booleans are written as YAML
+// numbers and booleans rather than as their JSON mapping, which writes 64-bit integers as
+// strings, and strings are only quoted if they would be read as something else.
+func yamlForAnyValue(value *anypb.Any) (string, error) {
+	message, err := value.UnmarshalNew()
+	if err != nil {
+		return "", err
+	}
+	var v interface{}
+	switch message := message.(type) {
+	case *wrapperspb.BoolValue:
+		v = message.Value
+	case *wrapperspb.Int32Value:
+		v = message.Value
+	case *wrapperspb.Int64Value:
+		v = message.Value
+	case *wrapperspb.UInt32Value:
+		v = message.Value
+	case *wrapperspb.UInt64Value:
+		v = message.Value
+	case *wrapperspb.FloatValue:
+		v = message.Value
+	case *wrapperspb.DoubleValue:
+		v = message.Value
+	case *wrapperspb.StringValue:
+		v = message.Value
+	case *wrapperspb.BytesValue:
+		v = base64.StdEncoding.EncodeToString(message.Value)
+	case *structpb.Struct:
+		v = message.AsMap()
+	case *structpb.Value:
+		v = message.AsInterface()
+	case *structpb.ListValue:
+		v = message.AsSlice()
+	default:
+		return "", fmt.Errorf("%s values aren't supported", message.ProtoReflect().Descriptor().FullName())
+	}
+	bytes, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"slices"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+// Merged documents can describe services, methods and messages of several packages with
+// the same names. The tags and operationIds that would be the same are qualified with the
+// packages of their services and methods, along with the other operationIds of the services
+// whose tags are qualified, and if schema names would be the same, the
+// document is built again with fq_schema_naming. Names that are the same within a package
+// are still errors.
+
+// merged returns true if the document describes all of the files to generate.
+func (g *OpenAPIv3Generator) merged() bool {
+	return g.service == nil && (g.conf.OutputMode == nil || *g.conf.OutputMode == "merged")
+}
+
+// qualifyCollidingNames finds the services whose tags and the methods with HTTP annotations
+// whose operationIds would be the same as those of services and methods of other packages.
+func (g *OpenAPIv3Generator) qualifyCollidingNames() {
+	services := map[string][]*protogen.Service{}
+	methods := map[string][]*protogen.Method{}
+	for _, file := range g.inputFiles {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			annotated := false
+			for _, method := range service.Methods {
+				if !proto.HasExtension(method.Desc.Options(), annotations.E_Http) {
+					continue
+				}
+				annotated = true
+				// The operationIds of annotations are kept as they are.
+				if proto.GetExtension(method.Desc.Options(), v3.E_Operation).(*v3.Operation).GetOperationId() == "" {
+					methods[g.operationID(method)] = append(methods[g.operationID(method)], method)
+				}
+			}
+			if annotated {
+				services[g.tagName(service)] = append(services[g.tagName(service)], service)
+			}
+		}
+	}
+	g.qualifiedServices = make(map[protoreflect.FullName]bool)
+	g.qualifiedMethods = make(map[protoreflect.FullName]bool)
+	for _, services := range services {
+		if len(packagesOf(services)) > 1 {
+			for _, service := range services {
+				g.qualifiedServices[service.Desc.FullName()] = true
+				// The operationIds of the methods of the service start with its tag.
+				if g.conf.OperationID == nil || *g.conf.OperationID != "method" {
+					for _, method := range service.Methods {
+						g.qualifiedMethods[method.Desc.FullName()] = true
+					}
+				}
+			}
+		}
+	}
+	for _, methods := range methods {
+		parents := make([]*protogen.Service, len(methods))
+		for i, method := range methods {
+			parents[i] = method.Parent
+		}
+		if len(packagesOf(parents)) > 1 {
+			for _, method := range methods {
+				g.qualifiedMethods[method.Desc.FullName()] = true
+			}
+		}
+	}
+}
+
+// packagesOf returns the packages of services, without duplicates.
+func packagesOf(services []*protogen.Service) []protoreflect.FullName {
+	packages := []protoreflect.FullName{}
+	for _, service := range services {
+		if pkg := service.Desc.ParentFile().Package(); !slices.Contains(packages, pkg) {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// qualifySchemaNames builds a merged document again with fq_schema_naming if the schema
+// names of its messages were the same, and returns the generator and the document if that
+// gives every message its own schema name. It returns nil otherwise.
+func (g *OpenAPIv3Generator) qualifySchemaNames() (*OpenAPIv3Generator, *v3.Document) {
+	if len(g.schemaCollisions) == 0 || !g.merged() || (g.conf.FQSchemaNaming != nil && *g.conf.FQSchemaNaming) {
+		return nil, nil
+	}
+	conf := g.conf
+	conf.FQSchemaNaming = proto.Bool(true)
+	qualified := NewOpenAPIv3Generator(g.plugin, conf, g.inputFiles)
+	d := qualified.buildDocumentV3()
+	if len(qualified.schemaCollisions) > 0 {
+		return nil, nil
+	}
+	return qualified, d
+}
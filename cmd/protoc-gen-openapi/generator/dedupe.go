@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"log"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// dedupeIdenticalSchemas replaces the schemas of components.schemas that are identical to
+// another schema with references to it. Of each group of identical schemas, the one whose
+// name sorts first is kept. Schemas that only differ in the schemas that they refer to
+// are identical once those schemas are merged, so merging is repeated until nothing changes.
+// The schemas must be sorted by name.
+func (g *OpenAPIv3Generator) dedupeIdenticalSchemas(d *v3.Document) {
+	if g.conf.DedupeIdenticalSchemas == nil || !*g.conf.DedupeIdenticalSchemas {
+		return
+	}
+	ignoreDescriptions := g.conf.DedupeIgnoreDescriptions == nil || *g.conf.DedupeIgnoreDescriptions
+	// merged maps the names of the merged schemas to the names of the schemas that they refer to.
+	merged := map[string]string{}
+	for {
+		count := len(merged)
+		kept := map[string]string{} // Names of the kept schemas by their contents.
+		for _, pair := range d.Components.Schemas.AdditionalProperties {
+			schema := pair.Value.GetSchema()
+			if schema == nil {
+				continue
+			}
+			key := schemaKey(schema, merged, ignoreDescriptions)
+			if name, ok := kept[key]; ok {
+				merged[pair.Name] = name
+				pair.Value = &v3.SchemaOrReference{Oneof: &v3.SchemaOrReference_Reference{
+					Reference: &v3.Reference{XRef: schemaRefPrefix + name}}}
+			} else {
+				kept[key] = pair.Name
+			}
+		}
+		if len(merged) == count {
+			break
+		}
+	}
+	// Schemas that were kept in one round may have been merged in a later one.
+	for _, pair := range d.Components.Schemas.AdditionalProperties {
+		if _, ok := merged[pair.Name]; ok {
+			name := resolveMergedSchema(pair.Name, merged)
+			pair.Value.GetReference().XRef = schemaRefPrefix + name
+			log.Printf("dedupe_identical_schemas: %s refers to the identical schema %s", pair.Name, name)
+		}
+	}
+}
+
+// resolveMergedSchema returns the name of the schema that a schema was merged into, or
+// the name itself if the schema wasn't merged.
+func resolveMergedSchema(name string, merged map[string]string) string {
+	for {
+		next, ok := merged[name]
+		if !ok {
+			return name
+		}
+		name = next
+	}
+}
+
+// schemaKey returns the contents of a schema as a string, with references to merged schemas
+// replaced with references to the schemas that they were merged into.
+func schemaKey(schema *v3.Schema, merged map[string]string, ignoreDescriptions bool) string {
+	schema = proto.Clone(schema).(*v3.Schema)
+	normalizeSchema(schema.ProtoReflect(), merged, ignoreDescriptions)
+	bytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(schema)
+	if err != nil {
+		// Schemas that can't be compared are kept.
+		return err.Error()
+	}
+	return string(bytes)
+}
+
+// normalizeSchema resolves the references of a message of a schema and the messages that
+// it contains, and clears their descriptions if ignoreDescriptions is set.
+func normalizeSchema(m protoreflect.Message, merged map[string]string, ignoreDescriptions bool) {
+	fields := m.Descriptor().Fields()
+	if field := fields.ByName("description"); ignoreDescriptions && field != nil && field.Kind() == protoreflect.StringKind {
+		m.Clear(field)
+	}
+	if reference, ok := m.Interface().(*v3.Reference); ok && strings.HasPrefix(reference.XRef, schemaRefPrefix) {
+		reference.XRef = schemaRefPrefix + resolveMergedSchema(strings.TrimPrefix(reference.XRef, schemaRefPrefix), merged)
+	}
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Message() == nil || field.IsMap() || !m.Has(field) {
+			continue
+		}
+		if !field.IsList() {
+			normalizeSchema(m.Get(field).Message(), merged, ignoreDescriptions)
+			continue
+		}
+		list := m.Get(field).List()
+		for j := 0; j < list.Len(); j++ {
+			normalizeSchema(list.Get(j).Message(), merged, ignoreDescriptions)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+// ellipsis ends descriptions that were truncated.
+const ellipsis = "…"
+
+// sanitizeDescription removes control characters other than newlines and tabs from a
+// description. If maxLength is positive and the description is longer than maxLength
+// characters, it is truncated at a word boundary and ends with an ellipsis, so that it
+// is at most maxLength characters long. It returns true if the description was truncated.
+func sanitizeDescription(description string, maxLength int) (string, bool) {
+	description = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, description)
+	runes := []rune(description)
+	if maxLength < 1 || len(runes) <= maxLength {
+		return description, false
+	}
+	// Keep room for the ellipsis and cut at the last space that fits, unless the first
+	// word is too long by itself.
+	cut := maxLength - 1
+	if !unicode.IsSpace(runes[cut]) {
+		for i := cut - 1; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + ellipsis, true
+}
+
+// sanitizeDescriptions sanitizes all descriptions of a document, including the ones of
+// annotations and well-known types, and reports the descriptions that were truncated.
+func (g *OpenAPIv3Generator) sanitizeDescriptions(d *v3.Document) {
+	maxLength := 0
+	if g.conf.MaxDescriptionLength != nil {
+		maxLength = *g.conf.MaxDescriptionLength
+	}
+	g.sanitizeMessageDescriptions(d.ProtoReflect(), "", maxLength)
+}
+
+// sanitizeMessageDescriptions sanitizes the description of a message of the document
+// and the descriptions of the messages that it contains. The location of a message is
+// the path of names and fields that lead to it, like "paths./v1/messages.get".
+func (g *OpenAPIv3Generator) sanitizeMessageDescriptions(m protoreflect.Message, location string, maxLength int) {
+	fields := m.Descriptor().Fields()
+	if field := fields.ByName("description"); field != nil && field.Kind() == protoreflect.StringKind && m.Has(field) {
+		description, truncated := sanitizeDescription(m.Get(field).String(), maxLength)
+		if truncated {
+			g.addWarning("description of %s was truncated to %d characters", strings.TrimPrefix(location, "."), maxLength)
+		}
+		m.Set(field, protoreflect.ValueOfString(description))
+	}
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Message() == nil || field.IsMap() || !m.Has(field) {
+			continue
+		}
+		// The values of named pairs and the members of oneofs don't add to the location.
+		fieldLocation := location
+		if field.Name() != "value" && field.ContainingOneof() == nil {
+			fieldLocation += "." + string(field.Name())
+		}
+		if !field.IsList() {
+			g.sanitizeMessageDescriptions(m.Get(field).Message(), fieldLocation, maxLength)
+			continue
+		}
+		list := m.Get(field).List()
+		for j := 0; j < list.Len(); j++ {
+			element := list.Get(j).Message()
+			// Elements of lists of named pairs are located by their name.
+			if name := element.Descriptor().Fields().ByName("name"); name != nil && name.Kind() == protoreflect.StringKind && element.Descriptor().Fields().ByName("value") != nil {
+				g.sanitizeMessageDescriptions(element, location+"."+element.Get(name).String(), maxLength)
+			} else {
+				g.sanitizeMessageDescriptions(element, fmt.Sprintf("%s[%d]", fieldLocation, j), maxLength)
+			}
+		}
+	}
+}
@@ -16,14 +16,19 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
 	"regexp"
+	"runtime"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"go.yaml.in/yaml/v3"
 	"google.golang.org/genproto/googleapis/api/annotations"
 	status_pb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/protobuf/compiler/protogen"
@@ -32,20 +37,123 @@ import (
 	any_pb "google.golang.org/protobuf/types/known/anypb"
 
 	wk "github.com/google/gnostic/cmd/protoc-gen-openapi/generator/wellknown"
+	"github.com/google/gnostic/compiler"
+	"github.com/google/gnostic/jsonwriter"
 	v3 "github.com/google/gnostic/openapiv3"
 )
 
 type Configuration struct {
-	Version           *string
-	Title             *string
-	Description       *string
-	Naming            *string
-	FQSchemaNaming    *bool
-	EnumType          *string
-	CircularDepth     *int
-	DefaultResponse   *bool
-	OutputMode        *string
-	WildcardBodyDedup *bool
+	Version     *string
+	Title       *string
+	Description *string
+	// ContactEmail, License and TermsOfService set the email address of info.contact,
+	// the name of info.license and info.termsOfService, unless annotations set them.
+	ContactEmail    *string
+	License         *string
+	TermsOfService  *string
+	Naming          *string
+	FQSchemaNaming  *bool
+	EnumType        *string
+	CircularDepth   *int
+	DefaultResponse *bool
+	// DefaultResponseRef makes the default responses of operations refer to a shared
+	// response in components.responses, named DefaultResponseName.
+	DefaultResponseRef  *bool
+	DefaultResponseName *string
+	OutputMode          *string
+	WildcardBodyDedup   *bool
+	Workers             *int
+	WarningsHeader      *bool
+	// MaxDescriptionLength is the number of characters that descriptions are truncated to.
+	// Descriptions aren't truncated if it is zero.
+	MaxDescriptionLength *int
+	// TitleTemplate is the title of documents, with {package} and {service} replaced
+	// with the packages and services of the document.
+	TitleTemplate *string
+	// DedupeIdenticalSchemas replaces schemas that are identical to another schema with
+	// references to it. Descriptions are ignored unless DedupeIgnoreDescriptions is false.
+	DedupeIdenticalSchemas   *bool
+	DedupeIgnoreDescriptions *bool
+	// OutputFormat is "yaml" or "json".
+	OutputFormat *string
+	// Lint reports the violations of openapi_v3.Validate in documents as errors.
+	Lint *bool
+	// TimestampFormat and DurationFormat are the formats of the schemas of
+	// google.protobuf.Timestamp and google.protobuf.Duration. Empty formats are omitted,
+	// and nil formats are the defaults of the wellknown package.
+	TimestampFormat *string
+	DurationFormat  *string
+	// AllowDuplicatePaths is "warn" to keep the first of the methods that are bound to the
+	// same HTTP method and path, and warn about the others. They are errors otherwise.
+	AllowDuplicatePaths *string
+	// EnumVarnames lists the values of integer enums in their schemas, with their names
+	// in an x-enum-varnames extension.
+	EnumVarnames *bool
+	// TagScopeExtensions lists the scopes of the security requirements of the operations
+	// of each service in an x-required-scopes extension of its tag.
+	TagScopeExtensions *bool
+	// ValidateRules sets the keywords of schemas from the validate.rules annotations of
+	// protoc-gen-validate and the buf.validate annotations of protovalidate.
+	ValidateRules *bool
+	// DryRun replaces the generated files with a summary of them.
+	DryRun *bool
+	// CustomVerbs is "extension" to write the operations of custom HTTP bindings whose kinds
+	// aren't methods of OpenAPI in an x-custom-method extension of their path items. They
+	// are skipped with a warning otherwise.
+	CustomVerbs *string
+	// NoComponents inlines the schemas and responses of components at their references and
+	// leaves components out of documents.
+	NoComponents *bool
+	// Streaming is the handling of streaming methods. With "skip", they are left out. With
+	// "array" or "sse", the JSON responses of server-streaming methods are arrays of their
+	// messages or text/event-stream responses. Otherwise they are described like unary methods.
+	Streaming *string
+	// OperationID is the naming scheme of operationIds and tags. With "method", operationIds
+	// are the names of methods, and with "fqn", the full names of methods and services with
+	// dots replaced by underscores. Otherwise they are the names of services and methods.
+	OperationID *string
+	// SchemaCase is the case of the names of schemas in components, "snake" like book_shelf
+	// or "camel" like bookShelf. Otherwise they keep the case of their messages.
+	SchemaCase *string
+	// JSONNames names properties, query parameters and the fields of required lists after
+	// the JSON names of fields, unless it is false. Then they are named after the proto names
+	// of fields, and path parameters keep the names that path templates give them.
+	JSONNames *bool
+	// DefaultHost is the URL of the server of documents whose annotations declare no servers.
+	// A host without a scheme, like api.example.com, is served over https.
+	DefaultHost *string
+	// Tags is "package" to tag operations with the packages of their services, described
+	// by the comments of the package statements. Otherwise operations are tagged with their
+	// services, described by the comments of the services.
+	Tags *string
+	// SecurityScheme is a security scheme that is added to the components of documents, like
+	// "BearerAuth:http", and Security is the name of a security scheme that documents require,
+	// followed by its scopes, like "OAuth;messages.read". The annotations of files take
+	// precedence over both of them.
+	SecurityScheme *string
+	Security       *string
+	// MappingOut writes a mapping of the operationIds of each document to their gRPC methods
+	// and their request and response messages next to it.
+	MappingOut *bool
+}
+
+// json returns true if documents are written as JSON.
+func (c Configuration) json() bool {
+	return c.OutputFormat != nil && *c.OutputFormat == "json"
+}
+
+// jsonNames returns true if fields are named after their JSON names.
+func (c Configuration) jsonNames() bool {
+	return c.JSONNames == nil || *c.JSONNames
+}
+
+// workers returns the number of goroutines that build schemas, which is GOMAXPROCS
+// unless Workers is set to a positive number.
+func (c Configuration) workers() int {
+	if c.Workers == nil || *c.Workers < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return *c.Workers
 }
 
 const (
@@ -63,42 +171,139 @@ type OpenAPIv3Generator struct {
 	conf   Configuration
 	plugin *protogen.Plugin
 
-	inputFiles        []*protogen.File
+	inputFiles        []*protogen.File // Files to generate, sorted by path.
+	files             []*protogen.File // All files of the request, sorted by path.
 	reflect           *OpenAPIv3Reflector
-	generatedSchemas  []string // Names of schemas that have already been generated.
+	generatedSchemas  map[string]bool                    // Names of schemas that have already been generated.
+	bodySchemas       map[string]string                  // Names of the request body schemas of wildcard_body_dedup, by message and excluded fields.
+	builtSchemas      map[*protogen.Message]*builtSchema // Schemas of messages built ahead of time by prebuildSchemas.
 	linterRulePattern *regexp.Regexp
-	pathPattern       *regexp.Regexp
-	namedPathPattern  *regexp.Regexp
+	warnings          []string // Descriptions of methods, parameters and descriptions that were left out of the document.
+	errors            []error  // Problems that prevent the document from being generated.
+	// operations maps the method and path of each operation to the method that it was built for.
+	operations map[string]protoreflect.FullName
+	// operationIDs maps the operationId of each operation to the method that it was built for.
+	operationIDs map[string]protoreflect.FullName
+	// defaultResponse is the shared default response, if an operation refers to it.
+	defaultResponse *v3.Response
+	services        []string // Names of the services that were added to the document.
+	// operationMethods maps the operationId of each operation of the document to its method.
+	operationMethods map[string]*protogen.Method
+	// tagScopes lists the security scopes of the operations of each tag.
+	tagScopes map[string][]string
+	// service is the only service of the document with output_mode=per_service.
+	service *protogen.Service
+	// qualifiedServices and qualifiedMethods are the services and methods of a merged
+	// document whose tags and operationIds are qualified with their packages, because they
+	// would be the same as those of services and methods of other packages.
+	qualifiedServices map[protoreflect.FullName]bool
+	qualifiedMethods  map[protoreflect.FullName]bool
+	// schemaCollisions describes the schema names that are used for different messages.
+	schemaCollisions []string
 }
 
 // NewOpenAPIv3Generator creates a new generator for a protoc plugin invocation.
 func NewOpenAPIv3Generator(plugin *protogen.Plugin, conf Configuration, inputFiles []*protogen.File) *OpenAPIv3Generator {
+	reflect := NewOpenAPIv3Reflector(conf)
+	if conf.ValidateRules != nil && *conf.ValidateRules {
+		reflect.validateRules = findValidateRules(plugin.Files)
+	}
 	return &OpenAPIv3Generator{
 		conf:   conf,
 		plugin: plugin,
 
-		inputFiles:        inputFiles,
-		reflect:           NewOpenAPIv3Reflector(conf),
-		generatedSchemas:  make([]string, 0),
+		inputFiles:        sortedFiles(inputFiles),
+		files:             sortedFiles(plugin.Files),
+		reflect:           reflect,
+		generatedSchemas:  make(map[string]bool),
+		bodySchemas:       make(map[string]string),
+		builtSchemas:      make(map[*protogen.Message]*builtSchema),
+		operations:        make(map[string]protoreflect.FullName),
+		operationIDs:      make(map[string]protoreflect.FullName),
+		operationMethods:  make(map[string]*protogen.Method),
+		tagScopes:         make(map[string][]string),
 		linterRulePattern: regexp.MustCompile(`(?s)\(-- .*? --\)`),
-		pathPattern:       regexp.MustCompile("{([^=}]+)}"),
-		namedPathPattern:  regexp.MustCompile("{(.+)=(.+)}"),
 	}
 }
 
+// NewServiceOpenAPIv3Generator creates a new generator for a document of a single service of
+// a file, for output_mode=per_service. The document has the paths of the service and the
+// schemas that they refer to.
+func NewServiceOpenAPIv3Generator(plugin *protogen.Plugin, conf Configuration, file *protogen.File, service *protogen.Service) *OpenAPIv3Generator {
+	g := NewOpenAPIv3Generator(plugin, conf, []*protogen.File{file})
+	g.service = service
+	return g
+}
+
+// sortedFiles returns the files sorted by path. Files are merged into the document in
+// this order, so that the output doesn't depend on the order of the files in the request.
+func sortedFiles(files []*protogen.File) []*protogen.File {
+	sorted := slices.Clone(files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Desc.Path() < sorted[j].Desc.Path()
+	})
+	return sorted
+}
+
 // Run runs the generator.
 func (g *OpenAPIv3Generator) Run(outputFile *protogen.GeneratedFile) error {
 	d := g.buildDocumentV3()
-	bytes, err := d.YAMLValue("Generated with protoc-gen-openapi\n" + infoURL)
+	if qualified, qualifiedDocument := g.qualifySchemaNames(); qualified != nil {
+		qualified.addWarning("%s, so fq_schema_naming was enabled", g.schemaCollisions[0])
+		*g = *qualified
+		d = qualifiedDocument
+	}
+	resolveAnyValues(d.ProtoReflect())
+	comment := "Generated with protoc-gen-openapi\n" + infoURL
+	for _, warning := range g.warnings {
+		log.Printf("warning: %s", warning)
+	}
+	if g.conf.Lint != nil && *g.conf.Lint {
+		for _, violation := range v3.Validate(d) {
+			g.addError("lint: %s", violation.Error())
+		}
+	}
+	if len(g.errors) > 0 {
+		return errors.Join(g.errors...)
+	}
+	if g.conf.WarningsHeader != nil && *g.conf.WarningsHeader && len(g.warnings) > 0 {
+		comment += "\n\nWarnings:\n- " + strings.Join(g.warnings, "\n- ")
+	}
+	format := "yaml"
+	if g.conf.json() {
+		format = "json"
+	}
+	// Write every value in full, since several YAML parsers reject anchors and aliases.
+	rawInfo, err := compiler.ExpandAliases(d.ToRawInfo())
 	if err != nil {
-		return fmt.Errorf("failed to marshal yaml: %s", err.Error())
+		return fmt.Errorf("failed to marshal %s: %s", format, err.Error())
+	}
+	var bytes []byte
+	if g.conf.json() {
+		// JSON has no comments, so the output starts with the document.
+		bytes, err = jsonwriter.Marshal(rawInfo)
+	} else {
+		bytes, err = compiler.RenderYAML(rawInfo, compiler.YAMLOptions{Comment: comment})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %s", format, err.Error())
 	}
 	if _, err = outputFile.Write(bytes); err != nil {
-		return fmt.Errorf("failed to write yaml: %s", err.Error())
+		return fmt.Errorf("failed to write %s: %s", format, err.Error())
 	}
 	return nil
 }
 
+// addWarning records something that was left out of the document.
+func (g *OpenAPIv3Generator) addWarning(format string, args ...interface{}) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+// addError records a problem that prevents the document from being generated.
+func (g *OpenAPIv3Generator) addError(format string, args ...interface{}) {
+	g.errors = append(g.errors, fmt.Errorf(format, args...))
+}
+
 // buildDocumentV3 builds an OpenAPIv3 document for a plugin request.
 func (g *OpenAPIv3Generator) buildDocumentV3() *v3.Document {
 	d := &v3.Document{}
@@ -106,9 +311,17 @@ func (g *OpenAPIv3Generator) buildDocumentV3() *v3.Document {
 	d.Openapi = "3.0.3"
 	d.Info = &v3.Info{
 		Version:     *g.conf.Version,
-		Title:       *g.conf.Title,
 		Description: *g.conf.Description,
 	}
+	if g.conf.ContactEmail != nil && *g.conf.ContactEmail != "" {
+		d.Info.Contact = &v3.Contact{Email: *g.conf.ContactEmail}
+	}
+	if g.conf.License != nil && *g.conf.License != "" {
+		d.Info.License = &v3.License{Name: *g.conf.License}
+	}
+	if g.conf.TermsOfService != nil {
+		d.Info.TermsOfService = *g.conf.TermsOfService
+	}
 
 	d.Paths = &v3.Paths{}
 	d.Components = &v3.Components{
@@ -117,6 +330,10 @@ func (g *OpenAPIv3Generator) buildDocumentV3() *v3.Document {
 		},
 	}
 
+	if g.merged() {
+		g.qualifyCollidingNames()
+	}
+
 	// Go through the files and add the services to the documents, keeping
 	// track of which schemas are referenced in the response so we can
 	// add them later.
@@ -128,100 +345,72 @@ func (g *OpenAPIv3Generator) buildDocumentV3() *v3.Document {
 				proto.Merge(d, extDocument.(*v3.Document))
 			}
 
-			g.addPathsToDocumentV3(d, file.Services)
+			services := file.Services
+			if g.service != nil {
+				services = []*protogen.Service{g.service}
+			}
+			g.addPathsToDocumentV3(d, services)
+		}
+	}
+
+	// Services of the same package share their tags with tags=package, and so do the tags
+	// of services and of the annotations of several files with the same names.
+	d.Tags = mergeTags(d.Tags)
+	if g.conf.TagScopeExtensions != nil && *g.conf.TagScopeExtensions {
+		for _, tag := range d.Tags {
+			addScopesToTag(tag, g.tagScopes[tag.Name])
 		}
 	}
 
+	g.addSecurityOptions(d)
+
+	g.addDefaultResponseToDocumentV3(d)
+
+	// Build the schemas of the required messages concurrently. They are added to the
+	// document below in the same order as they would be if they were built there.
+	g.prebuildSchemas()
+
 	// While we have required schemas left to generate, go through the files again
 	// looking for the related message and adding them to the document if required.
 	for len(g.reflect.requiredSchemas) > 0 {
 		count := len(g.reflect.requiredSchemas)
-		for _, file := range g.plugin.Files {
+		for _, file := range g.files {
 			g.addSchemasForMessagesToDocumentV3(d, file.Messages)
 		}
 		g.reflect.requiredSchemas = g.reflect.requiredSchemas[count:len(g.reflect.requiredSchemas)]
 	}
+	g.schemaCollisions = g.reflect.schemaMessages.collisions()
+	for _, collision := range g.schemaCollisions {
+		g.addError("%s", collision)
+	}
 
-	// If there is only 1 service, then use it's title for the
+	// Documents whose annotations don't set a title get one from the options or
+	// from their services and packages.
+	if d.Info.Title == "" {
+		d.Info.Title = g.defaultTitle(d)
+	}
+
+	// If there is only 1 service, then use its description for the
 	// document, if the document is missing it.
 	if len(d.Tags) == 1 {
-		if d.Info.Title == "" && d.Tags[0].Name != "" {
-			d.Info.Title = d.Tags[0].Name + " API"
-		}
 		if d.Info.Description == "" {
 			d.Info.Description = d.Tags[0].Description
 		}
 		d.Tags[0].Description = ""
 	}
 
-	allServers := []string{}
-
-	// If paths methods has servers, but they're all the same, then move servers to path level
-	for _, path := range d.Paths.Path {
-		servers := []string{}
-		// Only 1 server will ever be set, per method, by the generator
-
-		if path.Value.Get != nil && len(path.Value.Get.Servers) == 1 {
-			servers = appendUnique(servers, path.Value.Get.Servers[0].Url)
-			allServers = appendUnique(servers, path.Value.Get.Servers[0].Url)
-		}
-		if path.Value.Post != nil && len(path.Value.Post.Servers) == 1 {
-			servers = appendUnique(servers, path.Value.Post.Servers[0].Url)
-			allServers = appendUnique(servers, path.Value.Post.Servers[0].Url)
-		}
-		if path.Value.Put != nil && len(path.Value.Put.Servers) == 1 {
-			servers = appendUnique(servers, path.Value.Put.Servers[0].Url)
-			allServers = appendUnique(servers, path.Value.Put.Servers[0].Url)
-		}
-		if path.Value.Delete != nil && len(path.Value.Delete.Servers) == 1 {
-			servers = appendUnique(servers, path.Value.Delete.Servers[0].Url)
-			allServers = appendUnique(servers, path.Value.Delete.Servers[0].Url)
-		}
-		if path.Value.Patch != nil && len(path.Value.Patch.Servers) == 1 {
-			servers = appendUnique(servers, path.Value.Patch.Servers[0].Url)
-			allServers = appendUnique(servers, path.Value.Patch.Servers[0].Url)
-		}
-
-		if len(servers) == 1 {
-			path.Value.Servers = []*v3.Server{{Url: servers[0]}}
-
-			if path.Value.Get != nil {
-				path.Value.Get.Servers = nil
-			}
-			if path.Value.Post != nil {
-				path.Value.Post.Servers = nil
-			}
-			if path.Value.Put != nil {
-				path.Value.Put.Servers = nil
-			}
-			if path.Value.Delete != nil {
-				path.Value.Delete.Servers = nil
-			}
-			if path.Value.Patch != nil {
-				path.Value.Patch.Servers = nil
-			}
-		}
-	}
-
-	// Set all servers on API level
-	if len(allServers) > 0 {
-		d.Servers = []*v3.Server{}
-		for _, server := range allServers {
-			d.Servers = append(d.Servers, &v3.Server{Url: server})
-		}
+	// Documents without servers are served from the default host of the options.
+	if len(d.Servers) == 0 && g.conf.DefaultHost != nil && *g.conf.DefaultHost != "" {
+		d.Servers = []*v3.Server{{Url: defaultServerURL(*g.conf.DefaultHost)}}
 	}
 
-	// If there is only 1 server, we can safely remove all path level servers
-	if len(allServers) == 1 {
-		for _, path := range d.Paths.Path {
-			path.Value.Servers = nil
-		}
-	}
+	// Move the servers of operations up to their paths and to the document where they are shared.
+	collapseServers(d)
 
-	// Sort the tags.
+	// Sort the tags. Tags with the same name keep the order of their files.
 	{
 		pairs := d.Tags
-		sort.Slice(pairs, func(i, j int) bool {
+		sort.SliceStable(pairs, func(i, j int) bool {
 			return pairs[i].Name < pairs[j].Name
 		})
 		d.Tags = pairs
@@ -242,18 +431,102 @@ func (g *OpenAPIv3Generator) buildDocumentV3() *v3.Document {
 		})
 		d.Components.Schemas.AdditionalProperties = pairs
 	}
+	g.dedupeIdenticalSchemas(d)
+	g.sanitizeDescriptions(d)
+	if g.conf.NoComponents != nil && *g.conf.NoComponents {
+		g.inlineComponents(d)
+	}
 	return d
 }
 
+// defaultTitle returns the title of a document whose annotations don't set one. The
+// title_template option is expanded with the packages and services of the document and
+// the title option is used as it is. Otherwise the title is derived from the only service
+// of the document or, in source_relative mode, from the package of its file.
+func (g *OpenAPIv3Generator) defaultTitle(d *v3.Document) string {
+	packages := []string{}
+	for _, file := range g.inputFiles {
+		if file.Generate && !contains(packages, string(file.Desc.Package())) {
+			packages = append(packages, string(file.Desc.Package()))
+		}
+	}
+	if g.conf.TitleTemplate != nil && *g.conf.TitleTemplate != "" {
+		return strings.NewReplacer(
+			"{package}", strings.Join(packages, ", "),
+			"{service}", strings.Join(g.services, ", "),
+		).Replace(*g.conf.TitleTemplate)
+	}
+	if *g.conf.Title != "" {
+		return *g.conf.Title
+	}
+	if len(d.Tags) == 1 && d.Tags[0].Name != "" {
+		return d.Tags[0].Name + " API"
+	}
+	if g.conf.OutputMode != nil && *g.conf.OutputMode == "source_relative" && len(packages) == 1 {
+		return packages[0] + " API"
+	}
+	return ""
+}
+
 // filterCommentString removes linter rules from comments.
 func (g *OpenAPIv3Generator) filterCommentString(c protogen.Comments) string {
 	comment := g.linterRulePattern.ReplaceAllString(string(c), "")
 	return strings.TrimSpace(comment)
 }
 
+// enumDescription returns the description of a field followed by a list of the values of
+// its enum, or of the enum of the values of a map field, with their comments, like
+// "- DRAFT: The message isn't sent yet.", if any of the values have comments or are
+// deprecated. Values are written as numbers unless enum_type is string, so the list gives
+// their numbers too, like "- 1 (DRAFT): The message isn't sent yet.".
+func (g *OpenAPIv3Generator) enumDescription(field *protogen.Field, description string) string {
+	enum := fieldEnum(field)
+	if enum == nil {
+		return description
+	}
+	commented := false
+	lines := make([]string, 0, len(enum.Values))
+	for _, value := range enum.Values {
+		line := "- " + string(value.Desc.Name())
+		if g.conf.EnumType == nil || *g.conf.EnumType != "string" {
+			line = fmt.Sprintf("- %d (%s)", value.Desc.Number(), value.Desc.Name())
+		}
+		comment := strings.Join(strings.Fields(g.filterCommentString(value.Comments.Leading)), " ")
+		if isDeprecated(value.Desc) {
+			comment = strings.TrimSpace(comment + " Deprecated.")
+		}
+		if comment != "" {
+			commented = true
+			line += ": " + comment
+		}
+		lines = append(lines, line)
+	}
+	if !commented {
+		return description
+	}
+	return strings.TrimSpace(description + "\n\n" + strings.Join(lines, "\n"))
+}
+
+// fieldEnum returns the enum of a field, or the enum of the values of a map field, or nil if
+// the field has none.
+func fieldEnum(field *protogen.Field) *protogen.Enum {
+	if field.Desc.IsMap() {
+		return field.Message.Fields[1].Enum
+	}
+	return field.Enum
+}
+
+// findField finds the field of a message that a path template or a body refers to by its
+// proto name or, failing that, by its JSON name, so that a field whose JSON name is the
+// proto name of another field isn't found instead of that field.
 func (g *OpenAPIv3Generator) findField(name string, inMessage *protogen.Message) *protogen.Field {
 	for _, field := range inMessage.Fields {
-		if string(field.Desc.Name()) == name || string(field.Desc.JSONName()) == name {
+		if string(field.Desc.Name()) == name {
+			return field
+		}
+	}
+	for _, field := range inMessage.Fields {
+		if field.Desc.JSONName() == name {
 			return field
 		}
 	}
@@ -261,13 +534,125 @@ func (g *OpenAPIv3Generator) findField(name string, inMessage *protogen.Message)
 	return nil
 }
 
+// findFieldPath finds the fields along a dotted path of fields of a message, like the
+// variable {shelf.name} of a path template, with findField. It returns nil if the message
+// has no such fields.
+func (g *OpenAPIv3Generator) findFieldPath(name string, inMessage *protogen.Message) []*protogen.Field {
+	parts := strings.Split(name, ".")
+	fields := make([]*protogen.Field, 0, len(parts))
+	message := inMessage
+	for _, part := range parts {
+		if message == nil {
+			return nil
+		}
+		field := g.findField(part, message)
+		if field == nil {
+			return nil
+		}
+		fields = append(fields, field)
+		message = field.Message
+	}
+	return fields
+}
+
+// fieldProtoName returns the proto name of the field of a message that name refers to,
+// which is how fields are excluded from query parameters and bodies, or name itself if
+// the message has no such field. Dotted paths of fields, like the variables of path
+// templates, are returned as the dotted proto names of the fields along the path.
+func (g *OpenAPIv3Generator) fieldProtoName(name string, inMessage *protogen.Message) string {
+	fields := g.findFieldPath(name, inMessage)
+	if fields == nil {
+		return name
+	}
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = string(field.Desc.Name())
+	}
+	return strings.Join(names, ".")
+}
+
+// findAndFormatFieldName returns the name of the field of a message that name refers to,
+// as it is written in the document, or name itself if the message has no such field.
+// Dotted paths of fields are returned as the dotted names of the fields along the path,
+// like the names of the query parameters of the fields of message fields.
 func (g *OpenAPIv3Generator) findAndFormatFieldName(name string, inMessage *protogen.Message) string {
-	field := g.findField(name, inMessage)
-	if field != nil {
-		return g.reflect.formatFieldName(field.Desc)
+	fields := g.findFieldPath(name, inMessage)
+	if fields == nil {
+		return name
+	}
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = g.reflect.formatFieldName(field.Desc)
 	}
+	return strings.Join(names, ".")
+}
 
-	return name
+// pathParameterName returns the name of the path parameter of a variable of a path template,
+// or of a collection segment like shelves in shelves/*. With json_names=false, it is the
+// name that is written in the template.
+func (g *OpenAPIv3Generator) pathParameterName(name string, inMessage *protogen.Message) string {
+	if !g.conf.jsonNames() {
+		return name
+	}
+	return g.findAndFormatFieldName(name, inMessage)
+}
+
+// isDeprecated returns true if a field, method, message or enum value has the deprecated option.
+func isDeprecated(desc protoreflect.Descriptor) bool {
+	options, ok := desc.Options().(interface{ GetDeprecated() bool })
+	return ok && options.GetDeprecated()
+}
+
+// deprecatedDescription appends a note to the description of a deprecated message, since the
+// schema of a message can be used in places where deprecated: true wouldn't be shown.
+func deprecatedDescription(message protoreflect.MessageDescriptor, description string) string {
+	if !isDeprecated(message) {
+		return description
+	}
+	return strings.TrimSpace(description + "\n\nDeprecated.")
+}
+
+// isRequiredField returns true if a field has the REQUIRED google.api.field_behavior.
+func isRequiredField(field protoreflect.FieldDescriptor) bool {
+	behaviors, ok := proto.GetExtension(field.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	return ok && slices.Contains(behaviors, annotations.FieldBehavior_REQUIRED)
+}
+
+// allowReservedExtension is the extension of an openapi.v3.property annotation that makes
+// the query parameter of a field allowReserved, for values like filter expressions that
+// contain reserved characters. It sets the AllowReserved field of the parameter and isn't
+// written to the schema of the field: property annotations are schemas, which have no
+// allowReserved field, and there is no parameter annotation.
+const allowReservedExtension = "x-allow-reserved"
+
+// isAllowReservedField returns true if the property annotation of a field has a true
+// x-allow-reserved extension.
+func isAllowReservedField(field protoreflect.FieldDescriptor) bool {
+	property, _ := proto.GetExtension(field.Options(), v3.E_Property).(*v3.Schema)
+	for _, extension := range property.GetSpecificationExtension() {
+		if extension.Name == allowReservedExtension {
+			var allowReserved bool
+			return yaml.Unmarshal([]byte(extension.Value.GetYaml()), &allowReserved) == nil && allowReserved
+		}
+	}
+	return false
+}
+
+// fieldProperty returns the property annotation of a field without its x-allow-reserved
+// extension, or nil if the field has no other annotations.
+func fieldProperty(field protoreflect.FieldDescriptor) *v3.Schema {
+	property, _ := proto.GetExtension(field.Options(), v3.E_Property).(*v3.Schema)
+	if property == nil {
+		return nil
+	}
+	property = proto.Clone(property).(*v3.Schema)
+	property.SpecificationExtension = slices.DeleteFunc(property.SpecificationExtension, func(extension *v3.NamedAny) bool {
+		return extension.Name == allowReservedExtension
+	})
+	if proto.Size(property) == 0 {
+		return nil
+	}
+	return property
 }
 
 // Note that fields which are mapped to URL query parameters must have a primitive type
@@ -279,24 +664,53 @@ func (g *OpenAPIv3Generator) findAndFormatFieldName(name string, inMessage *prot
 // - for wrapper types it will use the same representation as the wrapped primitive type in JSON
 // - for google.protobuf.timestamp type it will be serialized as a string
 //
-// maps, Struct and Empty can NOT be used
+// maps, Struct and Empty can NOT be used, and maps and repeated messages are skipped with a warning
 // messages can have any number of sub messages - including circular (e.g. sub.subsub.sub.subsub.id)
 
-// buildQueryParamsV3 extracts any valid query params, including sub and recursive messages
-func (g *OpenAPIv3Generator) buildQueryParamsV3(field *protogen.Field) []*v3.ParameterOrReference {
+// buildQueryParamsV3 extracts any valid query params, including sub and recursive messages.
+// The fields of sub messages whose dotted proto paths are covered, like the fields of path
+// variables such as {shelf.name}, are left out.
+func (g *OpenAPIv3Generator) buildQueryParamsV3(method *protogen.Method, field *protogen.Field, covered []string) []*v3.ParameterOrReference {
 	depths := map[string]int{}
-	return g._buildQueryParamsV3(field, depths)
+	truncated := map[string]bool{}
+	skipped := map[string]string{}
+	parameters := g._buildQueryParamsV3(field, string(field.Desc.Name()), covered, depths, truncated, skipped)
+	messages := make([]string, 0, len(truncated))
+	for message := range truncated {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	for _, message := range messages {
+		g.addWarning("query parameters of %s for field %s were truncated at depth %d in message %s",
+			method.Desc.FullName(), field.Desc.Name(), *g.conf.CircularDepth, message)
+	}
+	paths := make([]string, 0, len(skipped))
+	for path := range skipped {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		g.addWarning("the query parameter %s of %s was skipped because it is a %s", path, method.Desc.FullName(), skipped[path])
+	}
+	return parameters
 }
 
+// path is the dotted proto path of the field and covered are the paths that are left out
 // depths are used to keep track of how many times a message's fields has been seen
-func (g *OpenAPIv3Generator) _buildQueryParamsV3(field *protogen.Field, depths map[string]int) []*v3.ParameterOrReference {
+// truncated collects the names of the messages whose fields were left out because of the depth
+// skipped collects the paths of the map and repeated message fields, which can't be query parameters
+func (g *OpenAPIv3Generator) _buildQueryParamsV3(field *protogen.Field, path string, covered []string, depths map[string]int, truncated map[string]bool, skipped map[string]string) []*v3.ParameterOrReference {
 	parameters := []*v3.ParameterOrReference{}
 
 	queryFieldName := g.reflect.formatFieldName(field.Desc)
-	fieldDescription := g.filterCommentString(field.Comments.Leading)
+	fieldDescription := g.enumDescription(field, g.filterCommentString(field.Comments.Leading))
+	required := isRequiredField(field.Desc) || g.reflect.isValidateRequiredField(field.Desc)
+	allowReserved := isAllowReservedField(field.Desc)
+	deprecated := isDeprecated(field.Desc)
 
 	if field.Desc.IsMap() {
 		// Map types are not allowed in query parameteres
+		skipped[path] = "map field"
 		return parameters
 
 	} else if field.Desc.Kind() == protoreflect.MessageKind {
@@ -309,11 +723,13 @@ func (g *OpenAPIv3Generator) _buildQueryParamsV3(field *protogen.Field, depths m
 				&v3.ParameterOrReference{
 					Oneof: &v3.ParameterOrReference_Parameter{
 						Parameter: &v3.Parameter{
-							Name:        queryFieldName,
-							In:          "query",
-							Description: fieldDescription,
-							Required:    false,
-							Schema:      fieldSchema,
+							Name:          queryFieldName,
+							In:            "query",
+							Description:   fieldDescription,
+							Required:      required,
+							Schema:        fieldSchema,
+							AllowReserved: allowReserved,
+							Deprecated:    deprecated,
 						},
 					},
 				})
@@ -328,42 +744,37 @@ func (g *OpenAPIv3Generator) _buildQueryParamsV3(field *protogen.Field, depths m
 				&v3.ParameterOrReference{
 					Oneof: &v3.ParameterOrReference_Parameter{
 						Parameter: &v3.Parameter{
-							Name:        queryFieldName,
-							In:          "query",
-							Description: fieldDescription,
-							Required:    false,
-							Schema:      fieldSchema,
+							Name:          queryFieldName,
+							In:            "query",
+							Description:   fieldDescription,
+							Required:      required,
+							Schema:        fieldSchema,
+							AllowReserved: allowReserved,
+							Deprecated:    deprecated,
 						},
 					},
 				})
 			return parameters
 
-		case ".google.protobuf.Timestamp":
-			fieldSchema := g.reflect.schemaOrReferenceForMessage(field.Message.Desc)
-			parameters = append(parameters,
-				&v3.ParameterOrReference{
-					Oneof: &v3.ParameterOrReference_Parameter{
-						Parameter: &v3.Parameter{
-							Name:        queryFieldName,
-							In:          "query",
-							Description: fieldDescription,
-							Required:    false,
-							Schema:      fieldSchema,
-						},
-					},
-				})
-			return parameters
-		case ".google.protobuf.Duration":
-			fieldSchema := g.reflect.schemaOrReferenceForMessage(field.Message.Desc)
+		case ".google.protobuf.Timestamp", ".google.protobuf.Duration", ".google.protobuf.FieldMask":
+			// These are written as strings, so they are represented directly (not expanded),
+			// and repeated fields are arrays of strings.
+			fieldSchema := g.queryParameterSchemaForWellKnownType(typeName)
+			if field.Desc.IsList() {
+				fieldSchema = wk.NewListSchema(fieldSchema)
+			}
 			parameters = append(parameters,
 				&v3.ParameterOrReference{
 					Oneof: &v3.ParameterOrReference_Parameter{
 						Parameter: &v3.Parameter{
-							Name:        queryFieldName,
-							In:          "query",
-							Description: fieldDescription,
-							Required:    false,
-							Schema:      fieldSchema,
+							Name:          queryFieldName,
+							In:            "query",
+							Description:   fieldDescription,
+							Required:      required,
+							Schema:        fieldSchema,
+							Explode:       field.Desc.IsList(),
+							AllowReserved: allowReserved,
+							Deprecated:    deprecated,
 						},
 					},
 				})
@@ -372,30 +783,17 @@ func (g *OpenAPIv3Generator) _buildQueryParamsV3(field *protogen.Field, depths m
 
 		if field.Desc.IsList() {
 			// Only non-repeated message types are valid
-			return parameters
-		}
-
-		// Represent field masks directly as strings (don't expand them).
-		if typeName == ".google.protobuf.FieldMask" {
-			fieldSchema := g.reflect.schemaOrReferenceForField(field.Desc)
-			parameters = append(parameters,
-				&v3.ParameterOrReference{
-					Oneof: &v3.ParameterOrReference_Parameter{
-						Parameter: &v3.Parameter{
-							Name:        queryFieldName,
-							In:          "query",
-							Description: fieldDescription,
-							Required:    false,
-							Schema:      fieldSchema,
-						},
-					},
-				})
+			skipped[path] = "repeated message field"
 			return parameters
 		}
 
 		// Sub messages are allowed, even circular, as long as the final type is a primitive.
 		// Go through each of the sub message fields
 		for _, subField := range field.Message.Fields {
+			subFieldPath := path + "." + string(subField.Desc.Name())
+			if contains(covered, subFieldPath) {
+				continue
+			}
 			subFieldFullName := string(subField.Desc.FullName())
 			seen, ok := depths[subFieldFullName]
 			if !ok {
@@ -404,29 +802,39 @@ func (g *OpenAPIv3Generator) _buildQueryParamsV3(field *protogen.Field, depths m
 
 			if seen < *g.conf.CircularDepth {
 				depths[subFieldFullName]++
-				subParams := g._buildQueryParamsV3(subField, depths)
+				subParams := g._buildQueryParamsV3(subField, subFieldPath, covered, depths, truncated, skipped)
 				for _, subParam := range subParams {
 					if param, ok := subParam.Oneof.(*v3.ParameterOrReference_Parameter); ok {
 						param.Parameter.Name = queryFieldName + "." + param.Parameter.Name
+						// The fields of a message are only required if the message is.
+						param.Parameter.Required = param.Parameter.Required && required
+						// They are deprecated if the message is.
+						param.Parameter.Deprecated = param.Parameter.Deprecated || deprecated
 						parameters = append(parameters, subParam)
 					}
 				}
+			} else {
+				truncated[string(field.Message.Desc.FullName())] = true
 			}
 		}
 
 	} else if field.Desc.Kind() != protoreflect.GroupKind {
-		// schemaOrReferenceForField also handles array types
+		// schemaOrReferenceForField also handles array types, whose values are repeated
+		// parameters like ?tag=a&tag=b
 		fieldSchema := g.reflect.schemaOrReferenceForField(field.Desc)
 
 		parameters = append(parameters,
 			&v3.ParameterOrReference{
 				Oneof: &v3.ParameterOrReference_Parameter{
 					Parameter: &v3.Parameter{
-						Name:        queryFieldName,
-						In:          "query",
-						Description: fieldDescription,
-						Required:    false,
-						Schema:      fieldSchema,
+						Name:          queryFieldName,
+						In:            "query",
+						Description:   fieldDescription,
+						Required:      required,
+						Schema:        fieldSchema,
+						Explode:       field.Desc.IsList(),
+						AllowReserved: allowReserved,
+						Deprecated:    deprecated,
 					},
 				},
 			})
@@ -435,6 +843,20 @@ func (g *OpenAPIv3Generator) _buildQueryParamsV3(field *protogen.Field, depths m
 	return parameters
 }
 
+// queryParameterSchemaForWellKnownType returns the inline schema of a query parameter for a
+// well-known type that is written as a string. Query parameters can't refer to the object
+// schemas in components, so this doesn't depend on how the type is written in bodies.
+func (g *OpenAPIv3Generator) queryParameterSchemaForWellKnownType(typeName string) *v3.SchemaOrReference {
+	switch typeName {
+	case ".google.protobuf.Timestamp":
+		return g.reflect.timestampSchema()
+	case ".google.protobuf.Duration":
+		return g.reflect.durationSchema()
+	default:
+		return wk.NewGoogleProtobufFieldMaskSchema()
+	}
+}
+
 // buildOperationV3 constructs an operation for a set of values.
 func (g *OpenAPIv3Generator) buildOperationV3(
 	d *v3.Document,
@@ -443,35 +865,49 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 	tagName string,
 	description string,
 	defaultHost string,
-	path string,
+	template *pathTemplate,
 	bodyField string,
+	responseBody string,
 	inputMessage *protogen.Message,
 	outputMessage *protogen.Message,
 ) (*v3.Operation, string) {
-	// coveredParameters tracks the parameters that have been used in the body or path.
+	// coveredParameters tracks the proto names of the parameters that have been used in the body or path.
 	coveredParameters := make([]string, 0)
+	if bodyField != "" && bodyField != "*" {
+		bodyField = g.fieldProtoName(bodyField, inputMessage)
+	}
 	if bodyField != "" {
 		coveredParameters = append(coveredParameters, bodyField)
 	}
 	// Initialize the list of operation parameters.
 	parameters := []*v3.ParameterOrReference{}
 
-	// Find simple path parameters like {id}
-	if allMatches := g.pathPattern.FindAllStringSubmatch(path, -1); allMatches != nil {
-		for _, matches := range allMatches {
-			// Add the value to the list of covered parameters.
-			coveredParameters = append(coveredParameters, matches[1])
-			pathParameter := g.findAndFormatFieldName(matches[1], inputMessage)
-			path = strings.Replace(path, matches[1], pathParameter, 1)
+	// Find the path parameters. A variable like {id} is a parameter of its field, and a
+	// variable like {name=shelves/*} has a parameter for each of its wildcards. Parameters
+	// of variables like {id} come first.
+	simpleParameters := []*v3.ParameterOrReference{}
+	namedParameters := []*v3.ParameterOrReference{}
+	segments := make([]string, 0, len(template.segments))
+	for _, segment := range template.segments {
+		variable := segment.variable
+		if variable == nil {
+			segments = append(segments, segment.literal)
+			continue
+		}
+		// Add the field to the list of covered parameters.
+		coveredParameters = append(coveredParameters, g.fieldProtoName(variable.fieldPath, inputMessage))
+		if variable.segments == nil {
+			pathParameter := g.pathParameterName(variable.fieldPath, inputMessage)
+			segments = append(segments, "{"+pathParameter+"}")
 
 			// Add the path parameters to the operation parameters.
 			var fieldSchema *v3.SchemaOrReference
 
 			var fieldDescription string
-			field := g.findField(pathParameter, inputMessage)
-			if field != nil {
+			if fields := g.findFieldPath(variable.fieldPath, inputMessage); fields != nil {
+				field := fields[len(fields)-1]
 				fieldSchema = g.reflect.schemaOrReferenceForField(field.Desc)
-				fieldDescription = g.filterCommentString(field.Comments.Leading)
+				fieldDescription = g.enumDescription(field, g.filterCommentString(field.Comments.Leading))
 			} else {
 				// If field does not exist, it is safe to set it to string, as it is ignored downstream
 				fieldSchema = &v3.SchemaOrReference{
@@ -483,7 +919,7 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 				}
 			}
 
-			parameters = append(parameters,
+			simpleParameters = append(simpleParameters,
 				&v3.ParameterOrReference{
 					Oneof: &v3.ParameterOrReference_Parameter{
 						Parameter: &v3.Parameter{
@@ -495,31 +931,19 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 						},
 					},
 				})
+			continue
 		}
-	}
 
-	// Find named path parameters like {name=shelves/*}
-	if matches := g.namedPathPattern.FindStringSubmatch(path); matches != nil {
-		// Build a list of named path parameters.
-		namedPathParameters := make([]string, 0)
-
-		// Add the "name=" "name" value to the list of covered parameters.
-		coveredParameters = append(coveredParameters, matches[1])
-		// Convert the path from the starred form to use named path parameters.
-		starredPath := matches[2]
-		parts := strings.Split(starredPath, "/")
-		// The starred path is assumed to be in the form "things/*/otherthings/*".
-		// We want to convert it to "things/{thingsId}/otherthings/{otherthingsId}".
+		// The variable's pattern is assumed to be in the form "things/*/otherthings/*".
+		// We want to convert it to "things/{thing}/otherthings/{otherthing}".
+		parts := slices.Clone(variable.segments)
+		names := make([]string, 0)
 		for i := 0; i < len(parts)-1; i += 2 {
-			section := parts[i]
-			namedPathParameter := g.findAndFormatFieldName(section, inputMessage)
-			namedPathParameter = singular(namedPathParameter)
+			namedPathParameter := singular(g.pathParameterName(parts[i], inputMessage))
 			parts[i+1] = "{" + namedPathParameter + "}"
-			namedPathParameters = append(namedPathParameters, namedPathParameter)
+			names = append(names, namedPathParameter)
 		}
-		// Rewrite the path to use the path parameters.
-		newPath := strings.Join(parts, "/")
-		path = strings.Replace(path, matches[0], newPath, 1)
+		segments = append(segments, parts...)
 
 		// Build a map of path parameter hints from the method options.
 		hints := map[string]*v3.PathParamHint{}
@@ -532,7 +956,7 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 		}
 
 		// Add the named path parameters to the operation parameters.
-		for _, namedPathParameter := range namedPathParameters {
+		for _, namedPathParameter := range names {
 			param := &v3.Parameter{
 				Name:        namedPathParameter,
 				In:          "path",
@@ -590,25 +1014,41 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 					}
 				}
 			}
-			parameters = append(parameters, &v3.ParameterOrReference{
+			namedParameters = append(namedParameters, &v3.ParameterOrReference{
 				Oneof: &v3.ParameterOrReference_Parameter{Parameter: param},
 			})
 		}
 	}
+	parameters = append(parameters, simpleParameters...)
+	parameters = append(parameters, namedParameters...)
+	path := template.path(segments)
 
 	// Add any unhandled fields in the request message as query parameters.
 	if bodyField != "*" && string(inputMessage.Desc.FullName()) != "google.api.HttpBody" {
 		for _, field := range inputMessage.Fields {
 			fieldName := string(field.Desc.Name())
 			if !contains(coveredParameters, fieldName) && fieldName != bodyField {
-				fieldParams := g.buildQueryParamsV3(field)
+				fieldParams := g.buildQueryParamsV3(method, field, coveredParameters)
 				parameters = append(parameters, fieldParams...)
 			}
 		}
 	}
 
-	// Create the response.
-	name, content := g.reflect.responseContentForMessage(outputMessage.Desc)
+	// Create the response. A response_body field of the rule is the body of the response
+	// in place of the output message.
+	var name string
+	var content *v3.MediaTypes
+	if responseBody == "" {
+		name, content = g.reflect.responseContentForMessage(outputMessage.Desc)
+	} else if field := g.findField(responseBody, outputMessage); field == nil {
+		g.addError("the response_body %q of %s isn't a field of %s", responseBody, method.Desc.FullName(), outputMessage.Desc.FullName())
+		name, content = "200", &v3.MediaTypes{}
+	} else {
+		name, content = "200", wk.NewApplicationJsonMediaType(g.reflect.schemaOrReferenceForField(field.Desc))
+	}
+	if method.Desc.IsStreamingServer() {
+		g.streamContent(content)
+	}
 	responses := &v3.Responses{
 		ResponseOrReference: []*v3.NamedResponseOrReference{
 			{
@@ -628,28 +1068,37 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 	// Add the default reponse if needed
 	if *g.conf.DefaultResponse {
 		anySchemaName := g.reflect.formatMessageName(anyProtoDesc)
-		anySchema := wk.NewGoogleProtobufAnySchema(anySchemaName)
-		g.addSchemaToDocumentV3(d, anySchema)
+		g.reflect.schemaMessages.add(anySchemaName, string(anyProtoDesc.FullName()))
+		g.addSchemaToDocumentV3(d, anySchemaName, func() *v3.NamedSchemaOrReference {
+			return g.anySchema(anySchemaName)
+		})
 
 		statusSchemaName := g.reflect.formatMessageName(statusProtoDesc)
-		statusSchema := wk.NewGoogleRpcStatusSchema(statusSchemaName, anySchemaName)
-		g.addSchemaToDocumentV3(d, statusSchema)
-
-		defaultResponse := &v3.NamedResponseOrReference{
-			Name: "default",
-			Value: &v3.ResponseOrReference{
-				Oneof: &v3.ResponseOrReference_Response{
-					Response: &v3.Response{
-						Description: "Default error response",
-						Content: wk.NewApplicationJsonMediaType(&v3.SchemaOrReference{
-							Oneof: &v3.SchemaOrReference_Reference{
-								Reference: &v3.Reference{XRef: "#/components/schemas/" + statusSchemaName}}}),
-					},
-				},
-			},
+		g.reflect.schemaMessages.add(statusSchemaName, string(statusProtoDesc.FullName()))
+		g.addSchemaToDocumentV3(d, statusSchemaName, func() *v3.NamedSchemaOrReference {
+			return g.statusSchema(statusSchemaName)
+		})
+
+		defaultResponse := &v3.Response{
+			Description: "Default error response",
+			Content: wk.NewApplicationJsonMediaType(&v3.SchemaOrReference{
+				Oneof: &v3.SchemaOrReference_Reference{
+					Reference: &v3.Reference{XRef: "#/components/schemas/" + statusSchemaName}}}),
+		}
+		value := &v3.ResponseOrReference{
+			Oneof: &v3.ResponseOrReference_Response{Response: defaultResponse},
+		}
+		if g.conf.DefaultResponseRef != nil && *g.conf.DefaultResponseRef {
+			value = &v3.ResponseOrReference{
+				Oneof: &v3.ResponseOrReference_Reference{
+					Reference: &v3.Reference{XRef: "#/components/responses/" + g.defaultResponseName()}}}
 		}
 
-		responses.ResponseOrReference = append(responses.ResponseOrReference, defaultResponse)
+		responses.ResponseOrReference = append(responses.ResponseOrReference, &v3.NamedResponseOrReference{
+			Name:  "default",
+			Value: value,
+		})
+		g.defaultResponse = defaultResponse
 	}
 
 	// Create the operation.
@@ -659,6 +1108,7 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 		OperationId: operationID,
 		Parameters:  parameters,
 		Responses:   responses,
+		Deprecated:  isDeprecated(method.Desc),
 	}
 
 	if defaultHost != "" {
@@ -677,28 +1127,26 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 			// Pass the entire request message as the request body.
 			requestSchema = g.createWildcardBodyRequestSchema(d, inputMessage, coveredParameters)
 
+		} else if field := g.findField(bodyField, inputMessage); field == nil {
+			g.addError("the body %q of %s isn't a field of %s", bodyField, method.Desc.FullName(), inputMessage.Desc.FullName())
+
 		} else {
 			// If body refers to a message field, use that type.
-			for _, field := range inputMessage.Fields {
-				if string(field.Desc.Name()) == bodyField {
-					switch field.Desc.Kind() {
-					case protoreflect.StringKind:
-						requestSchema = &v3.SchemaOrReference{
-							Oneof: &v3.SchemaOrReference_Schema{
-								Schema: &v3.Schema{
-									Type: "string",
-								},
-							},
-						}
+			switch field.Desc.Kind() {
+			case protoreflect.StringKind:
+				requestSchema = &v3.SchemaOrReference{
+					Oneof: &v3.SchemaOrReference_Schema{
+						Schema: &v3.Schema{
+							Type: "string",
+						},
+					},
+				}
 
-					case protoreflect.MessageKind:
-						requestSchema = g.reflect.schemaOrReferenceForMessage(field.Message.Desc)
+			case protoreflect.MessageKind:
+				requestSchema = g.reflect.schemaOrReferenceForMessage(field.Message.Desc)
 
-					default:
-						log.Printf("unsupported field type %+v", field.Desc)
-					}
-					break
-				}
+			default:
+				log.Printf("unsupported field type %+v", field.Desc)
 			}
 		}
 
@@ -726,6 +1174,29 @@ func (g *OpenAPIv3Generator) buildOperationV3(
 // buildAndAddSchemaForMessage builds a schema for a message, optionally excluding
 // specific fields, adds it to the document, and returns a reference to it.
 func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message *protogen.Message, schemaName, description string, excludedFields []string, ref string) *v3.SchemaOrReference {
+	g.addBuiltSchemaToDocumentV3(d, schemaName, g.buildSchemaForMessage(message, description, excludedFields))
+
+	// Return reference to the schema
+	return &v3.SchemaOrReference{
+		Oneof: &v3.SchemaOrReference_Reference{
+			Reference: &v3.Reference{XRef: ref},
+		},
+	}
+}
+
+// builtSchema is the schema of a message with the names of the schemas that it references,
+// in the order that they were found.
+type builtSchema struct {
+	schema          *v3.Schema
+	requiredSchemas []string
+}
+
+// buildSchemaForMessage builds a schema for a message, optionally excluding specific fields.
+// It doesn't change the generator, so schemas can be built concurrently.
+func (g *OpenAPIv3Generator) buildSchemaForMessage(message *protogen.Message, description string, excludedFields []string) *builtSchema {
+	// Record the references of this schema separately from those of the document.
+	reflect := g.reflect.fork()
+
 	definitionProperties := &v3.Properties{
 		AdditionalProperties: make([]*v3.NamedSchemaOrReference, 0),
 	}
@@ -739,8 +1210,13 @@ func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message
 			continue
 		}
 
-		// Get the field description from the comments
+		// Get the field description from the comments, followed by the format of the keys of a
+		// map and the comments of the values of an enum
 		fieldDescription := g.filterCommentString(field.Comments.Leading)
+		if keyDescription := mapKeyDescription(field.Desc); keyDescription != "" {
+			fieldDescription = strings.TrimSpace(fieldDescription + " " + keyDescription)
+		}
+		fieldDescription = g.enumDescription(field, fieldDescription)
 
 		// Check the field annotations
 		inputOnly := false
@@ -756,7 +1232,7 @@ func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message
 					case annotations.FieldBehavior_INPUT_ONLY:
 						inputOnly = true
 					case annotations.FieldBehavior_REQUIRED:
-						required = append(required, g.reflect.formatFieldName(field.Desc))
+						required = append(required, reflect.formatFieldName(field.Desc))
 					}
 				}
 			default:
@@ -764,13 +1240,20 @@ func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message
 			}
 		}
 
-		fieldSchema := g.reflect.schemaOrReferenceForField(field.Desc)
+		if reflect.isValidateRequiredField(field.Desc) && !slices.Contains(required, reflect.formatFieldName(field.Desc)) {
+			required = append(required, reflect.formatFieldName(field.Desc))
+		}
+
+		fieldSchema := reflect.schemaOrReferenceForField(field.Desc)
 		if fieldSchema == nil {
 			continue
 		}
 
+		property := fieldProperty(field.Desc)
+		deprecated := isDeprecated(field.Desc)
+
 		// If this field has siblings and is a $ref now, create a new schema use `allOf` to wrap it
-		wrapperNeeded := inputOnly || outputOnly || fieldDescription != ""
+		wrapperNeeded := inputOnly || outputOnly || deprecated || fieldDescription != "" || property != nil
 		if wrapperNeeded {
 			if _, ok := fieldSchema.Oneof.(*v3.SchemaOrReference_Reference); ok {
 				fieldSchema = &v3.SchemaOrReference{Oneof: &v3.SchemaOrReference_Schema{Schema: &v3.Schema{
@@ -783,18 +1266,20 @@ func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message
 			schema.Schema.Description = fieldDescription
 			schema.Schema.ReadOnly = outputOnly
 			schema.Schema.WriteOnly = inputOnly
+			schema.Schema.Deprecated = deprecated
 
-			// Merge any `Property` annotations with the current
-			extProperty := proto.GetExtension(field.Desc.Options(), v3.E_Property)
-			if extProperty != nil {
-				proto.Merge(schema.Schema, extProperty.(*v3.Schema))
+			// Merge any `Property` annotations with the current. They apply to the field, so
+			// the constraints of a repeated field, like max_items, apply to its array.
+			if property != nil {
+				proto.Merge(schema.Schema, property)
+				checkExample(property.Example, field.Desc.FullName())
 			}
 		}
 
 		definitionProperties.AdditionalProperties = append(
 			definitionProperties.AdditionalProperties,
 			&v3.NamedSchemaOrReference{
-				Name:  g.reflect.formatFieldName(field.Desc),
+				Name:  reflect.formatFieldName(field.Desc),
 				Value: fieldSchema,
 			},
 		)
@@ -802,7 +1287,7 @@ func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message
 
 	schema := &v3.Schema{
 		Type:        "object",
-		Description: description,
+		Description: reflect.validateMessageDescription(message.Desc, deprecatedDescription(message.Desc, description)),
 		Properties:  definitionProperties,
 		Required:    required,
 	}
@@ -811,24 +1296,40 @@ func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message
 	extSchema := proto.GetExtension(message.Desc.Options(), v3.E_Schema)
 	if extSchema != nil {
 		proto.Merge(schema, extSchema.(*v3.Schema))
+		checkExample(schema.Example, message.Desc.FullName())
 	}
 
-	// Add the schema to the document
-	g.addSchemaToDocumentV3(d, &v3.NamedSchemaOrReference{
-		Name: schemaName,
-		Value: &v3.SchemaOrReference{
-			Oneof: &v3.SchemaOrReference_Schema{
-				Schema: schema,
-			},
-		},
-	})
+	return &builtSchema{schema: schema, requiredSchemas: reflect.requiredSchemas}
+}
 
-	// Return reference to the schema
-	return &v3.SchemaOrReference{
-		Oneof: &v3.SchemaOrReference_Reference{
-			Reference: &v3.Reference{XRef: ref},
-		},
+// checkExample logs the example of an annotation if it isn't valid YAML, since it is
+// written as null. It doesn't change the generator, so schemas can be built concurrently.
+func checkExample(example *v3.Any, name protoreflect.FullName) {
+	if example.GetYaml() == "" {
+		return
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(example.GetYaml()), &node); err != nil {
+		log.Printf("the example of %s isn't valid YAML and is written as null: %v", name, err)
+	}
+}
+
+// addBuiltSchemaToDocumentV3 adds a built schema to the document if required and
+// requires the schemas that it references.
+func (g *OpenAPIv3Generator) addBuiltSchemaToDocumentV3(d *v3.Document, schemaName string, built *builtSchema) {
+	for _, name := range built.requiredSchemas {
+		g.reflect.requireSchema(name)
 	}
+	g.addSchemaToDocumentV3(d, schemaName, func() *v3.NamedSchemaOrReference {
+		return &v3.NamedSchemaOrReference{
+			Name: schemaName,
+			Value: &v3.SchemaOrReference{
+				Oneof: &v3.SchemaOrReference_Schema{
+					Schema: built.schema,
+				},
+			},
+		}
+	})
 }
 
 // createWildcardBodyRequestSchema sets or creates the body schema for an
@@ -850,7 +1351,10 @@ func (g *OpenAPIv3Generator) buildAndAddSchemaForMessage(d *v3.Document, message
 //
 //	<RequestType>_Body
 //
-// Where the overlapping body fields are removed.
+// Where the overlapping body fields are removed. The schema of the message itself is left
+// as it is for the other operations that refer to it. Operations that remove different
+// fields from the same message get schemas of their own, named <RequestType>_Body2,
+// <RequestType>_Body3 and so on in the order of the operations.
 //
 // [transcoding HTTP/JSON to gRPC]: https://docs.cloud.google.com/endpoints/docs/grpc/transcoding#use_wildcard_in_body
 func (g *OpenAPIv3Generator) createWildcardBodyRequestSchema(d *v3.Document, message *protogen.Message, pathParameters []string) *v3.SchemaOrReference {
@@ -874,12 +1378,22 @@ func (g *OpenAPIv3Generator) createWildcardBodyRequestSchema(d *v3.Document, mes
 		return g.reflect.schemaOrReferenceForMessage(message.Desc)
 	}
 
-	requestTypeName := g.reflect.formatMessageName(message.Desc)
-	schemaName := requestTypeName + "_Body"
+	// Operations that remove the same fields share a schema.
+	key := string(message.Desc.FullName()) + " without " + strings.Join(slices.Sorted(slices.Values(excludedFields)), ",")
+	schemaName, ok := g.bodySchemas[key]
+	if !ok {
+		bodyName := g.reflect.formatSchemaCase(g.reflect.formatMessageName(message.Desc) + "_Body")
+		schemaName = bodyName
+		for i := 2; g.generatedSchemas[schemaName]; i++ {
+			schemaName = bodyName + strconv.Itoa(i)
+		}
+		g.bodySchemas[key] = schemaName
+	}
+	g.reflect.schemaMessages.add(schemaName, "the request body of "+string(message.Desc.FullName()))
 
 	ref := "#/components/schemas/" + schemaName
 
-	if contains(g.generatedSchemas, schemaName) {
+	if g.generatedSchemas[schemaName] {
 		// already generated this schema for another op, so reuse it
 		return &v3.SchemaOrReference{
 			Oneof: &v3.SchemaOrReference_Reference{
@@ -892,8 +1406,293 @@ func (g *OpenAPIv3Generator) createWildcardBodyRequestSchema(d *v3.Document, mes
 	return g.buildAndAddSchemaForMessage(d, message, schemaName, messageDescription, excludedFields, ref)
 }
 
-// addOperationToDocumentV3 adds an operation to the specified path/method.
-func (g *OpenAPIv3Generator) addOperationToDocumentV3(d *v3.Document, op *v3.Operation, path string, methodName string) {
+// streamContent changes the JSON response of a server-streaming method for the streaming
+// option: "array" makes its schema an array of the messages of the stream, and "sse" writes
+// it as text/event-stream, whose events are the messages. Other responses, like those of
+// google.api.HttpBody, are already streams of bytes.
+func (g *OpenAPIv3Generator) streamContent(content *v3.MediaTypes) {
+	if g.conf.Streaming == nil {
+		return
+	}
+	for _, mediaType := range content.GetAdditionalProperties() {
+		if mediaType.Name != "application/json" {
+			continue
+		}
+		switch *g.conf.Streaming {
+		case "array":
+			mediaType.Value.Schema = wk.NewListSchema(mediaType.Value.Schema)
+		case "sse":
+			mediaType.Name = "text/event-stream"
+		}
+	}
+}
+
+// defaultResponseName returns the name of the shared default response in components.responses.
+func (g *OpenAPIv3Generator) defaultResponseName() string {
+	if g.conf.DefaultResponseName == nil || *g.conf.DefaultResponseName == "" {
+		return "Default"
+	}
+	return *g.conf.DefaultResponseName
+}
+
+// mergeAnnotatedMediaTypes merges the media types of the request body of an operation
+// annotation into the generated ones with the same names, so that an annotation can add
+// examples without repeating the schema. Media types of annotated responses that don't have
+// a schema take the schema of the generated response that they replace. It returns a copy of
+// the annotation without the merged media types, which proto.Merge would add a second time.
+func mergeAnnotatedMediaTypes(op *v3.Operation, annotation *v3.Operation) *v3.Operation {
+	annotation = proto.Clone(annotation).(*v3.Operation)
+	if content := annotation.GetRequestBody().GetRequestBody().GetContent(); content != nil {
+		generated := op.GetRequestBody().GetRequestBody().GetContent()
+		mediaTypes := make([]*v3.NamedMediaType, 0, len(content.AdditionalProperties))
+		for _, mediaType := range content.AdditionalProperties {
+			if target := findMediaType(generated, mediaType.Name); target != nil {
+				proto.Merge(target, mediaType.Value)
+			} else {
+				mediaTypes = append(mediaTypes, mediaType)
+			}
+		}
+		content.AdditionalProperties = mediaTypes
+	}
+	for _, response := range op.GetResponses().GetResponseOrReference() {
+		var annotated *v3.Response
+		if response.Name == "default" && annotation.GetResponses().GetDefault() != nil {
+			annotated = annotation.Responses.Default.GetResponse()
+		}
+		for _, annotationResponse := range annotation.GetResponses().GetResponseOrReference() {
+			if annotationResponse.Name == response.Name {
+				annotated = annotationResponse.Value.GetResponse()
+			}
+		}
+		for _, mediaType := range annotated.GetContent().GetAdditionalProperties() {
+			target := findMediaType(response.Value.GetResponse().GetContent(), mediaType.Name)
+			if mediaType.Value != nil && mediaType.Value.Schema == nil && target != nil {
+				mediaType.Value.Schema = target.Schema
+			}
+		}
+	}
+	return annotation
+}
+
+// findMediaType returns the media type with a name, or nil if there is none.
+func findMediaType(mediaTypes *v3.MediaTypes, name string) *v3.MediaType {
+	for _, mediaType := range mediaTypes.GetAdditionalProperties() {
+		if mediaType.Name == name {
+			return mediaType.Value
+		}
+	}
+	return nil
+}
+
+// removeAnnotatedResponses removes the responses of an operation that are also declared by
+// its annotation, so that the annotation replaces them instead of adding a second response
+// with the same name. A default response of the annotation replaces the generated one.
+func removeAnnotatedResponses(op *v3.Operation, annotation *v3.Operation) {
+	if op.Responses == nil || annotation.GetResponses() == nil {
+		return
+	}
+	responses := make([]*v3.NamedResponseOrReference, 0, len(op.Responses.ResponseOrReference))
+	for _, response := range op.Responses.ResponseOrReference {
+		annotated := response.Name == "default" && annotation.Responses.Default != nil
+		for _, annotationResponse := range annotation.Responses.ResponseOrReference {
+			if annotationResponse.Name == response.Name {
+				annotated = true
+			}
+		}
+		if !annotated {
+			responses = append(responses, response)
+		}
+	}
+	op.Responses.ResponseOrReference = responses
+}
+
+// removeAnnotatedParameters removes the parameters of an operation that are also declared
+// by its annotation with the same name and location, so that the annotation replaces them
+// instead of adding a second parameter, which OpenAPI doesn't allow.
+func removeAnnotatedParameters(op *v3.Operation, annotation *v3.Operation) {
+	if len(op.Parameters) == 0 || len(annotation.GetParameters()) == 0 {
+		return
+	}
+	parameters := make([]*v3.ParameterOrReference, 0, len(op.Parameters))
+	for _, parameter := range op.Parameters {
+		annotated := false
+		for _, annotationParameter := range annotation.Parameters {
+			if p := parameter.GetParameter(); p != nil && sameParameter(p, annotationParameter.GetParameter()) {
+				annotated = true
+			}
+		}
+		if !annotated {
+			parameters = append(parameters, parameter)
+		}
+	}
+	op.Parameters = parameters
+}
+
+// sameParameter returns true if two parameters have the same name and location. The names of
+// header parameters are compared case-insensitively, like the names of HTTP headers.
+func sameParameter(a, b *v3.Parameter) bool {
+	if a.GetIn() != b.GetIn() {
+		return false
+	}
+	if a.GetIn() == "header" {
+		return strings.EqualFold(a.GetName(), b.GetName())
+	}
+	return a.GetName() == b.GetName()
+}
+
+// parameterLocations are the values of the in field of parameters.
+var parameterLocations = []string{"query", "header", "path", "cookie"}
+
+// checkAnnotatedParameters reports the parameters of the annotation of a method that have no
+// name or an unknown location, which would make the document invalid.
+func (g *OpenAPIv3Generator) checkAnnotatedParameters(method *protogen.Method, annotation *v3.Operation) {
+	for _, parameter := range annotation.GetParameters() {
+		p := parameter.GetParameter()
+		if p == nil {
+			continue
+		}
+		if p.Name == "" {
+			g.addError("a parameter of the openapi.v3.operation annotation of %s has no name", method.Desc.FullName())
+		} else if !contains(parameterLocations, p.In) {
+			g.addError("the parameter %q of the openapi.v3.operation annotation of %s is in %q, which isn't query, header, path or cookie",
+				p.Name, method.Desc.FullName(), p.In)
+		}
+	}
+}
+
+// removeRepeatedTags removes the tags of an annotation that its operation already has,
+// like the tag of the service, since proto.Merge appends them to the tags of the operation.
+func removeRepeatedTags(op *v3.Operation, annotation *v3.Operation) {
+	if len(annotation.GetTags()) == 0 {
+		return
+	}
+	tags := make([]string, 0, len(annotation.Tags))
+	for _, tag := range annotation.Tags {
+		if !contains(op.Tags, tag) && !contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	annotation.Tags = tags
+}
+
+// addDefaultResponseToDocumentV3 adds the shared default response to the components of the
+// document if an operation refers to it.
+func (g *OpenAPIv3Generator) addDefaultResponseToDocumentV3(d *v3.Document) {
+	if g.conf.DefaultResponseRef == nil || !*g.conf.DefaultResponseRef || g.defaultResponse == nil {
+		return
+	}
+	ref := "#/components/responses/" + g.defaultResponseName()
+	referenced := false
+	for _, path := range d.Paths.Path {
+		for _, op := range pathOperations(path.Value) {
+			if op.Responses == nil {
+				continue
+			}
+			for _, response := range op.Responses.ResponseOrReference {
+				if response.Value.GetReference().GetXRef() == ref {
+					referenced = true
+				}
+			}
+		}
+	}
+	if !referenced {
+		return
+	}
+	if d.Components.Responses == nil {
+		d.Components.Responses = &v3.ResponsesOrReferences{}
+	}
+	d.Components.Responses.AdditionalProperties = append(d.Components.Responses.AdditionalProperties, &v3.NamedResponseOrReference{
+		Name: g.defaultResponseName(),
+		Value: &v3.ResponseOrReference{
+			Oneof: &v3.ResponseOrReference_Response{Response: g.defaultResponse},
+		},
+	})
+}
+
+// pathOperations returns the operations of a path.
+func pathOperations(item *v3.PathItem) []*v3.Operation {
+	operations := []*v3.Operation{}
+	for _, op := range []*v3.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+		if op != nil {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+// defaultServerURL returns the URL of the server of a default host, which is served over
+// https unless it has a scheme.
+func defaultServerURL(host string) string {
+	if strings.Contains(host, "://") {
+		return host
+	}
+	return "https://" + host
+}
+
+// equalServers returns true if two lists of servers are the same.
+func equalServers(a, b []*v3.Server) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// collapseServers moves the servers of operations to their path if every operation of
+// the path has the same servers, and the servers of paths to the document if every
+// path has the same servers. Operations keep servers that differ from the others.
+func collapseServers(d *v3.Document) {
+	var shared []*v3.Server
+	allShared := len(d.Paths.Path) > 0
+	for _, path := range d.Paths.Path {
+		operations := pathOperations(path.Value)
+		if len(operations) > 0 && len(operations[0].Servers) > 0 && len(path.Value.Servers) == 0 {
+			servers := operations[0].Servers
+			same := true
+			for _, op := range operations[1:] {
+				if !equalServers(op.Servers, servers) {
+					same = false
+				}
+			}
+			if same {
+				path.Value.Servers = servers
+				for _, op := range operations {
+					op.Servers = nil
+				}
+			}
+		}
+		if len(path.Value.Servers) == 0 || shared != nil && !equalServers(path.Value.Servers, shared) {
+			allShared = false
+		} else if shared == nil {
+			shared = path.Value.Servers
+		}
+	}
+	if !allShared || len(d.Servers) > 0 && !equalServers(d.Servers, shared) {
+		return
+	}
+	d.Servers = shared
+	for _, path := range d.Paths.Path {
+		path.Value.Servers = nil
+	}
+}
+
+// addOperationToDocumentV3 adds an operation to the specified path/method and returns true
+// if it was added. Operations of different methods can't share a path and method.
+func (g *OpenAPIv3Generator) addOperationToDocumentV3(d *v3.Document, method *protogen.Method, op *v3.Operation, path string, methodName string) bool {
+	key := methodName + " " + path
+	if other, ok := g.operations[key]; ok && other != method.Desc.FullName() {
+		if g.conf.AllowDuplicatePaths != nil && *g.conf.AllowDuplicatePaths == "warn" {
+			g.addWarning("%s was skipped because %s is also bound to %s", method.Desc.FullName(), other, key)
+		} else {
+			g.addError("%s and %s are both bound to %s", other, method.Desc.FullName(), key)
+		}
+		return false
+	}
+	g.operations[key] = method.Desc.FullName()
 	var selectedPathItem *v3.NamedPathItem
 	for _, namedPathItem := range d.Paths.Path {
 		if namedPathItem.Name == path {
@@ -918,19 +1717,186 @@ func (g *OpenAPIv3Generator) addOperationToDocumentV3(d *v3.Document, op *v3.Ope
 		selectedPathItem.Value.Delete = op
 	case "PATCH":
 		selectedPathItem.Value.Patch = op
+	case "HEAD":
+		selectedPathItem.Value.Head = op
+	case "OPTIONS":
+		selectedPathItem.Value.Options = op
+	case "TRACE":
+		selectedPathItem.Value.Trace = op
+	default:
+		addCustomMethod(selectedPathItem.Value, methodName, op)
+	}
+	return true
+}
+
+// openAPIMethods are the HTTP methods that path items have operations for. Custom bindings
+// of these methods are added like the bindings of their own fields of google.api.HttpRule.
+var openAPIMethods = map[string]bool{
+	"GET": true, "PUT": true, "POST": true, "DELETE": true,
+	"OPTIONS": true, "HEAD": true, "PATCH": true, "TRACE": true,
+}
+
+// addCustomMethod adds the operation of a custom binding whose kind isn't a method of
+// OpenAPI to the x-custom-method extension of a path item, which maps kinds to operations.
+func addCustomMethod(pathItem *v3.PathItem, kind string, op *v3.Operation) {
+	var extension *v3.NamedAny
+	for _, e := range pathItem.SpecificationExtension {
+		if e.Name == "x-custom-method" {
+			extension = e
+		}
+	}
+	methods := &yaml.Node{Kind: yaml.MappingNode}
+	if extension != nil {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(extension.Value.GetYaml()), &node); err == nil && len(node.Content) > 0 {
+			methods = node.Content[0]
+		}
+	} else {
+		extension = &v3.NamedAny{Name: "x-custom-method", Value: &v3.Any{}}
+		pathItem.SpecificationExtension = append(pathItem.SpecificationExtension, extension)
+	}
+	operation, err := compiler.ExpandAliases(op.ToRawInfo())
+	if err == nil {
+		methods.Content = append(methods.Content, compiler.NewScalarNodeForString(kind), operation)
+		var value []byte
+		if value, err = yaml.Marshal(methods); err == nil {
+			extension.Value.Yaml = string(value)
+			return
+		}
+	}
+	log.Printf("the custom %s operation %s was left out: %v", kind, op.OperationId, err)
+}
+
+// securityScopes returns the scopes of the security requirements of an operation.
+func securityScopes(op *v3.Operation) []string {
+	scopes := make([]string, 0)
+	for _, requirement := range op.Security {
+		for _, scheme := range requirement.AdditionalProperties {
+			scopes = append(scopes, scheme.GetValue().GetValue()...)
+		}
+	}
+	return scopes
+}
+
+// addScopesToTag adds the sorted and deduplicated scopes of the operations of a tag to it
+// as an x-required-scopes extension.
+func addScopesToTag(tag *v3.Tag, scopes []string) {
+	if len(scopes) == 0 {
+		return
+	}
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+	value, err := yaml.Marshal(scopes)
+	if err != nil {
+		log.Printf("the scopes of %s were left out: %v", tag.Name, err)
+		return
+	}
+	tag.SpecificationExtension = append(tag.SpecificationExtension, &v3.NamedAny{
+		Name:  "x-required-scopes",
+		Value: &v3.Any{Yaml: string(value)},
+	})
+}
+
+// operationID returns the operationId of the operations of a method, following the
+// operation_id option, or the full name of the method with its dots replaced by underscores
+// if its operationId would be the same as that of a method of another package.
+func (g *OpenAPIv3Generator) operationID(method *protogen.Method) string {
+	switch {
+	case g.qualifiedMethods[method.Desc.FullName()]:
+		return strings.ReplaceAll(string(method.Desc.FullName()), ".", "_")
+	case g.conf.OperationID == nil:
+	case *g.conf.OperationID == "method":
+		return method.GoName
+	case *g.conf.OperationID == "fqn":
+		return strings.ReplaceAll(string(method.Desc.FullName()), ".", "_")
 	}
+	return method.Parent.GoName + "_" + method.GoName
+}
+
+// addOperationID records the generated operationId of a method. It returns false, with an
+// error, if another method has the same operationId. The bindings of a method share it.
+func (g *OpenAPIv3Generator) addOperationID(method *protogen.Method, operationID string) bool {
+	if other, ok := g.operationIDs[operationID]; ok && other != method.Desc.FullName() {
+		g.addError("%s and %s have the same operationId %s", other, method.Desc.FullName(), operationID)
+		return false
+	}
+	g.operationIDs[operationID] = method.Desc.FullName()
+	return true
+}
+
+// tagName returns the name of the tag of the operations of a service. It is the part of
+// their operationIds before the name of the method, except with operation_id=method, or
+// the package of the service with tags=package. The tags of services whose names are the
+// same as those of services of other packages are qualified like with operation_id=fqn.
+func (g *OpenAPIv3Generator) tagName(service *protogen.Service) string {
+	if g.packageTags() {
+		return string(service.Desc.ParentFile().Package())
+	}
+	if g.conf.OperationID != nil && *g.conf.OperationID == "fqn" || g.qualifiedServices[service.Desc.FullName()] {
+		return strings.ReplaceAll(string(service.Desc.FullName()), ".", "_")
+	}
+	return service.GoName
+}
+
+// tagDescription returns the description of the tag of the operations of a service, the
+// comment of the service or, with tags=package, the comment of the package statement of
+// its file.
+func (g *OpenAPIv3Generator) tagDescription(service *protogen.Service) string {
+	if g.packageTags() {
+		// The package statement is field 2 of google.protobuf.FileDescriptorProto.
+		file := service.Desc.ParentFile()
+		location := file.SourceLocations().ByPath(protoreflect.SourcePath{2})
+		return g.filterCommentString(protogen.Comments(location.LeadingComments))
+	}
+	return g.filterCommentString(service.Comments.Leading)
+}
+
+// packageTags returns true if operations are tagged with the packages of their services.
+func (g *OpenAPIv3Generator) packageTags() bool {
+	return g.conf.Tags != nil && *g.conf.Tags == "package"
+}
+
+// mergeTags merges the tags with the same name into the first of them, which takes the
+// description, external docs and extensions of the others if it has none of its own.
+func mergeTags(tags []*v3.Tag) []*v3.Tag {
+	merged := make([]*v3.Tag, 0, len(tags))
+	names := make(map[string]*v3.Tag)
+	for _, tag := range tags {
+		first, ok := names[tag.Name]
+		if !ok {
+			names[tag.Name] = tag
+			merged = append(merged, tag)
+			continue
+		}
+		if first.Description == "" {
+			first.Description = tag.Description
+		}
+		if first.ExternalDocs == nil {
+			first.ExternalDocs = tag.ExternalDocs
+		}
+		for _, extension := range tag.SpecificationExtension {
+			if !slices.ContainsFunc(first.SpecificationExtension, func(other *v3.NamedAny) bool {
+				return other.Name == extension.Name
+			}) {
+				first.SpecificationExtension = append(first.SpecificationExtension, extension)
+			}
+		}
+	}
+	return merged
 }
 
 // addPathsToDocumentV3 adds paths from a specified file descriptor.
 func (g *OpenAPIv3Generator) addPathsToDocumentV3(d *v3.Document, services []*protogen.Service) {
 	for _, service := range services {
 		annotationsCount := 0
+		unannotated := make([]string, 0)
+		scopes := make([]string, 0)
 
 		for _, method := range service.Methods {
 			comment := g.filterCommentString(method.Comments.Leading)
 			inputMessage := method.Input
 			outputMessage := method.Output
-			operationID := service.GoName + "_" + method.GoName
+			operationID := g.operationID(method)
 
 			rules := make([]*annotations.HttpRule, 0)
 
@@ -941,6 +1907,18 @@ func (g *OpenAPIv3Generator) addPathsToDocumentV3(d *v3.Document, services []*pr
 				rule := extHTTP.(*annotations.HttpRule)
 				rules = append(rules, rule)
 				rules = append(rules, rule.AdditionalBindings...)
+			} else {
+				unannotated = append(unannotated, string(method.Desc.FullName()))
+			}
+
+			if len(rules) > 0 && (method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer()) {
+				if g.conf.Streaming != nil && *g.conf.Streaming == "skip" {
+					g.addWarning("%s was skipped because it is a streaming method", method.Desc.FullName())
+					continue
+				}
+				if method.Desc.IsStreamingClient() {
+					g.addWarning("the request body of %s describes a single message of its client stream", method.Desc.FullName())
+				}
 			}
 
 			for _, rule := range rules {
@@ -949,6 +1927,7 @@ func (g *OpenAPIv3Generator) addPathsToDocumentV3(d *v3.Document, services []*pr
 				var body string
 
 				body = rule.Body
+				responseBody := rule.ResponseBody
 				switch pattern := rule.Pattern.(type) {
 				case *annotations.HttpRule_Get:
 					path = pattern.Get
@@ -966,42 +1945,81 @@ func (g *OpenAPIv3Generator) addPathsToDocumentV3(d *v3.Document, services []*pr
 					path = pattern.Patch
 					methodName = "PATCH"
 				case *annotations.HttpRule_Custom:
-					path = "custom-unsupported"
+					path = pattern.Custom.GetPath()
+					methodName = strings.ToUpper(pattern.Custom.GetKind())
+					if !openAPIMethods[methodName] {
+						methodName = pattern.Custom.GetKind()
+						if g.conf.CustomVerbs == nil || *g.conf.CustomVerbs != "extension" {
+							methodName = ""
+							g.addWarning("%s was skipped because its custom %s binding is not a method of OpenAPI", method.Desc.FullName(), pattern.Custom.GetKind())
+						}
+					}
 				default:
 					path = "unknown-unsupported"
+					g.addWarning("%s was skipped because it has a binding without a pattern", method.Desc.FullName())
 				}
 
 				if methodName != "" {
+					template, err := parsePathTemplate(path)
+					if err != nil {
+						g.addError("the path %q of %s is malformed: %s", path, method.Desc.FullName(), err)
+						continue
+					}
 					defaultHost := proto.GetExtension(service.Desc.Options(), annotations.E_DefaultHost).(string)
 
 					op, path2 := g.buildOperationV3(
-						d, method, operationID, service.GoName, comment, defaultHost, path, body, inputMessage, outputMessage)
+						d, method, operationID, g.tagName(service), comment, defaultHost, template, body, responseBody, inputMessage, outputMessage)
 
 					// Merge any `Operation` annotations with the current
 					extOperation := proto.GetExtension(method.Desc.Options(), v3.E_Operation)
+					// Generated operationIds must be unique, while those of annotations are
+					// checked by lint.
+					if extOperation.(*v3.Operation).GetOperationId() == "" && !g.addOperationID(method, operationID) {
+						continue
+					}
 					if extOperation != nil {
-						proto.Merge(op, extOperation.(*v3.Operation))
+						annotation := mergeAnnotatedMediaTypes(op, extOperation.(*v3.Operation))
+						removeAnnotatedResponses(op, annotation)
+						g.checkAnnotatedParameters(method, annotation)
+						removeAnnotatedParameters(op, annotation)
+						removeRepeatedTags(op, annotation)
+						// Servers of the annotation replace the default host of the service.
+						if len(annotation.GetServers()) > 0 {
+							op.Servers = nil
+						}
+						proto.Merge(op, annotation)
 					}
 
-					g.addOperationToDocumentV3(d, op, path2, methodName)
+					if g.addOperationToDocumentV3(d, method, op, path2, methodName) {
+						g.addOperationMapping(op.OperationId, method)
+						scopes = append(scopes, securityScopes(op)...)
+					}
 				}
 			}
 		}
 
 		if annotationsCount > 0 {
-			comment := g.filterCommentString(service.Comments.Leading)
-			d.Tags = append(d.Tags, &v3.Tag{Name: service.GoName, Description: comment})
+			tag := &v3.Tag{Name: g.tagName(service), Description: g.tagDescription(service)}
+			g.tagScopes[tag.Name] = append(g.tagScopes[tag.Name], scopes...)
+			d.Tags = append(d.Tags, tag)
+			g.services = append(g.services, service.GoName)
+			for _, name := range unannotated {
+				g.addWarning("%s was skipped because it has no HTTP annotation", name)
+			}
+		} else if len(service.Methods) > 0 {
+			g.addWarning("%s was skipped because none of its methods have HTTP annotations", service.Desc.FullName())
 		}
 	}
 }
 
-// addSchemaForMessageToDocumentV3 adds the schema to the document if required
-func (g *OpenAPIv3Generator) addSchemaToDocumentV3(d *v3.Document, schema *v3.NamedSchemaOrReference) {
-	if contains(g.generatedSchemas, schema.Name) {
+// addSchemaToDocumentV3 adds the schema with a name to the document if it hasn't been added yet.
+// The schema is only built when it is added, so each schema of the document is built once.
+func (g *OpenAPIv3Generator) addSchemaToDocumentV3(d *v3.Document, name string, build func() *v3.NamedSchemaOrReference) {
+	if g.generatedSchemas[name] {
 		return
 	}
-	g.generatedSchemas = append(g.generatedSchemas, schema.Name)
-	d.Components.Schemas.AdditionalProperties = append(d.Components.Schemas.AdditionalProperties, schema)
+	g.generatedSchemas[name] = true
+	d.Components.Schemas.AdditionalProperties = append(d.Components.Schemas.AdditionalProperties, build())
 }
 
 // addSchemasForMessagesToDocumentV3 adds info from one file descriptor.
@@ -1014,9 +2032,11 @@ func (g *OpenAPIv3Generator) addSchemasForMessagesToDocumentV3(d *v3.Document, m
 
 		schemaName := g.reflect.formatMessageName(message.Desc)
 
-		// Only generate this if we need it and haven't already generated it.
+		// Only generate this if we need it and haven't already generated it. Messages of
+		// other packages can have the same name without being referenced.
 		if !contains(g.reflect.requiredSchemas, schemaName) ||
-			contains(g.generatedSchemas, schemaName) {
+			g.generatedSchemas[schemaName] ||
+			!g.reflect.schemaMessages.uses(schemaName, string(message.Desc.FullName())) {
 			continue
 		}
 
@@ -1026,19 +2046,140 @@ func (g *OpenAPIv3Generator) addSchemasForMessagesToDocumentV3(d *v3.Document, m
 		// `google.protobuf.Value` and `google.protobuf.Any` have special JSON transcoding
 		// so we can't just reflect on the message descriptor.
 		if typeName == ".google.protobuf.Value" {
-			g.addSchemaToDocumentV3(d, wk.NewGoogleProtobufValueSchema(schemaName))
+			g.addSchemaToDocumentV3(d, schemaName, func() *v3.NamedSchemaOrReference {
+				return wk.NewGoogleProtobufValueSchema(schemaName)
+			})
 			continue
 		} else if typeName == ".google.protobuf.Any" {
-			g.addSchemaToDocumentV3(d, wk.NewGoogleProtobufAnySchema(schemaName))
+			g.addSchemaToDocumentV3(d, schemaName, func() *v3.NamedSchemaOrReference {
+				return g.anySchema(schemaName)
+			})
 			continue
 		} else if typeName == ".google.rpc.Status" {
 			anySchemaName := g.reflect.formatMessageName(anyProtoDesc)
-			g.addSchemaToDocumentV3(d, wk.NewGoogleProtobufAnySchema(anySchemaName))
-			g.addSchemaToDocumentV3(d, wk.NewGoogleRpcStatusSchema(schemaName, anySchemaName))
+			g.reflect.schemaMessages.add(anySchemaName, string(anyProtoDesc.FullName()))
+			g.addSchemaToDocumentV3(d, anySchemaName, func() *v3.NamedSchemaOrReference {
+				return g.anySchema(anySchemaName)
+			})
+			g.addSchemaToDocumentV3(d, schemaName, func() *v3.NamedSchemaOrReference {
+				return g.statusSchema(schemaName)
+			})
 			continue
 		}
 
+		if built, ok := g.builtSchemas[message]; ok {
+			g.addBuiltSchemaToDocumentV3(d, schemaName, built)
+			continue
+		}
 		ref := "#/components/schemas/" + schemaName
 		g.buildAndAddSchemaForMessage(d, message, schemaName, messageDescription, nil, ref)
 	}
 }
+
+// statusSchema returns the schema of google.rpc.Status. Its fields are built like the fields
+// of other messages, so that they follow the same options, with the descriptions of the
+// wellknown package, since the descriptor of google.rpc.Status has no comments.
+func (g *OpenAPIv3Generator) statusSchema(schemaName string) *v3.NamedSchemaOrReference {
+	status := wk.NewGoogleRpcStatusSchema(schemaName, g.reflect.formatMessageName(anyProtoDesc))
+	for _, property := range status.Value.GetSchema().Properties.AdditionalProperties {
+		field := statusProtoDesc.Fields().ByName(protoreflect.Name(property.Name))
+		if field == nil {
+			continue
+		}
+		schema := g.reflect.schemaOrReferenceForField(field)
+		if schema.GetSchema() != nil {
+			schema.GetSchema().Description = property.Value.GetSchema().GetDescription()
+		}
+		property.Name = g.reflect.formatFieldName(field)
+		property.Value = schema
+	}
+	return status
+}
+
+// anySchema returns the schema of google.protobuf.Any. Its @type property is built from the
+// type_url field like the fields of other messages, so that it follows the same options. The
+// JSON mapping of Any always names it @type, and writes the fields of the packed message next
+// to it, which are the additional properties of the schema.
+func (g *OpenAPIv3Generator) anySchema(schemaName string) *v3.NamedSchemaOrReference {
+	anyMessage := wk.NewGoogleProtobufAnySchema(schemaName)
+	for _, property := range anyMessage.Value.GetSchema().Properties.AdditionalProperties {
+		if property.Name != "@type" {
+			continue
+		}
+		schema := g.reflect.schemaOrReferenceForField(anyProtoDesc.Fields().ByName("type_url"))
+		if schema.GetSchema() != nil {
+			schema.GetSchema().Description = property.Value.GetSchema().GetDescription()
+		}
+		property.Value = schema
+	}
+	return anyMessage
+}
+
+// hasWellKnownSchema returns true if the schema of a message is written without reflecting on it.
+func hasWellKnownSchema(typeName string) bool {
+	return typeName == ".google.protobuf.Value" || typeName == ".google.protobuf.Any" || typeName == ".google.rpc.Status"
+}
+
+// prebuildSchemas builds the schemas of the messages that are required so far and of the
+// messages that they require, transitively, with a pool of workers. Messages are built in
+// waves: each wave builds the messages named by the references of the previous one.
+func (g *OpenAPIv3Generator) prebuildSchemas() {
+	workers := g.conf.workers()
+	if workers < 2 {
+		return
+	}
+	// Find the messages with each schema name. Names can be shared by messages of different packages.
+	messagesByName := make(map[string][]*protogen.Message)
+	var addMessages func(messages []*protogen.Message)
+	addMessages = func(messages []*protogen.Message) {
+		for _, message := range messages {
+			addMessages(message.Messages)
+			if hasWellKnownSchema(g.reflect.fullMessageTypeName(message.Desc)) {
+				continue
+			}
+			schemaName := g.reflect.formatMessageName(message.Desc)
+			messagesByName[schemaName] = append(messagesByName[schemaName], message)
+		}
+	}
+	for _, file := range g.files {
+		addMessages(file.Messages)
+	}
+	queued := make(map[string]bool)
+	pending := g.reflect.requiredSchemas
+	for len(pending) > 0 {
+		wave := make([]*protogen.Message, 0)
+		for _, schemaName := range pending {
+			if !queued[schemaName] && !g.generatedSchemas[schemaName] {
+				queued[schemaName] = true
+				for _, message := range messagesByName[schemaName] {
+					if g.reflect.schemaMessages.uses(schemaName, string(message.Desc.FullName())) {
+						wave = append(wave, message)
+					}
+				}
+			}
+		}
+		built := make([]*builtSchema, len(wave))
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers && w < len(wave); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					message := wave[i]
+					built[i] = g.buildSchemaForMessage(message, g.filterCommentString(message.Comments.Leading), nil)
+				}
+			}()
+		}
+		for i := range wave {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+		pending = nil
+		for i, message := range wave {
+			g.builtSchemas[message] = built[i]
+			pending = append(pending, built[i].requiredSchemas...)
+		}
+	}
+}
@@ -0,0 +1,518 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+// largeRequest returns a plugin request for a generated file that describes a number of
+// resources, each with a service of three methods and nine messages.
+func largeRequest(resources int) *pluginpb.CodeGeneratorRequest {
+	field := func(name string, number int32, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if repeated {
+			f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		}
+		switch typeName {
+		case "string":
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+		case "int64":
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()
+		default:
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	method := func(name, input, output string, rule *annotations.HttpRule) *descriptorpb.MethodDescriptorProto {
+		options := &descriptorpb.MethodOptions{}
+		proto.SetExtension(options, annotations.E_Http, rule)
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".bench.v1." + input),
+			OutputType: proto.String(".bench.v1." + output),
+			Options:    options,
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("bench/v1/bench.proto"),
+		Package:    proto.String("bench.v1"),
+		Dependency: []string{"google/api/annotations.proto", "google/protobuf/timestamp.proto"},
+		Syntax:     proto.String("proto3"),
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/bench/v1;bench")},
+	}
+	for i := 0; i < resources; i++ {
+		resource := fmt.Sprintf("Resource%d", i)
+		details := []string{fmt.Sprintf("Detail%d", 2*i), fmt.Sprintf("Detail%d", 2*i+1)}
+		items := []string{fmt.Sprintf("Item%d", 2*i), fmt.Sprintf("Item%d", 2*i+1)}
+		// Details and items also refer to those of the next resource, and items refer back to details.
+		next := (i + 1) % resources
+		file.MessageType = append(file.MessageType,
+			message(resource,
+				field("name", 1, "string", false),
+				field("create_time", 2, ".google.protobuf.Timestamp", false),
+				field("primary", 3, ".bench.v1."+details[0], false),
+				field("secondary", 4, ".bench.v1."+details[1], false),
+				field("items", 5, ".bench.v1."+items[0], true)),
+			message("Get"+resource+"Request", field("name", 1, "string", false)),
+			message("Update"+resource+"Request",
+				field("name", 1, "string", false),
+				field("resource", 2, ".bench.v1."+resource, false)),
+			message("List"+resource+"sRequest",
+				field("parent", 1, "string", false),
+				field("page_size", 2, "int64", false),
+				field("page_token", 3, "string", false)),
+			message("List"+resource+"sResponse",
+				field("resources", 1, ".bench.v1."+resource, true),
+				field("next_page_token", 2, "string", false)))
+		for j := 0; j < 2; j++ {
+			file.MessageType = append(file.MessageType,
+				message(details[j],
+					field("title", 1, "string", false),
+					field("items", 2, ".bench.v1."+items[j], true),
+					field("related", 3, fmt.Sprintf(".bench.v1.Detail%d", 2*next+j), false)),
+				message(items[j],
+					field("value", 1, "int64", false),
+					field("update_time", 2, ".google.protobuf.Timestamp", false),
+					field("detail", 3, ".bench.v1."+details[1-j], false)))
+		}
+		file.Service = append(file.Service, &descriptorpb.ServiceDescriptorProto{
+			Name: proto.String(resource + "Service"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Get"+resource, "Get"+resource+"Request", resource,
+					&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=" + resource + "s/*}"}}),
+				method("Update"+resource, "Update"+resource+"Request", resource,
+					&annotations.HttpRule{Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{name=" + resource + "s/*}"}, Body: "resource"}),
+				method("List"+resource+"s", "List"+resource+"sRequest", "List"+resource+"sResponse",
+					&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/" + resource + "s"}}),
+			},
+		})
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+			protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+			file,
+		},
+	}
+}
+
+// testConfiguration returns the default configuration of the plugin.
+func testConfiguration() Configuration {
+	depth := 2
+	return Configuration{
+		Version:           proto.String("0.0.1"),
+		Title:             proto.String(""),
+		Description:       proto.String(""),
+		Naming:            proto.String("json"),
+		FQSchemaNaming:    proto.Bool(false),
+		EnumType:          proto.String("integer"),
+		CircularDepth:     &depth,
+		DefaultResponse:   proto.Bool(true),
+		OutputMode:        proto.String("merged"),
+		WildcardBodyDedup: proto.Bool(false),
+	}
+}
+
+// generate runs the generator for a plugin request and returns its output.
+func generate(tb testing.TB, request *pluginpb.CodeGeneratorRequest, conf Configuration) []byte {
+	plugin, err := protogen.Options{}.New(request)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	outputFile := plugin.NewGeneratedFile("openapi.yaml", "")
+	if err = NewOpenAPIv3Generator(plugin, conf, plugin.Files).Run(outputFile); err != nil {
+		tb.Fatal(err)
+	}
+	content, err := outputFile.Content()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return content
+}
+
+func TestConcurrentSchemas(t *testing.T) {
+	// Shared names make the message that is generated for a name depend on the order of the search.
+	fq := testConfiguration()
+	fq.FQSchemaNaming = proto.Bool(true)
+	for _, test := range []struct {
+		name string
+		conf Configuration
+	}{
+		{"default", testConfiguration()},
+		{"fully-qualified names", fq},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			request := largeRequest(50)
+			serial := test.conf
+			one := 1
+			serial.Workers = &one
+			expected := generate(t, request, serial)
+			for _, workers := range []int{2, 8} {
+				conf := test.conf
+				conf.Workers = &workers
+				if actual := generate(t, request, conf); string(actual) != string(expected) {
+					t.Errorf("output with %d workers differs from the serial output", workers)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemasBuiltOnce(t *testing.T) {
+	plugin, err := protogen.Options{}.New(largeRequest(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewOpenAPIv3Generator(plugin, testConfiguration(), plugin.Files)
+	d := g.buildDocumentV3()
+	count := len(d.Components.Schemas.AdditionalProperties)
+	// Schemas that are already in the document aren't built again.
+	for _, schema := range d.Components.Schemas.AdditionalProperties {
+		g.addSchemaToDocumentV3(d, schema.Name, func() *v3.NamedSchemaOrReference {
+			t.Errorf("%s was built again", schema.Name)
+			return schema
+		})
+	}
+	if len(d.Components.Schemas.AdditionalProperties) != count {
+		t.Errorf("expected %d schemas, got %d", count, len(d.Components.Schemas.AdditionalProperties))
+	}
+	// Schema names are computed once for each message.
+	message := plugin.Files[len(plugin.Files)-1].Messages[0]
+	if name := g.reflect.fork().formatMessageName(message.Desc); name != "Resource0" {
+		t.Errorf("unexpected schema name %s", name)
+	}
+	if _, ok := g.reflect.schemaNames.Load(message.Desc.FullName()); !ok {
+		t.Errorf("expected the name of %s to be cached", message.Desc.FullName())
+	}
+}
+
+func TestSanitizeDescription(t *testing.T) {
+	for _, test := range []struct {
+		description string
+		maxLength   int
+		expected    string
+		truncated   bool
+	}{
+		{"Keeps\ttabs\nand newlines.", 0, "Keeps\ttabs\nand newlines.", false},
+		{"Removes\x00 control\x1b characters\r\n\u0085.", 0, "Removes control characters\n.", false},
+		{"Short enough.", 13, "Short enough.", false},
+		{"Truncated at a word boundary.", 20, "Truncated at a word…", true},
+		{"Truncated at a word boundary.", 14, "Truncated at…", true},
+		{"Truncated before\nthe newline.", 18, "Truncated before…", true},
+		{"Unbreakable words are cut.", 8, "Unbreak…", true},
+		{"Control\x07 characters don't count.", 34, "Control characters don't count.", false},
+		{"Ünïcödé characters count once.", 12, "Ünïcödé…", true},
+	} {
+		actual, truncated := sanitizeDescription(test.description, test.maxLength)
+		if actual != test.expected || truncated != test.truncated {
+			t.Errorf("sanitizeDescription(%q, %d) = %q, %t (expected %q, %t)",
+				test.description, test.maxLength, actual, truncated, test.expected, test.truncated)
+		}
+		if test.maxLength > 0 && len([]rune(actual)) > test.maxLength {
+			t.Errorf("sanitizeDescription(%q, %d) is longer than %d characters", test.description, test.maxLength, test.maxLength)
+		}
+	}
+}
+
+func TestSanitizeDescriptions(t *testing.T) {
+	long := strings.Repeat("word ", 10)
+	d := &v3.Document{
+		Info: &v3.Info{Title: "API", Description: long},
+		Paths: &v3.Paths{Path: []*v3.NamedPathItem{{
+			Name: "/v1/messages",
+			Value: &v3.PathItem{Get: &v3.Operation{
+				Description: "Lists\x00 messages.",
+				Parameters: []*v3.ParameterOrReference{{Oneof: &v3.ParameterOrReference_Parameter{
+					Parameter: &v3.Parameter{Name: "filter", Description: long},
+				}}},
+			}},
+		}}},
+		Components: &v3.Components{Schemas: &v3.SchemasOrReferences{AdditionalProperties: []*v3.NamedSchemaOrReference{{
+			Name: "Message",
+			Value: &v3.SchemaOrReference{Oneof: &v3.SchemaOrReference_Schema{Schema: &v3.Schema{
+				Properties: &v3.Properties{AdditionalProperties: []*v3.NamedSchemaOrReference{{
+					Name:  "text",
+					Value: &v3.SchemaOrReference{Oneof: &v3.SchemaOrReference_Schema{Schema: &v3.Schema{Description: long}}},
+				}}},
+			}}},
+		}}}},
+	}
+	maxLength := 20
+	conf := testConfiguration()
+	conf.MaxDescriptionLength = &maxLength
+	g := &OpenAPIv3Generator{conf: conf}
+	g.sanitizeDescriptions(d)
+	if d.Info.Description != "word word word word…" {
+		t.Errorf("unexpected description %q", d.Info.Description)
+	}
+	if description := d.Paths.Path[0].Value.Get.Description; description != "Lists messages." {
+		t.Errorf("unexpected description %q", description)
+	}
+	expected := []string{
+		"description of info was truncated to 20 characters",
+		"description of paths./v1/messages.get.parameters[0] was truncated to 20 characters",
+		"description of components.schemas.Message.properties.text was truncated to 20 characters",
+	}
+	if strings.Join(g.warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected warnings\n%s\n(expected\n%s)", strings.Join(g.warnings, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestSchemaCase(t *testing.T) {
+	for _, test := range []struct {
+		schemaCase string
+		name       string
+		expected   string
+	}{
+		{"proto", "Shelf_Book_Author", "Shelf_Book_Author"},
+		{"snake", "Shelf_Book_Author", "shelf_book_author"},
+		{"camel", "Shelf_Book_Author", "shelfBookAuthor"},
+		{"snake", "HTTPRule", "http_rule"},
+		{"camel", "HTTPRule", "httpRule"},
+		{"snake", "V1Book", "v1_book"},
+		{"snake", "google.example.library_v1.BookShelf", "google.example.library_v1.book_shelf"},
+		{"camel", "google.example.library_v1.BookShelf", "google.example.libraryV1.bookShelf"},
+		// Names that are already converted stay the same.
+		{"snake", "book_shelf", "book_shelf"},
+		{"camel", "bookShelf", "bookShelf"},
+	} {
+		conf := testConfiguration()
+		conf.SchemaCase = proto.String(test.schemaCase)
+		if name := NewOpenAPIv3Reflector(conf).formatSchemaCase(test.name); name != test.expected {
+			t.Errorf("%s %s: unexpected name %s (expected %s)", test.schemaCase, test.name, name, test.expected)
+		}
+	}
+}
+
+func TestDedupeNestedSchemas(t *testing.T) {
+	d, err := v3.ParseDocument([]byte(`openapi: 3.0.3
+info:
+  title: Nested
+  version: 0.0.1
+paths: {}
+components:
+  schemas:
+    Alias:
+      $ref: '#/components/schemas/Name'
+    Name:
+      type: string
+    OtherName:
+      type: string
+      description: Another name.
+    OtherUser:
+      type: object
+      properties:
+        name:
+          $ref: '#/components/schemas/OtherName'
+    User:
+      type: object
+      properties:
+        name:
+          $ref: '#/components/schemas/Name'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := testConfiguration()
+	conf.DedupeIdenticalSchemas = proto.Bool(true)
+	NewOpenAPIv3Generator(&protogen.Plugin{}, conf, nil).dedupeIdenticalSchemas(d)
+	// The users are identical once the names are merged.
+	expected := []string{
+		"Alias: #/components/schemas/Name",
+		"Name: string",
+		"OtherName: #/components/schemas/Name",
+		"OtherUser: object",
+		"User: #/components/schemas/OtherUser",
+	}
+	actual := []string{}
+	for _, pair := range d.Components.Schemas.AdditionalProperties {
+		if ref := pair.Value.GetReference(); ref != nil {
+			actual = append(actual, pair.Name+": "+ref.XRef)
+		} else {
+			actual = append(actual, pair.Name+": "+pair.Value.GetSchema().Type)
+		}
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected schemas:\n%s", strings.Join(actual, "\n"))
+	}
+}
+
+// duplicatePathsRequest returns a plugin request for a service whose two methods are bound
+// to the same HTTP method and path.
+func duplicatePathsRequest() *pluginpb.CodeGeneratorRequest {
+	const pkg = ".tests.duplicate_paths.v1."
+	method := func(name string) *descriptorpb.MethodDescriptorProto {
+		options := &descriptorpb.MethodOptions{}
+		proto.SetExtension(options, annotations.E_Http, &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Get{Get: "/v1/messages/{message_id}"},
+		})
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(pkg + "GetMessageRequest"),
+			OutputType: proto.String(pkg + "Message"),
+			Options:    options,
+		}
+	}
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("tests/duplicate_paths.proto"),
+		Package:    proto.String("tests.duplicate_paths.v1"),
+		Dependency: []string{"google/api/annotations.proto"},
+		Syntax:     proto.String("proto3"),
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/tests/duplicate_paths")},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("Messaging"),
+			Method: []*descriptorpb.MethodDescriptorProto{method("GetMessage"), method("LookupMessage")},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("GetMessageRequest"), Field: []*descriptorpb.FieldDescriptorProto{field("message_id", 1)}},
+			{Name: proto.String("Message"), Field: []*descriptorpb.FieldDescriptorProto{field("message_id", 1), field("text", 2)}},
+		},
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+			file,
+		},
+	}
+}
+
+func TestDuplicatePaths(t *testing.T) {
+	plugin, err := protogen.Options{}.New(duplicatePathsRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = NewOpenAPIv3Generator(plugin, testConfiguration(), plugin.Files).Run(plugin.NewGeneratedFile("openapi.yaml", ""))
+	expected := "tests.duplicate_paths.v1.Messaging.GetMessage and tests.duplicate_paths.v1.Messaging.LookupMessage are both bound to GET /v1/messages/{messageId}"
+	if err == nil || err.Error() != expected {
+		t.Errorf("unexpected error %v (expected %q)", err, expected)
+	}
+
+	conf := testConfiguration()
+	conf.AllowDuplicatePaths = proto.String("warn")
+	conf.WarningsHeader = proto.Bool(true)
+	output := generate(t, duplicatePathsRequest(), conf)
+	warning := "# - tests.duplicate_paths.v1.Messaging.LookupMessage was skipped because tests.duplicate_paths.v1.Messaging.GetMessage is also bound to GET /v1/messages/{messageId}\n"
+	if !strings.Contains(string(output), warning) {
+		t.Errorf("expected warning\n%s\nin output\n%s", warning, output)
+	}
+	d, err := v3.ParseDocument(output)
+	if err != nil {
+		t.Fatalf("error parsing output: %v", err)
+	}
+	// The first method is kept.
+	if len(d.Paths.Path) != 1 || d.Paths.Path[0].Value.Get.OperationId != "Messaging_GetMessage" {
+		t.Errorf("unexpected paths %v", d.Paths.Path)
+	}
+}
+
+func TestParseSecurityScheme(t *testing.T) {
+	for option, expected := range map[string]string{
+		"ApiKeyAuth:apiKey":        `the security_scheme "ApiKeyAuth:apiKey" has no header name, like ApiKeyAuth:apiKey:X-API-Key`,
+		"OAuth:oauth2":             `the security_scheme "OAuth:oauth2" has no token URL, like OAuth:oauth2:https://example.com/oauth/token`,
+		":http":                    `the security_scheme ":http" has no name`,
+		"OIDC:openIdConnect:https": `unknown type "openIdConnect" of the security_scheme "OIDC:openIdConnect:https", expected "apiKey", "http" or "oauth2"`,
+	} {
+		if _, _, err := parseSecurityScheme(option); err == nil || err.Error() != expected {
+			t.Errorf("unexpected error %v for %s (expected %q)", err, option, expected)
+		}
+	}
+}
+
+func TestSameParameter(t *testing.T) {
+	for _, test := range []struct {
+		a, b     *v3.Parameter
+		expected bool
+	}{
+		{&v3.Parameter{Name: "Idempotency-Key", In: "header"}, &v3.Parameter{Name: "idempotency-key", In: "header"}, true},
+		{&v3.Parameter{Name: "filter", In: "query"}, &v3.Parameter{Name: "Filter", In: "query"}, false},
+		{&v3.Parameter{Name: "session", In: "cookie"}, &v3.Parameter{Name: "session", In: "query"}, false},
+		{&v3.Parameter{Name: "session", In: "cookie"}, &v3.Parameter{Name: "session", In: "cookie"}, true},
+	} {
+		if actual := sameParameter(test.a, test.b); actual != test.expected {
+			t.Errorf("sameParameter(%v, %v) = %t (expected %t)", test.a, test.b, actual, test.expected)
+		}
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	request := largeRequest(100)
+	for _, workers := range []int{1, 4, 0} {
+		conf := testConfiguration()
+		conf.Workers = &workers
+		name := fmt.Sprintf("workers=%d", workers)
+		if workers == 0 {
+			name = "workers=GOMAXPROCS"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				generate(b, request, conf)
+			}
+		})
+	}
+}
+
+// BenchmarkBuildDocument measures the construction of the document without rendering it.
+func BenchmarkBuildDocument(b *testing.B) {
+	request := largeRequest(100)
+	one := 1
+	conf := testConfiguration()
+	conf.Workers = &one
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		plugin, err := protogen.Options{}.New(request)
+		if err != nil {
+			b.Fatal(err)
+		}
+		g := NewOpenAPIv3Generator(plugin, conf, plugin.Files)
+		b.StartTimer()
+		g.buildDocumentV3()
+	}
+}
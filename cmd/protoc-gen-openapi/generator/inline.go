@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+const responseRefPrefix = "#/components/responses/"
+
+// inliner replaces the references to the schemas and responses of the components of a
+// document with copies of them.
+type inliner struct {
+	schemas   map[string]*v3.SchemaOrReference
+	responses map[string]*v3.ResponseOrReference
+	recursive []string // Names of the schemas that refer to themselves, in the order they were found.
+}
+
+// inlineComponents replaces the references to components.schemas and components.responses
+// with copies of the schemas and responses that they refer to and removes the components
+// from the document, for no_components. Schemas that refer to themselves, directly or
+// through other schemas, and components that can't be referred to with $ref, like security
+// schemes, can't be inlined, so they are errors.
+func (g *OpenAPIv3Generator) inlineComponents(d *v3.Document) {
+	components := d.Components
+	d.Components = nil
+	in := &inliner{
+		schemas:   make(map[string]*v3.SchemaOrReference),
+		responses: make(map[string]*v3.ResponseOrReference),
+	}
+	for _, pair := range components.GetSchemas().GetAdditionalProperties() {
+		in.schemas[pair.Name] = pair.Value
+	}
+	for _, pair := range components.GetResponses().GetAdditionalProperties() {
+		in.responses[pair.Name] = pair.Value
+	}
+	in.inline(d.ProtoReflect(), nil)
+	// The operations of custom methods are already written as YAML.
+	for _, path := range d.GetPaths().GetPath() {
+		for _, extension := range path.Value.SpecificationExtension {
+			if extension.Name == "x-custom-method" && strings.Contains(extension.Value.GetYaml(), "#/components/") {
+				g.addError("the custom methods of %s can't be inlined with no_components", path.Name)
+			}
+		}
+	}
+	for _, name := range in.recursive {
+		g.addError("the schema %s can't be inlined with no_components because it refers to itself", name)
+	}
+	if components == nil {
+		return
+	}
+	m := components.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Name() != "schemas" && field.Name() != "responses" && m.Has(field) {
+			g.addError("components.%s can't be inlined with no_components", field.JSONName())
+		}
+	}
+}
+
+// inline replaces the references of a message and of the messages that it contains. The
+// stack holds the names of the schemas that are being inlined, which a schema can't refer to.
+func (in *inliner) inline(m protoreflect.Message, stack []string) {
+	switch value := m.Interface().(type) {
+	case *v3.SchemaOrReference:
+		if ref := value.GetReference().GetXRef(); strings.HasPrefix(ref, schemaRefPrefix) {
+			name := strings.TrimPrefix(ref, schemaRefPrefix)
+			if contains(stack, name) {
+				if !contains(in.recursive, name) {
+					in.recursive = append(in.recursive, name)
+				}
+				return
+			}
+			if schema, ok := in.schemas[name]; ok {
+				schema = proto.Clone(schema).(*v3.SchemaOrReference)
+				in.inline(schema.ProtoReflect(), append(stack, name))
+				value.Oneof = schema.Oneof
+			}
+			return
+		}
+	case *v3.ResponseOrReference:
+		if ref := value.GetReference().GetXRef(); strings.HasPrefix(ref, responseRefPrefix) {
+			if response, ok := in.responses[strings.TrimPrefix(ref, responseRefPrefix)]; ok {
+				response = proto.Clone(response).(*v3.ResponseOrReference)
+				in.inline(response.ProtoReflect(), stack)
+				value.Oneof = response.Oneof
+			}
+			return
+		}
+	}
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Message() == nil || field.IsMap() || !m.Has(field) {
+			continue
+		}
+		if !field.IsList() {
+			in.inline(m.Get(field).Message(), stack)
+			continue
+		}
+		list := m.Get(field).List()
+		for j := 0; j < list.Len(); j++ {
+			in.inline(list.Get(j).Message(), stack)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/google/gnostic/compiler"
+	"github.com/google/gnostic/jsonwriter"
+)
+
+// The mapping of mapping_out lists the operations of a document by operationId, with the
+// gRPC method that each of them calls and the request and response messages of the method:
+//
+//	operations:
+//	    - operationId: Messaging_GetMessage
+//	      grpcMethod: /tests.mapping.v1.Messaging/GetMessage
+//	      request: tests.mapping.v1.GetMessageRequest
+//	      response: tests.mapping.v1.Message
+//
+// Operations are sorted by operationId, and the bindings of a method, which share its
+// operationId, are listed once.
+
+// addOperationMapping records the method of an operation of the document for the mapping.
+// The first method of an operationId is kept.
+func (g *OpenAPIv3Generator) addOperationMapping(operationID string, method *protogen.Method) {
+	if _, ok := g.operationMethods[operationID]; !ok {
+		g.operationMethods[operationID] = method
+	}
+}
+
+// RunMapping writes the mapping of the operations of the document that Run generated, in
+// the format of the document.
+func (g *OpenAPIv3Generator) RunMapping(outputFile *protogen.GeneratedFile) error {
+	operationIDs := make([]string, 0, len(g.operationMethods))
+	for operationID := range g.operationMethods {
+		operationIDs = append(operationIDs, operationID)
+	}
+	sort.Strings(operationIDs)
+	operations := compiler.NewSequenceNode()
+	for _, operationID := range operationIDs {
+		method := g.operationMethods[operationID]
+		operation := compiler.NewMappingNode()
+		for _, pair := range [][2]string{
+			{"operationId", operationID},
+			{"grpcMethod", fmt.Sprintf("/%s/%s", method.Parent.Desc.FullName(), method.Desc.Name())},
+			{"request", string(method.Input.Desc.FullName())},
+			{"response", string(method.Output.Desc.FullName())},
+		} {
+			operation.Content = append(operation.Content,
+				compiler.NewScalarNodeForString(pair[0]), compiler.NewScalarNodeForString(pair[1]))
+		}
+		operations.Content = append(operations.Content, operation)
+	}
+	mapping := compiler.NewMappingNode()
+	mapping.Content = append(mapping.Content, compiler.NewScalarNodeForString("operations"), operations)
+
+	var bytes []byte
+	var err error
+	if g.conf.json() {
+		bytes, err = jsonwriter.Marshal(mapping)
+	} else {
+		bytes, err = compiler.RenderYAML(mapping, compiler.YAMLOptions{Comment: "Generated with protoc-gen-openapi\n" + infoURL})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal the mapping: %s", err.Error())
+	}
+	if _, err = outputFile.Write(bytes); err != nil {
+		return fmt.Errorf("failed to write the mapping: %s", err.Error())
+	}
+	return nil
+}
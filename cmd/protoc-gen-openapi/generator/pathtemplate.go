@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pathTemplate is a parsed path template of a google.api.http rule, like
+// /v1/{name=shelves/*}/books/{book_id}:cancel. Its syntax is
+//
+//	Template = "/" Segments [ Verb ] ;
+//	Segments = Segment { "/" Segment } ;
+//	Segment  = "*" | "**" | LITERAL | Variable ;
+//	Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//	FieldPath = IDENT { "." IDENT } ;
+//	Verb     = ":" LITERAL ;
+type pathTemplate struct {
+	segments []pathSegment
+	verb     string // The custom verb without its colon, like "cancel".
+}
+
+// pathSegment is a literal or wildcard segment of a path template, or a variable.
+type pathSegment struct {
+	literal  string
+	variable *pathVariable
+}
+
+// pathVariable is a variable of a path template, like {name=shelves/*}.
+type pathVariable struct {
+	fieldPath string
+	// segments are the literal and wildcard segments that the variable matches, like
+	// shelves/*, or nil if it matches a single segment, like {name} and {name=*}.
+	segments []string
+}
+
+var fieldPathPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// parsePathTemplate parses a path template. Templates that don't follow the syntax, like
+// /v1/{id, are rejected with an error that describes the problem. Variables must be
+// whole segments, so /v1/{a}{b} is rejected too.
+func parsePathTemplate(template string) (*pathTemplate, error) {
+	if !strings.HasPrefix(template, "/") {
+		return nil, fmt.Errorf("it doesn't start with /")
+	}
+	t := &pathTemplate{}
+	if template == "/" {
+		return t, nil
+	}
+	for i := 1; ; {
+		if template[i] == '{' {
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("the { at offset %d isn't closed", i)
+			}
+			body := template[i+1 : i+end]
+			if nested := strings.IndexByte(body, '{'); nested >= 0 {
+				return nil, fmt.Errorf("the { at offset %d is inside a variable", i+1+nested)
+			}
+			variable, err := parsePathVariable(body)
+			if err != nil {
+				return nil, err
+			}
+			t.segments = append(t.segments, pathSegment{variable: variable})
+			i += end + 1
+		} else {
+			end := i + strings.IndexAny(template[i:]+"/", "/{}:")
+			if end == i && template[i] == '/' {
+				return nil, fmt.Errorf("the segment at offset %d is empty", i)
+			} else if end == i {
+				return nil, fmt.Errorf("unexpected %q at offset %d", template[i], i)
+			}
+			t.segments = append(t.segments, pathSegment{literal: template[i:end]})
+			i = end
+		}
+		if i == len(template) {
+			return t, nil
+		}
+		switch template[i] {
+		case '/':
+			i++
+			if i == len(template) {
+				return nil, fmt.Errorf("the segment at offset %d is empty", i)
+			}
+		case ':':
+			t.verb = template[i+1:]
+			if t.verb == "" || strings.ContainsAny(t.verb, "/{}:") {
+				return nil, fmt.Errorf("the verb %q isn't a literal", t.verb)
+			}
+			return t, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", template[i], i)
+		}
+	}
+}
+
+// parsePathVariable parses the text between the braces of a variable, like name=shelves/*.
+func parsePathVariable(body string) (*pathVariable, error) {
+	fieldPath, pattern, hasPattern := strings.Cut(body, "=")
+	if !fieldPathPattern.MatchString(fieldPath) {
+		return nil, fmt.Errorf("the variable {%s} doesn't name a field", body)
+	}
+	variable := &pathVariable{fieldPath: fieldPath}
+	if !hasPattern || pattern == "*" {
+		return variable, nil
+	}
+	variable.segments = strings.Split(pattern, "/")
+	for _, segment := range variable.segments {
+		if segment == "" || strings.ContainsAny(segment, "}:") {
+			return nil, fmt.Errorf("the variable {%s} has a malformed pattern", body)
+		}
+	}
+	return variable, nil
+}
+
+// path returns the path of a template whose segments are replaced with the texts of
+// segments, followed by its verb.
+func (t *pathTemplate) path(segments []string) string {
+	path := "/" + strings.Join(segments, "/")
+	if t.verb != "" {
+		path += ":" + t.verb
+	}
+	return path
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+// String returns a template in a form that shows how it was parsed, like
+// /v1/{name=shelves/*}/books :cancel.
+func (t *pathTemplate) String() string {
+	var b strings.Builder
+	for _, segment := range t.segments {
+		b.WriteString("/")
+		switch {
+		case segment.variable == nil:
+			b.WriteString(segment.literal)
+		case segment.variable.segments == nil:
+			b.WriteString("{" + segment.variable.fieldPath + "}")
+		default:
+			b.WriteString("{" + segment.variable.fieldPath + "=" + strings.Join(segment.variable.segments, "/") + "}")
+		}
+	}
+	if t.verb != "" {
+		b.WriteString(" :" + t.verb)
+	}
+	return b.String()
+}
+
+func TestParsePathTemplate(t *testing.T) {
+	for _, test := range []struct {
+		template string
+		parsed   string // The parsed template, or the error.
+	}{
+		{"/", ""},
+		{"/v1/messages", "/v1/messages"},
+		{"/v1/messages/{message_id}", "/v1/messages/{message_id}"},
+		{"/v1/{name=shelves/*}/books", "/v1/{name=shelves/*}/books"},
+		{"/v1/{book.name=shelves/*/books/*}", "/v1/{book.name=shelves/*/books/*}"},
+		{"/v1/{name=shelves/**}", "/v1/{name=shelves/**}"},
+		{"/v1/{name=*}", "/v1/{name}"},
+		{"/v1/*/messages/**", "/v1/*/messages/**"},
+		{"/v1/{name=shelves/*}/books/{book_id}", "/v1/{name=shelves/*}/books/{book_id}"},
+		// Verbs
+		{"/v1/messages:lookup", "/v1/messages :lookup"},
+		{"/v1/things/{id}:activate", "/v1/things/{id} :activate"},
+		{"/v1/{name=operations/*}:cancel", "/v1/{name=operations/*} :cancel"},
+		// Malformed templates
+		{"", "it doesn't start with /"},
+		{"v1/messages", "it doesn't start with /"},
+		{"/v1/{a}{b}", `unexpected '{' at offset 7`},
+		{"/v1/{id", "the { at offset 4 isn't closed"},
+		{"/v1/{name={id}}", "the { at offset 10 is inside a variable"},
+		{"/v1/id}", `unexpected '}' at offset 6`},
+		{"/v1/}", `unexpected '}' at offset 4`},
+		{"/v1/{}", "the variable {} doesn't name a field"},
+		{"/v1/{message-id}", "the variable {message-id} doesn't name a field"},
+		{"/v1/{name=shelves//*}", "the variable {name=shelves//*} has a malformed pattern"},
+		{"/v1/{name=operations/*:cancel}", "the variable {name=operations/*:cancel} has a malformed pattern"},
+		{"/v1//messages", "the segment at offset 4 is empty"},
+		{"/v1/messages/", "the segment at offset 13 is empty"},
+		{"/v1/things/{id}:", `the verb "" isn't a literal`},
+		{"/v1/things:activate/{id}", `the verb "activate/{id}" isn't a literal`},
+		{"/v1/things/{id}:a:b", `the verb "a:b" isn't a literal`},
+	} {
+		t.Run(test.template, func(t *testing.T) {
+			template, err := parsePathTemplate(test.template)
+			var parsed string
+			if err != nil {
+				parsed = err.Error()
+			} else {
+				parsed = template.String()
+			}
+			if parsed != test.parsed {
+				t.Errorf("unexpected result %q (expected %q)", parsed, test.parsed)
+			}
+		})
+	}
+}
+
+func TestPathTemplatePath(t *testing.T) {
+	template, err := parsePathTemplate("/v1/{name=operations/*}:cancel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path := template.path([]string{"v1", "operations", "{operation}"}); path != "/v1/operations/{operation}:cancel" {
+		t.Errorf("unexpected path %q", path)
+	}
+}
@@ -16,10 +16,20 @@
 package generator
 
 import (
+	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 
 	wk "github.com/google/gnostic/cmd/protoc-gen-openapi/generator/wellknown"
 	v3 "github.com/google/gnostic/openapiv3"
@@ -33,7 +43,53 @@ const (
 type OpenAPIv3Reflector struct {
 	conf Configuration
 
-	requiredSchemas []string // Names of schemas which are used through references.
+	requiredSchemas []string             // Names of schemas which are used through references.
+	schemaNames     *sync.Map            // Formatted schema names by message full name, shared with forks.
+	schemaMessages  *schemaMessages      // Messages referenced with each schema name, shared with forks.
+	validateRules   *protoregistry.Types // Resolves the annotations of validate.proto, if validate_rules is set.
+}
+
+// schemaMessages records the messages that are referenced with each schema name, so that
+// different messages with the same name can be reported. It can be used concurrently.
+type schemaMessages struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+// add records that a message is referenced with a schema name. Messages are identified by
+// their full names, or by a description of a schema that isn't the schema of a message.
+func (s *schemaMessages) add(schemaName, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !contains(s.messages[schemaName], message) {
+		s.messages[schemaName] = append(s.messages[schemaName], message)
+	}
+}
+
+// uses returns true if a schema name is only referenced for a message, or isn't referenced.
+func (s *schemaMessages) uses(schemaName, message string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := s.messages[schemaName]
+	return len(messages) == 0 || (len(messages) == 1 && messages[0] == message)
+}
+
+// collisions describes the schema names that are referenced for more than one message, sorted.
+func (s *schemaMessages) collisions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var collisions []string
+	for schemaName, messages := range s.messages {
+		if len(messages) < 2 {
+			continue
+		}
+		messages = slices.Sorted(slices.Values(messages))
+		last := len(messages) - 1
+		collisions = append(collisions, fmt.Sprintf("%s and %s have the same schema name %s",
+			strings.Join(messages[:last], ", "), messages[last], schemaName))
+	}
+	sort.Strings(collisions)
+	return collisions
 }
 
 // NewOpenAPIv3Reflector creates a new reflector.
@@ -42,21 +98,50 @@ func NewOpenAPIv3Reflector(conf Configuration) *OpenAPIv3Reflector {
 		conf: conf,
 
 		requiredSchemas: make([]string, 0),
+		schemaNames:     &sync.Map{},
+		schemaMessages:  &schemaMessages{messages: make(map[string][]string)},
 	}
 }
 
-func (r *OpenAPIv3Reflector) getMessageName(message protoreflect.MessageDescriptor) string {
-	prefix := ""
-	parent := message.Parent()
+// fork returns a reflector that records its required schemas separately from r
+// but shares the cached names of r. Forks can be used concurrently.
+func (r *OpenAPIv3Reflector) fork() *OpenAPIv3Reflector {
+	return &OpenAPIv3Reflector{
+		conf: r.conf,
 
-	if _, ok := parent.(protoreflect.MessageDescriptor); ok {
-		prefix = string(parent.Name()) + "_" + prefix
+		requiredSchemas: make([]string, 0),
+		schemaNames:     r.schemaNames,
+		schemaMessages:  r.schemaMessages,
+		validateRules:   r.validateRules,
 	}
+}
 
-	return prefix + string(message.Name())
+// getMessageName returns the name of a message prefixed with the names of the messages that
+// it is nested in, like Outer_Middle_Inner, so that nested messages with the same name in
+// different messages have different names.
+func (r *OpenAPIv3Reflector) getMessageName(message protoreflect.MessageDescriptor) string {
+	name := string(message.Name())
+	for parent := message.Parent(); parent != nil; parent = parent.Parent() {
+		if _, ok := parent.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+		name = string(parent.Name()) + "_" + name
+	}
+	return name
 }
 
+// formatMessageName returns the name of the schema of a message. Names depend only on the
+// message and the configuration, so they are computed once for each message.
 func (r *OpenAPIv3Reflector) formatMessageName(message protoreflect.MessageDescriptor) string {
+	if name, ok := r.schemaNames.Load(message.FullName()); ok {
+		return name.(string)
+	}
+	name := r.newMessageName(message)
+	r.schemaNames.Store(message.FullName(), name)
+	return name
+}
+
+func (r *OpenAPIv3Reflector) newMessageName(message protoreflect.MessageDescriptor) string {
 	typeName := r.fullMessageTypeName(message)
 
 	name := r.getMessageName(message)
@@ -83,11 +168,63 @@ func (r *OpenAPIv3Reflector) formatMessageName(message protoreflect.MessageDescr
 		name = package_name + "." + name
 	}
 
-	return name
+	return r.formatSchemaCase(name)
+}
+
+// formatSchemaCase converts a schema name to the case of the schema_case option. The
+// segments of fully-qualified names are converted separately and keep the dots between them.
+func (r *OpenAPIv3Reflector) formatSchemaCase(name string) string {
+	if r.conf.SchemaCase == nil || *r.conf.SchemaCase == "proto" {
+		return name
+	}
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		words := schemaNameWords(segment)
+		for j, word := range words {
+			word = strings.ToLower(word)
+			if *r.conf.SchemaCase == "camel" && j > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+			words[j] = word
+		}
+		if *r.conf.SchemaCase == "snake" {
+			segments[i] = strings.Join(words, "_")
+		} else {
+			segments[i] = strings.Join(words, "")
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// schemaNameWords splits a name into its words. Words are separated by underscores and
+// start at capitals that follow lowercase letters or digits, like Book in V1Book, and at
+// the last capital of acronyms that are followed by lowercase letters, like Rule in HTTPRule.
+func schemaNameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i > start && unicode.IsUpper(runes[i]) {
+			previous := runes[i-1]
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+				(unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	return words
 }
 
 func (r *OpenAPIv3Reflector) formatFieldName(field protoreflect.FieldDescriptor) string {
-	if *r.conf.Naming == "proto" {
+	if *r.conf.Naming == "proto" || !r.conf.jsonNames() {
 		return string(field.Name())
 	}
 
@@ -116,10 +253,34 @@ func (r *OpenAPIv3Reflector) responseContentForMessage(message protoreflect.Mess
 
 func (r *OpenAPIv3Reflector) schemaReferenceForMessage(message protoreflect.MessageDescriptor) string {
 	schemaName := r.formatMessageName(message)
+	r.schemaMessages.add(schemaName, string(message.FullName()))
+	r.requireSchema(schemaName)
+	return "#/components/schemas/" + schemaName
+}
+
+// requireSchema records that a schema is used through a reference.
+func (r *OpenAPIv3Reflector) requireSchema(schemaName string) {
 	if !contains(r.requiredSchemas, schemaName) {
 		r.requiredSchemas = append(r.requiredSchemas, schemaName)
 	}
-	return "#/components/schemas/" + schemaName
+}
+
+// timestampSchema returns the schema of google.protobuf.Timestamp with the configured format.
+func (r *OpenAPIv3Reflector) timestampSchema() *v3.SchemaOrReference {
+	schema := wk.NewGoogleProtobufTimestampSchema()
+	if r.conf.TimestampFormat != nil {
+		schema.GetSchema().Format = *r.conf.TimestampFormat
+	}
+	return schema
+}
+
+// durationSchema returns the schema of google.protobuf.Duration with the configured format.
+func (r *OpenAPIv3Reflector) durationSchema() *v3.SchemaOrReference {
+	schema := wk.NewGoogleProtobufDurationSchema()
+	if r.conf.DurationFormat != nil {
+		schema.GetSchema().Format = *r.conf.DurationFormat
+	}
+	return schema
 }
 
 // Returns a full schema for simple types, and a schema reference for complex types that reference
@@ -133,10 +294,10 @@ func (r *OpenAPIv3Reflector) schemaOrReferenceForMessage(message protoreflect.Me
 		return wk.NewGoogleApiHttpBodySchema()
 
 	case ".google.protobuf.Timestamp":
-		return wk.NewGoogleProtobufTimestampSchema()
+		return r.timestampSchema()
 
 	case ".google.protobuf.Duration":
-		return wk.NewGoogleProtobufDurationSchema()
+		return r.durationSchema()
 
 	case ".google.type.Date":
 		return wk.NewGoogleTypeDateSchema()
@@ -199,13 +360,14 @@ func (r *OpenAPIv3Reflector) schemaOrReferenceForField(field protoreflect.FieldD
 			//
 			// So we need to find the `value` field in the `MapFieldEntry` message and
 			// then return a MapFieldEntry schema using the schema for the `value` field
-			return wk.NewGoogleProtobufMapFieldEntrySchema(r.schemaOrReferenceForField(field.MapValue()))
+			kindSchema = wk.NewGoogleProtobufMapFieldEntrySchema(r.schemaOrReferenceForField(field.MapValue()))
 		} else {
 			kindSchema = r.schemaOrReferenceForMessage(field.Message())
 		}
 
 	case protoreflect.StringKind:
 		kindSchema = wk.NewStringSchema()
+		applyFieldInfo(kindSchema.GetSchema(), field)
 
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Uint32Kind,
 		protoreflect.Sfixed32Kind, protoreflect.Fixed32Kind:
@@ -217,6 +379,7 @@ func (r *OpenAPIv3Reflector) schemaOrReferenceForField(field protoreflect.FieldD
 
 	case protoreflect.EnumKind:
 		kindSchema = wk.NewEnumSchema(*&r.conf.EnumType, field)
+		r.addEnumVarnames(kindSchema.GetSchema(), field.Enum())
 
 	case protoreflect.BoolKind:
 		kindSchema = wk.NewBooleanSchema()
@@ -234,6 +397,64 @@ func (r *OpenAPIv3Reflector) schemaOrReferenceForField(field protoreflect.FieldD
 	if field.IsList() {
 		kindSchema = wk.NewListSchema(kindSchema)
 	}
+	for _, rules := range r.validateRulesForField(field) {
+		applyValidateRules(kindSchema.GetSchema(), rules)
+	}
 
 	return kindSchema
 }
+
+// addEnumVarnames lists the numbers of the values of an enum in the schema of an integer
+// enum and their names in an x-enum-varnames extension, in the same order, if enum_varnames
+// is set. The names of string enums are their values, so they are left as they are.
+func (r *OpenAPIv3Reflector) addEnumVarnames(schema *v3.Schema, enum protoreflect.EnumDescriptor) {
+	if r.conf.EnumVarnames == nil || !*r.conf.EnumVarnames || schema.Type != "integer" {
+		return
+	}
+	values := enum.Values()
+	names := make([]string, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		schema.Enum = append(schema.Enum, &v3.Any{Yaml: strconv.Itoa(int(values.Get(i).Number()))})
+		names = append(names, string(values.Get(i).Name()))
+	}
+	value, err := yaml.Marshal(names)
+	if err != nil {
+		log.Printf("the names of %s were left out: %v", enum.FullName(), err)
+		return
+	}
+	schema.SpecificationExtension = append(schema.SpecificationExtension, &v3.NamedAny{
+		Name:  "x-enum-varnames",
+		Value: &v3.Any{Yaml: string(value)},
+	})
+}
+
+// fieldFormats maps the formats of the google.api.field_info annotation to the formats of OpenAPI.
+var fieldFormats = map[string]string{
+	"UUID4": "uuid",
+	"IPV4":  "ipv4",
+	"IPV6":  "ipv6",
+	"EMAIL": "email",
+}
+
+// applyFieldInfo sets the format of the schema of a string field from its google.api.field_info
+// annotation. Formats that OpenAPI has no equivalent for are kept in an x-field-format extension.
+func applyFieldInfo(schema *v3.Schema, field protoreflect.FieldDescriptor) {
+	info, _ := proto.GetExtension(field.Options(), annotations.E_FieldInfo).(*annotations.FieldInfo)
+	if info.GetFormat() == annotations.FieldInfo_FORMAT_UNSPECIFIED {
+		return
+	}
+	name := info.GetFormat().String()
+	if format, ok := fieldFormats[name]; ok {
+		schema.Format = format
+		return
+	}
+	value, err := yaml.Marshal(name)
+	if err != nil {
+		log.Printf("unsupported field format %s: %v", name, err)
+		return
+	}
+	schema.SpecificationExtension = append(schema.SpecificationExtension, &v3.NamedAny{
+		Name:  "x-field-format",
+		Value: &v3.Any{Yaml: string(value)},
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+// addSecurityOptions adds the security scheme of the security_scheme option to the
+// components of a document, unless its annotations declare a scheme with the same name,
+// and the security requirement of the security option to the document, unless its
+// annotations declare security requirements.
+func (g *OpenAPIv3Generator) addSecurityOptions(d *v3.Document) {
+	name, scopes := "", []string{}
+	if g.conf.Security != nil && *g.conf.Security != "" {
+		parts := strings.Split(*g.conf.Security, ";")
+		name, scopes = parts[0], parts[1:]
+	}
+	if g.conf.SecurityScheme != nil && *g.conf.SecurityScheme != "" {
+		schemeName, scheme, err := parseSecurityScheme(*g.conf.SecurityScheme)
+		if err != nil {
+			g.addError("%s", err)
+			return
+		}
+		if d.Components.SecuritySchemes == nil {
+			d.Components.SecuritySchemes = &v3.SecuritySchemesOrReferences{}
+		}
+		if findSecurityScheme(d, schemeName) == nil {
+			d.Components.SecuritySchemes.AdditionalProperties = append(d.Components.SecuritySchemes.AdditionalProperties,
+				&v3.NamedSecuritySchemeOrReference{
+					Name: schemeName,
+					Value: &v3.SecuritySchemeOrReference{
+						Oneof: &v3.SecuritySchemeOrReference_SecurityScheme{SecurityScheme: scheme},
+					},
+				})
+		}
+	}
+	if name == "" || len(d.Security) > 0 {
+		return
+	}
+	if findSecurityScheme(d, name) == nil {
+		g.addError("the security option refers to the undeclared security scheme %s", name)
+		return
+	}
+	d.Security = []*v3.SecurityRequirement{{
+		AdditionalProperties: []*v3.NamedStringArray{{Name: name, Value: &v3.StringArray{Value: scopes}}},
+	}}
+}
+
+// parseSecurityScheme parses the name and the security scheme of a security_scheme option,
+// like "ApiKeyAuth:apiKey:X-API-Key" for an API key in a header, "BearerAuth:http" or
+// "BasicAuth:http:basic" for HTTP authentication, which is bearer unless a scheme is given,
+// and "OAuth:oauth2:https://example.com/oauth/token" for the client credentials flow of
+// OAuth 2.0 with a token URL. The flow has an empty map of scopes, since the models of
+// documents don't write the descriptions of scopes.
+func parseSecurityScheme(option string) (string, *v3.SecurityScheme, error) {
+	parts := strings.SplitN(option, ":", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	name, schemeType, params := parts[0], parts[1], parts[2]
+	if name == "" {
+		return "", nil, fmt.Errorf("the security_scheme %q has no name", option)
+	}
+	switch schemeType {
+	case "apiKey":
+		if params == "" {
+			return "", nil, fmt.Errorf("the security_scheme %q has no header name, like %s:apiKey:X-API-Key", option, name)
+		}
+		return name, &v3.SecurityScheme{Type: "apiKey", In: "header", Name: params}, nil
+	case "http":
+		if params == "" {
+			params = "bearer"
+		}
+		return name, &v3.SecurityScheme{Type: "http", Scheme: params}, nil
+	case "oauth2":
+		if params == "" {
+			return "", nil, fmt.Errorf("the security_scheme %q has no token URL, like %s:oauth2:https://example.com/oauth/token", option, name)
+		}
+		return name, &v3.SecurityScheme{Type: "oauth2", Flows: &v3.OauthFlows{
+			ClientCredentials: &v3.OauthFlow{TokenUrl: params, Scopes: &v3.Strings{}},
+		}}, nil
+	}
+	return "", nil, fmt.Errorf(`unknown type %q of the security_scheme %q, expected "apiKey", "http" or "oauth2"`, schemeType, option)
+}
+
+// findSecurityScheme returns the security scheme with a name in the components of a
+// document, or nil if there is none.
+func findSecurityScheme(d *v3.Document, name string) *v3.NamedSecuritySchemeOrReference {
+	for _, scheme := range d.GetComponents().GetSecuritySchemes().GetAdditionalProperties() {
+		if scheme.Name == name {
+			return scheme
+		}
+	}
+	return nil
+}
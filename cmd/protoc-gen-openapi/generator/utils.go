@@ -31,14 +31,6 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-// appendUnique appends a string, to a string slice, if the string is not already in the slice
-func appendUnique(s []string, e string) []string {
-	if !contains(s, e) {
-		return append(s, e)
-	}
-	return s
-}
-
 // singular produces the singular form of a collection name.
 func singular(plural string) string {
 	return inflection.Singular(plural)
@@ -53,3 +45,23 @@ func getValueField(message protoreflect.MessageDescriptor) protoreflect.FieldDes
 	fields := message.Fields()
 	return fields.ByName("value")
 }
+
+// mapKeyDescription describes the keys of a map field with integer or boolean keys,
+// which are written as strings in JSON. OpenAPI 3.0 has no propertyNames keyword, so
+// the format of the keys can only be given in the description of the map.
+func mapKeyDescription(field protoreflect.FieldDescriptor) string {
+	if !field.IsMap() {
+		return ""
+	}
+	kind := field.MapKey().Kind()
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "Keys are " + kind.String() + " values written as strings that match ^-?[0-9]+$."
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "Keys are " + kind.String() + " values written as strings that match ^[0-9]+$."
+	case protoreflect.BoolKind:
+		return "Keys are bool values written as strings that match ^(true|false)$."
+	}
+	return ""
+}
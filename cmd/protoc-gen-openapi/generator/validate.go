@@ -0,0 +1,314 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	v3 "github.com/google/gnostic/openapiv3"
+)
+
+// The extensions of protoc-gen-validate and protovalidate for the rules of fields, and the
+// extension of protovalidate for the rules of messages. Their rules for fields have the same
+// names, so they are translated alike.
+const (
+	validateRulesName      = "validate.rules"
+	bufValidateFieldName   = "buf.validate.field"
+	bufValidateMessageName = "buf.validate.message"
+)
+
+// findValidateRules returns a resolver for the extensions of protoc-gen-validate and
+// protovalidate, or nil if none of the files declares them. Neither is a dependency of the
+// generator, so the extensions are read with the descriptors of validate.proto that protoc
+// sends with the files that import them.
+func findValidateRules(files []*protogen.File) *protoregistry.Types {
+	var types *protoregistry.Types
+	for _, file := range files {
+		extensions := file.Desc.Extensions()
+		for i := 0; i < extensions.Len(); i++ {
+			extension := extensions.Get(i)
+			switch extension.FullName() {
+			case validateRulesName, bufValidateFieldName, bufValidateMessageName:
+			default:
+				continue
+			}
+			if extension.Message() == nil {
+				continue
+			}
+			if types == nil {
+				types = new(protoregistry.Types)
+			}
+			if err := types.RegisterExtension(dynamicpb.NewExtensionType(extension)); err != nil {
+				log.Printf("the %s annotations are ignored: %v", extension.FullName(), err)
+			}
+		}
+	}
+	return types
+}
+
+// validateExtension returns the value of an extension of validate.proto in options, or nil
+// if it isn't set or validate_rules isn't set.
+func (r *OpenAPIv3Reflector) validateExtension(options proto.Message, name protoreflect.FullName) protoreflect.Message {
+	if r.validateRules == nil || options == nil || !options.ProtoReflect().IsValid() {
+		return nil
+	}
+	extension, err := r.validateRules.FindExtensionByName(name)
+	if err != nil {
+		return nil
+	}
+	// The options were parsed without the extension, so it is kept in their unknown fields.
+	b, err := proto.Marshal(options)
+	if err != nil || len(b) == 0 {
+		return nil
+	}
+	parsed := options.ProtoReflect().New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: r.validateRules}).Unmarshal(b, parsed); err != nil {
+		log.Printf("the %s annotations are ignored: %v", name, err)
+		return nil
+	}
+	if !parsed.ProtoReflect().Has(extension.TypeDescriptor()) {
+		return nil
+	}
+	return parsed.ProtoReflect().Get(extension.TypeDescriptor()).Message()
+}
+
+// validateRulesForField returns the validate.rules and buf.validate.field annotations of a field.
+func (r *OpenAPIv3Reflector) validateRulesForField(field protoreflect.FieldDescriptor) []protoreflect.Message {
+	var rules []protoreflect.Message
+	for _, name := range []protoreflect.FullName{validateRulesName, bufValidateFieldName} {
+		if extension := r.validateExtension(field.Options(), name); extension != nil {
+			rules = append(rules, extension)
+		}
+	}
+	return rules
+}
+
+// isValidateRequiredField returns true if the buf.validate.field annotation of a field
+// has the required rule.
+func (r *OpenAPIv3Reflector) isValidateRequiredField(field protoreflect.FieldDescriptor) bool {
+	for _, rules := range r.validateRulesForField(field) {
+		if required, ok := validateRule(rules, "required"); ok && required.Bool() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMessageDescription appends the CEL expressions of the buf.validate.message
+// annotation of a message to its description, with their messages, since they can't be
+// written as keywords of its schema.
+func (r *OpenAPIv3Reflector) validateMessageDescription(message protoreflect.MessageDescriptor, description string) string {
+	rules := r.validateExtension(message.Options(), bufValidateMessageName)
+	if rules == nil {
+		return description
+	}
+	var items []string
+	if field := rules.Descriptor().Fields().ByName("cel"); field != nil && field.IsList() {
+		list := rules.Get(field).List()
+		for i := 0; i < list.Len(); i++ {
+			expression, _ := validateRule(list.Get(i).Message(), "expression")
+			if expression.String() == "" {
+				continue
+			}
+			item := "- `" + expression.String() + "`"
+			if text, ok := validateRule(list.Get(i).Message(), "message"); ok && text.String() != "" {
+				item += ": " + text.String()
+			}
+			items = append(items, item)
+		}
+	}
+	if field := rules.Descriptor().Fields().ByName("cel_expression"); field != nil && field.IsList() {
+		list := rules.Get(field).List()
+		for i := 0; i < list.Len(); i++ {
+			items = append(items, "- `"+list.Get(i).String()+"`")
+		}
+	}
+	if len(items) == 0 {
+		return description
+	}
+	return strings.TrimSpace(description + "\n\nValidation rules:\n" + strings.Join(items, "\n"))
+}
+
+// validateStringFormats maps the well-known string rules of protoc-gen-validate and
+// protovalidate to the formats of OpenAPI.
+var validateStringFormats = map[protoreflect.Name]string{
+	"email":    "email",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"uri":      "uri",
+	"uri_ref":  "uri-reference",
+	"uuid":     "uuid",
+}
+
+// numericValidateRules are the rules for numbers, by the name of their field in the
+// FieldRules of protoc-gen-validate and protovalidate.
+var numericValidateRules = map[protoreflect.Name]bool{
+	"float": true, "double": true,
+	"int32": true, "int64": true, "uint32": true, "uint64": true,
+	"sint32": true, "sint64": true, "fixed32": true, "fixed64": true,
+	"sfixed32": true, "sfixed64": true,
+}
+
+// applyValidateRules sets the keywords of a schema that correspond to the validate.rules or
+// buf.validate.field annotation of its field. Rules that don't apply to the type of the schema or that have no equivalent
+// in OpenAPI, like the rules of 64-bit integers whose schemas are strings, are ignored.
+func applyValidateRules(schema *v3.Schema, rules protoreflect.Message) {
+	if schema == nil || rules == nil {
+		return
+	}
+	oneof := rules.Descriptor().Oneofs().ByName("type")
+	if oneof == nil {
+		return
+	}
+	kind := rules.WhichOneof(oneof)
+	if kind == nil || kind.Message() == nil {
+		return
+	}
+	typed := rules.Get(kind).Message()
+	switch {
+	case kind.Name() == "string" && schema.Type == "string":
+		if length, ok := validateRule(typed, "len"); ok {
+			schema.MinLength = int64(length.Uint())
+			schema.MaxLength = int64(length.Uint())
+		}
+		if length, ok := validateRule(typed, "min_len"); ok {
+			schema.MinLength = int64(length.Uint())
+		}
+		if length, ok := validateRule(typed, "max_len"); ok {
+			schema.MaxLength = int64(length.Uint())
+		}
+		if pattern, ok := validateRule(typed, "pattern"); ok {
+			schema.Pattern = pattern.String()
+		}
+		for name, format := range validateStringFormats {
+			if set, ok := validateRule(typed, name); ok && set.Bool() {
+				schema.Format = format
+			}
+		}
+
+	case kind.Name() == "repeated" && schema.Type == "array":
+		if count, ok := validateRule(typed, "min_items"); ok {
+			schema.MinItems = int64(count.Uint())
+		}
+		if count, ok := validateRule(typed, "max_items"); ok {
+			schema.MaxItems = int64(count.Uint())
+		}
+		if unique, ok := validateRule(typed, "unique"); ok {
+			schema.UniqueItems = unique.Bool()
+		}
+		if items, ok := validateRule(typed, "items"); ok && len(schema.GetItems().GetSchemaOrReference()) > 0 {
+			applyValidateRules(schema.Items.SchemaOrReference[0].GetSchema(), items.Message())
+		}
+
+	case kind.Name() == "map" && schema.Type == "object":
+		if count, ok := validateRule(typed, "min_pairs"); ok {
+			schema.MinProperties = int64(count.Uint())
+		}
+		if count, ok := validateRule(typed, "max_pairs"); ok {
+			schema.MaxProperties = int64(count.Uint())
+		}
+
+	case numericValidateRules[kind.Name()] && (schema.Type == "integer" || schema.Type == "number"):
+		applyValidateBounds(schema, typed)
+	}
+}
+
+// applyValidateBounds sets the minimum and maximum of a schema from the gt, gte, lt and lte
+// rules of a number. Exclusive bounds of integers are written as the inclusive bounds next
+// to them. Bounds of zero can't be written, so exclusive bounds of zero are left out rather
+// than written as an exclusiveMinimum or exclusiveMaximum without a bound.
+func applyValidateBounds(schema *v3.Schema, rules protoreflect.Message) {
+	integer := schema.Type == "integer"
+	var minimum, maximum *float64
+	var exclusiveMinimum, exclusiveMaximum bool
+	if bound, ok := validateBound(rules, "gte"); ok {
+		minimum = &bound
+	}
+	if bound, ok := validateBound(rules, "gt"); ok {
+		if integer {
+			bound++
+		} else {
+			exclusiveMinimum = bound != 0
+		}
+		minimum = &bound
+	}
+	if bound, ok := validateBound(rules, "lte"); ok {
+		maximum = &bound
+	}
+	if bound, ok := validateBound(rules, "lt"); ok {
+		if integer {
+			bound--
+		} else {
+			exclusiveMaximum = bound != 0
+		}
+		maximum = &bound
+	}
+	// A lower bound above the upper bound excludes the range between them, which a
+	// schema can't describe.
+	if minimum != nil && maximum != nil && *minimum > *maximum {
+		return
+	}
+	if minimum != nil {
+		schema.Minimum = *minimum
+		schema.ExclusiveMinimum = exclusiveMinimum
+	}
+	if maximum != nil {
+		schema.Maximum = *maximum
+		schema.ExclusiveMaximum = exclusiveMaximum
+	}
+}
+
+// validateRule returns the value of a rule of a message of validate.proto, if it is set.
+func validateRule(rules protoreflect.Message, name protoreflect.Name) (protoreflect.Value, bool) {
+	field := rules.Descriptor().Fields().ByName(name)
+	if field == nil || field.IsList() || !rules.Has(field) {
+		return protoreflect.Value{}, false
+	}
+	return rules.Get(field), true
+}
+
+// validateBound returns the value of a bound of the rules of a number as a float64.
+func validateBound(rules protoreflect.Message, name protoreflect.Name) (float64, bool) {
+	value, ok := validateRule(rules, name)
+	if !ok {
+		return 0, false
+	}
+	switch bound := value.Interface().(type) {
+	case int32:
+		return float64(bound), true
+	case int64:
+		return float64(bound), true
+	case uint32:
+		return float64(bound), true
+	case uint64:
+		return float64(bound), true
+	case float32:
+		// Written with the precision of a float32, so that 0.1 is 0.1 rather than 0.10000000149011612.
+		bound64, err := strconv.ParseFloat(strconv.FormatFloat(float64(bound), 'g', -1, 32), 64)
+		return bound64, err == nil
+	case float64:
+		return bound, true
+	}
+	return 0, false
+}
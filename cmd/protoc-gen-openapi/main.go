@@ -16,12 +16,21 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/google/gnostic/cmd/protoc-gen-openapi/generator"
+	v3 "github.com/google/gnostic/openapiv3"
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -29,41 +38,248 @@ var flags flag.FlagSet
 
 func main() {
 	conf := generator.Configuration{
-		Version:           flags.String("version", "0.0.1", "version number text, e.g. 1.2.3"),
-		Title:             flags.String("title", "", "name of the API"),
-		Description:       flags.String("description", "", "description of the API"),
-		Naming:            flags.String("naming", "json", `naming convention. Use "proto" for passing names directly from the proto files`),
-		FQSchemaNaming:    flags.Bool("fq_schema_naming", false, `schema naming convention. If "true", generates fully-qualified schema names by prefixing them with the proto message package name`),
-		EnumType:          flags.String("enum_type", "integer", `type for enum serialization. Use "string" for string-based serialization`),
-		CircularDepth:     flags.Int("depth", 2, "depth of recursion for circular messages"),
-		DefaultResponse:   flags.Bool("default_response", true, `add default response. If "true", automatically adds a default response to operations which use the google.rpc.Status message. Useful if you use envoy or grpc-gateway to transcode as they use this type for their default error responses.`),
-		OutputMode:        flags.String("output_mode", "merged", `output generation mode. By default, a single openapi.yaml is generated at the out folder. Use "source_relative' to generate a separate '[inputfile].openapi.yaml' next to each '[inputfile].proto'.`),
-		WildcardBodyDedup: flags.Bool("wildcard_body_dedup", false, `removes path parameter overlap from wildcard body schemas. If "true", generates a separate schema for an operation's request body without the overlapping fields.`),
+		Version:                  flags.String("version", "0.0.1", "version number text, e.g. 1.2.3"),
+		Title:                    flags.String("title", "", "name of the API"),
+		TitleTemplate:            flags.String("title_template", "", `name of the API with placeholders, e.g. "{service} ({package})". {package} and {service} are replaced with the packages and services of each document, which is useful with output_mode=source_relative`),
+		Description:              flags.String("description", "", "description of the API"),
+		ContactEmail:             flags.String("contact_email", "", "email address of the contact for the API"),
+		License:                  flags.String("license", "", "name of the license of the API"),
+		TermsOfService:           flags.String("terms_of_service", "", "URL of the terms of service of the API"),
+		Naming:                   flags.String("naming", "json", `naming convention. Use "proto" for passing names directly from the proto files`),
+		FQSchemaNaming:           flags.Bool("fq_schema_naming", false, `schema naming convention. If "true", generates fully-qualified schema names by prefixing them with the proto message package name`),
+		EnumType:                 flags.String("enum_type", "integer", `type for enum serialization. Use "string" for string-based serialization`),
+		CircularDepth:            flags.Int("depth", 2, "depth of recursion for circular messages"),
+		DefaultResponse:          flags.Bool("default_response", true, `add default response. If "true", automatically adds a default response to operations which use the google.rpc.Status message. Useful if you use envoy or grpc-gateway to transcode as they use this type for their default error responses.`),
+		OutputMode:               flags.String("output_mode", "merged", `output generation mode. By default, a single openapi.yaml is generated at the out folder. Use "source_relative' to generate a separate '[inputfile].openapi.yaml' next to each '[inputfile].proto', or "per_service" to generate a separate '[service].openapi.yaml' for each service with HTTP annotations.`),
+		WildcardBodyDedup:        flags.Bool("wildcard_body_dedup", false, `removes path parameter overlap from wildcard body schemas. If "true", generates a separate schema for an operation's request body without the overlapping fields.`),
+		Workers:                  flags.Int("workers", 0, "number of goroutines that build schemas. The default of 0 uses one for each available CPU"),
+		WarningsHeader:           flags.Bool("warnings_header", false, `list skipped methods and truncated query parameters in a comment at the top of the output. They are always logged.`),
+		DefaultResponseRef:       flags.Bool("default_response_ref", false, `share the default response. If "true", the default response of each operation refers to a single response in components.responses instead of repeating it.`),
+		DefaultResponseName:      flags.String("default_response_name", "Default", `name of the shared default response in components.responses, used with default_response_ref`),
+		MaxDescriptionLength:     flags.Int("max_description_length", 0, "number of characters that descriptions are truncated to at a word boundary. The default of 0 doesn't truncate descriptions"),
+		DedupeIdenticalSchemas:   flags.Bool("dedupe_identical_schemas", false, `replaces schemas that are identical to another schema with references to it. If "true", the schema whose name sorts first is kept and the others refer to it`),
+		DedupeIgnoreDescriptions: flags.Bool("dedupe_ignore_descriptions", true, `ignore descriptions when comparing schemas for dedupe_identical_schemas`),
+		OutputFormat:             flags.String("output_format", "yaml", `output format. Use "json" to write openapi.json, '[inputfile].openapi.json' with output_mode=source_relative or '[service].openapi.json' with output_mode=per_service`),
+		Lint:                     flags.Bool("lint", false, `check the generated documents. If "true", unique operationIds, resolvable references, declared path parameters and a non-empty info.version are checked, and violations fail the plugin`),
+		TimestampFormat:          flags.String("timestamp_format", "date-time", `format of the schemas of google.protobuf.Timestamp fields. An empty format is omitted`),
+		DurationFormat:           flags.String("duration_format", "", `format of the schemas of google.protobuf.Duration fields. The default of an empty format is omitted`),
+		AllowDuplicatePaths:      flags.String("allow_duplicate_paths", "", `handling of methods that are bound to the same HTTP method and path. They are errors by default. Use "warn" to keep the first method and warn about the others`),
+		EnumVarnames:             flags.Bool("enum_varnames", false, `list the names of enum values. If "true", the schemas of integer enums list their values in enum and their names in an x-enum-varnames extension. It has no effect with enum_type=string`),
+		TagScopeExtensions:       flags.Bool("tag_scope_extensions", false, `list the scopes of services. If "true", the tag of each service lists the scopes of the security requirements of its operations in an x-required-scopes extension`),
+		ValidateRules:            flags.Bool("validate_rules", false, `translate the validation rules of protoc-gen-validate and protovalidate. If "true", rules like min_len, pattern, gte, max_items and required set the corresponding keywords of schemas`),
+		DryRun:                   flags.Bool("dry_run", false, `list the files that would be generated instead of writing them. If "true", only openapi.dry_run.txt is written, with the size and the numbers of paths and schemas of each file`),
+		CustomVerbs:              flags.String("custom_verbs", "", `handling of custom HTTP bindings whose kinds aren't methods of OpenAPI, like REPORT. They are skipped with a warning by default. Use "extension" to write their operations in an x-custom-method extension of their path items`),
+		NoComponents:             flags.Bool("no_components", false, `leave out components. If "true", the schemas and responses of components, including google.rpc.Status, are written in full wherever they are referenced. Recursive schemas and other components are errors`),
+		Streaming:                flags.String("streaming", "", `handling of streaming methods. By default they are described like unary methods. Use "skip" to leave them out, "array" to make the responses of server-streaming methods arrays of their messages, or "sse" to write them as text/event-stream`),
+		OperationID:              flags.String("operation_id", "service_method", `naming scheme of operationIds. By default they are '[service]_[method]'. Use "method" for '[method]', or "fqn" for the full name of the method with dots replaced by underscores, which also names tags after the full names of services`),
+		SchemaCase:               flags.String("schema_case", "proto", `case of the names of schemas in components and of the references to them. By default they keep the case of their messages, like BookShelf. Use "snake" for book_shelf or "camel" for bookShelf`),
+		JSONNames:                flags.Bool("json_names", true, `name properties, query parameters and required fields after the JSON names of fields. If "false", they are named after the proto names of fields, like the JSON of gateways with UseProtoNames, and path parameters keep the names that are written in path templates`),
+		DefaultHost:              flags.String("default_host", "", `URL of the server of documents whose document annotations declare no servers, e.g. api.example.com, which is served over https, or http://localhost:8080`),
+		Tags:                     flags.String("tags", "service", `tags of operations. By default operations are tagged with their services, described by the comments of the services. Use "package" to tag them with the packages of their services, described by the comments of the package statements`),
+		SecurityScheme:           flags.String("security_scheme", "", `security scheme of documents, as name:type[:params]. Use "apiKey" with the name of a header, e.g. ApiKeyAuth:apiKey:X-API-Key, "http" with a scheme, which is bearer by default, e.g. BearerAuth:http, or "oauth2" with the token URL of the client credentials flow, e.g. OAuth:oauth2:https://example.com/oauth/token`),
+		Security:                 flags.String("security", "", `name of the security scheme that every operation of documents requires, followed by its scopes, e.g. OAuth;messages.read;messages.write`),
+		MappingOut:               flags.Bool("mapping_out", false, `write the mapping of operations next to each document. If "true", '[document].mapping.yaml' or '[document].mapping.json', like openapi.mapping.yaml, lists the operationId, the gRPC method and the request and response messages of each operation`),
 	}
 
 	opts := protogen.Options{
 		ParamFunc: flags.Set,
 	}
 
-	opts.Run(func(plugin *protogen.Plugin) error {
-		// Enable "optional" keyword in front of type (e.g. optional string label = 1;)
-		plugin.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
-		if *conf.OutputMode == "source_relative" {
-			for _, file := range plugin.Files {
-				if !file.Generate {
+	err := run(os.Stdin, os.Stdout, opts, func(plugin *protogen.Plugin) error {
+		switch *conf.OutputMode {
+		case "merged", "source_relative", "per_service":
+		default:
+			return fmt.Errorf(`unknown output_mode %q, expected "merged", "source_relative" or "per_service"`, *conf.OutputMode)
+		}
+		if *conf.OutputFormat != "yaml" && *conf.OutputFormat != "json" {
+			return fmt.Errorf(`unknown output_format %q, expected "yaml" or "json"`, *conf.OutputFormat)
+		}
+		if *conf.AllowDuplicatePaths != "" && *conf.AllowDuplicatePaths != "warn" {
+			return fmt.Errorf(`unknown allow_duplicate_paths %q, expected "warn"`, *conf.AllowDuplicatePaths)
+		}
+		if *conf.CustomVerbs != "" && *conf.CustomVerbs != "extension" {
+			return fmt.Errorf(`unknown custom_verbs %q, expected "extension"`, *conf.CustomVerbs)
+		}
+		switch *conf.Streaming {
+		case "", "skip", "array", "sse":
+		default:
+			return fmt.Errorf(`unknown streaming %q, expected "skip", "array" or "sse"`, *conf.Streaming)
+		}
+		switch *conf.OperationID {
+		case "service_method", "method", "fqn":
+		default:
+			return fmt.Errorf(`unknown operation_id %q, expected "service_method", "method" or "fqn"`, *conf.OperationID)
+		}
+		switch *conf.SchemaCase {
+		case "proto", "snake", "camel":
+		default:
+			return fmt.Errorf(`unknown schema_case %q, expected "proto", "snake" or "camel"`, *conf.SchemaCase)
+		}
+		if *conf.Tags != "service" && *conf.Tags != "package" {
+			return fmt.Errorf(`unknown tags %q, expected "service" or "package"`, *conf.Tags)
+		}
+		outputs, err := generate(plugin, conf)
+		if err != nil || !*conf.DryRun {
+			return err
+		}
+		return writeDryRunSummary(plugin, outputs)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", filepath.Base(os.Args[0]), err)
+		os.Exit(1)
+	}
+}
+
+// output is a file that was generated for a plugin request, with its mapping if
+// mapping_out is true.
+type output struct {
+	name    string
+	file    *protogen.GeneratedFile
+	mapping *protogen.GeneratedFile
+}
+
+// runGenerator writes the document of a generator to a file with a name, and with
+// mapping_out=true, its mapping to a file next to it, like openapi.mapping.yaml.
+func runGenerator(plugin *protogen.Plugin, conf generator.Configuration, gen *generator.OpenAPIv3Generator, name string) (output, error) {
+	out := output{name: name, file: plugin.NewGeneratedFile(name, "")}
+	if err := gen.Run(out.file); err != nil {
+		return out, err
+	}
+	if conf.MappingOut == nil || !*conf.MappingOut {
+		return out, nil
+	}
+	ext := filepath.Ext(name)
+	out.mapping = plugin.NewGeneratedFile(strings.TrimSuffix(name, ext)+".mapping"+ext, "")
+	return out, gen.RunMapping(out.mapping)
+}
+
+// generate generates the documents of a plugin request, one for each file to generate with
+// output_mode=source_relative, one for each service with HTTP annotations with
+// output_mode=per_service or a single one otherwise.
+func generate(plugin *protogen.Plugin, conf generator.Configuration) ([]output, error) {
+	if *conf.OutputMode == "per_service" {
+		var outputs []output
+		var errs []error
+		services := map[string]protoreflect.FullName{}
+		for _, file := range plugin.Files {
+			if !file.Generate {
+				continue
+			}
+			for _, service := range file.Services {
+				if !hasHTTPAnnotations(service) {
+					continue
+				}
+				outfileName := string(service.Desc.Name()) + ".openapi." + *conf.OutputFormat
+				if other, ok := services[outfileName]; ok {
+					errs = append(errs, fmt.Errorf("%s and %s would both be written to %s", other, service.Desc.FullName(), outfileName))
 					continue
 				}
-				outfileName := strings.TrimSuffix(file.Desc.Path(), filepath.Ext(file.Desc.Path())) + ".openapi.yaml"
-				outputFile := plugin.NewGeneratedFile(outfileName, "")
-				gen := generator.NewOpenAPIv3Generator(plugin, conf, []*protogen.File{file})
-				if err := gen.Run(outputFile); err != nil {
-					return err
+				services[outfileName] = service.Desc.FullName()
+				gen := generator.NewServiceOpenAPIv3Generator(plugin, conf, file, service)
+				out, err := runGenerator(plugin, conf, gen, outfileName)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", service.Desc.FullName(), err))
 				}
+				outputs = append(outputs, out)
 			}
-		} else {
-			outputFile := plugin.NewGeneratedFile("openapi.yaml", "")
-			return generator.NewOpenAPIv3Generator(plugin, conf, plugin.Files).Run(outputFile)
 		}
-		return nil
-	})
+		return outputs, errors.Join(errs...)
+	}
+	if *conf.OutputMode == "source_relative" {
+		var outputs []output
+		var errs []error
+		for _, file := range plugin.Files {
+			if !file.Generate {
+				continue
+			}
+			outfileName := strings.TrimSuffix(file.Desc.Path(), filepath.Ext(file.Desc.Path())) + ".openapi." + *conf.OutputFormat
+			gen := generator.NewOpenAPIv3Generator(plugin, conf, []*protogen.File{file})
+			out, err := runGenerator(plugin, conf, gen, outfileName)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", file.Desc.Path(), err))
+			}
+			outputs = append(outputs, out)
+		}
+		return outputs, errors.Join(errs...)
+	}
+	outfileName := "openapi." + *conf.OutputFormat
+	out, err := runGenerator(plugin, conf, generator.NewOpenAPIv3Generator(plugin, conf, plugin.Files), outfileName)
+	return []output{out}, err
+}
+
+// hasHTTPAnnotations returns true if a method of a service has a google.api.http annotation.
+func hasHTTPAnnotations(service *protogen.Service) bool {
+	for _, method := range service.Methods {
+		if proto.HasExtension(method.Desc.Options(), annotations.E_Http) {
+			return true
+		}
+	}
+	return false
+}
+
+// dryRunSummaryName is the name of the file that dry_run writes instead of the documents.
+const dryRunSummaryName = "openapi.dry_run.txt"
+
+// writeDryRunSummary replaces the generated documents with a summary that lists the name,
+// the size in bytes and the numbers of paths and schemas of each of them, separated by tabs
+// and sorted by name.
+func writeDryRunSummary(plugin *protogen.Plugin, outputs []output) error {
+	lines := make([]string, 0, len(outputs))
+	for _, output := range outputs {
+		content, err := output.file.Content()
+		if err != nil {
+			return err
+		}
+		// JSON is also YAML, so documents of either format can be read back.
+		d, err := v3.ParseDocument(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", output.name, err)
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%d\t%d\t%d", output.name, len(content),
+			len(d.GetPaths().GetPath()), len(d.GetComponents().GetSchemas().GetAdditionalProperties())))
+		output.file.Skip()
+		if output.mapping != nil {
+			output.mapping.Skip()
+		}
+	}
+	slices.Sort(lines)
+	summary := plugin.NewGeneratedFile(dryRunSummaryName, "")
+	summary.P("file\tbytes\tpaths\tschemas")
+	for _, line := range lines {
+		summary.P(line)
+	}
+	return nil
+}
+
+// run reads a CodeGeneratorRequest from in, calls f and writes the CodeGeneratorResponse to out.
+// Unlike protogen.Options.Run, it reports invalid parameters in the error of the response
+// along with the errors of f, so that protoc passes them on. Errors that are returned are
+// those of reading the request and writing the response.
+func run(in io.Reader, out io.Writer, opts protogen.Options, f func(*protogen.Plugin) error) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	req := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		return err
+	}
+	var resp *pluginpb.CodeGeneratorResponse
+	plugin, err := opts.New(req)
+	if err != nil {
+		resp = &pluginpb.CodeGeneratorResponse{Error: proto.String(err.Error())}
+	} else {
+		if err := f(plugin); err != nil {
+			plugin.Error(err)
+		}
+		resp = plugin.Response()
+	}
+	// Enable "optional" keyword in front of type (e.g. optional string label = 1;)
+	resp.SupportedFeatures = proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL))
+	data, err = proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
 }
@@ -16,15 +16,24 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/pkg/diff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
 )
 
 const testPlugin = `protoc-gen-openapi-test`
@@ -33,6 +42,11 @@ var (
 	regenerate bool
 	protoc     string
 	pluginPath string
+	// reportCollisions lists the fixture directories with identical outputs after the tests.
+	reportCollisions bool
+	// fixtureOutputs maps the hash of each generated fixture output to the fixture
+	// directories that it was generated for, in the order of the tests.
+	fixtureOutputs = map[string][]string{}
 )
 
 func TestGenOpenAPI(t *testing.T) {
@@ -40,20 +54,137 @@ func TestGenOpenAPI(t *testing.T) {
 	fixtureTest(t, "additional bindings", "examples/tests/additional_bindings/message.proto")
 	fixtureTest(t, "allof wrapping", "examples/tests/allofwrap/message.proto")
 	fixtureTest(t, "body mapping", "examples/tests/bodymapping/message.proto")
+	fixtureTest(t, "response body", "examples/tests/response_body/message.proto")
 	fixtureTest(t, "linter comments", "examples/tests/lintercomments/message.proto")
 	fixtureTest(t, "map fields", "examples/tests/mapfields/message.proto")
 	fixtureTest(t, "skip unannotated services", "examples/tests/noannotations/message.proto")
 	fixtureTest(t, "openapiv3annotations", "examples/tests/openapiv3annotations/message.proto")
 	fixtureTest(t, "path parameters", "examples/tests/pathparams/message.proto")
+	fixtureTest(t, "nested query parameters", "examples/tests/nested_query_params/message.proto")
+	fixtureTest(t, "custom verbs", "examples/tests/custom_verbs/message.proto")
 	fixtureTest(t, "path param hints", "examples/tests/pathparamhints/message.proto")
+	fixtureTest(t, "operation servers", "examples/tests/operation_servers/message.proto")
+	fixtureTest(t, "document servers", "examples/tests/document_servers/message.proto")
+	fixtureTest(t, "tags", "examples/tests/tags/message.proto")
 	fixtureTest(t, "protobuf types", "examples/tests/protobuftypes/message.proto")
+	fixtureTest(t, "field mask", "examples/tests/field_mask/message.proto")
 	fixtureTest(t, "json options", "examples/tests/jsonoptions/message.proto")
+	fixtureTest(t, "json names", "examples/tests/json_names/message.proto")
+	fixtureTest(t, "field info", "examples/tests/field_info/message.proto")
+	fixtureTest(t, "array constraints", "examples/tests/array_constraints/message.proto")
+	fixtureTest(t, "message examples", "examples/tests/message_examples/message.proto")
+	fixtureTest(t, "operation examples", "examples/tests/operation_examples/message.proto")
+	fixtureTest(t, "enum descriptions", "examples/tests/enum_descriptions/message.proto")
+	fixtureTest(t, "allow reserved", "examples/tests/allow_reserved/message.proto")
+	fixtureTest(t, "required fields", "examples/tests/required_fields/message.proto")
+	fixtureTest(t, "deprecated", "examples/tests/deprecated/message.proto")
+	fixtureTest(t, "nested names", "examples/tests/nested_names/message.proto")
+	fixtureTest(t, "operation annotations", "examples/tests/operation_annotations/message.proto")
+	fixtureTest(t, "header parameters", "examples/tests/header_parameters/message.proto")
+	fixtureTest(t, "extension values", "examples/tests/extension_values/message.proto")
+	fixtureTest(t, "comments", "examples/tests/comments/message.proto")
+	fixtureTest(t, "path servers", "examples/tests/path_servers/message.proto")
+	fixtureTest(t, "shared servers", "examples/tests/shared_servers/message.proto")
+	fixtureTest(t, "path field names", "examples/tests/path_field_names/message.proto")
 	optionFixtureTest(t, "proto naming", "examples/tests/naming_proto/message.proto", "naming=proto")
 	optionFixtureTest(t, "string enums", "examples/tests/enumoptions/message.proto", "enum_type=string")
 	optionFixtureTest(t, "wildcard_body_dedup", "examples/tests/wildcard_body_dedup/message.proto", "wildcard_body_dedup=true")
+	optionFixtureTest(t, "field behavior", "examples/tests/field_behavior/message.proto", "wildcard_body_dedup=true")
 	optionFixtureTest(t, "no_default_response", "examples/tests/no_default_response/message.proto", "default_response=false")
 	optionFixtureTest(t, "circular depth", "examples/tests/circulardepth/message.proto", "depth=3")
 	optionFixtureTest(t, "fully-qualified schema naming", "examples/tests/fq_schema_naming/message.proto", "fq_schema_naming=true")
+	optionFixtureTest(t, "warnings header", "examples/tests/warnings_header/message.proto", "warnings_header=true")
+	optionFixtureTest(t, "default response ref", "examples/tests/default_response_ref/message.proto", "default_response_ref=true")
+	optionFixtureTest(t, "max description length", "examples/tests/max_description_length/message.proto", "max_description_length=200")
+	optionFixtureTest(t, "dedupe identical schemas", "examples/tests/dedupe_identical_schemas/message.proto", "dedupe_identical_schemas=true")
+	optionFixtureTest(t, "json output", "examples/tests/output_format/message.proto", "output_format=json")
+	optionFixtureTest(t, "time formats", "examples/tests/time_formats/message.proto", "timestamp_format=,duration_format=google-duration")
+	optionFixtureTest(t, "enum varnames", "examples/tests/enum_varnames/message.proto", "enum_varnames=true")
+	optionFixtureTest(t, "tag scope extensions", "examples/tests/tag_scopes/message.proto", "tag_scope_extensions=true")
+	optionFixtureTest(t, "validate rules", "examples/tests/validate_rules/message.proto", "validate_rules=true")
+	optionFixtureTest(t, "buf validate", "examples/tests/buf_validate/message.proto", "validate_rules=true")
+	optionFixtureTest(t, "custom methods", "examples/tests/custom_methods/message.proto", "custom_verbs=extension")
+	optionFixtureTest(t, "no components", "examples/tests/no_components/message.proto", "no_components=true")
+	optionFixtureTest(t, "streaming array", "examples/tests/streaming_array/message.proto", "streaming=array")
+	optionFixtureTest(t, "streaming sse", "examples/tests/streaming_sse/message.proto", "streaming=sse")
+	optionFixtureTest(t, "fully-qualified operation ids", "examples/tests/operation_id/message.proto", "operation_id=fqn")
+	optionFixtureTest(t, "proto field names", "examples/tests/jsonoptions_proto_names/message.proto", "json_names=false")
+	optionFixtureTest(t, "status schema", "examples/tests/status_schema/message.proto", "enum_type=string,json_names=false")
+	optionFixtureTest(t, "snake case schemas", "examples/tests/schema_case/message.proto", "schema_case=snake")
+	optionFixtureTest(t, "camel case fully-qualified schemas", "examples/tests/schema_case_fq/message.proto", "schema_case=camel,fq_schema_naming=true")
+	optionFixtureTest(t, "default host", "examples/tests/default_host/message.proto", "default_host=api.example.com")
+	optionFixtureTest(t, "package tags", "examples/tests/package_tags/message.proto", "tags=package")
+	optionFixtureTest(t, "security options", "examples/tests/security_options/message.proto", "security_scheme=BearerAuth:http,security=BearerAuth")
+	optionFixtureTest(t, "info options", "examples/tests/info_options/message.proto", "title=Messaging Reference,description=Reads the messages of the published API.,version=1.2.3")
+
+	// The fixtures of other combinations of options are in subdirectories of the fixtures of their files.
+	for _, test := range []struct {
+		name       string
+		protoFile  string
+		fixtureDir string
+		args       []string
+	}{
+		{"named default response ref", "examples/tests/default_response_ref/message.proto", "named", []string{"default_response_ref=true", "default_response_name=Error"}},
+		{"default response ref without default responses", "examples/tests/default_response_ref/message.proto", "no_default_response", []string{"default_response=false", "default_response_ref=true"}},
+		{"operation servers and default host", "examples/tests/operation_servers/message.proto", "default_host", []string{"default_host=api.example.com"}},
+		{"shared servers and default host with scheme", "examples/tests/shared_servers/message.proto", "default_host", []string{"default_host=http://localhost:8080"}},
+		{"title template", "examples/tests/info_options/message.proto", "title_template", []string{"title_template={service} ({package})"}},
+		{"merged services", "examples/tests/output_mode/per_service/library.proto", "merged", nil},
+		{"title template of merged services", "examples/tests/output_mode/per_service/library.proto", "title_template", []string{"title_template={service} API"}},
+		{"source_relative services", "examples/tests/output_mode/per_service/library.proto", "source_relative", []string{"output_mode=source_relative"}},
+		{"contact options", "examples/tests/info_options/message.proto", "contact_options", []string{"contact_email=api@example.com", "license=Apache 2.0", "terms_of_service=https://example.com/terms"}},
+		{"path field names with proto naming", "examples/tests/path_field_names/message.proto", "naming_proto", []string{"naming=proto"}},
+		{"dedupe schemas with descriptions", "examples/tests/dedupe_identical_schemas/message.proto", "descriptions", []string{"dedupe_identical_schemas=true", "dedupe_ignore_descriptions=false"}},
+		{"json mapping", "examples/tests/mapping_out/message.proto", "json", []string{"mapping_out=true", "output_format=json"}},
+		{"json message examples", "examples/tests/message_examples/message.proto", "json", []string{"output_format=json"}},
+		{"json extension values", "examples/tests/extension_values/message.proto", "json", []string{"output_format=json"}},
+		{"default time formats", "examples/tests/time_formats/message.proto", "default", nil},
+		{"custom time formats", "examples/tests/time_formats/message.proto", "custom", []string{"timestamp_format=rfc3339-nanos", "duration_format=google-duration"}},
+		{"string enum descriptions", "examples/tests/enum_descriptions/message.proto", "string", []string{"enum_type=string"}},
+		{"dedupe enum descriptions", "examples/tests/enum_descriptions/message.proto", "dedupe", []string{"dedupe_identical_schemas=true", "dedupe_ignore_descriptions=false"}},
+		{"dedupe enum varnames", "examples/tests/enum_descriptions/message.proto", "enum_varnames", []string{"enum_varnames=true", "dedupe_identical_schemas=true"}},
+		{"string enum maps", "examples/tests/mapfields/message.proto", "string", []string{"enum_type=string"}},
+		{"tag scope extensions of packages", "examples/tests/tag_scopes/message.proto", "package_tags", []string{"tag_scope_extensions=true", "tags=package"}},
+		{"security options and annotations", "examples/tests/tag_scopes/message.proto", "security_options", []string{"security_scheme=OAuth:http", "security=OAuth;messages.read"}},
+		{"api key security scheme", "examples/tests/security_options/message.proto", "api_key", []string{"security_scheme=ApiKeyAuth:apiKey:X-API-Key"}},
+		{"basic security scheme", "examples/tests/security_options/message.proto", "basic", []string{"security_scheme=BasicAuth:http:basic", "security=BasicAuth"}},
+		{"oauth2 security scheme", "examples/tests/security_options/message.proto", "oauth2", []string{"security_scheme=Client:oauth2:https://auth.example.com/token", "security=Client;messages.read;messages.write"}},
+		{"fully-qualified nested names", "examples/tests/nested_names/message.proto", "fq_schema_naming", []string{"fq_schema_naming=true"}},
+		{"skipped custom methods", "examples/tests/custom_methods/message.proto", "warnings_header", []string{"warnings_header=true"}},
+		{"streaming methods", "examples/tests/streaming_array/message.proto", "warnings_header", []string{"warnings_header=true"}},
+		{"skipped streaming methods", "examples/tests/streaming_array/message.proto", "skip", []string{"streaming=skip", "warnings_header=true"}},
+	} {
+		variantFixtureTest(t, test.name, []string{test.protoFile}, filepath.Join(filepath.Dir(test.protoFile), test.fixtureDir), test.args...)
+	}
+	// Options that don't apply leave the output unchanged.
+	unchangedFixtureTest(t, "annotation and title template", "examples/tests/openapiv3annotations/message.proto", "examples/tests/openapiv3annotations", "title_template={service} ({package})")
+	unchangedFixtureTest(t, "annotation and info options", "examples/tests/openapiv3annotations/message.proto", "examples/tests/openapiv3annotations", "title=Messages", "description=The messages.", "version=1.2.3")
+	unchangedFixtureTest(t, "annotation and contact options", "examples/tests/openapiv3annotations/message.proto", "examples/tests/openapiv3annotations", "contact_email=api@example.com", "license=Apache 2.0", "terms_of_service=https://example.com/terms")
+	unchangedFixtureTest(t, "enum varnames of string enums", "examples/tests/enum_descriptions/message.proto", "examples/tests/enum_descriptions/string", "enum_type=string", "enum_varnames=true")
+	unchangedFixtureTest(t, "no components and default response ref", "examples/tests/no_components/message.proto", "examples/tests/no_components", "no_components=true", "default_response_ref=true")
+
+	// Both packages have an AdminService with a GetStatus method and messages with the same names.
+	collisionFiles := []string{
+		"examples/tests/package_collisions/a/admin.proto",
+		"examples/tests/package_collisions/b/admin.proto",
+	}
+	t.Run("package collisions", func(t *testing.T) {
+		outputDir, err := generateOpenAPI(t, collisionFiles)
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
+		}
+		checkFixtures(t, outputDir, "examples/tests/package_collisions")
+	})
+	orderingTest(t, "package collisions file ordering", collisionFiles)
+	variantFixtureTest(t, "package collisions warnings", collisionFiles, "examples/tests/package_collisions/warnings_header", "warnings_header=true")
+
+	outputModeFiles := []string{
+		"examples/tests/output_mode/source_relative/service_a/testservice.proto",
+		"examples/tests/output_mode/source_relative/service_b/testservice.proto",
+		"examples/tests/output_mode/source_relative/shared.proto",
+	}
+	orderingTest(t, "merged file ordering", outputModeFiles)
+	orderingTest(t, "source_relative file ordering", outputModeFiles, "output_mode=source_relative")
 }
 
 func TestOutputMode(t *testing.T) {
@@ -69,20 +200,38 @@ func TestOutputMode(t *testing.T) {
 			t.Fatalf("generating openapi: %v", err)
 		}
 		outputDir = filepath.Join(outputDir, "tests/output_mode/source_relative")
+		checkFixtures(t, outputDir, fixtureDir)
+	})
+
+	t.Run("source_relative title template", func(t *testing.T) {
+		fixtureDir := "examples/tests/output_mode/title_template"
+		outputDir, err := generateOpenAPI(t, protoFiles, "output_mode=source_relative", "title_template={service} ({package})")
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
+		}
+		outputDir = filepath.Join(outputDir, "tests/output_mode/source_relative")
+		checkFixtures(t, outputDir, fixtureDir)
+	})
 
-		if regenerate {
-			if diffTest(outputDir, fixtureDir) == nil {
-				t.Skip("no change to fixtures")
-			}
-			if err := cpr(outputDir, fixtureDir); err != nil {
-				t.Fatalf("error copying regenerated fixtures: %v", err)
-			}
-			t.Log("regenerated fixtures")
-			return
+	t.Run("source_relative mapping", func(t *testing.T) {
+		// Each document gets its own mapping.
+		fixtureDir := "examples/tests/output_mode/mapping"
+		outputDir, err := generateOpenAPI(t, protoFiles, "output_mode=source_relative", "mapping_out=true")
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
 		}
-		if err := diffTest(outputDir, fixtureDir); err != nil {
-			t.Fatalf("comparing fixtures:\n%v", err)
+		outputDir = filepath.Join(outputDir, "tests/output_mode/source_relative")
+		checkFixtures(t, outputDir, fixtureDir)
+	})
+
+	t.Run("source_relative json", func(t *testing.T) {
+		fixtureDir := "examples/tests/output_mode/json"
+		outputDir, err := generateOpenAPI(t, protoFiles, "output_mode=source_relative", "output_format=json")
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
 		}
+		outputDir = filepath.Join(outputDir, "tests/output_mode/source_relative")
+		checkFixtures(t, outputDir, fixtureDir)
 	})
 
 	t.Run("merged", func(t *testing.T) {
@@ -92,20 +241,333 @@ func TestOutputMode(t *testing.T) {
 		if err != nil {
 			t.Fatalf("generating openapi: %v", err)
 		}
-		if regenerate {
-			if diffTest(outputDir, fixtureDir) == nil {
-				t.Skip("no change to fixtures")
-			}
-			if err := cpr(outputDir, fixtureDir); err != nil {
-				t.Fatalf("error copying regenerated fixtures: %v", err)
-			}
-			t.Log("regenerated fixtures")
-			return
+		checkFixtures(t, outputDir, fixtureDir)
+	})
+
+	t.Run("per_service", func(t *testing.T) {
+		// Shelves and Books share the Shelf schema, and Admin has no HTTP annotations.
+		fixtureDir := "examples/tests/output_mode/per_service"
+		outputDir, err := generateOpenAPI(t, []string{fixtureDir + "/library.proto"}, "output_mode=per_service")
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
 		}
-		if err := diffTest(outputDir, fixtureDir); err != nil {
-			t.Fatalf("comparing fixtures:\n%v", err)
+		if _, err := os.Stat(filepath.Join(outputDir, "Admin.openapi.yaml")); !os.IsNotExist(err) {
+			t.Errorf("expected no document for the Admin service, got %v", err)
 		}
+		checkFixtures(t, outputDir, fixtureDir)
 	})
+
+	// dry_run replaces the documents of each mode with a summary of them.
+	for _, test := range []struct {
+		name       string
+		fixtureDir string
+		args       []string
+	}{
+		{"dry run merged", "examples/tests/output_mode/dry_run/merged", nil},
+		{"dry run source_relative", "examples/tests/output_mode/dry_run/source_relative", []string{"output_mode=source_relative"}},
+		{"dry run source_relative json", "examples/tests/output_mode/dry_run/json", []string{"output_mode=source_relative", "output_format=json"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			outputDir, err := generateOpenAPI(t, protoFiles, append(test.args, "dry_run=true")...)
+			if err != nil {
+				t.Fatalf("generating openapi: %v", err)
+			}
+			checkFixtures(t, outputDir, test.fixtureDir)
+		})
+	}
+}
+
+func TestMappingOut(t *testing.T) {
+	fixtureDir := "examples/tests/mapping_out"
+	protoFiles := []string{fixtureDir + "/message.proto"}
+	outputDir, err := generateOpenAPI(t, protoFiles, "mapping_out=true")
+	if err != nil {
+		t.Fatalf("generating openapi: %v", err)
+	}
+	checkFixtures(t, outputDir, fixtureDir)
+	outputDir, err = generateOpenAPI(t, protoFiles)
+	if err != nil {
+		t.Fatalf("generating openapi: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "openapi.mapping.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected no mapping by default, got %v", err)
+	}
+}
+
+// errorsRequest returns a plugin request for tests/errors.proto, whose Messaging
+// service has a method for each of the rules.
+func errorsRequest(parameter string, rules ...*annotations.HttpRule) *pluginpb.CodeGeneratorRequest {
+	const pkg = ".tests.errors.v1."
+	stringField := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+	}
+	service := &descriptorpb.ServiceDescriptorProto{Name: proto.String("Messaging")}
+	for i, rule := range rules {
+		options := &descriptorpb.MethodOptions{}
+		proto.SetExtension(options, annotations.E_Http, rule)
+		service.Method = append(service.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(fmt.Sprintf("Method%d", i)),
+			InputType:  proto.String(pkg + "Message"),
+			OutputType: proto.String(pkg + "Message"),
+			Options:    options,
+		})
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("tests/errors.proto"),
+		Package:    proto.String("tests.errors.v1"),
+		Dependency: []string{"google/api/annotations.proto"},
+		Syntax:     proto.String("proto3"),
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/errors")},
+		Service:    []*descriptorpb.ServiceDescriptorProto{service},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:  proto.String("Message"),
+			Field: []*descriptorpb.FieldDescriptorProto{stringField("message_id", 1), stringField("text", 2)},
+		}},
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		Parameter:      proto.String(parameter),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+			file,
+		},
+	}
+}
+
+// runPlugin runs the plugin binary with a request on its standard input and returns its response.
+func runPlugin(t *testing.T, request *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+	input, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(pluginPath)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("plugin invocation failed: %v\n%s", err, stderr.Bytes())
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(stdout.Bytes(), response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestPluginErrors(t *testing.T) {
+	get := func(path string) *annotations.HttpRule {
+		return &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: path}}
+	}
+	post := func(path, body string) *annotations.HttpRule {
+		return &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: path}, Body: body}
+	}
+	const (
+		unresolvableBody = `the body "message" of tests.errors.v1.Messaging.Method1 isn't a field of tests.errors.v1.Message`
+		collision        = "tests.errors.v1.Messaging.Method0 and tests.errors.v1.Messaging.Method2 are both bound to GET /v1/messages/{messageId}"
+	)
+	for _, test := range []struct {
+		name      string
+		parameter string
+		rules     []*annotations.HttpRule
+		errors    []string // Lines of the error of the response.
+	}{
+		{
+			name:  "valid",
+			rules: []*annotations.HttpRule{get("/v1/messages/{message_id}"), post("/v1/messages", "*")},
+		},
+		{
+			name:   "unresolvable body",
+			rules:  []*annotations.HttpRule{get("/v1/messages/{message_id}"), post("/v1/messages", "message")},
+			errors: []string{unresolvableBody},
+		},
+		{
+			name: "unresolvable response body",
+			rules: []*annotations.HttpRule{{
+				Pattern:      &annotations.HttpRule_Get{Get: "/v1/messages/{message_id}"},
+				ResponseBody: "items",
+			}},
+			errors: []string{`the response_body "items" of tests.errors.v1.Messaging.Method0 isn't a field of tests.errors.v1.Message`},
+		},
+		{
+			name:   "collision",
+			rules:  []*annotations.HttpRule{get("/v1/messages/{message_id}"), post("/v1/messages", "*"), get("/v1/messages/{message_id}")},
+			errors: []string{collision},
+		},
+		{
+			name:   "multiple errors",
+			rules:  []*annotations.HttpRule{get("/v1/messages/{message_id}"), post("/v1/messages", "message"), get("/v1/messages/{message_id}")},
+			errors: []string{unresolvableBody, collision},
+		},
+		{
+			name:   "malformed path",
+			rules:  []*annotations.HttpRule{get("/v1/messages/{message_id"), post("/v1/messages", "*")},
+			errors: []string{`the path "/v1/messages/{message_id" of tests.errors.v1.Messaging.Method0 is malformed: the { at offset 13 isn't closed`},
+		},
+		{
+			name:      "source_relative",
+			parameter: "output_mode=source_relative",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}"), post("/v1/messages", "message")},
+			errors:    []string{"tests/errors.proto: " + unresolvableBody},
+		},
+		{
+			name:      "unknown output format",
+			parameter: "output_format=toml",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{`unknown output_format "toml"`},
+		},
+		{
+			name:      "collision warning",
+			parameter: "allow_duplicate_paths=warn",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}"), post("/v1/messages", "*"), get("/v1/messages/{message_id}")},
+		},
+		{
+			name:      "unknown allow_duplicate_paths",
+			parameter: "allow_duplicate_paths=ignore",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{`unknown allow_duplicate_paths "ignore"`},
+		},
+		{
+			name:      "unknown custom_verbs",
+			parameter: "custom_verbs=skip",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{`unknown custom_verbs "skip"`},
+		},
+		{
+			name:      "unknown streaming",
+			parameter: "streaming=chunks",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{`unknown streaming "chunks", expected "skip", "array" or "sse"`},
+		},
+		{
+			name:      "unknown security scheme type",
+			parameter: "security_scheme=BasicAuth:basic",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{`unknown type "basic" of the security_scheme "BasicAuth:basic", expected "apiKey", "http" or "oauth2"`},
+		},
+		{
+			name:      "undeclared security scheme",
+			parameter: "security=BearerAuth",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{"the security option refers to the undeclared security scheme BearerAuth"},
+		},
+		{
+			name:      "unknown tags",
+			parameter: "tags=method",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{`unknown tags "method", expected "service" or "package"`},
+		},
+		{
+			name:      "lint",
+			parameter: "lint=true,version=",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{"lint: MISSING_INFO_VERSION: info.version is empty (info.version)"},
+		},
+		{
+			name:      "dry run error",
+			parameter: "dry_run=true",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}"), post("/v1/messages", "message")},
+			errors:    []string{unresolvableBody},
+		},
+		{
+			name:      "unknown parameter",
+			parameter: "colour=red",
+			rules:     []*annotations.HttpRule{get("/v1/messages/{message_id}")},
+			errors:    []string{"colour"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			response := runPlugin(t, errorsRequest(test.parameter, test.rules...))
+			if features := pluginpb.CodeGeneratorResponse_Feature(response.GetSupportedFeatures()); features != pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL {
+				t.Errorf("unexpected supported features %v", features)
+			}
+			if len(test.errors) == 0 {
+				if response.Error != nil || len(response.File) != 1 {
+					t.Errorf("unexpected response %v", response)
+				}
+				return
+			}
+			if len(response.File) > 0 {
+				t.Errorf("unexpected files in a response with an error: %v", response.File)
+			}
+			lines := strings.Split(response.GetError(), "\n")
+			if len(lines) != len(test.errors) {
+				t.Fatalf("unexpected error %q (expected %q)", response.GetError(), strings.Join(test.errors, "\n"))
+			}
+			for i, line := range lines {
+				if !strings.Contains(line, test.errors[i]) {
+					t.Errorf("unexpected error %q (expected %q)", line, test.errors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFixtureErrors(t *testing.T) {
+	collisionFiles := []string{
+		"examples/tests/package_collisions/a/admin.proto",
+		"examples/tests/package_collisions/b/admin.proto",
+	}
+	// Merged documents qualify the names that collide, but documents of single files don't.
+	errorFixtureTest(t, "source_relative package collisions", collisionFiles,
+		"google.rpc.Status and tests.package_collisions.a.v1.Status have the same schema name Status", "output_mode=source_relative")
+	errorFixtureTest(t, "operationIds of methods", []string{"examples/tests/operation_id_collision/message.proto"},
+		"tests.operation_id_collision.message.v1.Shelves.List and tests.operation_id_collision.message.v1.Books.List have the same operationId List", "operation_id=method")
+	errorFixtureTest(t, "nested name collision", []string{"examples/tests/nested_name_collision/message.proto"},
+		"tests.nested_name_collision.message.v1.Header.Style and tests.nested_name_collision.message.v1.Header_Style have the same schema name Header_Style")
+	errorFixtureTest(t, "annotated parameter location", []string{"examples/tests/parameter_errors/message.proto"},
+		`the parameter "text" of the openapi.v3.operation annotation of tests.parameter_errors.message.v1.Messaging.GetMessage is in "body", which isn't query, header, path or cookie`)
+	errorFixtureTest(t, "annotated parameter name", []string{"examples/tests/parameter_errors/message.proto"},
+		"a parameter of the openapi.v3.operation annotation of tests.parameter_errors.message.v1.Messaging.GetMessage has no name")
+	errorFixtureTest(t, "recursive schema without components", []string{"examples/tests/circulardepth/message.proto"},
+		"the schema Sub can't be inlined with no_components because it refers to itself", "no_components=true")
+	errorFixtureTest(t, "security schemes without components", []string{"examples/tests/tag_scopes/message.proto"},
+		"components.securitySchemes can't be inlined with no_components", "no_components=true")
+}
+
+func TestDryRun(t *testing.T) {
+	rules := []*annotations.HttpRule{
+		{Pattern: &annotations.HttpRule_Get{Get: "/v1/messages/{message_id}"}},
+		{Pattern: &annotations.HttpRule_Post{Post: "/v1/messages"}, Body: "*"},
+	}
+	response := runPlugin(t, errorsRequest("dry_run=true", rules...))
+	if response.Error != nil || len(response.File) != 1 {
+		t.Fatalf("unexpected response %v", response)
+	}
+	file := response.File[0]
+	lines := strings.Split(strings.TrimSuffix(file.GetContent(), "\n"), "\n")
+	if file.GetName() != "openapi.dry_run.txt" || len(lines) != 2 || lines[0] != "file\tbytes\tpaths\tschemas" {
+		t.Fatalf("unexpected summary %s:\n%s", file.GetName(), file.GetContent())
+	}
+	// The size of the document depends on the version of the plugin, so only its name
+	// and its counts are compared.
+	fields := strings.Split(lines[1], "\t")
+	if len(fields) != 4 || fields[0] != "openapi.yaml" || fields[2] != "2" || fields[3] != "3" {
+		t.Errorf("unexpected summary line %q", lines[1])
+	}
+}
+
+func TestLint(t *testing.T) {
+	protoFiles := []string{"examples/tests/lint/message.proto"}
+	if _, err := generateOpenAPI(t, protoFiles); err != nil {
+		t.Fatalf("generating openapi: %v", err)
+	}
+	_, err := generateOpenAPI(t, protoFiles, "lint=true")
+	if err == nil {
+		t.Fatal("expected lint=true to fail")
+	}
+	const expected = `lint: DUPLICATE_OPERATION_ID: operationId "GetMessage" is also used by get /v1/messages/{messageId} (paths./v1/messages:lookup.get.operationId)`
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("unexpected error %v (expected %q)", err, expected)
+	}
 }
 
 func TestMain(m *testing.M) {
@@ -132,7 +594,11 @@ func TestMain(m *testing.M) {
 	}
 
 	regenerate = strings.ToLower(os.Getenv("GNOSTIC_REGEN_FIXTURES")) == "true"
+	reportCollisions = strings.ToLower(os.Getenv("GNOSTIC_REPORT_FIXTURE_COLLISIONS")) == "true"
 	exitCode := m.Run()
+	if reportCollisions {
+		printCollisions(os.Stderr)
+	}
 	if exitCode == 0 && regenerate {
 		fmt.Fprint(os.Stderr, "fixtures have been regenerated, you may now run tests")
 		os.Exit(1)
@@ -143,7 +609,7 @@ func TestMain(m *testing.M) {
 // optionFixtureTest verifies that the generated code from protoFile matches the
 // openapi.yaml fixture in the same directory. It will also verify that the
 // output is changed from the default settings and fail the test if they are the
-// same.
+// same, or if it is the same as the output of another fixture.
 func optionFixtureTest(t *testing.T, testName string, protoFile string, pluginArg string) {
 	t.Helper()
 	t.Run(testName, func(t *testing.T) {
@@ -161,19 +627,10 @@ func optionFixtureTest(t *testing.T, testName string, protoFile string, pluginAr
 		if err := diffTest(defaultOutputDir, outputDir); err == nil {
 			t.Fatalf("output was identical to default output")
 		}
-		if regenerate {
-			if diffTest(outputDir, fixtureDir) == nil {
-				t.Skip("no change to fixtures")
-			}
-			if err := cpr(outputDir, fixtureDir); err != nil {
-				t.Fatalf("error copying regenerated fixtures")
-			}
-			t.Log("regenerated fixtures")
-			return
-		}
-		if err := diffTest(outputDir, fixtureDir); err != nil {
-			t.Fatalf("comparing fixtures: \n%v", err)
+		if other := recordFixtureOutput(t, outputDir, fixtureDir); other != "" {
+			t.Fatalf("output was identical to the fixtures in %s; the fixtures in %s are redundant", other, fixtureDir)
 		}
+		checkFixtures(t, outputDir, fixtureDir)
 	})
 }
 
@@ -189,15 +646,39 @@ func fixtureTest(t *testing.T, testName string, protoFile string) {
 		if err != nil {
 			t.Fatalf("generating openapi: %v", err)
 		}
-		if regenerate {
-			if diffTest(outputDir, fixtureDir) == nil {
-				t.Skip("no change to fixtures")
-			}
-			if err := cpr(outputDir, fixtureDir); err != nil {
-				t.Fatalf("error copying regenerated fixtures")
-			}
-			t.Log("regenerated fixtures")
-			return
+		recordFixtureOutput(t, outputDir, fixtureDir)
+		checkFixtures(t, outputDir, fixtureDir)
+	})
+}
+
+// variantFixtureTest verifies that the generated code from protoFiles with the
+// plugin options matches the fixtures in fixtureDir, which is usually a
+// subdirectory of the fixtures of the files for one combination of options. It
+// fails the test if the output is the same as the output of another fixture.
+func variantFixtureTest(t *testing.T, testName string, protoFiles []string, fixtureDir string, pluginArgs ...string) {
+	t.Helper()
+	t.Run(testName, func(t *testing.T) {
+		t.Helper()
+		outputDir, err := generateOpenAPI(t, protoFiles, pluginArgs...)
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
+		}
+		if other := recordFixtureOutput(t, outputDir, fixtureDir); other != "" {
+			t.Fatalf("output was identical to the fixtures in %s; the fixtures in %s are redundant", other, fixtureDir)
+		}
+		checkFixtures(t, outputDir, fixtureDir)
+	})
+}
+
+// unchangedFixtureTest verifies that generating code from protoFile with the plugin
+// options matches the existing fixtures in fixtureDir.
+func unchangedFixtureTest(t *testing.T, testName string, protoFile string, fixtureDir string, pluginArgs ...string) {
+	t.Helper()
+	t.Run(testName, func(t *testing.T) {
+		t.Helper()
+		outputDir, err := generateOpenAPI(t, []string{protoFile}, pluginArgs...)
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
 		}
 		if err := diffTest(outputDir, fixtureDir); err != nil {
 			t.Fatalf("output did not match fixture data\n%v", err)
@@ -205,6 +686,179 @@ func fixtureTest(t *testing.T, testName string, protoFile string) {
 	})
 }
 
+// errorFixtureTest verifies that generating code from protoFiles with the plugin
+// options fails with an error that contains expected.
+func errorFixtureTest(t *testing.T, testName string, protoFiles []string, expected string, pluginArgs ...string) {
+	t.Helper()
+	t.Run(testName, func(t *testing.T) {
+		t.Helper()
+		_, err := generateOpenAPI(t, protoFiles, pluginArgs...)
+		if err == nil {
+			t.Fatalf("expected an error containing %q", expected)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("unexpected error %v (expected %q)", err, expected)
+		}
+	})
+}
+
+// checkFixtures compares the files under outputDir with the fixtures in
+// fixtureDir, or copies them there when fixtures are being regenerated.
+func checkFixtures(t *testing.T, outputDir, fixtureDir string) {
+	t.Helper()
+	if regenerate {
+		if diffTest(outputDir, fixtureDir) == nil {
+			t.Skip("no change to fixtures")
+		}
+		if err := cpr(outputDir, fixtureDir); err != nil {
+			t.Fatalf("error copying regenerated fixtures: %v", err)
+		}
+		t.Log("regenerated fixtures")
+		return
+	}
+	if err := diffTest(outputDir, fixtureDir); err != nil {
+		t.Fatalf("output did not match fixture data\n%v", err)
+	}
+}
+
+// recordFixtureOutput adds the output generated for fixtureDir to the registry of
+// fixture outputs. If the same output was recorded for another fixture directory,
+// the first such directory is returned. Outputs are hashed rather than compared
+// with the files in fixtureDir so that collisions are also found while fixtures
+// are regenerated.
+func recordFixtureOutput(t *testing.T, outputDir, fixtureDir string) string {
+	t.Helper()
+	hash, err := hashOutput(outputDir)
+	if err != nil {
+		t.Fatalf("hashing output: %v", err)
+	}
+	dirs := fixtureOutputs[hash]
+	for _, dir := range dirs {
+		if dir == fixtureDir {
+			return ""
+		}
+	}
+	fixtureOutputs[hash] = append(dirs, fixtureDir)
+	if len(dirs) > 0 {
+		return dirs[0]
+	}
+	return ""
+}
+
+// hashOutput returns a hash of the names and contents of the files under dir.
+func hashOutput(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(content))
+		h.Write(content)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// printCollisions writes the groups of fixture directories whose outputs are
+// identical. It is enabled with GNOSTIC_REPORT_FIXTURE_COLLISIONS=true.
+func printCollisions(w io.Writer) {
+	var collisions []string
+	for _, dirs := range fixtureOutputs {
+		if len(dirs) > 1 {
+			collisions = append(collisions, strings.Join(dirs, ", "))
+		}
+	}
+	if len(collisions) == 0 {
+		fmt.Fprintln(w, "no fixture directories have identical outputs")
+		return
+	}
+	sort.Strings(collisions)
+	fmt.Fprintln(w, "fixture directories with identical outputs:")
+	for _, collision := range collisions {
+		fmt.Fprintf(w, "  %s\n", collision)
+	}
+}
+
+// maxOrderings is the largest number of orderings of the files that orderingTest generates.
+const maxOrderings = 24
+
+// orderingTest verifies that the output generated from protoFiles is the same for
+// every order in which the files are passed to protoc. All of the permutations of
+// the files are tried, or a sample of maxOrderings of them if there are more.
+func orderingTest(t *testing.T, testName string, protoFiles []string, pluginArgs ...string) {
+	t.Helper()
+	t.Run(testName, func(t *testing.T) {
+		t.Helper()
+		orderings := fileOrderings(protoFiles)
+		expectedDir, err := generateOpenAPI(t, orderings[0], pluginArgs...)
+		if err != nil {
+			t.Fatalf("generating openapi: %v", err)
+		}
+		for _, ordering := range orderings[1:] {
+			outputDir, err := generateOpenAPI(t, ordering, pluginArgs...)
+			if err != nil {
+				t.Fatalf("generating openapi: %v", err)
+			}
+			if err := diffTest(outputDir, expectedDir); err != nil {
+				t.Fatalf("output for %v differs from the output for %v:\n%v", ordering, orderings[0], err)
+			}
+		}
+	})
+}
+
+// fileOrderings returns the permutations of files, starting with files itself. If
+// there are more than maxOrderings permutations, it returns files, its reverse, and
+// a fixed sample of the others.
+func fileOrderings(files []string) [][]string {
+	count := 1
+	for i := 2; i <= len(files) && count <= maxOrderings; i++ {
+		count *= i
+	}
+	if count <= maxOrderings {
+		var orderings [][]string
+		var permute func(ordering []string, k int)
+		permute = func(ordering []string, k int) {
+			if k == len(ordering) {
+				orderings = append(orderings, append([]string(nil), ordering...))
+				return
+			}
+			for i := k; i < len(ordering); i++ {
+				ordering[k], ordering[i] = ordering[i], ordering[k]
+				permute(ordering, k+1)
+				ordering[k], ordering[i] = ordering[i], ordering[k]
+			}
+		}
+		permute(append([]string(nil), files...), 0)
+		return orderings
+	}
+	reversed := make([]string, len(files))
+	for i, file := range files {
+		reversed[len(files)-1-i] = file
+	}
+	orderings := [][]string{files, reversed}
+	seen := map[string]bool{strings.Join(files, " "): true, strings.Join(reversed, " "): true}
+	r := rand.New(rand.NewSource(int64(len(files))))
+	for len(orderings) < maxOrderings {
+		ordering := append([]string(nil), files...)
+		r.Shuffle(len(ordering), func(i, j int) {
+			ordering[i], ordering[j] = ordering[j], ordering[i]
+		})
+		if key := strings.Join(ordering, " "); !seen[key] {
+			seen[key] = true
+			orderings = append(orderings, ordering)
+		}
+	}
+	return orderings
+}
+
 func generateOpenAPI(t *testing.T, protoFiles []string, pluginArgs ...string) (string, error) {
 	t.Helper()
 	outputDir := t.TempDir()
@@ -20,14 +20,27 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
+	"go.yaml.in/yaml/v3"
 
+	"github.com/google/gnostic/compiler"
+	"github.com/google/gnostic/metrics/sourceinfo"
 	"github.com/google/gnostic/printer"
 
 	plugins "github.com/google/gnostic/plugins"
 )
 
+// severityOrder lists message levels from most to least severe.
+var severityOrder = []plugins.Message_Level{
+	plugins.Message_FATAL,
+	plugins.Message_ERROR,
+	plugins.Message_WARNING,
+	plugins.Message_INFO,
+	plugins.Message_UNKNOWN,
+}
+
 func readMessagesFromFileWithName(filename string) *plugins.Messages {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -42,29 +55,172 @@ func readMessagesFromFileWithName(filename string) *plugins.Messages {
 	return messages
 }
 
-func printMessages(code *printer.Code, messages *plugins.Messages) {
-	for _, message := range messages.Messages {
-		line := fmt.Sprintf("%-7s %-14s %s %+v",
-			message.Level,
-			message.Code,
-			message.Text,
-			message.Keys)
-		code.Print(line)
+// readSourceFromFileWithName parses the API description that messages refer to.
+func readSourceFromFileWithName(filename string) (*yaml.Node, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	err = yaml.Unmarshal(data, &node)
+	if err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// parseSeverity returns the message level with the given name.
+func parseSeverity(name string) (plugins.Message_Level, error) {
+	level, ok := plugins.Message_Level_value[strings.ToUpper(name)]
+	if !ok {
+		return plugins.Message_UNKNOWN, fmt.Errorf("unknown severity %q", name)
+	}
+	return plugins.Message_Level(level), nil
+}
+
+// filterMessages returns the messages with a level of at least minSeverity.
+func filterMessages(messages []*plugins.Message, minSeverity plugins.Message_Level) []*plugins.Message {
+	filtered := make([]*plugins.Message, 0)
+	for _, message := range messages {
+		if message.Level >= minSeverity {
+			filtered = append(filtered, message)
+		}
+	}
+	return filtered
+}
+
+// keyPath returns a JSON pointer for the keys of a message.
+func keyPath(keys []string) string {
+	return compiler.JSONPointer(keys)
+}
+
+// sourcePosition returns the line and column of a message's keys in source, or zeroes if unknown.
+func sourcePosition(source *yaml.Node, keys []string) (int, int) {
+	if source == nil || len(keys) == 0 {
+		return 0, 0
+	}
+	node := sourceinfo.FindKeyPath(source, keys)
+	if node == nil {
+		return 0, 0
+	}
+	return node.Line, node.Column
+}
+
+func printMessage(code *printer.Code, message *plugins.Message) {
+	line := fmt.Sprintf("%-7s %-14s %s %+v",
+		message.Level,
+		message.Code,
+		message.Text,
+		message.Keys)
+	code.Print(line)
+}
+
+func printMessages(code *printer.Code, messages []*plugins.Message) {
+	for _, message := range messages {
+		printMessage(code, message)
+	}
+}
+
+// printMessagesBySeverity prints messages in groups, most severe first.
+func printMessagesBySeverity(code *printer.Code, messages []*plugins.Message) {
+	for _, level := range severityOrder {
+		group := make([]*plugins.Message, 0)
+		for _, message := range messages {
+			if message.Level == level {
+				group = append(group, message)
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+		code.Print("%s (%d)", level, len(group))
+		code.Indent()
+		printMessages(code, group)
+		code.Outdent()
+	}
+}
+
+// printMessagesByLocation prints messages grouped by key path, in order of first appearance.
+// If a source is provided, each group is labeled with its position in the source.
+func printMessagesByLocation(code *printer.Code, messages []*plugins.Message, sourceName string, source *yaml.Node) {
+	paths := make([]string, 0)
+	groups := make(map[string][]*plugins.Message)
+	for _, message := range messages {
+		path := keyPath(message.Keys)
+		if _, ok := groups[path]; !ok {
+			paths = append(paths, path)
+		}
+		groups[path] = append(groups[path], message)
+	}
+	for _, path := range paths {
+		group := groups[path]
+		label := path
+		if label == "" {
+			label = "(no location)"
+		}
+		if line, column := sourcePosition(source, group[0].Keys); line > 0 {
+			label = fmt.Sprintf("%s:%d:%d %s", sourceName, line, column, label)
+		}
+		code.Print("%s (%d)", label, len(group))
+		code.Indent()
+		printMessages(code, group)
+		code.Outdent()
 	}
 }
 
 func main() {
+	format := flag.String("format", "text", "output format (text or sarif)")
+	groupBy := flag.String("group-by", "", "group text output by \"severity\" or \"location\"")
+	minSeverity := flag.String("min-severity", "unknown", "omit messages below this severity (info, warning, error, fatal)")
+	sourceName := flag.String("source", "", "API description that the messages refer to, used to report file positions")
 	flag.Parse()
 	args := flag.Args()
 
 	if len(args) != 1 {
-		fmt.Printf("Usage: report-messages <file.pb>\n")
+		fmt.Printf("Usage: report-messages [--format=text|sarif] [--group-by=severity|location] [--min-severity=<level>] [--source=<file>] <file.pb>\n")
 		return
 	}
 
-	messages := readMessagesFromFileWithName(args[0])
+	level, err := parseSeverity(*minSeverity)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
 
-	code := &printer.Code{}
-	printMessages(code, messages)
-	fmt.Printf("%s", code)
+	var source *yaml.Node
+	if *sourceName != "" {
+		source, err = readSourceFromFileWithName(*sourceName)
+		if err != nil {
+			fmt.Printf("Source error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	messages := filterMessages(readMessagesFromFileWithName(args[0]).Messages, level)
+
+	switch *format {
+	case "text":
+		code := &printer.Code{}
+		switch *groupBy {
+		case "":
+			printMessages(code, messages)
+		case "severity":
+			printMessagesBySeverity(code, messages)
+		case "location":
+			printMessagesByLocation(code, messages, *sourceName, source)
+		default:
+			fmt.Printf("Unknown grouping: %s\n", *groupBy)
+			os.Exit(1)
+		}
+		fmt.Printf("%s", code)
+	case "sarif":
+		err = sarifLog(messages, *sourceName, source).Write(os.Stdout)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Unknown format: %s\n", *format)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/google/gnostic/printer"
+
+	plugins "github.com/google/gnostic/plugins"
+)
+
+const sourceFile = "../../examples/v3.0/yaml/petstore.yaml"
+
+// writeMessagesFile converts the synthetic messages to the binary form read by the tool.
+func writeMessagesFile(t *testing.T) string {
+	text, err := os.ReadFile("testdata/messages.prototext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := &plugins.Messages{}
+	if err = prototext.Unmarshal(text, messages); err != nil {
+		t.Fatal(err)
+	}
+	data, err := proto.Marshal(messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "messages.pb")
+	if err = os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestTextReports(t *testing.T) {
+	filename := writeMessagesFile(t)
+	source, err := readSourceFromFileWithName(sourceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		golden      string
+		minSeverity string
+		print       func(*printer.Code, []*plugins.Message)
+	}{
+		{
+			name:        "flat",
+			golden:      "testdata/messages.text",
+			minSeverity: "unknown",
+			print:       printMessages,
+		},
+		{
+			name:        "by severity",
+			golden:      "testdata/messages-by-severity.text",
+			minSeverity: "unknown",
+			print:       printMessagesBySeverity,
+		},
+		{
+			name:        "by location",
+			golden:      "testdata/messages-by-location.text",
+			minSeverity: "unknown",
+			print: func(code *printer.Code, messages []*plugins.Message) {
+				printMessagesByLocation(code, messages, "", nil)
+			},
+		},
+		{
+			name:        "by source location",
+			golden:      "testdata/messages-by-source-location.text",
+			minSeverity: "unknown",
+			print: func(code *printer.Code, messages []*plugins.Message) {
+				printMessagesByLocation(code, messages, "petstore.yaml", source)
+			},
+		},
+		{
+			name:        "warnings and above by severity",
+			golden:      "testdata/messages-min-warning.text",
+			minSeverity: "warning",
+			print:       printMessagesBySeverity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := parseSeverity(tt.minSeverity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages := filterMessages(readMessagesFromFileWithName(filename).Messages, level)
+			code := &printer.Code{}
+			tt.print(code, messages)
+			expected, err := os.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code.String() != string(expected) {
+				t.Errorf("output does not match %s\ngot:\n%s\nwant:\n%s", tt.golden, code.String(), expected)
+			}
+		})
+	}
+}
+
+func TestUnknownSeverity(t *testing.T) {
+	if _, err := parseSeverity("severe"); err == nil {
+		t.Errorf("expected an error for an unknown severity")
+	}
+}
+
+func TestSarifReport(t *testing.T) {
+	filename := writeMessagesFile(t)
+	source, err := readSourceFromFileWithName(sourceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := readMessagesFromFileWithName(filename).Messages
+	var b bytes.Buffer
+	if err = sarifLog(messages, "petstore.yaml", source).Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := os.ReadFile("../../sarif/testdata/sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := jsonschema.New(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = validator.Validate(b.Bytes()); err != nil {
+		t.Fatalf("report does not conform to the SARIF schema: %s", err)
+	}
+	expected, err := os.ReadFile("testdata/messages.sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(expected) {
+		t.Errorf("output does not match testdata/messages.sarif\ngot:\n%s", b.String())
+	}
+}
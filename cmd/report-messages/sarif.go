@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"go.yaml.in/yaml/v3"
+
+	plugins "github.com/google/gnostic/plugins"
+	"github.com/google/gnostic/sarif"
+)
+
+// sarifLevel maps gnostic message levels to SARIF result levels.
+func sarifLevel(level plugins.Message_Level) string {
+	switch level {
+	case plugins.Message_INFO:
+		return sarif.LevelNote
+	case plugins.Message_WARNING:
+		return sarif.LevelWarning
+	case plugins.Message_ERROR, plugins.Message_FATAL:
+		return sarif.LevelError
+	default:
+		return sarif.LevelNone
+	}
+}
+
+// sarifLog converts messages to a SARIF log. Messages with keys are given a logical
+// location; if a source is provided they are also mapped to a file and region.
+func sarifLog(messages []*plugins.Message, sourceName string, source *yaml.Node) *sarif.Log {
+	log := sarif.NewLog("gnostic")
+	for _, message := range messages {
+		result := &sarif.Result{
+			RuleID:  message.Code,
+			Level:   sarifLevel(message.Level),
+			Message: &sarif.Message{Text: message.Text},
+		}
+		if len(message.Keys) > 0 || sourceName != "" {
+			location := &sarif.Location{}
+			if sourceName != "" {
+				location.PhysicalLocation = &sarif.PhysicalLocation{
+					ArtifactLocation: &sarif.ArtifactLocation{URI: sourceName},
+				}
+				if line, column := sourcePosition(source, message.Keys); line > 0 {
+					location.PhysicalLocation.Region = &sarif.Region{StartLine: line, StartColumn: column}
+				}
+			}
+			if len(message.Keys) > 0 {
+				location.LogicalLocations = []*sarif.LogicalLocation{
+					{FullyQualifiedName: keyPath(message.Keys)},
+				}
+			}
+			result.Locations = []*sarif.Location{location}
+		}
+		log.AddResult(result)
+	}
+	return log
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ExpandAliases returns a node that encodes to the same YAML as node but without anchors
+// or aliases, which some YAML parsers reject. Aliases are replaced with copies of the nodes
+// that they refer to. Nodes that are shared by several parents, like the nodes returned by
+// ToRawInfo for values that are used more than once, are encoded in full wherever they
+// appear. The nodes of node are never changed; if it has no anchors or aliases, it is
+// returned as it is. It fails if an alias refers to a node that contains it.
+func ExpandAliases(node *yaml.Node) (*yaml.Node, error) {
+	if node == nil || !hasAnchors(node) {
+		return node, nil
+	}
+	return expandAliases(node, map[*yaml.Node]bool{})
+}
+
+// hasAnchors returns true if a node or one of its descendants is an alias or has an anchor.
+func hasAnchors(node *yaml.Node) bool {
+	if node.Anchor != "" || node.Kind == yaml.AliasNode {
+		return true
+	}
+	for _, child := range node.Content {
+		if hasAnchors(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandAliases copies a node without anchors and with its aliases expanded. Ancestors
+// holds the nodes that are being copied, to detect aliases that refer to them.
+func expandAliases(node *yaml.Node, ancestors map[*yaml.Node]bool) (*yaml.Node, error) {
+	if node.Kind == yaml.AliasNode {
+		if node.Alias == nil {
+			return nil, fmt.Errorf("alias *%s at line %d refers to no node", node.Value, node.Line)
+		}
+		if ancestors[node.Alias] {
+			return nil, fmt.Errorf("alias *%s at line %d refers to a node that contains it", node.Value, node.Line)
+		}
+		expanded, err := expandAliases(node.Alias, ancestors)
+		if err != nil {
+			return nil, err
+		}
+		// Comments of the alias are kept instead of the ones of the node that it refers to.
+		if node.HeadComment != "" || node.LineComment != "" || node.FootComment != "" {
+			expanded.HeadComment, expanded.LineComment, expanded.FootComment = node.HeadComment, node.LineComment, node.FootComment
+		}
+		return expanded, nil
+	}
+	if ancestors[node] {
+		return nil, fmt.Errorf("node at line %d contains itself", node.Line)
+	}
+	ancestors[node] = true
+	defer delete(ancestors, node)
+	expanded := *node
+	expanded.Anchor = ""
+	expanded.Alias = nil
+	if node.Content != nil {
+		expanded.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			var err error
+			if expanded.Content[i], err = expandAliases(child, ancestors); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &expanded, nil
+}
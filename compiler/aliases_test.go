@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// anchorPattern matches the anchors and aliases of values in YAML output.
+var anchorPattern = regexp.MustCompile(`(?m)(^|[:\-] )[&*]\w`)
+
+func TestExpandAliases(t *testing.T) {
+	// A schema that is shared by hundreds of properties, and anchored like a schema
+	// that was read from a document with anchors.
+	shared := NewMappingNode()
+	shared.Anchor = "shared"
+	shared.Content = append(shared.Content, NewScalarNodeForString("type"), NewScalarNodeForString("string"))
+	properties := NewMappingNode()
+	for i := 0; i < 300; i++ {
+		value := shared
+		if i%2 == 1 {
+			value = &yaml.Node{Kind: yaml.AliasNode, Value: "shared", Alias: shared}
+		}
+		properties.Content = append(properties.Content, NewScalarNodeForString(fmt.Sprintf("property%d", i)), value)
+	}
+	root := NewMappingNode()
+	root.Content = append(root.Content, NewScalarNodeForString("properties"), properties)
+
+	expanded, err := ExpandAliases(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytes, err := yaml.Marshal(expanded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anchors := anchorPattern.FindAllString(string(bytes), -1); len(anchors) > 0 {
+		t.Errorf("unexpected anchors or aliases %q in output:\n%s", anchors, bytes)
+	}
+	if count := strings.Count(string(bytes), "type: string"); count != 300 {
+		t.Errorf("expected 300 expanded schemas, got %d", count)
+	}
+	// The original nodes are unchanged.
+	if shared.Anchor != "shared" || properties.Content[3].Kind != yaml.AliasNode {
+		t.Errorf("ExpandAliases changed its input")
+	}
+	original, err := yaml.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !anchorPattern.Match(original) {
+		t.Errorf("expected anchors in the output of the original nodes:\n%s", original)
+	}
+}
+
+func TestExpandAliasesWithoutAnchors(t *testing.T) {
+	// Nodes without anchors aren't copied, even if they are shared.
+	shared := NewScalarNodeForString("value")
+	root := NewSequenceNode()
+	root.Content = append(root.Content, shared, shared)
+	expanded, err := ExpandAliases(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expanded != root {
+		t.Errorf("expected the node to be returned as it is")
+	}
+	if expanded, err = ExpandAliases(nil); expanded != nil || err != nil {
+		t.Errorf("unexpected result %v, %v for nil", expanded, err)
+	}
+}
+
+func TestExpandParsedAliases(t *testing.T) {
+	var info yaml.Node
+	if err := yaml.Unmarshal([]byte("a: &a\n  b: 1\nc: *a\n"), &info); err != nil {
+		t.Fatal(err)
+	}
+	expanded, err := ExpandAliases(&info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytes, err := yaml.Marshal(expanded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bytes) != "a:\n    b: 1\nc:\n    b: 1\n" {
+		t.Errorf("unexpected output:\n%s", bytes)
+	}
+	// An alias that refers to a node that contains it can't be expanded.
+	loop := NewMappingNode()
+	loop.Anchor = "loop"
+	loop.Content = append(loop.Content, NewScalarNodeForString("self"), &yaml.Node{Kind: yaml.AliasNode, Value: "loop", Alias: loop})
+	if _, err := ExpandAliases(loop); err == nil || !strings.Contains(err.Error(), "refers to a node that contains it") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// NewContextWithCancellation returns a new object representing the compiler state like
+// NewContextWithExtensionConfig, for a compilation that stops when ctx is done.
+// Documents stop compiling between their top-level sections, and CallExtension returns
+// ctx.Err() instead of calling extension handlers. Handlers that are running are killed.
+func NewContextWithCancellation(ctx gocontext.Context, name string, node *yaml.Node, parent *Context, extensionHandlers *[]ExtensionHandler, config *ExtensionConfig) *Context {
+	context := NewContextWithExtensionConfig(name, node, parent, extensionHandlers, config)
+	state := extensionStateForContext(context)
+	state.ctx = ctx
+	state.yaml11Compat, _ = ctx.Value(yaml11CompatKey{}).(bool)
+	return context
+}
+
+// CancellationError returns the error of the Go context of a compilation that was started
+// with NewContextWithCancellation, or nil if the compilation can continue.
+func CancellationError(context *Context) error {
+	return extensionStateForContext(context).cancellationError()
+}
+
+// goContext returns the Go context of a compilation.
+func (s *extensionState) goContext() gocontext.Context {
+	if s == nil {
+		return gocontext.Background()
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	if s.ctx == nil {
+		return gocontext.Background()
+	}
+	return s.ctx
+}
+
+func (s *extensionState) cancellationError() error {
+	return s.goContext().Err()
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// cancellationContext returns a context for a document with an extension and the extension's value.
+func cancellationContext(t *testing.T, ctx gocontext.Context, config *ExtensionConfig) (*Context, *yaml.Node) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("x-amazon-slow: 1\n"), &node); err != nil {
+		t.Fatal(err)
+	}
+	root := node.Content[0]
+	context := NewContextWithCancellation(ctx, "$root", root, nil, nil, config)
+	t.Cleanup(func() { RemoveExtensionConfig(context) })
+	return context, root.Content[1]
+}
+
+func TestCanceledCompilation(t *testing.T) {
+	log := setupStubHandlerMode(t, "sleep")
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+	config := &ExtensionConfig{Handlers: []*ConfiguredExtensionHandler{{Pattern: WildcardPattern, Command: os.Args[0]}}}
+	context, value := cancellationContext(t, ctx, config)
+	if err := CancellationError(NewContext("info", value, context)); !errors.Is(err, gocontext.Canceled) {
+		t.Errorf("expected the compilation to be canceled, got %v", err)
+	}
+	CallExtensionsInBatches(context, context.Node)
+	if _, _, err := CallExtension(context, value, "x-amazon-slow"); !errors.Is(err, gocontext.Canceled) {
+		t.Errorf("expected the extension call to be canceled, got %v", err)
+	}
+	if calls := countStubHandlerCalls(t, log); calls != 0 {
+		t.Errorf("expected no handler calls, got %d", calls)
+	}
+	resolver := setupMemoryResolver(t, map[string]string{"/specs/root.yaml": "a: 1\n"})
+	if _, err := ReadBytesForFileContext(ctx, "/specs/root.yaml"); !errors.Is(err, gocontext.Canceled) {
+		t.Errorf("expected the read to be canceled, got %v", err)
+	}
+	if len(resolver.requests) != 0 {
+		t.Errorf("expected no resolver requests, got %v", resolver.requests)
+	}
+	// Compilations without a Go context aren't canceled.
+	if err := CancellationError(NewContext("$root", value, nil)); err != nil {
+		t.Errorf("unexpected cancellation %v", err)
+	}
+}
+
+func TestExtensionHandlerDeadline(t *testing.T) {
+	setupStubHandlerMode(t, "sleep")
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+	defer cancel()
+	config := &ExtensionConfig{Handlers: []*ConfiguredExtensionHandler{{Pattern: WildcardPattern, Command: os.Args[0]}}}
+	context, value := cancellationContext(t, ctx, config)
+	start := time.Now()
+	_, _, err := CallExtension(context, value, "x-amazon-slow")
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the handler to be killed, but it ran for %v", elapsed)
+	}
+	if !errors.Is(err, gocontext.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestFileResolverDeadline(t *testing.T) {
+	// The server responds after the client gives up.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(30 * time.Second):
+		}
+	}))
+	defer server.Close()
+	root := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(root, []byte("pet:\n  $ref: '"+server.URL+"/pet.yaml#/Pet'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ClearCaches()
+	defer ClearCaches()
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := ReadReferencedFilesContext(ctx, root)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the request to be canceled, but it took %v", elapsed)
+	}
+	if !errors.Is(err, gocontext.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
@@ -16,13 +16,27 @@ package compiler
 
 import (
 	"github.com/google/gnostic-models/compiler"
+	"go.yaml.in/yaml/v3"
 )
 
 // Context contains state of the compiler as it traverses a document.
 type Context = compiler.Context
 
-// NewContextWithExtensions returns a new object representing the compiler state
-var NewContextWithExtensions = compiler.NewContextWithExtensions
+// NewContextWithExtensions returns a new object representing the compiler state.
+// A context without a parent, or with a list of extension handlers that doesn't belong
+// to a compilation, starts a new compilation, which uses a copy of the list.
+func NewContextWithExtensions(name string, node *yaml.Node, parent *Context, extensionHandlers *[]ExtensionHandler) *Context {
+	if (parent == nil || extensionHandlers != nil) && compilationForHandlers(extensionHandlers) == nil {
+		extensionHandlers = newCompilation(extensionHandlers)
+	}
+	return compiler.NewContextWithExtensions(name, node, parent, extensionHandlers)
+}
 
-// NewContext returns a new object representing the compiler state
-var NewContext = compiler.NewContext
+// NewContext returns a new object representing the compiler state.
+// A context without a parent starts a new compilation, which records its warnings.
+func NewContext(name string, node *yaml.Node, parent *Context) *Context {
+	if parent == nil {
+		return NewContextWithExtensions(name, node, nil, nil)
+	}
+	return compiler.NewContext(name, node, parent)
+}
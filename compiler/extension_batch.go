@@ -0,0 +1,258 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/proto"
+
+	extensions "github.com/google/gnostic/extensions"
+)
+
+// pendingExtension is an extension value waiting to be sent to a handler.
+type pendingExtension struct {
+	node     *yaml.Node
+	wrapper  *extensions.Wrapper
+	location *extensions.ExtensionLocation
+	// The first failure of a handler that the extension was offered to.
+	failure *extensionResult
+}
+
+// CallExtensionsInBatches finds the specification extensions in a document and sends them
+// to their handlers with one call per handler. The results are saved in the context and
+// returned by later calls to CallExtension, so that compiling a document with many
+// extensions doesn't start a handler process for each of them.
+//
+// Extensions are offered to handlers in the same order as by CallExtension, so the wildcard
+// handler receives one batch of the extensions that no other handler accepted.
+//
+// Handlers that don't support batching receive the first extension of a batch and are
+// then called once for each of the others.
+//
+// Extensions that are found this way are sent without their locations, which are only
+// known to the compiler; see CallExtensionsInBatchesWithCompiler.
+func CallExtensionsInBatches(context *Context, node *yaml.Node) {
+	state := extensionStateForBatches(context)
+	if state == nil {
+		return
+	}
+	state.callInBatches(context, findExtensions(node, nil))
+}
+
+// CallExtensionsInBatchesWithCompiler is like CallExtensionsInBatches, but it finds the
+// extensions by calling compile, which should compile the document with the context.
+// During this call, CallExtension and CallExtensionForObject record the extensions and
+// their locations instead of calling handlers. The extensions are then sent to their
+// handlers in batches, and the document can be compiled again with the saved results.
+func CallExtensionsInBatchesWithCompiler(context *Context, compile func()) {
+	state := extensionStateForBatches(context)
+	if state == nil {
+		return
+	}
+	extensionStatesMutex.Lock()
+	state.collecting = true
+	extensionStatesMutex.Unlock()
+	compile()
+	extensionStatesMutex.Lock()
+	state.collecting = false
+	collected := state.collected
+	state.collected = nil
+	extensionStatesMutex.Unlock()
+	state.callInBatches(context, collected)
+}
+
+// extensionStateForBatches returns the extension state of a context, or nil if it has no handlers.
+func extensionStateForBatches(context *Context) *extensionState {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	if (state.config == nil || len(state.config.Handlers) == 0) && len(*context.ExtensionHandlers) == 0 {
+		return nil
+	}
+	return state
+}
+
+// callInBatches sends extensions to their handlers with one call per handler and saves the results.
+func (s *extensionState) callInBatches(context *Context, pending []*pendingExtension) {
+	if s.cancellationError() != nil {
+		// CallExtension reports the cancellation.
+		return
+	}
+	configured := make(map[*ConfiguredExtensionHandler][]*pendingExtension)
+	unconfigured := make([]*pendingExtension, 0)
+	for _, extension := range pending {
+		if result := callRegisteredExtensionHandler(extension.wrapper.ExtensionName, extension.wrapper.Yaml); result.handled {
+			s.saveResult(extension.node, extension.wrapper.ExtensionName, result)
+		} else if handler := s.config.HandlerForExtension(extension.wrapper.ExtensionName); handler != nil {
+			configured[handler] = append(configured[handler], extension)
+		} else {
+			unconfigured = append(unconfigured, extension)
+		}
+	}
+	if s.config != nil {
+		for _, handler := range s.config.Handlers {
+			pending := configured[handler]
+			if len(pending) == 0 {
+				continue
+			}
+			unconfigured = append(unconfigured, s.callBatch(handler.Command, handler.Args, pending, true)...)
+		}
+	}
+	for _, handler := range *context.ExtensionHandlers {
+		if len(unconfigured) == 0 {
+			break
+		}
+		if handler.Name != "" {
+			unconfigured = s.callBatch(handler.Name, nil, unconfigured, false)
+		}
+	}
+	if handler := s.config.WildcardHandler(); handler != nil && len(unconfigured) > 0 {
+		unconfigured = s.callBatch(handler.Command, handler.Args, unconfigured, true)
+	}
+	for _, extension := range unconfigured {
+		result := extension.failure
+		if result == nil {
+			result = &extensionResult{}
+		}
+		s.saveResult(extension.node, extension.wrapper.ExtensionName, result)
+	}
+}
+
+// findExtensions returns the values of all mapping entries with names beginning with "x-".
+// Extension values are not searched for further extensions.
+func findExtensions(node *yaml.Node, found []*pendingExtension) []*pendingExtension {
+	if node == nil {
+		return found
+	}
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			found = findExtensions(child, found)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if strings.HasPrefix(key.Value, "x-") {
+				found = append(found, &pendingExtension{node: value, wrapper: newExtensionWrapper(value, key.Value), location: &extensions.ExtensionLocation{}})
+			} else {
+				found = findExtensions(value, found)
+			}
+		}
+	}
+	return found
+}
+
+// callBatch sends extensions to a handler and saves the results of the extensions that it
+// handles. The remaining extensions are returned. If failuresAreHandled is true, extensions
+// that fail are saved with their errors; otherwise they are returned for other handlers to try.
+func (s *extensionState) callBatch(command string, args []string, pending []*pendingExtension, failuresAreHandled bool) []*pendingExtension {
+	results := s.callExtensionHandlerBatch(command, args, pending)
+	remaining := make([]*pendingExtension, 0)
+	for i, extension := range pending {
+		result := results[i]
+		if result.response != nil || (result.err != nil && failuresAreHandled) {
+			s.saveResult(extension.node, extension.wrapper.ExtensionName, result)
+		} else {
+			if extension.failure == nil && result.err != nil {
+				extension.failure = result
+			}
+			remaining = append(remaining, extension)
+		}
+	}
+	return remaining
+}
+
+// callExtensionHandlerBatch sends extensions to a handler in a single batch request and returns
+// a result for each, which is unhandled if the handler declined the extension. If the handler
+// doesn't support batching, its response to the first extension is used and the rest are sent
+// one at a time. Handlers whose capabilities show that they don't support batching are sent
+// every extension one at a time.
+func (s *extensionState) callExtensionHandlerBatch(command string, args []string, pending []*pendingExtension) []*extensionResult {
+	results := make([]*extensionResult, len(pending))
+	fail := func(err error) []*extensionResult {
+		for i := range results {
+			results[i] = failedExtensionResult(err)
+		}
+		return results
+	}
+	wrappers := make([]*extensions.Wrapper, len(pending))
+	locations := make([]*extensions.ExtensionLocation, len(pending))
+	for i, extension := range pending {
+		wrappers[i] = extension.wrapper
+		locations[i] = extension.location
+	}
+	if capabilities := s.handlerCapabilities(command, args); capabilities != nil && !capabilities.HasFeature(extensions.FeatureBatch) {
+		for i, wrapper := range wrappers {
+			results[i] = s.callExtensionHandler(command, args, wrapper, locations[i])
+		}
+		return results
+	}
+	output, err := s.runExtensionHandler(command, args, &extensions.ExtensionHandlerBatchRequest{
+		Wrapper:         wrappers[0],
+		CompilerVersion: compilerVersion(),
+		Wrappers:        wrappers,
+		BatchVersion:    extensions.BatchVersion,
+		Location:        locations[0],
+		Locations:       locations,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	batchResponse := &extensions.ExtensionHandlerBatchResponse{}
+	err = proto.Unmarshal(output, batchResponse)
+	if err != nil {
+		return fail(fmt.Errorf("extension handler %s returned an invalid response: %v", command, err))
+	}
+	if batchResponse.BatchVersion > 0 {
+		capabilities := batchResponse.GetCapabilities()
+		if capabilities == nil {
+			// The handler predates capabilities, but its reply shows that it supports batching.
+			capabilities = &extensions.Capabilities{Features: []string{extensions.FeatureBatch}}
+		}
+		s.saveHandlerCapabilities(command, args, capabilities)
+		if len(batchResponse.Responses) != len(wrappers) {
+			return fail(fmt.Errorf("extension handler %s returned %d responses for %d extensions", command, len(batchResponse.Responses), len(wrappers)))
+		}
+		for i, response := range batchResponse.Responses {
+			var warnings []string
+			if i < len(batchResponse.Warnings) {
+				warnings = batchResponse.Warnings[i].Warnings
+			}
+			results[i] = extensionHandlerResult(command, wrappers[i], response, warnings)
+		}
+		return results
+	}
+	// The handler doesn't support batching and only handled the first extension.
+	response := &extensions.ExtensionHandlerSingleResponse{}
+	err = proto.Unmarshal(output, response)
+	if err != nil {
+		return fail(fmt.Errorf("extension handler %s returned an invalid response: %v", command, err))
+	}
+	capabilities := response.GetCapabilities()
+	if capabilities == nil {
+		// The handler predates capabilities and batching.
+		capabilities = &extensions.Capabilities{}
+	}
+	s.saveHandlerCapabilities(command, args, capabilities)
+	results[0] = extensionHandlerResult(command, wrappers[0], response.Response(), response.Warnings)
+	for i := 1; i < len(wrappers); i++ {
+		results[i] = s.callExtensionHandler(command, args, wrappers[i], locations[i])
+	}
+	return results
+}
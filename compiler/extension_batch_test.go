@@ -0,0 +1,468 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	extensions "github.com/google/gnostic/extensions"
+)
+
+// When these environment variables are set, the test binary acts as an extension handler.
+// Handlers record each invocation in the log and handle extensions beginning with "x-amazon-",
+// except in the "sleep" and "crash" modes, which simulate handlers that hang or fail.
+// Handlers that don't support batching also record each batch request that they receive.
+// In the "warnings" mode, the handler accepts every extension and reports warnings about
+// extensions with names containing "warn", and errors and warnings for names containing "fail".
+const (
+	stubHandlerModeVariable = "GNOSTIC_TEST_EXTENSION_HANDLER"
+	stubHandlerLogVariable  = "GNOSTIC_TEST_EXTENSION_HANDLER_LOG"
+)
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(stubHandlerModeVariable) {
+	case "batch":
+		logStubHandlerCall()
+		extensions.Main(stubHandler)
+		os.Exit(0)
+	case "single":
+		logStubHandlerCall()
+		singleStubHandlerMain(nil)
+		os.Exit(0)
+	case "nobatch":
+		logStubHandlerCall()
+		singleStubHandlerMain(&extensions.Capabilities{ProtocolVersion: extensions.ProtocolVersion})
+		os.Exit(0)
+	case "warnings":
+		logStubHandlerCall()
+		warningsStubHandlerMain()
+		os.Exit(0)
+	case "legacy-batch":
+		logStubHandlerCall()
+		legacyBatchStubHandlerMain()
+		os.Exit(0)
+	case "sleep":
+		logStubHandlerCall()
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	case "crash":
+		logStubHandlerCall()
+		fmt.Fprintln(os.Stderr, "stub handler crashed")
+		os.Exit(2)
+	}
+	os.Exit(m.Run())
+}
+
+func logStubHandlerCall() {
+	logStubHandlerEvent("call")
+}
+
+func logStubHandlerEvent(event string) {
+	f, err := os.OpenFile(os.Getenv(stubHandlerLogVariable), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	fmt.Fprintln(f, event)
+}
+
+func stubHandler(name string, yamlInput string) (bool, proto.Message, error) {
+	if !strings.HasPrefix(name, "x-amazon-") {
+		return false, nil, nil
+	}
+	return true, wrapperspb.String(name + ": " + strings.TrimSpace(yamlInput)), nil
+}
+
+// singleStubHandlerMain implements a handler that doesn't support batching. It reports
+// capabilities if they are given; otherwise, it predates them and the batch protocol.
+func singleStubHandlerMain(capabilities *extensions.Capabilities) {
+	data, _ := io.ReadAll(os.Stdin)
+	request := &extensions.ExtensionHandlerBatchRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		panic(err)
+	}
+	if request.BatchVersion > 0 {
+		logStubHandlerEvent("batch request")
+	}
+	if !request.Capabilities.HasFeature(extensions.FeatureBatch) {
+		panic("missing compiler capabilities")
+	}
+	response := stubHandlerResponse(request.Wrapper)
+	var responseBytes []byte
+	if capabilities != nil {
+		responseBytes, _ = proto.Marshal(&extensions.ExtensionHandlerSingleResponse{
+			Handled:      response.Handled,
+			Value:        response.Value,
+			Capabilities: capabilities,
+		})
+	} else {
+		responseBytes, _ = proto.Marshal(response)
+	}
+	os.Stdout.Write(responseBytes)
+}
+
+// legacyBatchStubHandlerMain implements a handler that supports batching but predates capabilities.
+func legacyBatchStubHandlerMain() {
+	data, _ := io.ReadAll(os.Stdin)
+	request := &extensions.ExtensionHandlerBatchRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		panic(err)
+	}
+	var responseBytes []byte
+	if request.BatchVersion > 0 {
+		response := &extensions.ExtensionHandlerBatchResponse{BatchVersion: extensions.BatchVersion}
+		for _, wrapper := range request.Wrappers {
+			response.Responses = append(response.Responses, stubHandlerResponse(wrapper))
+		}
+		responseBytes, _ = proto.Marshal(response)
+	} else {
+		responseBytes, _ = proto.Marshal(stubHandlerResponse(request.Wrapper))
+	}
+	os.Stdout.Write(responseBytes)
+}
+
+// warningsStubHandlerMain implements a handler that reports warnings with its responses.
+func warningsStubHandlerMain() {
+	data, _ := io.ReadAll(os.Stdin)
+	request := &extensions.ExtensionHandlerBatchRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		panic(err)
+	}
+	var responseBytes []byte
+	if request.BatchVersion > 0 {
+		response := &extensions.ExtensionHandlerBatchResponse{BatchVersion: extensions.BatchVersion}
+		for _, wrapper := range request.Wrappers {
+			extensionResponse, warnings := warningsStubHandlerResponse(wrapper)
+			response.Responses = append(response.Responses, extensionResponse)
+			response.Warnings = append(response.Warnings, &extensions.ExtensionWarnings{Warnings: warnings})
+		}
+		responseBytes, _ = proto.Marshal(response)
+	} else {
+		response, warnings := warningsStubHandlerResponse(request.Wrapper)
+		responseBytes, _ = proto.Marshal(&extensions.ExtensionHandlerSingleResponse{
+			Handled:  response.Handled,
+			Errors:   response.Errors,
+			Value:    response.Value,
+			Warnings: warnings,
+		})
+	}
+	os.Stdout.Write(responseBytes)
+}
+
+func warningsStubHandlerResponse(wrapper *extensions.Wrapper) (*extensions.ExtensionHandlerResponse, []string) {
+	name := wrapper.ExtensionName
+	value, _ := anypb.New(wrapperspb.String(name))
+	switch {
+	case strings.Contains(name, "warn"):
+		return &extensions.ExtensionHandlerResponse{Handled: true, Value: value}, []string{name + " should be lowercase"}
+	case strings.Contains(name, "fail"):
+		return &extensions.ExtensionHandlerResponse{Handled: true, Errors: []string{"invalid value"}}, []string{name + " is deprecated"}
+	}
+	return &extensions.ExtensionHandlerResponse{Handled: true, Value: value}, nil
+}
+
+func stubHandlerResponse(wrapper *extensions.Wrapper) *extensions.ExtensionHandlerResponse {
+	response := &extensions.ExtensionHandlerResponse{}
+	handled, output, _ := stubHandler(wrapper.ExtensionName, wrapper.Yaml)
+	if handled {
+		response.Handled = true
+		response.Value, _ = anypb.New(output)
+	}
+	return response
+}
+
+// setupStubHandlerMode makes the test binary available as an extension handler and
+// returns the path of the file that logs its invocations.
+func setupStubHandlerMode(tb testing.TB, mode string) string {
+	log := filepath.Join(tb.TempDir(), "calls.log")
+	os.Setenv(stubHandlerModeVariable, mode)
+	os.Setenv(stubHandlerLogVariable, log)
+	tb.Cleanup(func() {
+		os.Unsetenv(stubHandlerModeVariable)
+		os.Unsetenv(stubHandlerLogVariable)
+	})
+	return log
+}
+
+func countStubHandlerCalls(tb testing.TB, log string) int {
+	data, err := os.ReadFile(log)
+	if os.IsNotExist(err) {
+		return 0
+	} else if err != nil {
+		tb.Fatal(err)
+	}
+	count := bytes.Count(data, []byte("call\n"))
+	os.Remove(log)
+	return count
+}
+
+// documentWithExtensions returns a document with n handled and n unhandled extensions.
+func documentWithExtensions(tb testing.TB, n int) *yaml.Node {
+	var b strings.Builder
+	b.WriteString("paths:\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "  /items/%d:\n    get:\n      x-amazon-apigateway-integration: %d\n      x-other: %d\n", i, i, i)
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(b.String()), &node); err != nil {
+		tb.Fatal(err)
+	}
+	return &node
+}
+
+// callAllExtensions calls CallExtension for each extension in a document, as the compiler does.
+func callAllExtensions(tb testing.TB, context *Context, node *yaml.Node) map[string]string {
+	results := make(map[string]string)
+	for _, extension := range findExtensions(node, nil) {
+		name := extension.wrapper.ExtensionName
+		handled, response, err := CallExtension(context, extension.node, name)
+		if err != nil {
+			tb.Fatalf("%s: %v", name, err)
+		}
+		if !handled {
+			continue
+		}
+		value := &wrapperspb.StringValue{}
+		if err = response.UnmarshalTo(value); err != nil {
+			tb.Fatal(err)
+		}
+		results[value.Value] = name
+	}
+	return results
+}
+
+func TestCallExtensionsInBatches(t *testing.T) {
+	for _, tt := range []struct {
+		mode  string
+		calls int
+	}{
+		// One batch for the handler.
+		{mode: "batch", calls: 1},
+		// The batch is read as a request for the first extension,
+		// then each of the other extensions is sent separately.
+		{mode: "single", calls: 10},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			log := setupStubHandlerMode(t, tt.mode)
+			node := documentWithExtensions(t, 5)
+			handlers := []ExtensionHandler{{Name: os.Args[0]}}
+			context := NewContextWithExtensions("$root", node, nil, &handlers)
+			defer RemoveExtensionConfig(context)
+			CallExtensionsInBatches(context, node)
+			if calls := countStubHandlerCalls(t, log); calls != tt.calls {
+				t.Errorf("expected %d handler calls, got %d", tt.calls, calls)
+			}
+			results := callAllExtensions(t, context, node)
+			if calls := countStubHandlerCalls(t, log); calls != 0 {
+				t.Errorf("expected saved results to be used, got %d more handler calls", calls)
+			}
+			if len(results) != 5 {
+				t.Errorf("expected 5 handled extensions, got %v", results)
+			}
+			for i := 0; i < 5; i++ {
+				if _, ok := results[fmt.Sprintf("x-amazon-apigateway-integration: %d", i)]; !ok {
+					t.Errorf("missing result for extension %d in %v", i, results)
+				}
+			}
+		})
+	}
+}
+
+func TestCallExtensionsInBatchesWithConfig(t *testing.T) {
+	log := setupStubHandlerMode(t, "batch")
+	node := documentWithExtensions(t, 3)
+	config := &ExtensionConfig{Handlers: []*ConfiguredExtensionHandler{
+		{Pattern: "x-amazon-*", Command: os.Args[0]},
+		{Pattern: "x-other", Command: os.Args[0]},
+	}}
+	context := NewContextWithExtensionConfig("$root", node, nil, nil, config)
+	defer RemoveExtensionConfig(context)
+	CallExtensionsInBatches(context, node)
+	if calls := countStubHandlerCalls(t, log); calls != 2 {
+		t.Errorf("expected one call for each configured handler, got %d", calls)
+	}
+	if results := callAllExtensions(t, context, node); len(results) != 3 {
+		t.Errorf("expected 3 handled extensions, got %v", results)
+	}
+}
+
+func TestExtensionHandlerCapabilities(t *testing.T) {
+	for _, tt := range []struct {
+		mode           string
+		calls          int
+		batchRequests  int
+		batchSupported bool
+	}{
+		// The handler reports that it supports batching.
+		{mode: "batch", calls: 2, batchSupported: true},
+		// The handler's batch reply shows that it supports batching.
+		{mode: "legacy-batch", calls: 2, batchSupported: true},
+		// After the first batch is read as a request for its first extension,
+		// each of the remaining extensions is sent separately.
+		{mode: "single", calls: 6, batchRequests: 1},
+		{mode: "nobatch", calls: 6, batchRequests: 1},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			log := setupStubHandlerMode(t, tt.mode)
+			node := documentWithExtensions(t, 3)
+			// The same handler command is called for two batches.
+			config := &ExtensionConfig{Handlers: []*ConfiguredExtensionHandler{
+				{Pattern: "x-amazon-*", Command: os.Args[0]},
+				{Pattern: "x-other", Command: os.Args[0]},
+			}}
+			context := NewContextWithExtensionConfig("$root", node, nil, nil, config)
+			defer RemoveExtensionConfig(context)
+			CallExtensionsInBatches(context, node)
+			data, err := os.ReadFile(log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if batchRequests := bytes.Count(data, []byte("batch request\n")); batchRequests != tt.batchRequests {
+				t.Errorf("expected %d batch requests to a handler that doesn't support batching, got %d", tt.batchRequests, batchRequests)
+			}
+			if calls := countStubHandlerCalls(t, log); calls != tt.calls {
+				t.Errorf("expected %d handler calls, got %d", tt.calls, calls)
+			}
+			state := extensionStateForContext(context)
+			if capabilities := state.handlerCapabilities(os.Args[0], nil); capabilities.HasFeature(extensions.FeatureBatch) != tt.batchSupported {
+				t.Errorf("unexpected handler capabilities %v", capabilities)
+			}
+			if results := callAllExtensions(t, context, node); len(results) != 3 {
+				t.Errorf("expected 3 handled extensions, got %v", results)
+			}
+		})
+	}
+}
+
+func TestWildcardExtensionHandler(t *testing.T) {
+	dir := setupStubHandler(t)
+	stub := filepath.Join(dir, "stub-extension-handler.sh")
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte("info:\n  x-amazon-special: 1\n  x-amazon-other: 2\n  x-plain: 3\n"), &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, batched := range []bool{false, true} {
+		t.Run(fmt.Sprintf("batched=%t", batched), func(t *testing.T) {
+			log := setupStubHandlerMode(t, "batch")
+			os.Remove(filepath.Join(dir, "calls.log"))
+			// The test binary handles extensions beginning with "x-amazon-" and declines the rest.
+			config := &ExtensionConfig{Handlers: []*ConfiguredExtensionHandler{
+				{Pattern: WildcardPattern, Command: os.Args[0]},
+				{Pattern: "x-amazon-special", Command: stub, Args: []string{"--special"}},
+			}}
+			context := NewContextWithExtensionConfig("$root", &node, nil, nil, config)
+			defer RemoveExtensionConfig(context)
+			if batched {
+				CallExtensionsInBatches(context, &node)
+			}
+			results := make(map[string]*anypb.Any)
+			for _, extension := range findExtensions(&node, nil) {
+				name := extension.wrapper.ExtensionName
+				handled, response, err := CallExtension(context, extension.node, name)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if handled {
+					results[name] = response
+				}
+			}
+			// The specific handler takes precedence over the wildcard.
+			if response := results["x-amazon-special"]; response == nil || string(response.Value) != "handled" {
+				t.Errorf("expected x-amazon-special to be handled by its configured handler, got %v", response)
+			}
+			calls, err := os.ReadFile(filepath.Join(dir, "calls.log"))
+			if err != nil || string(calls) != "--special\n" {
+				t.Errorf("expected one call of the specific handler, got %q (%v)", calls, err)
+			}
+			// The wildcard handler receives the extension name with its value.
+			value := &wrapperspb.StringValue{}
+			if response := results["x-amazon-other"]; response == nil || response.UnmarshalTo(value) != nil || value.Value != "x-amazon-other: 2" {
+				t.Errorf("expected x-amazon-other to be handled by the wildcard handler, got %v", response)
+			}
+			// Extensions declined by the wildcard handler are left unhandled and kept as raw values.
+			if response, ok := results["x-plain"]; ok {
+				t.Errorf("expected x-plain to be unhandled, got %v", response)
+			}
+			expected := 2
+			if batched {
+				expected = 1
+			}
+			if calls := countStubHandlerCalls(t, log); calls != expected {
+				t.Errorf("expected %d wildcard handler calls, got %d", expected, calls)
+			}
+		})
+	}
+}
+
+func TestWildcardExtensionHandlerAfterDefaultHandlers(t *testing.T) {
+	dir := setupStubHandler(t)
+	log := setupStubHandlerMode(t, "batch")
+	node := documentWithExtensions(t, 2)
+	// The stub script handles every extension, so the wildcard handler is never called.
+	handlers := []ExtensionHandler{{Name: filepath.Join(dir, "stub-extension-handler.sh")}}
+	config := &ExtensionConfig{Handlers: []*ConfiguredExtensionHandler{{Pattern: WildcardPattern, Command: os.Args[0]}}}
+	context := NewContextWithExtensionConfig("$root", node, nil, &handlers, config)
+	defer RemoveExtensionConfig(context)
+	for _, extension := range findExtensions(node, nil) {
+		handled, response, err := CallExtension(context, extension.node, extension.wrapper.ExtensionName)
+		if err != nil || !handled || string(response.Value) != "handled" {
+			t.Errorf("%s: expected the default handler's response, got %t %v %v", extension.wrapper.ExtensionName, handled, response, err)
+		}
+	}
+	if calls := countStubHandlerCalls(t, log); calls != 0 {
+		t.Errorf("expected no wildcard handler calls, got %d", calls)
+	}
+}
+
+func benchmarkExtensions(b *testing.B, batched bool) {
+	log := setupStubHandlerMode(b, "batch")
+	node := documentWithExtensions(b, 50)
+	calls := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handlers := []ExtensionHandler{{Name: os.Args[0]}}
+		context := NewContextWithExtensions("$root", node, nil, &handlers)
+		if batched {
+			CallExtensionsInBatches(context, node)
+		}
+		callAllExtensions(b, context, node)
+		RemoveExtensionConfig(context)
+		calls += countStubHandlerCalls(b, log)
+	}
+	b.ReportMetric(float64(calls)/float64(b.N), "subprocesses/op")
+}
+
+// BenchmarkExtensionsPerItem starts a handler process for each of 100 extensions.
+func BenchmarkExtensionsPerItem(b *testing.B) {
+	benchmarkExtensions(b, false)
+}
+
+// BenchmarkExtensionsBatched sends the same 100 extensions to the handler in one batch.
+func BenchmarkExtensionsBatched(b *testing.B) {
+	benchmarkExtensions(b, true)
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"weak"
+
+	"github.com/google/gnostic-models/compiler"
+	"go.yaml.in/yaml/v3"
+)
+
+// ExtensionConfig maps specification extension names to the commands that handle them.
+//
+// A configuration file is written in YAML:
+//
+//	handlers:
+//	- pattern: x-amazon-*
+//	  command: ./bin/amazon-extensions
+//	  args: [--strict]
+//	- pattern: x-book
+//	  command: gnostic-x-book
+//	- pattern: "*"
+//	  command: audit-extensions
+//	timeout: 10s
+//	errors: warn
+//	strict: true
+type ExtensionConfig struct {
+	Handlers []*ConfiguredExtensionHandler `yaml:"handlers"`
+	// Timeout limits the time taken by each call of a handler.
+	// If it is zero, DefaultExtensionTimeout is used.
+	Timeout time.Duration `yaml:"timeout"`
+	// Errors is ExtensionErrorsFail or ExtensionErrorsWarn. If it is empty, handler failures are errors.
+	Errors string `yaml:"errors"`
+	// Strict records the extensions that no handler accepts; see UnhandledExtensionsError.
+	Strict bool `yaml:"strict"`
+}
+
+// ConfiguredExtensionHandler describes a command that handles extensions matching a pattern.
+// A pattern is an exact extension name, a prefix followed by "*", or WildcardPattern.
+type ConfiguredExtensionHandler struct {
+	Pattern string   `yaml:"pattern"`
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+}
+
+// DefaultExtensionTimeout is the time that a handler may take before it is killed.
+const DefaultExtensionTimeout = time.Minute
+
+// Values of ExtensionConfig.Errors.
+const (
+	// ExtensionErrorsFail makes handler failures compilation errors.
+	ExtensionErrorsFail = "error"
+	// ExtensionErrorsWarn reports handler failures as warnings and leaves the extensions unhandled.
+	ExtensionErrorsWarn = "warn"
+)
+
+// WildcardPattern is the pattern of a catch-all handler. It is offered every extension
+// that isn't handled by a more specific configured handler or by an --x-EXTENSION handler.
+const WildcardPattern = "*"
+
+// ReadExtensionConfig reads an extension configuration file.
+// Commands given as relative paths are resolved against the directory of the file.
+func ReadExtensionConfig(filename string) (*ExtensionConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ParseExtensionConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	dir := filepath.Dir(filename)
+	for _, handler := range config.Handlers {
+		if !filepath.IsAbs(handler.Command) && strings.ContainsRune(handler.Command, os.PathSeparator) {
+			handler.Command = filepath.Join(dir, handler.Command)
+		}
+	}
+	return config, nil
+}
+
+// ParseExtensionConfig parses an extension configuration from its YAML representation.
+func ParseExtensionConfig(data []byte) (*ExtensionConfig, error) {
+	config := &ExtensionConfig{}
+	err := yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, err
+	}
+	for i, handler := range config.Handlers {
+		if handler == nil || handler.Pattern == "" {
+			return nil, fmt.Errorf("handler %d has no pattern", i)
+		}
+		if handler.Command == "" {
+			return nil, fmt.Errorf("handler for %s has no command", handler.Pattern)
+		}
+	}
+	if config.Timeout < 0 {
+		return nil, fmt.Errorf("invalid timeout %v", config.Timeout)
+	}
+	if config.Errors != "" && config.Errors != ExtensionErrorsFail && config.Errors != ExtensionErrorsWarn {
+		return nil, fmt.Errorf("invalid errors value %q (expected %q or %q)", config.Errors, ExtensionErrorsFail, ExtensionErrorsWarn)
+	}
+	return config, nil
+}
+
+// HandlerForExtension returns the most specific handler whose pattern matches an extension name,
+// or nil. An exact name is preferred to a prefix and a longer prefix to a shorter one.
+// The wildcard handler is not returned; see WildcardHandler.
+func (c *ExtensionConfig) HandlerForExtension(extensionName string) *ConfiguredExtensionHandler {
+	if c == nil {
+		return nil
+	}
+	var match *ConfiguredExtensionHandler
+	matchSpecificity := -1
+	for _, handler := range c.Handlers {
+		if handler.Pattern == WildcardPattern {
+			continue
+		}
+		if specificity := patternSpecificity(handler.Pattern, extensionName); specificity > matchSpecificity {
+			match, matchSpecificity = handler, specificity
+		}
+	}
+	return match
+}
+
+// patternSpecificity returns how specifically a pattern matches an extension name, or -1 if it
+// doesn't match. An exact name is more specific than any prefix and a longer prefix is more
+// specific than a shorter one. The wildcard pattern is the empty prefix.
+func patternSpecificity(pattern string, extensionName string) int {
+	if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+		if strings.HasPrefix(extensionName, prefix) {
+			return len(prefix)
+		}
+	} else if extensionName == pattern {
+		return len(extensionName) + 1
+	}
+	return -1
+}
+
+// WildcardHandler returns the first handler with the wildcard pattern, or nil.
+func (c *ExtensionConfig) WildcardHandler() *ConfiguredExtensionHandler {
+	if c == nil {
+		return nil
+	}
+	for _, handler := range c.Handlers {
+		if handler.Pattern == WildcardPattern {
+			return handler
+		}
+	}
+	return nil
+}
+
+// NewContextWithExtensionConfig returns a new object representing the compiler state
+// in which extensions matching the configuration are handled by the configured commands.
+// Other extensions are passed to the extension handlers as usual and then to the wildcard handler.
+func NewContextWithExtensionConfig(name string, node *yaml.Node, parent *Context, extensionHandlers *[]ExtensionHandler, config *ExtensionConfig) *Context {
+	extensionHandlers = newCompilation(extensionHandlers)
+	compilationForHandlers(extensionHandlers).state.config = config
+	return compiler.NewContextWithExtensions(name, node, parent, extensionHandlers)
+}
+
+// RemoveExtensionConfig releases the configuration associated with a context,
+// along with any extension results collected by CallExtensionsInBatches.
+// They are released when the contexts of the compilation are no longer used,
+// so this only releases them early.
+func RemoveExtensionConfig(context *Context) {
+	if context == nil || context.ExtensionHandlers == nil {
+		return
+	}
+	compilations.Delete(weak.Make(context.ExtensionHandlers))
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	extensions "github.com/google/gnostic-models/extensions"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// setupStubHandler copies the stub handler and its configuration to a temporary directory.
+func setupStubHandler(t *testing.T) string {
+	dir := t.TempDir()
+	for _, name := range []string{"stub-extension-handler.sh", "extensions.yaml"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(dir, name), data, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	response, err := proto.Marshal(&extensions.ExtensionHandlerResponse{
+		Handled: true,
+		Value:   &anypb.Any{TypeUrl: "type.googleapis.com/stub", Value: []byte("handled")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "response.pb"), response, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestExtensionConfigPatterns(t *testing.T) {
+	config, err := ParseExtensionConfig([]byte(`
+handlers:
+- pattern: x-amazon-*
+  command: amazon
+- pattern: x-book
+  command: book
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, command := range map[string]string{
+		"x-amazon-apigateway-integration": "amazon",
+		"x-amazon-":                       "amazon",
+		"x-book":                          "book",
+		"x-books":                         "",
+		"x-amazon":                        "",
+		"x-other":                         "",
+	} {
+		handler := config.HandlerForExtension(name)
+		if command == "" && handler != nil {
+			t.Errorf("%s: expected no handler, got %s", name, handler.Command)
+		} else if command != "" && (handler == nil || handler.Command != command) {
+			t.Errorf("%s: expected handler %s, got %+v", name, command, handler)
+		}
+	}
+}
+
+func TestExtensionConfigPrecedence(t *testing.T) {
+	config, err := ParseExtensionConfig([]byte(`
+handlers:
+- pattern: "*"
+  command: wildcard
+- pattern: x-*
+  command: any
+- pattern: x-amazon-*
+  command: amazon
+- pattern: x-amazon-apigateway-*
+  command: apigateway
+- pattern: x-amazon-apigateway-integration
+  command: integration
+- pattern: "*"
+  command: second-wildcard
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, command := range map[string]string{
+		"x-amazon-apigateway-integration": "integration",
+		"x-amazon-apigateway-auth":        "apigateway",
+		"x-amazon-other":                  "amazon",
+		"x-other":                         "any",
+		"other":                           "",
+	} {
+		handler := config.HandlerForExtension(name)
+		if command == "" && handler != nil {
+			t.Errorf("%s: expected no handler, got %s", name, handler.Command)
+		} else if command != "" && (handler == nil || handler.Command != command) {
+			t.Errorf("%s: expected handler %s, got %+v", name, command, handler)
+		}
+	}
+	if handler := config.WildcardHandler(); handler == nil || handler.Command != "wildcard" {
+		t.Errorf("expected the first wildcard handler, got %+v", handler)
+	}
+	var empty *ExtensionConfig
+	if handler := empty.WildcardHandler(); handler != nil {
+		t.Errorf("expected no wildcard handler without a configuration, got %+v", handler)
+	}
+}
+
+func TestExtensionConfigOptions(t *testing.T) {
+	config, err := ParseExtensionConfig([]byte("handlers: []\ntimeout: 10s\nerrors: warn\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Timeout != 10*time.Second || config.Errors != ExtensionErrorsWarn {
+		t.Errorf("unexpected options %+v", config)
+	}
+}
+
+func TestExtensionConfigErrors(t *testing.T) {
+	for _, text := range []string{
+		"handlers:\n- command: amazon\n",
+		"handlers:\n- pattern: x-amazon-*\n",
+		"handlers: {}\n",
+		"handlers: []\ntimeout: soon\n",
+		"handlers: []\ntimeout: -1s\n",
+		"handlers: []\nerrors: ignore\n",
+	} {
+		if _, err := ParseExtensionConfig([]byte(text)); err == nil {
+			t.Errorf("expected an error for configuration %q", text)
+		}
+	}
+}
+
+func TestConfiguredExtensionHandlers(t *testing.T) {
+	dir := setupStubHandler(t)
+	config, err := ReadExtensionConfig(filepath.Join(dir, "extensions.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root yaml.Node
+	if err = yaml.Unmarshal([]byte("{}"), &root); err != nil {
+		t.Fatal(err)
+	}
+	context := NewContextWithExtensionConfig("$root", &root, nil, nil, config)
+	defer RemoveExtensionConfig(context)
+	// Extensions are checked from child contexts, which share the configuration.
+	child := NewContext("info", &root, context)
+	value := &yaml.Node{Kind: yaml.ScalarNode, Value: "value"}
+	for _, name := range []string{"x-amazon-apigateway-integration", "x-book", "x-books"} {
+		handled, response, err := CallExtension(child, value, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if expected := name != "x-books"; handled != expected {
+			t.Errorf("%s: expected handled to be %t", name, expected)
+		}
+		if handled && string(response.Value) != "handled" {
+			t.Errorf("%s: unexpected response %+v", name, response)
+		}
+	}
+	calls, err := os.ReadFile(filepath.Join(dir, "calls.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "--prefix amazon\n--exact book\n"
+	if string(calls) != expected {
+		t.Errorf("unexpected handler calls %q (expected %q)", calls, expected)
+	}
+	// After the configuration is removed, extensions fall back to the default behavior.
+	RemoveExtensionConfig(context)
+	if handled, _, _ := CallExtension(child, value, "x-book"); handled {
+		t.Errorf("expected x-book to be unhandled without a configuration")
+	}
+	if !strings.HasPrefix(config.Handlers[0].Command, dir) {
+		t.Errorf("expected command to be resolved relative to the configuration, got %s", config.Handlers[0].Command)
+	}
+}
+
+func TestCompilationsWithSharedHandlers(t *testing.T) {
+	dir := setupStubHandler(t)
+	config, err := ReadExtensionConfig(filepath.Join(dir, "extensions.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Value: "value"}
+	handlers := []ExtensionHandler{}
+	configured := NewContextWithExtensionConfig("$root", value, nil, &handlers, config)
+	unconfigured := NewContextWithExtensions("$root", value, nil, &handlers)
+	// Compilations that share a list of handlers don't share their configurations.
+	if handled, _, _ := CallExtension(unconfigured, value, "x-book"); handled {
+		t.Errorf("expected x-book to be unhandled without a configuration")
+	}
+	if handled, _, err := CallExtension(configured, value, "x-book"); !handled || err != nil {
+		t.Errorf("expected x-book to be handled with the configuration (error %v)", err)
+	}
+	// Releasing one compilation doesn't release the other.
+	RemoveExtensionConfig(unconfigured)
+	if handled, _, err := CallExtension(NewContext("info", value, configured), value, "x-book"); !handled || err != nil {
+		t.Errorf("expected x-book to be handled after another compilation was released (error %v)", err)
+	}
+}
+
+func TestCompilationsAreReleased(t *testing.T) {
+	count := func() int {
+		n := 0
+		compilations.Range(func(key, value any) bool {
+			n++
+			return true
+		})
+		return n
+	}
+	before := count()
+	for range 100 {
+		context := NewContextWithExtensionConfig("$root", nil, nil, nil, &ExtensionConfig{})
+		AddWarning(context, NewWarning(NewContext("info", nil, context), WarningInvalidKey, "unused"))
+	}
+	// Compilations are released without RemoveExtensionConfig when their contexts are unused.
+	deadline := time.Now().Add(10 * time.Second)
+	for count() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the compilations to be released, %d remain", count()-before)
+		}
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
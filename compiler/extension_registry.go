@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ExtensionHandlerFunc handles specification extensions in the compiler's process.
+// It returns the compiled value of an extension and true if it handles the extension,
+// false if it declines it, or an error if the extension is invalid.
+type ExtensionHandlerFunc func(name string, yamlValue string) (proto.Message, bool, error)
+
+type registeredExtensionHandler struct {
+	pattern string
+	handler ExtensionHandlerFunc
+}
+
+var (
+	registeredExtensionHandlers      []*registeredExtensionHandler
+	registeredExtensionHandlersMutex sync.RWMutex
+)
+
+// RegisterExtensionHandler registers a function that handles the extensions whose names match
+// a pattern, for programs that use the compiler as a library. A pattern is an exact extension
+// name, a prefix followed by "*", or WildcardPattern, as in an ExtensionConfig.
+//
+// Registered handlers are consulted before any handler commands. Each extension is offered to
+// the handler with the most specific matching pattern; if it declines, the extension is offered
+// to the handler commands as usual. Registering a pattern again replaces its handler.
+func RegisterExtensionHandler(namePattern string, fn ExtensionHandlerFunc) {
+	registeredExtensionHandlersMutex.Lock()
+	defer registeredExtensionHandlersMutex.Unlock()
+	for _, registered := range registeredExtensionHandlers {
+		if registered.pattern == namePattern {
+			registered.handler = fn
+			return
+		}
+	}
+	registeredExtensionHandlers = append(registeredExtensionHandlers, &registeredExtensionHandler{pattern: namePattern, handler: fn})
+}
+
+// UnregisterExtensionHandler removes the handler registered for a pattern, if any.
+func UnregisterExtensionHandler(namePattern string) {
+	registeredExtensionHandlersMutex.Lock()
+	defer registeredExtensionHandlersMutex.Unlock()
+	for i, registered := range registeredExtensionHandlers {
+		if registered.pattern == namePattern {
+			registeredExtensionHandlers = append(registeredExtensionHandlers[:i], registeredExtensionHandlers[i+1:]...)
+			return
+		}
+	}
+}
+
+// registeredHandlerForExtension returns the registered handler with the most specific pattern
+// that matches an extension name, or nil.
+func registeredHandlerForExtension(extensionName string) ExtensionHandlerFunc {
+	registeredExtensionHandlersMutex.RLock()
+	defer registeredExtensionHandlersMutex.RUnlock()
+	var match ExtensionHandlerFunc
+	matchSpecificity := -1
+	for _, registered := range registeredExtensionHandlers {
+		if specificity := patternSpecificity(registered.pattern, extensionName); specificity > matchSpecificity {
+			match, matchSpecificity = registered.handler, specificity
+		}
+	}
+	return match
+}
+
+// callRegisteredExtensionHandler offers an extension to its registered handler. The result is
+// unhandled if there is no registered handler or if it declined the extension.
+func callRegisteredExtensionHandler(extensionName string, yamlValue string) *extensionResult {
+	handler := registeredHandlerForExtension(extensionName)
+	if handler == nil {
+		return &extensionResult{}
+	}
+	message, handled, err := handler(extensionName, yamlValue)
+	if err != nil {
+		return failedExtensionResult(fmt.Errorf("in-process extension handler failed: %v", err))
+	}
+	if !handled {
+		return &extensionResult{}
+	}
+	if message == nil {
+		return failedExtensionResult(fmt.Errorf("in-process extension handler returned no value"))
+	}
+	response, err := anypb.New(message)
+	if err != nil {
+		return failedExtensionResult(fmt.Errorf("in-process extension handler returned an invalid value: %v", err))
+	}
+	return &extensionResult{handled: true, response: response}
+}
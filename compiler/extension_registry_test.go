@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// registerTestExtensionHandler registers an in-process handler for the duration of a test.
+func registerTestExtensionHandler(t *testing.T, pattern string, fn ExtensionHandlerFunc) {
+	RegisterExtensionHandler(pattern, fn)
+	t.Cleanup(func() { UnregisterExtensionHandler(pattern) })
+}
+
+func TestRegisteredExtensionHandlers(t *testing.T) {
+	registerTestExtensionHandler(t, "x-amazon-*", func(name string, yamlValue string) (proto.Message, bool, error) {
+		switch name {
+		case "x-amazon-declined":
+			return nil, false, nil
+		case "x-amazon-invalid":
+			return nil, true, errors.New("invalid value")
+		}
+		return wrapperspb.String("prefix " + strings.TrimSpace(yamlValue)), true, nil
+	})
+	registerTestExtensionHandler(t, "x-amazon-special", func(name string, yamlValue string) (proto.Message, bool, error) {
+		return wrapperspb.String("exact " + strings.TrimSpace(yamlValue)), true, nil
+	})
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte("x-amazon-other: 1\nx-amazon-special: 2\nx-amazon-declined: 3\nx-amazon-invalid: 4\n"), &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := node.Content[0]
+	for _, batched := range []bool{false, true} {
+		// The test binary handles extensions beginning with "x-amazon-" when they're declined in-process.
+		log := setupStubHandlerMode(t, "batch")
+		handlers := []ExtensionHandler{{Name: os.Args[0]}}
+		context := NewContextWithExtensions("$root", root, nil, &handlers)
+		if batched {
+			CallExtensionsInBatches(context, root)
+		}
+		values := make(map[string]string)
+		for _, extension := range findExtensions(root, nil) {
+			name := extension.wrapper.ExtensionName
+			handled, response, err := CallExtension(context, extension.node, name)
+			if name == "x-amazon-invalid" {
+				if !handled || err == nil || !strings.Contains(err.Error(), "$root.x-amazon-invalid extension x-amazon-invalid: in-process extension handler failed: invalid value") {
+					t.Errorf("batched=%t: unexpected result for %s: %t %v", batched, name, handled, err)
+				}
+				continue
+			}
+			value := &wrapperspb.StringValue{}
+			if !handled || err != nil || response.UnmarshalTo(value) != nil {
+				t.Fatalf("batched=%t: %s was not handled: %v %v", batched, name, response, err)
+			}
+			values[name] = value.Value
+		}
+		// The most specific pattern is used, and declined extensions are sent to the handler command.
+		if values["x-amazon-other"] != "prefix 1" || values["x-amazon-special"] != "exact 2" || values["x-amazon-declined"] != "x-amazon-declined: 3" {
+			t.Errorf("batched=%t: unexpected values %v", batched, values)
+		}
+		if calls := countStubHandlerCalls(t, log); calls != 1 {
+			t.Errorf("batched=%t: expected 1 handler command call, got %d", batched, calls)
+		}
+		RemoveExtensionConfig(context)
+	}
+	UnregisterExtensionHandler("x-amazon-*")
+	if registeredHandlerForExtension("x-amazon-other") != nil {
+		t.Errorf("expected the handler to be unregistered")
+	}
+}
@@ -15,11 +15,492 @@
 package compiler
 
 import (
+	"bytes"
+	gocontext "context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"weak"
+
 	"github.com/google/gnostic-models/compiler"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	extensions "github.com/google/gnostic/extensions"
 )
 
 // ExtensionHandler describes a binary that is called by the compiler to handle specification extensions.
 type ExtensionHandler = compiler.ExtensionHandler
 
-// CallExtension calls a binary extension handler.
-var CallExtension = compiler.CallExtension
+// CallExtension calls a binary extension handler. If the extension was already handled
+// by CallExtensionsInBatches, the saved result is returned. Handlers registered with
+// RegisterExtensionHandler are consulted first. Then, if the context has an extension
+// configuration with a handler for the extension, that handler is called.
+// Otherwise, or if it declines, the extension is offered to each of the context's
+// extension handlers and finally to the configuration's wildcard handler, if any.
+//
+// In a strict configuration, extensions that no handler accepts are recorded for
+// UnhandledExtensionsError.
+//
+// A handler that fails doesn't stop the search: the extension is still offered to the
+// remaining extension handlers and the wildcard handler, as before handlers were configured,
+// and the first failure is returned only if none of them accepts the extension.
+// Handler failures are returned as errors that locate the extension in the document.
+// If the configuration sets ExtensionErrorsWarn, they are saved as warnings instead,
+// which are returned by ExtensionWarnings, and the extension is left unhandled.
+// Warnings that handlers report about the extensions they accept are saved separately
+// and returned by ExtensionHandlerWarnings.
+func CallExtension(context *Context, in *yaml.Node, extensionName string) (handled bool, response *anypb.Any, err error) {
+	return CallExtensionForObject(context, in, extensionName, "")
+}
+
+// CallExtensionForObject calls a binary extension handler like CallExtension, for an extension
+// of an object of the named kind. Handlers receive the kind with the extension's path.
+func CallExtensionForObject(context *Context, in *yaml.Node, extensionName string, objectKind string) (handled bool, response *anypb.Any, err error) {
+	state := extensionStateForContext(context)
+	if err := state.cancellationError(); err != nil {
+		return true, nil, err
+	}
+	location := &extensions.ExtensionLocation{
+		Path:       NewContext(extensionName, in, context).Description(),
+		ObjectKind: objectKind,
+	}
+	if state.collect(&pendingExtension{node: in, wrapper: newExtensionWrapper(in, extensionName), location: location}) {
+		return false, nil, nil
+	}
+	result, ok := state.result(in, extensionName)
+	if !ok {
+		result = state.callExtension(context, in, extensionName, location)
+		if err := state.cancellationError(); err != nil {
+			return true, nil, err
+		}
+	}
+	for _, warning := range result.warnings {
+		state.addHandlerWarning(NewError(NewContext(extensionName, in, context), fmt.Sprintf("extension %s: %s", extensionName, warning)))
+	}
+	if result.err == nil {
+		if result.handled {
+			AddStat(state.goContext(), StatExtensionsHandled, 1)
+		}
+		if !result.handled && state.strict() {
+			state.addUnhandled(NewContext(extensionName, in, context), extensionName)
+		}
+		return result.handled, result.response, nil
+	}
+	extensionError := NewError(NewContext(extensionName, in, context), fmt.Sprintf("extension %s: %v", extensionName, result.err))
+	if state.warnOnErrors() {
+		state.addWarning(extensionError)
+		return false, nil, nil
+	}
+	return true, nil, extensionError
+}
+
+// ExtensionWarnings returns the handler failures that were reported as warnings
+// during a compilation that uses an extension configuration.
+func ExtensionWarnings(context *Context) []*Error {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	return append([]*Error(nil), state.warnings...)
+}
+
+// ExtensionHandlerWarnings returns the warnings that extension handlers reported about the
+// extensions that they accepted during a compilation that uses an extension configuration
+// or CallExtensionsInBatches. They don't cause the compilation to fail.
+func ExtensionHandlerWarnings(context *Context) []*Error {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	return append([]*Error(nil), state.handlerWarnings...)
+}
+
+// UnhandledExtensionsError returns an error that lists the extensions that no handler accepted
+// during a compilation with a strict extension configuration, or nil if there were none.
+// Each extension is listed once with the location of its first use.
+func UnhandledExtensionsError(context *Context) error {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	errors := make([]error, 0)
+	for _, extension := range state.unhandled {
+		message := fmt.Sprintf("extension %s has no handler", extension.name)
+		if extension.count > 1 {
+			message += fmt.Sprintf(" (used %d times)", extension.count)
+		}
+		errors = append(errors, NewError(extension.context, message))
+	}
+	return NewErrorGroupOrNil(errors)
+}
+
+// callExtension offers an extension to its handlers in order of precedence. If every handler
+// declines it but an extension handler failed, the first failure is returned.
+func (s *extensionState) callExtension(context *Context, in *yaml.Node, extensionName string, location *extensions.ExtensionLocation) *extensionResult {
+	wrapper := newExtensionWrapper(in, extensionName)
+	if result := callRegisteredExtensionHandler(extensionName, wrapper.Yaml); result.handled {
+		return result
+	}
+	config := s.extensionConfig()
+	if handler := config.HandlerForExtension(extensionName); handler != nil {
+		if result := s.callExtensionHandler(handler.Command, handler.Args, wrapper, location); result.handled {
+			return result
+		}
+	}
+	var failure *extensionResult
+	if context != nil && context.ExtensionHandlers != nil {
+		for _, handler := range *context.ExtensionHandlers {
+			if handler.Name == "" {
+				continue
+			}
+			result := s.callExtensionHandler(handler.Name, nil, wrapper, location)
+			if result.response != nil {
+				return result
+			}
+			if failure == nil && result.err != nil {
+				failure = result
+			}
+		}
+	}
+	if handler := config.WildcardHandler(); handler != nil {
+		if result := s.callExtensionHandler(handler.Command, handler.Args, wrapper, location); result.handled {
+			return result
+		}
+	}
+	if failure != nil {
+		return failure
+	}
+	return &extensionResult{}
+}
+
+// newExtensionWrapper wraps an extension value for an extension handler.
+func newExtensionWrapper(in *yaml.Node, extensionName string) *extensions.Wrapper {
+	yamlData, _ := yaml.Marshal(in)
+	return &extensions.Wrapper{
+		Version:       "unknown",
+		Yaml:          string(yamlData),
+		ExtensionName: extensionName,
+	}
+}
+
+// compilerVersion returns the compiler version reported to extension handlers.
+func compilerVersion() *extensions.Version {
+	return &extensions.Version{
+		Major: 0,
+		Minor: 1,
+		Patch: 0,
+	}
+}
+
+// compilerCapabilities returns the capabilities that the compiler reports to extension handlers.
+func compilerCapabilities() *extensions.Capabilities {
+	return &extensions.Capabilities{
+		ProtocolVersion: extensions.ProtocolVersion,
+		Features:        []string{extensions.FeatureBatch},
+	}
+}
+
+// runExtensionHandler runs an extension handler command with a request and returns its output.
+// The request is sent with the compiler's capabilities.
+// The handler is killed if it doesn't finish within the configured timeout or if the
+// compilation is canceled, and it isn't started if the compilation was already canceled.
+func (s *extensionState) runExtensionHandler(command string, args []string, request *extensions.ExtensionHandlerBatchRequest) ([]byte, error) {
+	if err := s.cancellationError(); err != nil {
+		return nil, err
+	}
+	request.Capabilities = compilerCapabilities()
+	requestBytes, _ := proto.Marshal(request)
+	cmd := exec.CommandContext(s.goContext(), command, args...)
+	cmd.Stdin = bytes.NewReader(requestBytes)
+	cmd.Stderr = os.Stderr
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	// Don't wait for processes started by the handler that keep its output open.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("extension handler %s failed: %v", command, err)
+	}
+	timeout := s.timeout()
+	timer := time.AfterFunc(timeout, func() {
+		cmd.Process.Kill()
+	})
+	err := cmd.Wait()
+	if !timer.Stop() {
+		return nil, fmt.Errorf("extension handler %s timed out after %v", command, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extension handler %s failed: %v", command, err)
+	}
+	return output.Bytes(), nil
+}
+
+// callExtensionHandler sends a single extension with its location to an extension handler command.
+// The result is unhandled if the handler declined the extension.
+func (s *extensionState) callExtensionHandler(command string, args []string, wrapper *extensions.Wrapper, location *extensions.ExtensionLocation) *extensionResult {
+	// Handlers that predate locations read this as an ExtensionHandlerRequest.
+	output, err := s.runExtensionHandler(command, args, &extensions.ExtensionHandlerBatchRequest{
+		CompilerVersion: compilerVersion(),
+		Wrapper:         wrapper,
+		Location:        location,
+	})
+	if err != nil {
+		return failedExtensionResult(err)
+	}
+	// Handlers that predate capabilities reply with an ExtensionHandlerResponse.
+	response := &extensions.ExtensionHandlerSingleResponse{}
+	err = proto.Unmarshal(output, response)
+	if err != nil {
+		return failedExtensionResult(fmt.Errorf("extension handler %s returned an invalid response: %v", command, err))
+	}
+	if capabilities := response.GetCapabilities(); capabilities.GetProtocolVersion() > 0 {
+		s.saveHandlerCapabilities(command, args, capabilities)
+	}
+	return extensionHandlerResult(command, wrapper, response.Response(), response.Warnings)
+}
+
+// extensionHandlerResult interprets the response of an extension handler and the warnings that
+// came with it. Warnings are dropped if the handler declined the extension.
+func extensionHandlerResult(command string, wrapper *extensions.Wrapper, response *extensions.ExtensionHandlerResponse, warnings []string) *extensionResult {
+	if !response.Handled {
+		return &extensionResult{}
+	}
+	if len(response.Errors) != 0 {
+		return &extensionResult{
+			handled:  true,
+			warnings: warnings,
+			err:      fmt.Errorf("Errors when parsing: %+v for field %s by vendor extension handler %s. Details %+v", wrapper.Yaml, wrapper.ExtensionName, command, strings.Join(response.Errors, ",")),
+		}
+	}
+	if response.Value == nil {
+		return &extensionResult{}
+	}
+	return &extensionResult{handled: true, response: response.Value, warnings: warnings}
+}
+
+// failedExtensionResult returns the result of an extension handler that failed.
+func failedExtensionResult(err error) *extensionResult {
+	return &extensionResult{handled: true, err: err}
+}
+
+// extensionState holds the extension configuration and saved extension results for a compilation.
+type extensionState struct {
+	config    *ExtensionConfig
+	results   map[extensionKey]*extensionResult
+	warnings  []*Error
+	unhandled []*unhandledExtension
+	// Warnings reported by handlers about the extensions that they accepted.
+	handlerWarnings []*Error
+	// The capabilities reported by each handler command, keyed by handlerKey.
+	capabilities map[string]*extensions.Capabilities
+	// While collecting, extensions are saved here instead of being sent to handlers.
+	collecting bool
+	collected  []*pendingExtension
+	// The Go context of a compilation started with NewContextWithCancellation.
+	ctx gocontext.Context
+	// Whether scalars are read with their YAML 1.1 meanings; see SetYAML11Compat.
+	yaml11Compat bool
+	// Scalars that are read differently by YAML 1.1, reported once each.
+	yaml11Scalars     map[*yaml.Node]bool
+	yaml11Coercions   []*Error
+	yaml11Ambiguities []*Error
+	// All of the warnings, in the order that they were reported; see Warnings.
+	allWarnings []*Warning
+	warningKeys map[warningKey]bool
+}
+
+type extensionKey struct {
+	node *yaml.Node
+	name string
+}
+
+type extensionResult struct {
+	handled  bool
+	response *anypb.Any
+	warnings []string
+	err      error
+}
+
+// unhandledExtension records the uses of an extension that no handler accepted.
+type unhandledExtension struct {
+	name    string
+	context *Context
+	count   int
+}
+
+// compilation holds the extension handlers and the extension state of a compilation. The
+// contexts of the compilation refer to its handlers, which keeps it alive while they are used.
+type compilation struct {
+	handlers []ExtensionHandler
+	state    extensionState
+}
+
+var (
+	// The compilations, by weak pointers to their handlers. Compilations remove themselves
+	// when they are collected.
+	compilations         sync.Map // weak.Pointer[[]ExtensionHandler] -> weak.Pointer[compilation]
+	extensionStatesMutex sync.Mutex
+)
+
+// newCompilation starts a compilation with a copy of a list of extension handlers and returns
+// the list that its contexts use.
+func newCompilation(extensionHandlers *[]ExtensionHandler) *[]ExtensionHandler {
+	c := &compilation{
+		state: extensionState{
+			results:       make(map[extensionKey]*extensionResult),
+			capabilities:  make(map[string]*extensions.Capabilities),
+			yaml11Scalars: make(map[*yaml.Node]bool),
+		},
+	}
+	if extensionHandlers != nil {
+		c.handlers = *extensionHandlers
+	}
+	key := weak.Make(&c.handlers)
+	compilations.Store(key, weak.Make(c))
+	runtime.AddCleanup(c, func(key weak.Pointer[[]ExtensionHandler]) {
+		compilations.Delete(key)
+	}, key)
+	return &c.handlers
+}
+
+// compilationForHandlers returns the compilation that a list of extension handlers belongs to,
+// or nil if it doesn't belong to one.
+func compilationForHandlers(extensionHandlers *[]ExtensionHandler) *compilation {
+	if extensionHandlers == nil {
+		return nil
+	}
+	value, ok := compilations.Load(weak.Make(extensionHandlers))
+	if !ok {
+		return nil
+	}
+	return value.(weak.Pointer[compilation]).Value()
+}
+
+// extensionStateForContext returns the extension state of the compilation of a context, or nil
+// if the context isn't part of a compilation.
+func extensionStateForContext(context *Context) *extensionState {
+	if context == nil {
+		return nil
+	}
+	c := compilationForHandlers(context.ExtensionHandlers)
+	if c == nil {
+		return nil
+	}
+	return &c.state
+}
+
+func (s *extensionState) extensionConfig() *ExtensionConfig {
+	if s == nil {
+		return nil
+	}
+	return s.config
+}
+
+func (s *extensionState) timeout() time.Duration {
+	if s == nil || s.config == nil || s.config.Timeout <= 0 {
+		return DefaultExtensionTimeout
+	}
+	return s.config.Timeout
+}
+
+func (s *extensionState) warnOnErrors() bool {
+	return s != nil && s.config != nil && s.config.Errors == ExtensionErrorsWarn
+}
+
+func (s *extensionState) addWarning(err *Error) {
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	s.warnings = append(s.warnings, err)
+	s.addWarningLocked(NewWarning(err.Context, WarningExtensionHandlerFailed, err.Message))
+}
+
+func (s *extensionState) addHandlerWarning(err *Error) {
+	if s == nil {
+		return
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	s.handlerWarnings = append(s.handlerWarnings, err)
+	s.addWarningLocked(NewWarning(err.Context, WarningExtensionHandler, err.Message))
+}
+
+func (s *extensionState) strict() bool {
+	return s != nil && s.config != nil && s.config.Strict
+}
+
+func (s *extensionState) addUnhandled(context *Context, name string) {
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	for _, extension := range s.unhandled {
+		if extension.name == name {
+			extension.count++
+			return
+		}
+	}
+	s.unhandled = append(s.unhandled, &unhandledExtension{name: name, context: context, count: 1})
+}
+
+// collect saves an extension if the state is collecting extensions and returns true if it did.
+func (s *extensionState) collect(extension *pendingExtension) bool {
+	if s == nil {
+		return false
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	if !s.collecting {
+		return false
+	}
+	s.collected = append(s.collected, extension)
+	return true
+}
+
+func (s *extensionState) result(node *yaml.Node, name string) (*extensionResult, bool) {
+	if s == nil {
+		return nil, false
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	result, ok := s.results[extensionKey{node: node, name: name}]
+	return result, ok
+}
+
+func (s *extensionState) saveResult(node *yaml.Node, name string, result *extensionResult) {
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	s.results[extensionKey{node: node, name: name}] = result
+}
+
+// handlerKey identifies a handler command and its arguments.
+func handlerKey(command string, args []string) string {
+	return strings.Join(append([]string{command}, args...), "\x00")
+}
+
+// handlerCapabilities returns the capabilities reported by a handler, or nil if they are not yet known.
+func (s *extensionState) handlerCapabilities(command string, args []string) *extensions.Capabilities {
+	if s == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	return s.capabilities[handlerKey(command, args)]
+}
+
+func (s *extensionState) saveHandlerCapabilities(command string, args []string, capabilities *extensions.Capabilities) {
+	if s == nil {
+		return
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	s.capabilities[handlerKey(command, args)] = capabilities
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// failingExtensionContext returns a document with an extension, a context for its
+// "info" section, and the extension's value.
+func failingExtensionContext(t *testing.T, config *ExtensionConfig, handlers []ExtensionHandler) (*Context, *Context, *yaml.Node) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("info:\n  x-amazon-failing: 1\n"), &node); err != nil {
+		t.Fatal(err)
+	}
+	root := node.Content[0]
+	context := NewContextWithExtensionConfig("$root", root, nil, &handlers, config)
+	t.Cleanup(func() { RemoveExtensionConfig(context) })
+	info := root.Content[1]
+	return context, NewContext("info", info, context), info.Content[1]
+}
+
+func checkExtensionError(t *testing.T, err error, expected ...string) {
+	t.Helper()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, ok := err.(*Error); !ok {
+		t.Errorf("expected a compiler error, got %T", err)
+	}
+	for _, text := range append([]string{"[2,21] $root.info.x-amazon-failing", "extension x-amazon-failing", os.Args[0]}, expected...) {
+		if !strings.Contains(err.Error(), text) {
+			t.Errorf("expected %q in error %q", text, err)
+		}
+	}
+}
+
+func TestExtensionHandlerTimeout(t *testing.T) {
+	log := setupStubHandlerMode(t, "sleep")
+	config := &ExtensionConfig{
+		Handlers: []*ConfiguredExtensionHandler{{Pattern: "x-amazon-*", Command: os.Args[0]}},
+		Timeout:  100 * time.Millisecond,
+	}
+	_, info, value := failingExtensionContext(t, config, nil)
+	start := time.Now()
+	handled, _, err := CallExtension(info, value, "x-amazon-failing")
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the handler to be killed, but it ran for %v", elapsed)
+	}
+	if !handled {
+		t.Errorf("expected a failed extension to be reported as handled")
+	}
+	checkExtensionError(t, err, "timed out after 100ms")
+	if calls := countStubHandlerCalls(t, log); calls != 1 {
+		t.Errorf("expected 1 handler call, got %d", calls)
+	}
+}
+
+func TestExtensionHandlerCrash(t *testing.T) {
+	for _, batched := range []bool{false, true} {
+		setupStubHandlerMode(t, "crash")
+		// A default handler that fails is reported if no other handler accepts the extension.
+		context, info, value := failingExtensionContext(t, nil, []ExtensionHandler{{Name: os.Args[0]}})
+		if batched {
+			CallExtensionsInBatches(context, context.Node)
+		}
+		handled, _, err := CallExtension(info, value, "x-amazon-failing")
+		if !handled {
+			t.Errorf("batched=%t: expected a failed extension to be reported as handled", batched)
+		}
+		checkExtensionError(t, err, "failed: exit status 2")
+	}
+}
+
+func TestExtensionHandlerFailureFallback(t *testing.T) {
+	setupStubHandlerMode(t, "batch")
+	missing := filepath.Join(t.TempDir(), "missing-handler")
+	// A handler that fails doesn't keep a later handler from accepting the extension.
+	_, info, value := failingExtensionContext(t, nil, []ExtensionHandler{{Name: missing}, {Name: os.Args[0]}})
+	handled, response, err := CallExtension(info, value, "x-amazon-failing")
+	if !handled || response == nil || err != nil {
+		t.Errorf("expected the extension to be handled by the second handler, got %t %v %v", handled, response, err)
+	}
+	// If no handler accepts it, the first failure is returned.
+	_, info, value = failingExtensionContext(t, nil, []ExtensionHandler{{Name: missing}, {Name: missing + "-2"}})
+	handled, _, err = CallExtension(info, value, "x-amazon-failing")
+	if !handled {
+		t.Errorf("expected a failed extension to be reported as handled")
+	}
+	if err == nil || !strings.Contains(err.Error(), "extension handler "+missing+" failed") {
+		t.Errorf("expected the failure of the first handler, got %v", err)
+	}
+}
+
+func TestExtensionErrorsWarn(t *testing.T) {
+	setupStubHandlerMode(t, "crash")
+	config := &ExtensionConfig{
+		Handlers: []*ConfiguredExtensionHandler{{Pattern: WildcardPattern, Command: os.Args[0]}},
+		Errors:   ExtensionErrorsWarn,
+	}
+	context, info, value := failingExtensionContext(t, config, nil)
+	handled, response, err := CallExtension(info, value, "x-amazon-failing")
+	if handled || response != nil || err != nil {
+		t.Errorf("expected the extension to be left unhandled, got %t %v %v", handled, response, err)
+	}
+	warnings := ExtensionWarnings(context)
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	checkExtensionError(t, warnings[0], "failed: exit status 2")
+	RemoveExtensionConfig(context)
+	if warnings := ExtensionWarnings(context); len(warnings) != 0 {
+		t.Errorf("expected warnings to be removed with the configuration, got %v", warnings)
+	}
+}
+
+func TestExtensionHandlerWarnings(t *testing.T) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("info:\n  x-clean: 1\n  x-warn: 2\n  x-fail: 3\n"), &node); err != nil {
+		t.Fatal(err)
+	}
+	root := node.Content[0]
+	extensions := findExtensions(root, nil)
+	for _, batched := range []bool{false, true} {
+		log := setupStubHandlerMode(t, "warnings")
+		config := &ExtensionConfig{Handlers: []*ConfiguredExtensionHandler{{Pattern: WildcardPattern, Command: os.Args[0]}}}
+		context := NewContextWithExtensionConfig("$root", root, nil, nil, config)
+		info := NewContext("info", root.Content[1], context)
+		if batched {
+			CallExtensionsInBatches(context, root)
+		}
+		results := make(map[string]error)
+		for _, extension := range extensions {
+			name := extension.wrapper.ExtensionName
+			handled, response, err := CallExtension(info, extension.node, name)
+			if !handled || (response == nil) == (err == nil) {
+				t.Errorf("batched=%t: expected %s to be handled with a value or an error, got %t %v %v", batched, name, handled, response, err)
+			}
+			results[name] = err
+		}
+		// Warnings don't cause failures, and errors are reported as before.
+		if results["x-clean"] != nil || results["x-warn"] != nil {
+			t.Errorf("batched=%t: unexpected errors %v", batched, results)
+		}
+		if err := results["x-fail"]; err == nil || !strings.Contains(err.Error(), "[4,11] $root.info.x-fail extension x-fail: Errors when parsing") {
+			t.Errorf("batched=%t: unexpected error for x-fail: %v", batched, err)
+		}
+		warnings := ExtensionHandlerWarnings(context)
+		expected := []string{
+			"[3,11] $root.info.x-warn extension x-warn: x-warn should be lowercase",
+			"[4,11] $root.info.x-fail extension x-fail: x-fail is deprecated",
+		}
+		if len(warnings) != len(expected) {
+			t.Fatalf("batched=%t: expected %d warnings, got %v", batched, len(expected), warnings)
+		}
+		for i, warning := range warnings {
+			if warning.Error() != expected[i] {
+				t.Errorf("batched=%t: unexpected warning %q (expected %q)", batched, warning, expected[i])
+			}
+		}
+		if len(ExtensionWarnings(context)) != 0 {
+			t.Errorf("batched=%t: expected handler warnings to be kept apart from failures", batched)
+		}
+		expectedCalls := 3
+		if batched {
+			expectedCalls = 1
+		}
+		if calls := countStubHandlerCalls(t, log); calls != expectedCalls {
+			t.Errorf("batched=%t: expected %d handler calls, got %d", batched, expectedCalls, calls)
+		}
+		RemoveExtensionConfig(context)
+	}
+}
+
+func TestUnhandledExtensionsError(t *testing.T) {
+	setupStubHandlerMode(t, "batch")
+	node := documentWithExtensions(t, 3)
+	for _, strict := range []bool{false, true} {
+		handlers := []ExtensionHandler{{Name: os.Args[0]}}
+		context := NewContextWithExtensionConfig("$root", node, nil, &handlers, &ExtensionConfig{Strict: strict})
+		CallExtensionsInBatches(context, node)
+		callAllExtensions(t, context, node)
+		err := UnhandledExtensionsError(context)
+		RemoveExtensionConfig(context)
+		if !strict {
+			if err != nil {
+				t.Errorf("expected no error without strict mode, got %v", err)
+			}
+			continue
+		}
+		// The extensions handled by the stub handler aren't listed, and x-other is listed once.
+		expected := "[5,16] $root.x-other extension x-other has no handler (used 3 times)"
+		if err == nil || err.Error() != expected {
+			t.Errorf("unexpected error %v (expected %q)", err, expected)
+		}
+	}
+}
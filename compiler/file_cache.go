@@ -0,0 +1,274 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"sync"
+
+	"github.com/google/gnostic-models/compiler"
+	"go.yaml.in/yaml/v3"
+)
+
+// FileCache caches the documents that the compiler reads with its file resolver and the
+// YAML nodes parsed from them. Parsed nodes are keyed by file name or by $ref.
+// Implementations must be safe for concurrent use.
+type FileCache interface {
+	Bytes(location string) ([]byte, bool)
+	SetBytes(location string, bytes []byte)
+	Info(key string) (*yaml.Node, bool)
+	SetInfo(key string, info *yaml.Node)
+}
+
+// NewFileCache returns an empty in-memory FileCache.
+func NewFileCache() FileCache {
+	return &memoryFileCache{}
+}
+
+// memoryFileCache is a FileCache that keeps its entries in maps guarded by a mutex.
+type memoryFileCache struct {
+	mutex sync.Mutex
+	bytes map[string][]byte
+	info  map[string]*yaml.Node
+}
+
+func (c *memoryFileCache) Bytes(location string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	bytes, ok := c.bytes[location]
+	return bytes, ok
+}
+
+func (c *memoryFileCache) SetBytes(location string, bytes []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.bytes == nil {
+		c.bytes = make(map[string][]byte)
+	}
+	c.bytes[location] = bytes
+}
+
+func (c *memoryFileCache) Info(key string) (*yaml.Node, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	info, ok := c.info[key]
+	return info, ok
+}
+
+func (c *memoryFileCache) SetInfo(key string, info *yaml.Node) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.info == nil {
+		c.info = make(map[string]*yaml.Node)
+	}
+	c.info[key] = info
+}
+
+func (c *memoryFileCache) removeBytes(location string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.bytes, location)
+}
+
+func (c *memoryFileCache) removeInfo(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.info, key)
+}
+
+func (c *memoryFileCache) clearBytes() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.bytes = nil
+}
+
+func (c *memoryFileCache) clearInfo() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.info = nil
+}
+
+// processFileCache is the cache that is used by reads without a cache in their Go context.
+// It is shared by the whole process and can be disabled with DisableFileCache and DisableInfoCache.
+type processFileCache struct{}
+
+var (
+	processCache     = &memoryFileCache{}
+	fileCacheEnable  = true
+	infoCacheEnable  = true
+	cacheEnableMutex sync.Mutex
+)
+
+func (processFileCache) Bytes(location string) ([]byte, bool) {
+	if !cacheEnabled(&fileCacheEnable) {
+		return nil, false
+	}
+	return processCache.Bytes(location)
+}
+
+func (processFileCache) SetBytes(location string, bytes []byte) {
+	if cacheEnabled(&fileCacheEnable) {
+		processCache.SetBytes(location, bytes)
+	}
+}
+
+func (processFileCache) Info(key string) (*yaml.Node, bool) {
+	if !cacheEnabled(&infoCacheEnable) {
+		return nil, false
+	}
+	return processCache.Info(key)
+}
+
+func (processFileCache) SetInfo(key string, info *yaml.Node) {
+	if cacheEnabled(&infoCacheEnable) {
+		processCache.SetInfo(key, info)
+	}
+}
+
+func cacheEnabled(enable *bool) bool {
+	cacheEnableMutex.Lock()
+	defer cacheEnableMutex.Unlock()
+	return *enable
+}
+
+func setCacheEnabled(enable *bool, value bool) {
+	cacheEnableMutex.Lock()
+	defer cacheEnableMutex.Unlock()
+	*enable = value
+}
+
+// noFileCache is the cache of Go contexts that disable caching.
+type noFileCache struct{}
+
+func (noFileCache) Bytes(location string) ([]byte, bool)   { return nil, false }
+func (noFileCache) SetBytes(location string, bytes []byte) {}
+func (noFileCache) Info(key string) (*yaml.Node, bool)     { return nil, false }
+func (noFileCache) SetInfo(key string, info *yaml.Node)    {}
+
+type fileCacheKey struct{}
+
+type fileCacheValue struct {
+	cache FileCache
+}
+
+// WithFileCache returns a copy of a Go context in which the compiler caches the files that it
+// reads in cache. Compilations that use the context share the cache, and other compilations
+// don't see its entries. A nil cache disables caching. Reads with Go contexts that have no
+// cache use a cache that is shared by the whole process.
+func WithFileCache(ctx gocontext.Context, cache FileCache) gocontext.Context {
+	return gocontext.WithValue(ctx, fileCacheKey{}, fileCacheValue{cache: cache})
+}
+
+// FileCacheFromContext returns the cache that was set with WithFileCache and true,
+// or nil and false if the Go context has no cache.
+func FileCacheFromContext(ctx gocontext.Context) (FileCache, bool) {
+	value, ok := ctx.Value(fileCacheKey{}).(fileCacheValue)
+	return value.cache, ok
+}
+
+// WithoutCaching returns a copy of a Go context in which the compiler reads every file
+// and reference again instead of using any cache, like WithFileCache with a nil cache.
+// Use it for single compilations that must see the current contents of remote files.
+func WithoutCaching(ctx gocontext.Context) gocontext.Context {
+	return WithFileCache(ctx, nil)
+}
+
+// fileCacheForContext returns the cache to use for reads with a Go context.
+func fileCacheForContext(ctx gocontext.Context) FileCache {
+	cache, ok := FileCacheFromContext(ctx)
+	if !ok {
+		return processFileCache{}
+	}
+	if cache == nil {
+		return noFileCache{}
+	}
+	return cache
+}
+
+// EnableFileCache turns on file caching.
+func EnableFileCache() {
+	setCacheEnabled(&fileCacheEnable, true)
+}
+
+// EnableInfoCache turns on parsed info caching.
+func EnableInfoCache() {
+	setCacheEnabled(&infoCacheEnable, true)
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+	compiler.EnableInfoCache()
+}
+
+// DisableFileCache turns off file caching.
+func DisableFileCache() {
+	setCacheEnabled(&fileCacheEnable, false)
+}
+
+// DisableInfoCache turns off parsed info caching.
+// References are then resolved without the file resolver; see ReadReferencedFiles.
+func DisableInfoCache() {
+	setCacheEnabled(&infoCacheEnable, false)
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+	compiler.DisableInfoCache()
+}
+
+// EnableCaching turns on the file and parsed info caching that DisableCaching turned off.
+func EnableCaching() {
+	EnableFileCache()
+	EnableInfoCache()
+}
+
+// DisableCaching turns off file and parsed info caching for reads with Go contexts that
+// have no cache, and clears the caches, so that their entries aren't used if caching
+// is turned on again. Caches that are set with WithFileCache are still used.
+func DisableCaching() {
+	DisableFileCache()
+	DisableInfoCache()
+	ClearCaches()
+}
+
+// RemoveFromFileCache removes an entry from the file cache.
+func RemoveFromFileCache(fileurl string) {
+	processCache.removeBytes(fileurl)
+}
+
+// RemoveFromInfoCache removes an entry from the info cache.
+func RemoveFromInfoCache(filename string) {
+	processCache.removeInfo(filename)
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+	compiler.RemoveFromInfoCache(filename)
+}
+
+// ClearFileCache clears the file cache.
+func ClearFileCache() {
+	processCache.clearBytes()
+}
+
+// ClearInfoCache clears the info cache.
+func ClearInfoCache() {
+	processCache.clearInfo()
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+	compiler.ClearInfoCache()
+}
+
+// ClearCaches clears the caches that are shared by the whole process, so that later
+// compilations read files and references again. It is safe to call during compilations,
+// which then read again any entries that they haven't used yet.
+func ClearCaches() {
+	ClearFileCache()
+	ClearInfoCache()
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestFileCacheInContext(t *testing.T) {
+	resolver := setupMemoryResolver(t, map[string]string{
+		"/specs/root.yaml": "pet:\n  $ref: 'pet.yaml#/Pet'\n",
+		"/specs/pet.yaml":  "Pet:\n  type: object\n",
+	})
+	cache := NewFileCache()
+	ctx := WithFileCache(gocontext.Background(), cache)
+	if c, ok := FileCacheFromContext(ctx); !ok || c != cache {
+		t.Errorf("expected the cache in the context")
+	}
+	for i := 0; i < 2; i++ {
+		if err := ReadReferencedFilesContext(ctx, "/specs/root.yaml"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if resolver.requests["/specs/root.yaml"] != 1 || resolver.requests["/specs/pet.yaml"] != 1 {
+		t.Errorf("expected one request for each file, got %v", resolver.requests)
+	}
+	if _, ok := cache.Bytes("/specs/pet.yaml"); !ok {
+		t.Errorf("expected the file in the cache")
+	}
+	if info, ok := cache.Info("pet.yaml#/Pet"); !ok || MapValueForKey(info, "type") == nil {
+		t.Errorf("expected the reference in the cache")
+	}
+	// Reads with other contexts don't see the cache.
+	if _, ok := (processFileCache{}).Bytes("/specs/pet.yaml"); ok {
+		t.Errorf("expected the file not to be in the process cache")
+	}
+	if _, err := ReadBytesForFileContext(WithFileCache(gocontext.Background(), NewFileCache()), "/specs/pet.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if resolver.requests["/specs/pet.yaml"] != 2 {
+		t.Errorf("expected a second request, got %v", resolver.requests)
+	}
+	// A nil cache disables caching.
+	ctx = WithFileCache(gocontext.Background(), nil)
+	if c, ok := FileCacheFromContext(ctx); !ok || c != nil {
+		t.Errorf("expected a nil cache in the context")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := ReadBytesForFileContext(ctx, "/specs/pet.yaml"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if resolver.requests["/specs/pet.yaml"] != 4 {
+		t.Errorf("expected uncached requests, got %v", resolver.requests)
+	}
+}
+
+func TestConcurrentCompilations(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 8; i++ {
+		files[fmt.Sprintf("/specs/%d/root.yaml", i)] = "pet:\n  $ref: 'pet.yaml#/Pet'\n"
+		files[fmt.Sprintf("/specs/%d/pet.yaml", i)] = fmt.Sprintf("Pet:\n  title: pet%d\n", i)
+	}
+	resolver := setupMemoryResolver(t, files)
+	defer ClearCaches()
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithFileCache(gocontext.Background(), NewFileCache())
+			root := fmt.Sprintf("/specs/%d/root.yaml", i)
+			for j := 0; j < 10; j++ {
+				err := ResolveReferencesContext(ctx, root, func() error {
+					// The same reference names a different fragment in each compilation.
+					info := GetInfoCache()["pet.yaml#/Pet"]
+					if title := MapValueForKey(info, "title"); title == nil || title.Value != fmt.Sprintf("pet%d", i) {
+						return fmt.Errorf("compilation %d resolved the reference with %v", i, title)
+					}
+					return nil
+				})
+				if err != nil {
+					errs[i] = err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if len(resolver.requests) != len(files) {
+		t.Errorf("unexpected requests %v", resolver.requests)
+	}
+	for location, count := range resolver.requests {
+		if count != 1 {
+			t.Errorf("expected one request for %s, got %d", location, count)
+		}
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	var mutex sync.Mutex
+	title := "first"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fmt.Fprintf(w, "Pet:\n  title: %s\n", title)
+	}))
+	defer server.Close()
+	setTitle := func(value string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		title = value
+	}
+	location := server.URL + "/pet.yaml"
+	read := func(ctx gocontext.Context) string {
+		t.Helper()
+		bytes, err := ReadBytesForFileContext(ctx, location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(bytes)
+	}
+	ClearCaches()
+	defer ClearCaches()
+	ctx := gocontext.Background()
+	if s := read(ctx); s != "Pet:\n  title: first\n" {
+		t.Fatalf("unexpected response %q", s)
+	}
+	// Changed responses aren't seen until the cache is cleared.
+	setTitle("second")
+	if s := read(ctx); s != "Pet:\n  title: first\n" {
+		t.Errorf("expected the cached response, got %q", s)
+	}
+	// Single compilations can bypass the cache.
+	if s := read(WithoutCaching(ctx)); s != "Pet:\n  title: second\n" {
+		t.Errorf("expected the changed response without caching, got %q", s)
+	}
+	ClearCaches()
+	if s := read(ctx); s != "Pet:\n  title: second\n" {
+		t.Errorf("expected the changed response after ClearCaches, got %q", s)
+	}
+	// Without caching, every read sees the current response.
+	DisableCaching()
+	defer EnableCaching()
+	setTitle("third")
+	if s := read(ctx); s != "Pet:\n  title: third\n" {
+		t.Errorf("expected the changed response with caching disabled, got %q", s)
+	}
+	setTitle("fourth")
+	if s := read(ctx); s != "Pet:\n  title: fourth\n" {
+		t.Errorf("expected the changed response with caching disabled, got %q", s)
+	}
+	// Entries from before caching was disabled aren't used when it is turned on again.
+	EnableCaching()
+	if s := read(ctx); s != "Pet:\n  title: fourth\n" {
+		t.Errorf("expected the current response after enabling caching, got %q", s)
+	}
+}
+
+func TestClearCachesDuringCompilations(t *testing.T) {
+	setupMemoryResolver(t, map[string]string{
+		"/specs/root.yaml": "pet:\n  $ref: 'pet.yaml#/Pet'\n",
+		"/specs/pet.yaml":  "Pet:\n  type: object\n",
+	})
+	defer ClearCaches()
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if i%2 == 0 {
+					ClearCaches()
+				} else if err := ReadReferencedFiles("/specs/root.yaml"); err != nil {
+					errs[i] = err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
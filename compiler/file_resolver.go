@@ -0,0 +1,526 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"bufio"
+	"compress/gzip"
+	gocontext "context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// FileResolver reads the contents of documents. Locations are file names or URLs,
+// either the name of a root document or a file named by a $ref.
+type FileResolver interface {
+	Resolve(location string) ([]byte, error)
+}
+
+// ContextFileResolver is a FileResolver that stops reading when a Go context is done.
+// Functions that take a Go context, like ReadBytesForFileContext, pass it to resolvers
+// that implement this interface.
+type ContextFileResolver interface {
+	FileResolver
+	ResolveContext(ctx gocontext.Context, location string) ([]byte, error)
+}
+
+// DefaultFileResolver reads URLs with HTTP GET requests and other locations from the local filesystem.
+type DefaultFileResolver struct {
+	// Client sends the HTTP requests. If it is nil, a client that gives up after
+	// DefaultHTTPTimeout and MaxHTTPRedirects redirects is used.
+	Client *http.Client
+}
+
+const (
+	// DefaultHTTPTimeout limits the time to read a document with the default HTTP client.
+	DefaultHTTPTimeout = time.Minute
+	// MaxHTTPRedirects is the number of redirects that the default HTTP client follows.
+	MaxHTTPRedirects = 5
+)
+
+var defaultHTTPClient = &http.Client{
+	Timeout: DefaultHTTPTimeout,
+	CheckRedirect: func(request *http.Request, via []*http.Request) error {
+		if len(via) > MaxHTTPRedirects {
+			return fmt.Errorf("stopped after %d redirects", MaxHTTPRedirects)
+		}
+		return nil
+	},
+}
+
+// Resolve reads the document at a location.
+func (r DefaultFileResolver) Resolve(location string) ([]byte, error) {
+	return r.ResolveContext(gocontext.Background(), location)
+}
+
+// ResolveContext reads the document at a location. HTTP requests are canceled when ctx is done.
+// Responses with statuses other than 2xx are errors, and gzip-compressed responses and files are decompressed.
+func (r DefaultFileResolver) ResolveContext(ctx gocontext.Context, location string) ([]byte, error) {
+	if isURL(location) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+		client := r.Client
+		if client == nil {
+			client = defaultHTTPClient
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return nil, fmt.Errorf("Error downloading %s: %s", location, response.Status)
+		}
+		if mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type")); err == nil &&
+			(mediaType == "text/html" || mediaType == "application/xhtml+xml") {
+			log.Printf("Warning: %s returned %s, which is probably not an API description", location, mediaType)
+		}
+		// Transports decompress responses unless they are configured not to or the server
+		// compressed the document itself, as when serving .gz files.
+		return readDecompressed(location, response.Body)
+	}
+	file, err := os.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readDecompressed(location, file)
+}
+
+// readDecompressed reads a document like readLimited, first decompressing it if it begins
+// with the gzip magic bytes, whatever its name. The input size limit applies to the
+// decompressed document, so small files that decompress to huge documents are rejected
+// without reading all of them.
+func readDecompressed(location string, r io.Reader) ([]byte, error) {
+	body := bufio.NewReader(r)
+	if magic, err := body.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		unzipped, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("Error decompressing %s: %v", location, err)
+		}
+		defer unzipped.Close()
+		return readLimited(location, unzipped)
+	}
+	return readLimited(location, body)
+}
+
+// readLimited reads a document without reading more than the input size limit.
+func readLimited(location string, r io.Reader) ([]byte, error) {
+	l := CurrentLimits()
+	if l.MaxInputBytes > 0 {
+		r = io.LimitReader(r, l.MaxInputBytes+1)
+	}
+	bytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkInputSize(location, int64(len(bytes)), l); err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
+
+var fileResolver FileResolver = DefaultFileResolver{}
+var fileResolverMutex sync.Mutex
+
+// referencesMutex serializes the uses of the process-wide info cache of the OpenAPI models,
+// which their ResolveReferences methods read without synchronization with this package.
+var referencesMutex sync.Mutex
+
+// SetFileResolver sets the resolver that reads root documents and the files named by
+// references. A nil resolver restores the DefaultFileResolver. Because cached files
+// and references were read with the previous resolver, the process-wide caches are cleared.
+func SetFileResolver(resolver FileResolver) {
+	if resolver == nil {
+		resolver = DefaultFileResolver{}
+	}
+	fileResolverMutex.Lock()
+	fileResolver = resolver
+	fileResolverMutex.Unlock()
+	ClearCaches()
+}
+
+// FetchFile gets a specified file from the local filesystem or a remote location.
+func FetchFile(fileurl string) ([]byte, error) {
+	return ReadBytesForFileContext(gocontext.Background(), fileurl)
+}
+
+// FetchFileContext is like FetchFile, but it stops with ctx.Err() when ctx is done.
+func FetchFileContext(ctx gocontext.Context, fileurl string) ([]byte, error) {
+	return ReadBytesForFileContext(ctx, fileurl)
+}
+
+// ReadBytesForFile reads the bytes of a file with the file resolver.
+func ReadBytesForFile(filename string) ([]byte, error) {
+	return ReadBytesForFileContext(gocontext.Background(), filename)
+}
+
+// ReadBytesForFileContext is like ReadBytesForFile, but it stops with ctx.Err() when ctx is done
+// and uses the file cache of ctx; see WithFileCache.
+func ReadBytesForFileContext(ctx gocontext.Context, filename string) ([]byte, error) {
+	cache := fileCacheForContext(ctx)
+	if bytes, ok := cache.Bytes(filename); ok {
+		return bytes, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	fileResolverMutex.Lock()
+	resolver := fileResolver
+	fileResolverMutex.Unlock()
+	var bytes []byte
+	var err error
+	if contextResolver, ok := resolver.(ContextFileResolver); ok {
+		bytes, err = contextResolver.ResolveContext(ctx, filename)
+	} else {
+		bytes, err = resolver.Resolve(filename)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// Report cancellation rather than the error of an interrupted read.
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = checkInputSize(filename, int64(len(bytes)), CurrentLimits()); err != nil {
+		return nil, err
+	}
+	if isURL(filename) {
+		AddStat(ctx, StatRemoteFetches, 1)
+	}
+	cache.SetBytes(filename, bytes)
+	return bytes, nil
+}
+
+// isURL returns true if a location is a URL rather than a file name.
+func isURL(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && u.Scheme != ""
+}
+
+// ReadInfoForRef reads a file and return the fragment needed to resolve a $ref.
+// Relative references are resolved against basefile, which can be a file name or a URL.
+func ReadInfoForRef(basefile string, ref string) (*yaml.Node, error) {
+	return ReadInfoForRefContext(gocontext.Background(), basefile, ref)
+}
+
+// ReadInfoForRefContext is like ReadInfoForRef, but it stops with ctx.Err() when ctx is done
+// and uses the file cache of ctx; see WithFileCache.
+func ReadInfoForRefContext(ctx gocontext.Context, basefile string, ref string) (*yaml.Node, error) {
+	cache := fileCacheForContext(ctx)
+	if info, ok := cache.Info(ref); ok {
+		return info, nil
+	}
+	parts := strings.SplitN(ref, "#", 2)
+	filename := referenceLocation(basefile, parts[0])
+	bytes, err := ReadBytesForFileContext(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := ReadInfoFromBytesContext(ctx, filename, bytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) > 1 {
+		info = NodeForJSONPointer(info, parts[1])
+	} else if info.Kind == yaml.DocumentNode && len(info.Content) > 0 {
+		info = info.Content[0]
+	}
+	if info == nil {
+		return nil, NewError(nil, fmt.Sprintf("could not resolve %s", ref))
+	}
+	cache.SetInfo(ref, info)
+	return info, nil
+}
+
+// ReadReferencedFiles reads the fragments named by the references in a root document and,
+// transitively, in the fragments themselves, and saves them in the info cache. The
+// ResolveReferences methods of the OpenAPI models read references from the info cache, so
+// calling this first makes them use the file resolver for every file that they need.
+// References that can't be read are skipped and left for ResolveReferences to report.
+// References in cycles are saved as fragments that the models can't resolve, so that
+// they are left in place; see ResolveReferencesWithCycles.
+func ReadReferencedFiles(root string) error {
+	return ReadReferencedFilesContext(gocontext.Background(), root)
+}
+
+// ReadReferencedFilesContext is like ReadReferencedFiles, but it stops with ctx.Err() when ctx
+// is done and uses the file cache of ctx; see WithFileCache.
+func ReadReferencedFilesContext(ctx gocontext.Context, root string) error {
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+	_, err := readReferencedFiles(ctx, root, true)
+	return err
+}
+
+// ResolveReferences calls resolve, which should call the ResolveReferences method of the
+// model of a root document, after reading the files that the document references with
+// ReadReferencedFiles. Because the models resolve references with a cache that is shared
+// by the whole process, calls are serialized.
+func ResolveReferences(root string, resolve func() error) error {
+	return ResolveReferencesContext(gocontext.Background(), root, resolve)
+}
+
+// ResolveReferencesContext is like ResolveReferences, but it reads files with ReadReferencedFilesContext.
+func ResolveReferencesContext(ctx gocontext.Context, root string, resolve func() error) error {
+	_, err := ResolveReferencesWithCyclesContext(ctx, root, true, resolve)
+	return err
+}
+
+// ResolveReferencesWithCycles is like ResolveReferences, but it returns the cycles of
+// references that it leaves in place. The models of OpenAPI v3 only follow references to
+// fragments that are themselves references, so only cycles of these are found unless
+// nested is true. The models of OpenAPI v2 also follow the references in schemas and
+// path items that they resolve, so they need nested cycles to be found too.
+// If the info cache is disabled, the models can't be kept from following cycles, so
+// resolve isn't called and the cycles are returned as errors.
+func ResolveReferencesWithCycles(root string, nested bool, resolve func() error) ([]*ReferenceCycleError, error) {
+	return ResolveReferencesWithCyclesContext(gocontext.Background(), root, nested, resolve)
+}
+
+// ResolveReferencesWithCyclesContext is like ResolveReferencesWithCycles, but it reads files with the
+// file cache of ctx and stops with ctx.Err() when ctx is done.
+func ResolveReferencesWithCyclesContext(ctx gocontext.Context, root string, nested bool, resolve func() error) ([]*ReferenceCycleError, error) {
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+	cycles, err := readReferencedFiles(ctx, root, nested)
+	if err != nil {
+		return nil, err
+	}
+	if len(cycles) > 0 && !cacheEnabled(&infoCacheEnable) {
+		errors := make([]error, len(cycles))
+		for i, cycle := range cycles {
+			errors[i] = cycle
+		}
+		return cycles, NewErrorGroupOrNil(errors)
+	}
+	return cycles, resolve()
+}
+
+// readReferencedFiles reads the fragments named by the references in a root document and
+// copies them to the info cache of the models. It returns the cycles of the references,
+// which are copied as fragments that can't be resolved. The caller must hold referencesMutex.
+func readReferencedFiles(ctx gocontext.Context, root string, nested bool) ([]*ReferenceCycleError, error) {
+	bytes, err := ReadBytesForFileContext(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := ReadInfoFromBytesContext(ctx, root, bytes)
+	if err != nil {
+		return nil, err
+	}
+	publish := cacheEnabled(&infoCacheEnable)
+	modelsCache := GetInfoCache()
+	fragments := make(map[string]*yaml.Node)
+	refs := make([]string, 0)
+	pending := []*yaml.Node{info}
+	for len(pending) > 0 {
+		node := pending[0]
+		pending = pending[1:]
+		for _, ref := range findReferences(node, nil) {
+			if _, ok := fragments[ref]; ok {
+				continue
+			}
+			fragment, err := ReadInfoForRefContext(ctx, root, ref)
+			if err == nil {
+				// The models resolve all references from the root, including those in other documents.
+				fragment = rebaseReferences(fragment, referenceLocation(root, strings.SplitN(ref, "#", 2)[0]), root)
+			}
+			fragments[ref] = fragment
+			refs = append(refs, ref)
+			if err == nil {
+				if publish {
+					modelsCache[ref] = fragment
+				}
+				pending = append(pending, fragment)
+				continue
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			// Don't let the models resolve the reference with a fragment of another compilation.
+			delete(modelsCache, ref)
+		}
+	}
+	cycles := findReferenceCycles(refs, fragments, nested)
+	unresolvable := make(map[string]bool)
+	for _, cycle := range cycles {
+		for _, ref := range cycle.Cycle {
+			unresolvable[ref] = true
+			if publish {
+				modelsCache[ref] = unresolvableFragment(ref)
+			}
+		}
+	}
+	resolved := 0
+	for _, ref := range refs {
+		if fragments[ref] != nil && !unresolvable[ref] {
+			resolved++
+		}
+	}
+	AddStat(ctx, StatReferencesResolved, int64(resolved))
+	return cycles, nil
+}
+
+// ReferencedFiles returns the locations of the documents that a root document references,
+// directly or through the documents that it references, in the order that they are found.
+// Documents that can't be read are included, but their references aren't.
+func ReferencedFiles(root string) ([]string, error) {
+	return ReferencedFilesContext(gocontext.Background(), root)
+}
+
+// ReferencedFilesContext is like ReferencedFiles, but it stops with ctx.Err() when ctx is done
+// and uses the file cache of ctx; see WithFileCache.
+func ReferencedFilesContext(ctx gocontext.Context, root string) ([]string, error) {
+	found := map[string]bool{root: true}
+	files := make([]string, 0)
+	pending := []string{root}
+	for len(pending) > 0 {
+		location := pending[0]
+		pending = pending[1:]
+		bytes, err := ReadBytesForFileContext(ctx, location)
+		if err == nil {
+			var info *yaml.Node
+			info, err = ReadInfoFromBytesContext(ctx, location, bytes)
+			if err == nil {
+				for _, ref := range findReferences(info, nil) {
+					file := referenceLocation(location, strings.SplitN(ref, "#", 2)[0])
+					if !found[file] {
+						found[file] = true
+						files = append(files, file)
+						pending = append(pending, file)
+					}
+				}
+			}
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if location == root {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// referenceLocation returns the location of the document named by the file part of a reference
+// in the document at base. Relative references are resolved against the location of base, which
+// is either a URL or a file name; an empty reference names base itself.
+func referenceLocation(base string, ref string) string {
+	if ref == "" {
+		return base
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	if refURL.Scheme != "" {
+		return ref
+	}
+	if baseURL, err := url.Parse(base); err == nil && baseURL.Scheme != "" {
+		return baseURL.ResolveReference(refURL).String()
+	}
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	return filepath.Join(filepath.Dir(base), ref)
+}
+
+// relativeLocation returns a reference to the document at location from the document at root,
+// which is empty for root itself.
+func relativeLocation(root string, location string) string {
+	if location == root {
+		return ""
+	}
+	if u, err := url.Parse(location); err == nil && u.Scheme != "" {
+		return location
+	}
+	if u, err := url.Parse(root); err == nil && u.Scheme != "" {
+		return location
+	}
+	if filepath.IsAbs(location) != filepath.IsAbs(root) {
+		return location
+	}
+	rel, err := filepath.Rel(filepath.Dir(root), location)
+	if err != nil {
+		return location
+	}
+	return filepath.ToSlash(rel)
+}
+
+// rebaseReferences returns a fragment of the document at base with its references rewritten
+// as references from the document at root. The fragment is copied if any of them change.
+func rebaseReferences(fragment *yaml.Node, base string, root string) *yaml.Node {
+	if base == root || fragment == nil {
+		return fragment
+	}
+	rebased := *fragment
+	if len(fragment.Content) > 0 {
+		rebased.Content = make([]*yaml.Node, len(fragment.Content))
+	}
+	for i, child := range fragment.Content {
+		if fragment.Kind == yaml.MappingNode && i%2 == 1 && fragment.Content[i-1].Value == "$ref" && child.Kind == yaml.ScalarNode {
+			parts := strings.SplitN(child.Value, "#", 2)
+			value := relativeLocation(root, referenceLocation(base, parts[0]))
+			if len(parts) > 1 {
+				value += "#" + parts[1]
+			}
+			ref := *child
+			ref.Value = value
+			rebased.Content[i] = &ref
+		} else {
+			rebased.Content[i] = rebaseReferences(child, base, root)
+		}
+	}
+	return &rebased
+}
+
+// findReferences returns the values of all $ref entries in a node.
+func findReferences(node *yaml.Node, found []string) []string {
+	if node == nil {
+		return found
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "$ref" && value.Kind == yaml.ScalarNode {
+				found = append(found, value.Value)
+			} else {
+				found = findReferences(value, found)
+			}
+		}
+		return found
+	}
+	for _, child := range node.Content {
+		found = findReferences(child, found)
+	}
+	return found
+}
@@ -0,0 +1,392 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	gocontext "context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryResolver serves documents from a map and counts the requests for each location.
+type memoryResolver struct {
+	files    map[string]string
+	requests map[string]int
+	mutex    sync.Mutex
+}
+
+func (r *memoryResolver) Resolve(location string) ([]byte, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests[location]++
+	if file, ok := r.files[location]; ok {
+		return []byte(file), nil
+	}
+	return nil, fmt.Errorf("%s not found", location)
+}
+
+// setupMemoryResolver uses an in-memory resolver for the duration of a test.
+func setupMemoryResolver(t *testing.T, files map[string]string) *memoryResolver {
+	resolver := &memoryResolver{files: files, requests: make(map[string]int)}
+	SetFileResolver(resolver)
+	t.Cleanup(func() { SetFileResolver(nil) })
+	return resolver
+}
+
+func TestFileResolver(t *testing.T) {
+	resolver := setupMemoryResolver(t, map[string]string{
+		"/specs/root.yaml":               "a:\n  $ref: 'parts/a.yaml#/A'\n",
+		"/specs/parts/a.yaml":            "A:\n  b:\n    $ref: 'https://example.invalid/b.yaml#/B'\n",
+		"https://example.invalid/b.yaml": "B:\n  value: 1\n",
+	})
+	err := ReadReferencedFiles("/specs/root.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := ReadInfoForRef("/specs/root.yaml", "https://example.invalid/b.yaml#/B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := MapValueForKey(info, "value"); value == nil || value.Value != "1" {
+		t.Errorf("unexpected fragment for B: %v", info)
+	}
+	// Reading files again uses the cache instead of the resolver.
+	if _, err = ReadBytesForFile("/specs/parts/a.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	for location := range resolver.files {
+		if resolver.requests[location] != 1 {
+			t.Errorf("expected one request for %s, got %d", location, resolver.requests[location])
+		}
+	}
+	if len(resolver.requests) != len(resolver.files) {
+		t.Errorf("unexpected requests %v", resolver.requests)
+	}
+	// Without the file cache, every read uses the resolver.
+	DisableFileCache()
+	defer EnableFileCache()
+	if _, err = ReadBytesForFile("/specs/parts/a.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if resolver.requests["/specs/parts/a.yaml"] != 2 {
+		t.Errorf("expected the resolver to be called when the cache is disabled")
+	}
+}
+
+func TestFileResolverErrors(t *testing.T) {
+	setupMemoryResolver(t, map[string]string{
+		"/specs/root.yaml": "a:\n  $ref: 'missing.yaml#/A'\nb:\n  $ref: '#/c/d'\nc: {}\n",
+	})
+	// References that can't be read are left for ResolveReferences to report.
+	err := ReadReferencedFiles("/specs/root.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadInfoForRef("/specs/root.yaml", "missing.yaml#/A")
+	if err == nil || err.Error() != "/specs/missing.yaml not found" {
+		t.Errorf("unexpected error for missing.yaml: %v", err)
+	}
+	_, err = ReadInfoForRef("/specs/root.yaml", "#/c/d")
+	if err == nil || err.Error() != "could not resolve #/c/d" {
+		t.Errorf("unexpected error for #/c/d: %v", err)
+	}
+	// A root document that can't be read is an error.
+	if err = ReadReferencedFiles("/specs/missing.yaml"); err == nil {
+		t.Errorf("expected an error for a missing root document")
+	}
+}
+
+func TestReferencedFiles(t *testing.T) {
+	setupMemoryResolver(t, map[string]string{
+		"/specs/root.yaml":         "a:\n  $ref: 'common/a.yaml#/A'\nb:\n  $ref: 'missing.yaml#/B'\nc:\n  $ref: '#/a'\n",
+		"/specs/common/a.yaml":     "A:\n  $ref: '../b.yaml#/B'\nC:\n  $ref: 'c.yaml'\n",
+		"/specs/b.yaml":            "B:\n  $ref: 'root.yaml#/c'\n",
+		"/specs/common/c.yaml":     "type: object\n",
+		"/specs/unreferenced.yaml": "type: object\n",
+	})
+	files, err := ReferencedFiles("/specs/root.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/specs/common/a.yaml", "/specs/missing.yaml", "/specs/b.yaml", "/specs/common/c.yaml"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("ReferencedFiles() = %q, expected %q", files, expected)
+	}
+	if _, err = ReferencedFiles("/specs/missing.yaml"); err == nil {
+		t.Errorf("expected an error for a missing root document")
+	}
+}
+
+func TestReferenceLocations(t *testing.T) {
+	for _, test := range []struct {
+		base     string
+		ref      string
+		location string
+		relative string
+	}{
+		{"specs/root.yaml", "", "specs/root.yaml", ""},
+		{"specs/root.yaml", "a/a.yaml", "specs/a/a.yaml", "a/a.yaml"},
+		{"specs/a/a.yaml", "b/b.yaml", "specs/a/b/b.yaml", "a/b/b.yaml"},
+		{"specs/a/a.yaml", "../common.yaml", "specs/common.yaml", "common.yaml"},
+		{"specs/a/a.yaml", "../root.yaml", "specs/root.yaml", ""},
+		{"/specs/a/a.yaml", "/common/error.yaml", "/common/error.yaml", "../common/error.yaml"},
+		{"specs/a/a.yaml", "https://example.com/b.yaml", "https://example.com/b.yaml", "https://example.com/b.yaml"},
+		{"https://example.com/specs/a/a.yaml", "./b/b.yaml", "https://example.com/specs/a/b/b.yaml", "https://example.com/specs/a/b/b.yaml"},
+		{"https://example.com/specs/a/a.yaml", "../common.yaml", "https://example.com/specs/common.yaml", "https://example.com/specs/common.yaml"},
+		{"https://example.com/specs/a/a.yaml", "/common.yaml", "https://example.com/common.yaml", "https://example.com/common.yaml"},
+	} {
+		root := "specs/root.yaml"
+		if filepath.IsAbs(test.base) {
+			root = "/specs/root.yaml"
+		}
+		if location := referenceLocation(test.base, test.ref); location != test.location {
+			t.Errorf("referenceLocation(%q, %q) = %q, expected %q", test.base, test.ref, location, test.location)
+		}
+		if relative := relativeLocation(root, test.location); relative != test.relative {
+			t.Errorf("relativeLocation(%q, %q) = %q, expected %q", root, test.location, relative, test.relative)
+		}
+	}
+}
+
+// nestedReferencesTree is a tree of documents with references that are relative to the documents that contain them.
+var nestedReferencesTree = map[string]string{
+	"specs/root.yaml":     "a:\n  $ref: 'a/a.yaml#/A'\n",
+	"specs/a/a.yaml":      "A:\n  b:\n    $ref: 'b/b.yaml#/B'\n  local:\n    $ref: '#/Local'\nLocal:\n  value: local\n",
+	"specs/a/b/b.yaml":    "B:\n  value: b\n  common:\n    $ref: '../../common.yaml#/Common'\n",
+	"specs/common.yaml":   "Common:\n  value: common\n",
+	"specs/a/common.yaml": "Common:\n  value: wrong\n",
+}
+
+// checkNestedReferences checks that the references of nestedReferencesTree were read from
+// the right documents and rewritten as references from the root.
+func checkNestedReferences(t *testing.T, ctx gocontext.Context, root string, prefix string) {
+	if err := ReadReferencedFilesContext(ctx, root); err != nil {
+		t.Fatal(err)
+	}
+	cache := GetInfoCache()
+	for _, test := range []struct {
+		ref   string
+		value string
+	}{
+		{"a/a.yaml#/A", ""},
+		{prefix + "a/b/b.yaml#/B", "b"},
+		{prefix + "a/a.yaml#/Local", "local"},
+		{prefix + "common.yaml#/Common", "common"},
+	} {
+		info := cache[test.ref]
+		if info == nil {
+			t.Errorf("expected %s in the info cache, got %v", test.ref, cache)
+			continue
+		}
+		if test.value != "" {
+			if value := MapValueForKey(info, "value"); value == nil || value.Value != test.value {
+				t.Errorf("unexpected fragment for %s", test.ref)
+			}
+		}
+	}
+	a := cache["a/a.yaml#/A"]
+	if ref := MapValueForKey(MapValueForKey(a, "b"), "$ref"); ref == nil || ref.Value != prefix+"a/b/b.yaml#/B" {
+		t.Errorf("expected the reference to B to be rewritten, got %v", ref)
+	}
+}
+
+func TestNestedRelativeReferences(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range nestedReferencesTree {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Run("files", func(t *testing.T) {
+		ctx := WithFileCache(gocontext.Background(), NewFileCache())
+		checkNestedReferences(t, ctx, filepath.Join(dir, "specs", "root.yaml"), "")
+	})
+	t.Run("urls", func(t *testing.T) {
+		server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+		defer server.Close()
+		ctx := WithFileCache(gocontext.Background(), NewFileCache())
+		checkNestedReferences(t, ctx, server.URL+"/specs/root.yaml", server.URL+"/specs/")
+	})
+}
+
+func TestDefaultFileResolverHTTP(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte("title: compressed\n"))
+	zw.Close()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("title: ok\n"))
+	})
+	mux.HandleFunc("/accepted.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		w.Write([]byte("title: accepted\n"))
+	})
+	mux.HandleFunc("/missing.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<html><body>Not here</body></html>"))
+	})
+	mux.HandleFunc("/page.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body>Sign in</body></html>"))
+	})
+	mux.HandleFunc("/encoded.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	})
+	mux.HandleFunc("/spec.yaml.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(compressed.Bytes())
+	})
+	mux.HandleFunc("/redirect/", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+		if n == 0 {
+			http.Redirect(w, r, "/ok.yaml", http.StatusFound)
+		} else {
+			http.Redirect(w, r, fmt.Sprintf("/redirect/%d", n-1), http.StatusFound)
+		}
+	})
+	mux.HandleFunc("/slow.yaml", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	for _, test := range []struct {
+		name     string
+		resolver DefaultFileResolver
+		path     string
+		document string
+		err      string
+		warning  string
+	}{
+		{name: "ok", path: "/ok.yaml", document: "title: ok\n"},
+		{name: "2xx", path: "/accepted.yaml", document: "title: accepted\n"},
+		{name: "404", path: "/missing.yaml", err: "404 Not Found"},
+		{name: "html", path: "/page.yaml", document: "<html><body>Sign in</body></html>", warning: "returned text/html"},
+		{name: "content encoding", path: "/encoded.yaml", document: "title: compressed\n"},
+		{
+			name:     "content encoding without transport decompression",
+			resolver: DefaultFileResolver{Client: &http.Client{Transport: &http.Transport{DisableCompression: true}}},
+			path:     "/encoded.yaml",
+			document: "title: compressed\n",
+		},
+		{name: "gzip file", path: "/spec.yaml.gz", document: "title: compressed\n"},
+		{name: "redirects", path: fmt.Sprintf("/redirect/%d", MaxHTTPRedirects-1), document: "title: ok\n"},
+		{name: "too many redirects", path: fmt.Sprintf("/redirect/%d", MaxHTTPRedirects), err: fmt.Sprintf("stopped after %d redirects", MaxHTTPRedirects)},
+		{
+			name:     "timeout",
+			resolver: DefaultFileResolver{Client: &http.Client{Timeout: 50 * time.Millisecond}},
+			path:     "/slow.yaml",
+			err:      "Client.Timeout exceeded",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			logged.Reset()
+			b, err := test.resolver.Resolve(server.URL + test.path)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != test.document {
+				t.Errorf("unexpected document %q (expected %q)", b, test.document)
+			}
+			if !strings.Contains(logged.String(), test.warning) || (test.warning == "" && logged.Len() > 0) {
+				t.Errorf("unexpected warnings %q (expected %q)", logged.String(), test.warning)
+			}
+		})
+	}
+	if defaultHTTPClient.Timeout != DefaultHTTPTimeout {
+		t.Errorf("expected the default client to time out after %s", DefaultHTTPTimeout)
+	}
+}
+
+// writeGzipFile writes a gzip-compressed file for a test.
+func writeGzipFile(t *testing.T, filename string, contents string) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(contents))
+	zw.Close()
+	if err := os.WriteFile(filename, compressed.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultFileResolverGzipFiles(t *testing.T) {
+	ClearCaches()
+	t.Cleanup(ClearCaches)
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root.yaml.gz")
+	writeGzipFile(t, root, "a:\n  $ref: 'parts/a.yaml#/A'\n")
+	if err := os.Mkdir(filepath.Join(dir, "parts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Compressed files are detected by their contents, not their names.
+	writeGzipFile(t, filepath.Join(dir, "parts", "a.yaml"), "A:\n  value: 1\n")
+	if err := ReadReferencedFiles(root); err != nil {
+		t.Fatal(err)
+	}
+	info, err := ReadInfoForRef(root, "parts/a.yaml#/A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := MapValueForKey(info, "value"); value == nil || value.Value != "1" {
+		t.Errorf("unexpected fragment for A: %v", info)
+	}
+	// The size limit applies to the decompressed document.
+	setupLimits(t, Limits{MaxInputBytes: 1000})
+	large := filepath.Join(dir, "large.yaml.gz")
+	writeGzipFile(t, large, "title: "+strings.Repeat("a", 10000)+"\n")
+	if _, err := (DefaultFileResolver{}).Resolve(large); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected a limit error for a large compressed document, got %v", err)
+	}
+	corrupt := filepath.Join(dir, "corrupt.yaml")
+	if err := os.WriteFile(corrupt, []byte{0x1f, 0x8b, 0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (DefaultFileResolver{}).Resolve(corrupt); err == nil {
+		t.Errorf("expected an error for a corrupt compressed document")
+	}
+}
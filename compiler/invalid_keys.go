@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// InvalidKeyError reports a mapping key that is neither a field of an object nor matches
+// one of the patterns that the object allows, like the pattern of x- extensions.
+// It is located at the key. Typos in field names are usually reported with this error.
+type InvalidKeyError struct {
+	// Context is the context of the object.
+	Context *Context
+	// Key is the invalid key.
+	Key *yaml.Node
+}
+
+// Message describes an InvalidKeyError without its location.
+func (err *InvalidKeyError) Message() string {
+	return fmt.Sprintf("has invalid property: %s", err.Key.Value)
+}
+
+// Error returns the string value of an InvalidKeyError.
+func (err *InvalidKeyError) Error() string {
+	message := err.Message()
+	if err.Context == nil {
+		return NewError(nil, message).Error()
+	}
+	// The error is described with the path of the object and the location of the key.
+	context := &Context{Name: err.Context.Name, Parent: err.Context.Parent, Node: err.Key, ExtensionHandlers: err.Context.ExtensionHandlers}
+	return NewError(context, message).Error()
+}
+
+// InvalidKeyErrors returns an InvalidKeyError for each key in a map that doesn't match a list of allowed keys and patterns.
+func InvalidKeyErrors(context *Context, m *yaml.Node, allowedKeys []string, allowedPatterns []*regexp.Regexp) []error {
+	invalidKeys := InvalidKeysInMap(m, allowedKeys, allowedPatterns)
+	if len(invalidKeys) == 0 {
+		return nil
+	}
+	invalid := make(map[string]bool)
+	for _, key := range invalidKeys {
+		invalid[key] = true
+	}
+	errors := make([]error, 0, len(invalidKeys))
+	for i := 0; i < len(m.Content); i += 2 {
+		if key := m.Content[i]; invalid[key.Value] {
+			errors = append(errors, &InvalidKeyError{Context: context, Key: key})
+		}
+	}
+	return errors
+}
+
+// SplitInvalidKeyErrors separates the InvalidKeyErrors in an error returned by the compiler
+// from its other errors, which are returned in an ErrorGroup, or nil if there are none.
+// Callers can use it to report invalid keys as warnings.
+func SplitInvalidKeyErrors(err error) ([]*InvalidKeyError, error) {
+	invalidKeys := make([]*InvalidKeyError, 0)
+	others := make([]error, 0)
+	var split func(err error)
+	split = func(err error) {
+		switch err := err.(type) {
+		case nil:
+		case *InvalidKeyError:
+			invalidKeys = append(invalidKeys, err)
+		case *ErrorGroup:
+			for _, err := range err.Errors {
+				split(err)
+			}
+		default:
+			others = append(others, err)
+		}
+	}
+	split(err)
+	return invalidKeys, NewErrorGroupOrNil(others)
+}
+
+// IsBetterPartialMatch returns true if err, an error of one of the possibilities of a oneof,
+// only reports invalid keys, and fewer of them than previous, the error of the best partial
+// match so far, if there is one. When no possibility matches, the compiler reports the
+// invalid keys of the best partial match instead of failing to match.
+func IsBetterPartialMatch(err error, previous error) bool {
+	invalidKeys, others := SplitInvalidKeyErrors(err)
+	if others != nil || len(invalidKeys) == 0 {
+		return false
+	}
+	if previous == nil {
+		return true
+	}
+	previousInvalidKeys, _ := SplitInvalidKeyErrors(previous)
+	return len(invalidKeys) < len(previousInvalidKeys)
+}
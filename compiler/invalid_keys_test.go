@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"regexp"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestInvalidKeyErrors(t *testing.T) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("info:\n  title: a\n  titel: b\n  x-audit: c\n  versoin: d\n"), &node); err != nil {
+		t.Fatal(err)
+	}
+	root := NewContext("$root", node.Content[0], nil)
+	info := MapValueForKey(node.Content[0], "info")
+	context := NewContext("info", info, root)
+	invalidKeys := InvalidKeyErrors(context, info, []string{"title", "version"}, []*regexp.Regexp{regexp.MustCompile("^x-")})
+	expected := []string{
+		"[3,3] $root.info has invalid property: titel",
+		"[5,3] $root.info has invalid property: versoin",
+	}
+	if len(invalidKeys) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), invalidKeys)
+	}
+	for i, err := range invalidKeys {
+		if err.Error() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], err)
+		}
+	}
+	// Invalid keys are separated from other errors, even in nested groups.
+	other := NewError(context, "is missing required property: version")
+	err := NewErrorGroupOrNil([]error{invalidKeys[0], NewErrorGroupOrNil([]error{other, invalidKeys[1]})})
+	keys, others := SplitInvalidKeyErrors(err)
+	if len(keys) != 2 || others != other {
+		t.Errorf("unexpected split %v %v", keys, others)
+	}
+	if !IsBetterPartialMatch(invalidKeys[0], nil) || !IsBetterPartialMatch(invalidKeys[0], NewErrorGroupOrNil(invalidKeys)) {
+		t.Errorf("expected a better partial match")
+	}
+	if IsBetterPartialMatch(err, nil) || IsBetterPartialMatch(NewErrorGroupOrNil(invalidKeys), invalidKeys[0]) {
+		t.Errorf("unexpected better partial match")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	jsonPointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// EscapeJSONPointerToken escapes a key for use as a reference token of a JSON pointer (RFC 6901),
+// so that /pets/{id} becomes ~1pets~1{id}.
+func EscapeJSONPointerToken(key string) string {
+	return jsonPointerEscaper.Replace(key)
+}
+
+// UnescapeJSONPointerToken returns the key named by a reference token of a JSON pointer.
+func UnescapeJSONPointerToken(token string) string {
+	return jsonPointerUnescaper.Replace(token)
+}
+
+// JSONPointer returns the JSON pointer of a path of keys, like /paths/~1pets/get.
+// The pointer of an empty path, which names the whole document, is empty.
+func JSONPointer(keys []string) string {
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString("/")
+		b.WriteString(EscapeJSONPointerToken(key))
+	}
+	return b.String()
+}
+
+// SplitJSONPointer returns the path of keys named by a JSON pointer.
+func SplitJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = UnescapeJSONPointerToken(token)
+	}
+	return tokens, nil
+}
+
+// NodeForJSONPointer returns the node named by a JSON pointer in a document, or nil if there is none.
+// Keys of sequences are indexes.
+func NodeForJSONPointer(node *yaml.Node, pointer string) *yaml.Node {
+	keys, err := SplitJSONPointer(pointer)
+	if err != nil {
+		return nil
+	}
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, key := range keys {
+		if node == nil {
+			return nil
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = MapValueForKey(node, key)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node.Content) || strconv.Itoa(i) != key {
+				return nil
+			}
+			node = node.Content[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
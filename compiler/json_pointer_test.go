@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"reflect"
+	"testing"
+)
+
+func TestJSONPointerTokens(t *testing.T) {
+	for _, test := range []struct {
+		key   string
+		token string
+	}{
+		{"pets", "pets"},
+		{"/pets/{id}", "~1pets~1{id}"},
+		{"~user", "~0user"},
+		{"a~/b", "a~0~1b"},
+		{"~1", "~01"},
+		{"~0", "~00"},
+		{"", ""},
+	} {
+		if token := EscapeJSONPointerToken(test.key); token != test.token {
+			t.Errorf("EscapeJSONPointerToken(%q) = %q, expected %q", test.key, token, test.token)
+		}
+		if key := UnescapeJSONPointerToken(test.token); key != test.key {
+			t.Errorf("UnescapeJSONPointerToken(%q) = %q, expected %q", test.token, key, test.key)
+		}
+	}
+}
+
+func TestJSONPointers(t *testing.T) {
+	for _, test := range []struct {
+		pointer string
+		keys    []string
+	}{
+		{"", nil},
+		{"/", []string{""}},
+		{"/paths/~1pets~1{id}/get", []string{"paths", "/pets/{id}", "get"}},
+		{"/paths/~1users~1~0{name}", []string{"paths", "/users/~{name}"}},
+		{"/a~01b", []string{"a~1b"}},
+	} {
+		keys, err := SplitJSONPointer(test.pointer)
+		if err != nil {
+			t.Errorf("SplitJSONPointer(%q) failed: %v", test.pointer, err)
+		} else if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("SplitJSONPointer(%q) = %q, expected %q", test.pointer, keys, test.keys)
+		}
+		if pointer := JSONPointer(test.keys); pointer != test.pointer {
+			t.Errorf("JSONPointer(%q) = %q, expected %q", test.keys, pointer, test.pointer)
+		}
+	}
+	if _, err := SplitJSONPointer("paths"); err == nil {
+		t.Errorf("expected an error for a pointer without a leading slash")
+	}
+}
+
+const pointerTestDocument = `
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+  /users/~{name}:
+    get:
+      operationId: getUser
+      parameters:
+        - name: first
+        - name: second
+  /a~1b:
+    get:
+      operationId: getTilde
+`
+
+func TestReadInfoForRefWithEscapes(t *testing.T) {
+	setupMemoryResolver(t, map[string]string{"/specs/root.yaml": pointerTestDocument})
+	ctx := WithFileCache(gocontext.Background(), NewFileCache())
+	for _, test := range []struct {
+		ref       string
+		key       string
+		value     string
+		unresolve bool
+	}{
+		{ref: "#/paths/~1pets~1{id}/get", key: "operationId", value: "getPet"},
+		{ref: "#/paths/~1users~1~0{name}/get", key: "operationId", value: "getUser"},
+		{ref: "#/paths/~1users~1~0{name}/get/parameters/1", key: "name", value: "second"},
+		{ref: "#/paths/~1a~01b/get", key: "operationId", value: "getTilde"},
+		{ref: "#/paths/~1a~1b/get", unresolve: true},
+		{ref: "#/paths/~1users~1~0{name}/get/parameters/01", unresolve: true},
+		{ref: "#/paths/~1pets~1{id}/get/parameters/0", unresolve: true},
+	} {
+		info, err := ReadInfoForRefContext(ctx, "/specs/root.yaml", test.ref)
+		if test.unresolve {
+			if err == nil {
+				t.Errorf("expected %s not to resolve", test.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.ref, err)
+			continue
+		}
+		if value := MapValueForKey(info, test.key); value == nil || value.Value != test.value {
+			t.Errorf("%s: expected %s %q", test.ref, test.key, test.value)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// DefaultMaxInputBytes is the default limit on the size of a document.
+	DefaultMaxInputBytes = 100 << 20
+	// DefaultMaxDepth is the default limit on the nesting depth of a document.
+	DefaultMaxDepth = 1000
+	// DefaultMaxAliasExpansions is the default limit on the number of aliases in an expanded document.
+	DefaultMaxAliasExpansions = 100000
+)
+
+// ErrLimitExceeded is wrapped by the errors for documents that exceed the compiler's limits.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limits bounds the documents that the compiler reads, so that pathological inputs are
+// rejected before they exhaust memory or the stack. A zero field selects the default
+// limit and a negative field disables the limit.
+type Limits struct {
+	// MaxInputBytes is the maximum size of a document in bytes.
+	MaxInputBytes int64
+	// MaxDepth is the maximum nesting depth of the collections in a document,
+	// including the collections that aliases refer to.
+	MaxDepth int
+	// MaxAliasExpansions is the maximum number of aliases in a document
+	// when the aliases in the values that they refer to are expanded.
+	MaxAliasExpansions int
+}
+
+var limits = Limits{
+	MaxInputBytes:      DefaultMaxInputBytes,
+	MaxDepth:           DefaultMaxDepth,
+	MaxAliasExpansions: DefaultMaxAliasExpansions,
+}
+var limitsMutex sync.Mutex
+
+// SetLimits sets the limits that ReadBytesForFile, ReadInfoFromBytes and ReadInfoFromReader enforce.
+func SetLimits(l Limits) {
+	if l.MaxInputBytes == 0 {
+		l.MaxInputBytes = DefaultMaxInputBytes
+	}
+	if l.MaxDepth == 0 {
+		l.MaxDepth = DefaultMaxDepth
+	}
+	if l.MaxAliasExpansions == 0 {
+		l.MaxAliasExpansions = DefaultMaxAliasExpansions
+	}
+	limitsMutex.Lock()
+	defer limitsMutex.Unlock()
+	limits = l
+}
+
+// CurrentLimits returns the limits that are enforced, with defaults for the limits that weren't set.
+func CurrentLimits() Limits {
+	limitsMutex.Lock()
+	defer limitsMutex.Unlock()
+	return limits
+}
+
+// checkInputSize returns an error if a document is larger than the limit.
+func checkInputSize(filename string, size int64, l Limits) error {
+	if l.MaxInputBytes > 0 && size > l.MaxInputBytes {
+		return fmt.Errorf("%sdocument exceeds the maximum size of %d bytes: %w", filenamePrefix(filename), l.MaxInputBytes, ErrLimitExceeded)
+	}
+	return nil
+}
+
+func depthError(filename string, l Limits) error {
+	return fmt.Errorf("%sdocument exceeds the maximum nesting depth of %d: %w", filenamePrefix(filename), l.MaxDepth, ErrLimitExceeded)
+}
+
+func filenamePrefix(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	return filename + ": "
+}
+
+// checkNodeLimits returns an error if a parsed document is nested too deeply, counting the
+// values that aliases refer to, or if it expands to too many aliases.
+func checkNodeLimits(filename string, node *yaml.Node, l Limits) error {
+	measured := make(map[*yaml.Node]*nodeMeasure)
+	measure := measureNode(node, measured, l)
+	if l.MaxDepth > 0 && measure.depth > l.MaxDepth {
+		return depthError(filename, l)
+	}
+	if l.MaxAliasExpansions > 0 && measure.aliases > l.MaxAliasExpansions {
+		return fmt.Errorf("%sdocument exceeds the maximum of %d alias expansions: %w", filenamePrefix(filename), l.MaxAliasExpansions, ErrLimitExceeded)
+	}
+	return nil
+}
+
+// nodeMeasure is the depth of a node and the number of aliases in it when aliases are expanded.
+type nodeMeasure struct {
+	depth   int
+	aliases int
+}
+
+// measureNode measures a node, saving the measures of its descendants so that each node is
+// measured once however many aliases refer to it. Measures are capped just above the limits.
+func measureNode(node *yaml.Node, measured map[*yaml.Node]*nodeMeasure, l Limits) *nodeMeasure {
+	if node == nil {
+		return &nodeMeasure{}
+	}
+	if m, ok := measured[node]; ok {
+		return m
+	}
+	m := &nodeMeasure{}
+	// Recursive aliases measure the node in progress as empty.
+	measured[node] = m
+	for _, child := range node.Content {
+		c := measureNode(child, measured, l)
+		if c.depth > m.depth {
+			m.depth = c.depth
+		}
+		m.aliases = capMeasure(m.aliases+c.aliases, l.MaxAliasExpansions)
+		if l.MaxDepth > 0 && m.depth > l.MaxDepth {
+			break
+		}
+	}
+	switch node.Kind {
+	case yaml.AliasNode:
+		target := measureNode(node.Alias, measured, l)
+		m.depth = target.depth
+		m.aliases = capMeasure(1+target.aliases, l.MaxAliasExpansions)
+	case yaml.MappingNode, yaml.SequenceNode:
+		m.depth++
+	}
+	return m
+}
+
+func capMeasure(value int, limit int) int {
+	if limit > 0 && value > limit {
+		return limit + 1
+	}
+	return value
+}
+
+// estimateDepth scans a YAML document without parsing it and estimates its nesting depth.
+// The scan stops when the estimate exceeds limit.
+func estimateDepth(bytes []byte, limit int) int {
+	e := newDepthEstimator(limit)
+	e.write(bytes)
+	e.close()
+	return e.maxDepth
+}
+
+// depthEstimator estimates the nesting depth of a YAML document as it is read, by counting
+// indentation levels, block collection indicators, and open flow collections. The estimate
+// only needs to be close enough to reject documents that would exhaust the stack; parsed
+// documents are measured exactly by checkNodeLimits. Documents are scanned a byte at a time,
+// so that long lines, like those of minified JSON, are checked before they are parsed.
+type depthEstimator struct {
+	limit       int
+	indents     []int // the columns of the open block levels
+	flowDepth   int   // the number of open flow collections
+	blockScalar int   // the indentation of the line that started a block scalar, if any
+	maxDepth    int
+
+	// The state of the current line.
+	phase      linePhase
+	column     int
+	indent     int
+	indicator  byte // a "-", "?" or ":" that is a block indicator if a space follows
+	valueStart bool // outside of flow collections, brackets and quotes are only indicators at the start of a value
+	colon      bool // the previous byte was a colon that ends a key if a space follows
+	quote      byte
+	escaped    bool
+	last       byte
+	tail       []byte // the end of the line with runs of spaces collapsed, for isBlockScalarHeader
+}
+
+type linePhase int
+
+const (
+	phaseIndent          linePhase = iota // reading the indentation of a line
+	phaseCompact                          // reading a value that may start with a block indicator
+	phaseIndicator                        // reading the byte after a possible block indicator
+	phaseIndicatorSpaces                  // reading the spaces after a block indicator
+	phaseContent                          // reading the rest of a line
+	phaseComment                          // reading a comment at the end of a line
+	phaseSkip                             // skipping a comment line or a line of a block scalar
+)
+
+// tailSize bounds the end of a line that is kept to recognize block scalar headers.
+const tailSize = 16
+
+func newDepthEstimator(limit int) *depthEstimator {
+	return &depthEstimator{limit: limit, blockScalar: -1, tail: make([]byte, 0, tailSize)}
+}
+
+// exceeded returns true if the estimate exceeds the limit.
+func (e *depthEstimator) exceeded() bool {
+	return e.maxDepth > e.limit
+}
+
+// write scans the next bytes of a document. Once the limit is exceeded, the rest is ignored.
+func (e *depthEstimator) write(bytes []byte) {
+	for _, c := range bytes {
+		if e.exceeded() {
+			return
+		}
+		if c == '\n' {
+			e.endLine()
+			continue
+		}
+		e.appendTail(c)
+		e.scan(c)
+	}
+}
+
+// close finishes the scan of a document that doesn't end with a newline.
+func (e *depthEstimator) close() {
+	if !e.exceeded() {
+		e.endLine()
+	}
+}
+
+func (e *depthEstimator) scan(c byte) {
+	switch e.phase {
+	case phaseIndent:
+		if c == ' ' {
+			e.column++
+			return
+		}
+		e.startLine(c)
+	case phaseCompact:
+		// Compact collections like "- - x" open a level for each indicator.
+		if c == '-' || c == '?' || c == ':' {
+			e.indicator = c
+			e.phase = phaseIndicator
+			return
+		}
+		e.phase = phaseContent
+		e.content(c)
+	case phaseIndicator:
+		if c == ' ' {
+			e.column += 2
+			e.phase = phaseIndicatorSpaces
+			return
+		}
+		e.phase = phaseContent
+		e.content(e.indicator)
+		e.content(c)
+	case phaseIndicatorSpaces:
+		if c == ' ' {
+			e.column++
+			return
+		}
+		e.push()
+		e.phase = phaseCompact
+		e.scan(c)
+	case phaseContent:
+		e.content(c)
+	}
+}
+
+// startLine starts scanning a line at its first byte that isn't a space.
+func (e *depthEstimator) startLine(c byte) {
+	if c == '#' {
+		e.phase = phaseSkip
+		return
+	}
+	if e.blockScalar >= 0 {
+		if e.column > e.blockScalar {
+			e.phase = phaseSkip
+			return
+		}
+		e.blockScalar = -1
+	}
+	e.indent = e.column
+	e.valueStart = true
+	e.last = ' '
+	if e.flowDepth > 0 {
+		e.phase = phaseContent
+		e.content(c)
+		return
+	}
+	for len(e.indents) > 0 && e.indents[len(e.indents)-1] >= e.column {
+		e.indents = e.indents[:len(e.indents)-1]
+	}
+	e.push()
+	e.phase = phaseCompact
+	e.scan(c)
+}
+
+// push opens a block level at the current column.
+func (e *depthEstimator) push() {
+	e.indents = append(e.indents, e.column)
+	e.measure()
+}
+
+func (e *depthEstimator) measure() {
+	if depth := len(e.indents) + e.flowDepth; depth > e.maxDepth {
+		e.maxDepth = depth
+	}
+}
+
+// content scans a byte of the value of a line.
+func (e *depthEstimator) content(c byte) {
+	if e.escaped {
+		e.escaped = false
+		return
+	}
+	if e.colon {
+		e.colon = false
+		e.valueStart = c == ' '
+	}
+	switch {
+	case e.quote != 0:
+		if c == '\\' && e.quote == '"' {
+			e.escaped = true
+		} else if c == e.quote {
+			e.quote = 0
+		}
+	case c == '#' && (e.last == ' ' || e.last == '\t'):
+		e.phase = phaseComment
+	case (c == '"' || c == '\'') && (e.valueStart || (e.flowDepth > 0 && strings.IndexByte(" [{,:", e.last) >= 0)):
+		e.quote = c
+	case (c == '[' || c == '{') && (e.valueStart || e.flowDepth > 0):
+		e.flowDepth++
+	case (c == ']' || c == '}') && e.flowDepth > 0:
+		e.flowDepth--
+	}
+	if c == ':' && e.flowDepth == 0 && e.quote == 0 {
+		e.colon = true
+		e.valueStart = false
+	} else if c != ' ' {
+		e.valueStart = false
+	}
+	e.measure()
+	e.last = c
+}
+
+// endLine finishes scanning a line.
+func (e *depthEstimator) endLine() {
+	switch e.phase {
+	case phaseIndicator:
+		e.content(e.indicator)
+	case phaseIndicatorSpaces:
+		e.push()
+	}
+	if e.phase != phaseIndent && e.phase != phaseSkip && e.flowDepth == 0 && isBlockScalarHeader(e.tail) {
+		e.blockScalar = e.indent
+	}
+	e.phase = phaseIndent
+	e.column = 0
+	e.indicator = 0
+	e.colon = false
+	e.quote = 0
+	e.escaped = false
+	e.tail = e.tail[:0]
+}
+
+// appendTail adds a byte to the end of the current line. When the tail is full, its
+// first byte is replaced with one that can't precede a block scalar header.
+func (e *depthEstimator) appendTail(c byte) {
+	if c == ' ' || c == '\r' {
+		if n := len(e.tail); n > 0 && e.tail[n-1] == ' ' {
+			return
+		}
+		c = ' '
+	}
+	if len(e.tail) == tailSize {
+		copy(e.tail, e.tail[1:])
+		e.tail = e.tail[:tailSize-1]
+		e.tail[0] = 'x'
+	}
+	e.tail = append(e.tail, c)
+}
+
+// isBlockScalarHeader returns true if a line ends with the header of a literal or folded block scalar.
+func isBlockScalarHeader(line []byte) bool {
+	end := len(line)
+	for end > 0 && (line[end-1] == ' ' || line[end-1] == '\r') {
+		end--
+	}
+	i := end
+	for i > 0 && (line[i-1] == '+' || line[i-1] == '-' || (line[i-1] >= '1' && line[i-1] <= '9')) {
+		i--
+	}
+	return i > 0 && (line[i-1] == '|' || line[i-1] == '>') && (i == 1 || line[i-2] == ' ')
+}
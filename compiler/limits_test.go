@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// setupLimits sets limits for the duration of a test.
+func setupLimits(t *testing.T, l Limits) {
+	previous := CurrentLimits()
+	SetLimits(l)
+	t.Cleanup(func() { SetLimits(previous) })
+}
+
+// nestedMappings returns a document with mappings nested to a depth in block style.
+func nestedMappings(depth int) string {
+	var b strings.Builder
+	for i := 0; i < depth; i++ {
+		b.WriteString(strings.Repeat(" ", i) + "a:\n")
+	}
+	return b.String()
+}
+
+// aliasBomb returns a document in which each anchored sequence has ten aliases of the previous one.
+func aliasBomb(levels int) string {
+	var b strings.Builder
+	b.WriteString("a0: &a0 [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 1; i <= levels; i++ {
+		aliases := strings.TrimSuffix(strings.Repeat(fmt.Sprintf("*a%d, ", i-1), 10), ", ")
+		fmt.Fprintf(&b, "a%d: &a%d [%s]\n", i, i, aliases)
+	}
+	return b.String()
+}
+
+func checkLimitError(t *testing.T, name string, err error, expected string) {
+	t.Helper()
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("%s: expected a limit error, got %v", name, err)
+	} else if !strings.Contains(err.Error(), expected) {
+		t.Errorf("%s: expected %q in error %q", name, expected, err)
+	}
+}
+
+func TestPathologicalDocuments(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		document string
+		expected string
+	}{
+		{"flow sequences", strings.Repeat("[", 10000000), "maximum nesting depth of 1000"},
+		{"flow mappings", strings.Repeat("{a: ", 100000), "maximum nesting depth of 1000"},
+		{"block sequences", strings.Repeat("- ", 100000) + "x\n", "maximum nesting depth of 1000"},
+		{"block mappings", nestedMappings(1500), "maximum nesting depth of 1000"},
+		{"aliases", aliasBomb(9), "maximum of 100000 alias expansions"},
+	} {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		info, err := ReadInfoFromBytes("", []byte(test.document))
+		runtime.ReadMemStats(&after)
+		if info != nil {
+			t.Errorf("%s: expected the document to be rejected", test.name)
+		}
+		checkLimitError(t, test.name, err, test.expected)
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+			t.Errorf("%s: allocated %d bytes", test.name, allocated)
+		}
+	}
+}
+
+func TestDefaultLimits(t *testing.T) {
+	// Legitimate documents are well within the defaults.
+	for _, document := range []string{nestedMappings(100), aliasBomb(3), "a: [[[1]]]\nb: '[[[['\nc: |\n  {{{{\n"} {
+		if _, err := ReadInfoFromBytes("", []byte(document)); err != nil {
+			t.Errorf("unexpected error %v for %q", err, document)
+		}
+	}
+	b, err := os.ReadFile("../examples/v3.0/yaml/petstore.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadInfoFromBytes("petstore.yaml", b); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSetLimits(t *testing.T) {
+	setupLimits(t, Limits{MaxInputBytes: 500, MaxDepth: 10, MaxAliasExpansions: -1})
+	if l := CurrentLimits(); l.MaxInputBytes != 500 || l.MaxDepth != 10 || l.MaxAliasExpansions != -1 {
+		t.Errorf("unexpected limits %+v", l)
+	}
+	_, err := ReadInfoFromBytes("big.yaml", []byte(strings.Repeat("a", 501)))
+	checkLimitError(t, "size", err, "big.yaml: document exceeds the maximum size of 500 bytes")
+	_, err = ReadInfoFromBytes("deep.yaml", []byte(nestedMappings(11)))
+	checkLimitError(t, "depth", err, "deep.yaml: document exceeds the maximum nesting depth of 10")
+	// A negative limit is disabled.
+	if _, err = ReadInfoFromBytes("", []byte(aliasBomb(4))); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	// Files are read with the limit.
+	filename := filepath.Join(t.TempDir(), "big.yaml")
+	if err = os.WriteFile(filename, []byte(strings.Repeat("a", 1000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ClearCaches()
+	_, err = ReadBytesForFile(filename)
+	checkLimitError(t, "file", err, "document exceeds the maximum size of 500 bytes")
+	setupMemoryResolver(t, map[string]string{"/specs/big.yaml": strings.Repeat("a", 1000)})
+	_, err = ReadBytesForFile("/specs/big.yaml")
+	checkLimitError(t, "resolver", err, "document exceeds the maximum size of 500 bytes")
+	// Zero limits select the defaults.
+	SetLimits(Limits{})
+	if l := CurrentLimits(); l.MaxInputBytes != DefaultMaxInputBytes || l.MaxDepth != DefaultMaxDepth || l.MaxAliasExpansions != DefaultMaxAliasExpansions {
+		t.Errorf("unexpected limits %+v", l)
+	}
+}
@@ -15,47 +15,176 @@
 package compiler
 
 import (
+	"bytes"
+	gocontext "context"
+	"io"
+
 	"github.com/google/gnostic-models/compiler"
+	"go.yaml.in/yaml/v3"
 )
 
-// EnableFileCache turns on file caching.
-var EnableFileCache = compiler.EnableFileCache
-
-// EnableInfoCache turns on parsed info caching.
-var EnableInfoCache = compiler.EnableInfoCache
-
-// DisableFileCache turns off file caching.
-var DisableFileCache = compiler.DisableFileCache
-
-// DisableInfoCache turns off parsed info caching.
-var DisableInfoCache = compiler.DisableInfoCache
-
-// RemoveFromFileCache removes an entry from the file cache.
-var RemoveFromFileCache = compiler.RemoveFromFileCache
-
-// RemoveFromInfoCache removes an entry from the info cache.
-var RemoveFromInfoCache = compiler.RemoveFromInfoCache
-
 // GetInfoCache returns the info cache map.
 var GetInfoCache = compiler.GetInfoCache
 
-// ClearFileCache clears the file cache.
-var ClearFileCache = compiler.ClearFileCache
-
-// ClearInfoCache clears the info cache.
-var ClearInfoCache = compiler.ClearInfoCache
-
-// ClearCaches clears all caches.
-var ClearCaches = compiler.ClearCaches
-
-// FetchFile gets a specified file from the local filesystem or a remote location.
-var FetchFile = compiler.FetchFile
-
-// ReadBytesForFile reads the bytes of a file.
-var ReadBytesForFile = compiler.ReadBytesForFile
-
 // ReadInfoFromBytes unmarshals a file as a *yaml.Node.
-var ReadInfoFromBytes = compiler.ReadInfoFromBytes
-
-// ReadInfoForRef reads a file and return the fragment needed to resolve a $ref.
-var ReadInfoForRef = compiler.ReadInfoForRef
+// Documents that exceed the limits set with SetLimits are rejected with errors that wrap ErrLimitExceeded.
+func ReadInfoFromBytes(filename string, bytes []byte) (*yaml.Node, error) {
+	return ReadInfoFromBytesContext(gocontext.Background(), filename, bytes)
+}
+
+// ReadInfoFromBytesContext is like ReadInfoFromBytes, but it uses the file cache of ctx; see WithFileCache.
+func ReadInfoFromBytesContext(ctx gocontext.Context, filename string, b []byte) (*yaml.Node, error) {
+	// Oversized documents are rejected before they are parsed.
+	if err := checkInputSize(filename, int64(len(b)), CurrentLimits()); err != nil {
+		return nil, err
+	}
+	return ReadInfoFromReaderContext(ctx, filename, bytes.NewReader(b))
+}
+
+// ReadInfoFromReader unmarshals a file as a *yaml.Node, parsing it as it is read, so that
+// documents don't need to be read into memory first. Reading stops at the end of the
+// first YAML document or at the first error, and errors of r are returned unchanged;
+// ReadDocumentsFromReader reads every document of a stream.
+// Documents that exceed the limits set with SetLimits are rejected with errors that wrap
+// ErrLimitExceeded as soon as they are detected.
+func ReadInfoFromReader(filename string, r io.Reader) (*yaml.Node, error) {
+	return ReadInfoFromReaderContext(gocontext.Background(), filename, r)
+}
+
+// ReadInfoFromReaderContext is like ReadInfoFromReader, but it stops with ctx.Err() when ctx
+// is done and uses the file cache of ctx; see WithFileCache. When filename is cached, r isn't read.
+func ReadInfoFromReaderContext(ctx gocontext.Context, filename string, r io.Reader) (*yaml.Node, error) {
+	cache := fileCacheForContext(ctx)
+	if filename != "" {
+		if info, ok := cache.Info(filename); ok {
+			return info, nil
+		}
+	}
+	documents, err := readDocuments(ctx, filename, r, false)
+	if err != nil {
+		return nil, err
+	}
+	if filename != "" {
+		cache.SetInfo(filename, documents[0])
+	}
+	return documents[0], nil
+}
+
+// ReadDocumentsFromBytes unmarshals each document of a YAML stream, in which documents are
+// separated by "---" lines, as a *yaml.Node. Empty documents are skipped, so a stream
+// without any content is read as a single empty node, like ReadInfoFromBytes reads it.
+// The documents aren't saved in the file cache.
+func ReadDocumentsFromBytes(filename string, bytes []byte) ([]*yaml.Node, error) {
+	return ReadDocumentsFromBytesContext(gocontext.Background(), filename, bytes)
+}
+
+// ReadDocumentsFromBytesContext is like ReadDocumentsFromBytes, but it stops with ctx.Err() when ctx is done.
+func ReadDocumentsFromBytesContext(ctx gocontext.Context, filename string, b []byte) ([]*yaml.Node, error) {
+	if err := checkInputSize(filename, int64(len(b)), CurrentLimits()); err != nil {
+		return nil, err
+	}
+	return readDocuments(ctx, filename, bytes.NewReader(b), true)
+}
+
+// ReadDocumentsFromReader is like ReadDocumentsFromBytes, but it parses the stream as it is read from r.
+func ReadDocumentsFromReader(filename string, r io.Reader) ([]*yaml.Node, error) {
+	return ReadDocumentsFromReaderContext(gocontext.Background(), filename, r)
+}
+
+// ReadDocumentsFromReaderContext is like ReadDocumentsFromReader, but it stops with ctx.Err() when ctx is done.
+func ReadDocumentsFromReaderContext(ctx gocontext.Context, filename string, r io.Reader) ([]*yaml.Node, error) {
+	return readDocuments(ctx, filename, r, true)
+}
+
+// readDocuments reads the first document of a YAML stream or, if all is true, each of its
+// documents that isn't empty. It always returns at least one document.
+func readDocuments(ctx gocontext.Context, filename string, r io.Reader, all bool) ([]*yaml.Node, error) {
+	l := CurrentLimits()
+	reader := &limitedReader{ctx: ctx, filename: filename, r: r, limits: l}
+	if l.MaxDepth > 0 {
+		// The YAML parser recurses for each level of nesting, so deep documents are rejected before parsing.
+		reader.depth = newDepthEstimator(l.MaxDepth)
+	}
+	decoder := yaml.NewDecoder(reader)
+	documents := make([]*yaml.Node, 0)
+	for {
+		var info yaml.Node
+		err := decoder.Decode(&info)
+		if reader.err != nil {
+			// The decoder doesn't wrap the errors of the reader.
+			return nil, reader.err
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkNodeLimits(filename, &info, l); err != nil {
+			return nil, err
+		}
+		if !all {
+			return []*yaml.Node{&info}, nil
+		}
+		if !isEmptyDocument(&info) {
+			documents = append(documents, &info)
+		}
+	}
+	if len(documents) == 0 {
+		// Empty documents are read as empty nodes.
+		documents = append(documents, &yaml.Node{})
+	}
+	return documents, nil
+}
+
+// isEmptyDocument returns true for documents without content, like the one after a final "---".
+func isEmptyDocument(info *yaml.Node) bool {
+	if len(info.Content) == 0 {
+		return true
+	}
+	content := info.Content[0]
+	return content.Kind == yaml.ScalarNode && content.Tag == "!!null" && content.Value == ""
+}
+
+// limitedReader reads a document for the YAML decoder and stops with an error when the
+// document exceeds the limits or the Go context is done. The first error is saved, and
+// the underlying reader isn't read after it.
+type limitedReader struct {
+	ctx      gocontext.Context
+	filename string
+	r        io.Reader
+	limits   Limits
+	depth    *depthEstimator
+	size     int64
+	err      error
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if err := r.ctx.Err(); err != nil {
+		r.err = err
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	r.size += int64(n)
+	if sizeErr := checkInputSize(r.filename, r.size, r.limits); sizeErr != nil {
+		r.err = sizeErr
+		return 0, sizeErr
+	}
+	if r.depth != nil {
+		r.depth.write(p[:n])
+		if err == io.EOF {
+			r.depth.close()
+		}
+		if r.depth.exceeded() {
+			r.err = depthError(r.filename, r.limits)
+			return 0, r.err
+		}
+	}
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
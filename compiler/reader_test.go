@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// endlessReader repeats a pattern forever and counts the bytes that are read.
+type endlessReader struct {
+	pattern string
+	read    int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.pattern[(r.read+int64(i))%int64(len(r.pattern))]
+	}
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestReadInfoFromReader(t *testing.T) {
+	info, err := ReadInfoFromReader("", strings.NewReader("a: [1, 2]\nb:\n  c: d\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := MapValueForKey(info.Content[0], "b"); b == nil || MapValueForKey(b, "c").Value != "d" {
+		t.Errorf("unexpected document %v", info)
+	}
+	if info, err = ReadInfoFromReader("", strings.NewReader("")); err != nil || len(info.Content) != 0 {
+		t.Errorf("expected an empty document, got %v %v", info, err)
+	}
+	if _, err = ReadInfoFromReader("", strings.NewReader("a: [")); err == nil {
+		t.Errorf("expected a syntax error")
+	}
+}
+
+func TestReadInfoFromReaderLimits(t *testing.T) {
+	setupLimits(t, Limits{MaxInputBytes: 1 << 20, MaxDepth: 100})
+	for _, test := range []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{"size", "- x\n", "document exceeds the maximum size of 1048576 bytes"},
+		{"flow depth", "[", "document exceeds the maximum nesting depth of 100"},
+		{"block depth", "- ", "document exceeds the maximum nesting depth of 100"},
+	} {
+		r := &endlessReader{pattern: test.pattern}
+		_, err := ReadInfoFromReader("endless.yaml", r)
+		checkLimitError(t, test.name, err, test.expected)
+		// Reading stops when the limit is exceeded.
+		if r.read > 2<<20 {
+			t.Errorf("%s: read %d bytes", test.name, r.read)
+		}
+	}
+}
+
+func TestReadInfoFromReaderContext_Canceled(t *testing.T) {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+	r := &endlessReader{pattern: "- x\n"}
+	if _, err := ReadInfoFromReaderContext(ctx, "", r); !errors.Is(err, gocontext.Canceled) {
+		t.Errorf("expected the read to be canceled, got %v", err)
+	}
+	if r.read != 0 {
+		t.Errorf("expected no reads, got %d bytes", r.read)
+	}
+}
+
+func TestReadDocuments(t *testing.T) {
+	for _, test := range []struct {
+		text   string
+		titles []string
+	}{
+		{"title: one\n", []string{"one"}},
+		{"title: one\n---\ntitle: two\n", []string{"one", "two"}},
+		{"---\ntitle: one\n---\n---\ntitle: two\n---\n", []string{"one", "two"}},
+		{"", []string{""}},
+	} {
+		documents, err := ReadDocumentsFromBytes("", []byte(test.text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(documents) != len(test.titles) {
+			t.Fatalf("expected %d documents in %q, got %d", len(test.titles), test.text, len(documents))
+		}
+		for i, document := range documents {
+			title := ""
+			if len(document.Content) > 0 {
+				if value := MapValueForKey(document.Content[0], "title"); value != nil {
+					title = value.Value
+				}
+			}
+			if title != test.titles[i] {
+				t.Errorf("unexpected title %q of document %d in %q (expected %q)", title, i, test.text, test.titles[i])
+			}
+		}
+		// ReadInfoFromReader reads only the first document.
+		info, err := ReadInfoFromReader("", strings.NewReader(test.text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(info.Content) > 0 && MapValueForKey(info.Content[0], "title").Value != test.titles[0] {
+			t.Errorf("expected the first document of %q", test.text)
+		}
+	}
+	if _, err := ReadDocumentsFromReader("", strings.NewReader("title: one\n---\ntitle: [\n")); err == nil {
+		t.Errorf("expected an error for an invalid second document")
+	}
+}
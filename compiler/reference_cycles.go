@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ReferenceCycleError reports references that lead back to themselves and can't be resolved.
+type ReferenceCycleError struct {
+	// Cycle lists the references in the order that they are followed, starting and
+	// ending with the first of them in sort order.
+	Cycle []string
+}
+
+// Error returns the references of the cycle, like #/components/schemas/A -> #/components/schemas/A.
+func (e *ReferenceCycleError) Error() string {
+	return "reference cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+// findReferenceCycles returns the cycles of references in a set of fragments, which are
+// visited in the order of refs. A reference leads to the reference of its fragment if the
+// fragment is itself a reference or, if nested is true, to every reference in the fragment.
+func findReferenceCycles(refs []string, fragments map[string]*yaml.Node, nested bool) []*ReferenceCycleError {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[string]int)
+	cycles := make([]*ReferenceCycleError, 0)
+	path := make([]string, 0)
+	var visit func(ref string)
+	visit = func(ref string) {
+		states[ref] = visiting
+		path = append(path, ref)
+		for _, next := range referencesOfFragment(fragments[ref], nested) {
+			switch states[next] {
+			case unvisited:
+				if _, ok := fragments[next]; ok {
+					visit(next)
+				}
+			case visiting:
+				start := len(path) - 1
+				for path[start] != next {
+					start--
+				}
+				cycles = append(cycles, &ReferenceCycleError{Cycle: rotateCycle(path[start:])})
+			}
+		}
+		path = path[:len(path)-1]
+		states[ref] = visited
+	}
+	for _, ref := range refs {
+		if states[ref] == unvisited {
+			visit(ref)
+		}
+	}
+	return cycles
+}
+
+// rotateCycle returns a cycle of references that starts and ends with the first of them in sort order.
+func rotateCycle(refs []string) []string {
+	first := 0
+	for i, ref := range refs {
+		if ref < refs[first] {
+			first = i
+		}
+	}
+	cycle := append(append([]string{}, refs[first:]...), refs[:first]...)
+	return append(cycle, refs[first])
+}
+
+// referencesOfFragment returns the references that are followed when a fragment is resolved.
+func referencesOfFragment(fragment *yaml.Node, nested bool) []string {
+	if nested {
+		return findReferences(fragment, nil)
+	}
+	if ref := MapValueForKey(fragment, "$ref"); ref != nil && ref.Kind == yaml.ScalarNode {
+		return []string{ref.Value}
+	}
+	return nil
+}
+
+// unresolvableFragment returns a fragment for a reference in a cycle. It isn't a mapping,
+// so the models fail to build an object from it and leave the reference in place.
+func unresolvableFragment(ref string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ref}
+}
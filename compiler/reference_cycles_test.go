@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const cyclesTestDocument = `
+schemas:
+  Pet:
+    $ref: '#/schemas/Animal'
+  Animal:
+    type: object
+  A:
+    $ref: '#/schemas/B'
+  B:
+    $ref: '#/schemas/A'
+  X:
+    $ref: '#/schemas/Y'
+  Y:
+    $ref: '#/schemas/Z'
+  Z:
+    $ref: '#/schemas/X'
+  Self:
+    $ref: '#/schemas/Self'
+  Node:
+    type: object
+    properties:
+      next:
+        $ref: '#/schemas/Node'
+`
+
+func TestReferenceCycles(t *testing.T) {
+	for _, test := range []struct {
+		nested bool
+		cycles []string
+	}{
+		{
+			nested: false,
+			cycles: []string{
+				"reference cycle: #/schemas/A -> #/schemas/B -> #/schemas/A",
+				"reference cycle: #/schemas/X -> #/schemas/Y -> #/schemas/Z -> #/schemas/X",
+				"reference cycle: #/schemas/Self -> #/schemas/Self",
+			},
+		},
+		{
+			// Nested references in schemas like Node also make cycles.
+			nested: true,
+			cycles: []string{
+				"reference cycle: #/schemas/A -> #/schemas/B -> #/schemas/A",
+				"reference cycle: #/schemas/X -> #/schemas/Y -> #/schemas/Z -> #/schemas/X",
+				"reference cycle: #/schemas/Self -> #/schemas/Self",
+				"reference cycle: #/schemas/Node -> #/schemas/Node",
+			},
+		},
+	} {
+		setupMemoryResolver(t, map[string]string{"/specs/root.yaml": cyclesTestDocument})
+		ctx := WithFileCache(gocontext.Background(), NewFileCache())
+		resolved := false
+		cycles, err := ResolveReferencesWithCyclesContext(ctx, "/specs/root.yaml", test.nested, func() error {
+			resolved = true
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resolved {
+			t.Errorf("expected references to be resolved")
+		}
+		if len(cycles) != len(test.cycles) {
+			t.Fatalf("expected %d cycles, got %v", len(test.cycles), cycles)
+		}
+		for i, cycle := range cycles {
+			if cycle.Error() != test.cycles[i] {
+				t.Errorf("unexpected cycle %q (expected %q)", cycle.Error(), test.cycles[i])
+			}
+			// The references in cycles can't be resolved by the models.
+			for _, ref := range cycle.Cycle {
+				if info := GetInfoCache()[ref]; info == nil || info.Kind == yaml.MappingNode {
+					t.Errorf("expected %s to be unresolvable, got %v", ref, info)
+				}
+			}
+		}
+		if info := GetInfoCache()["#/schemas/Animal"]; info == nil || info.Kind != yaml.MappingNode {
+			t.Errorf("expected #/schemas/Animal to be resolvable, got %v", info)
+		}
+	}
+}
+
+func TestReferenceCyclesWithoutInfoCache(t *testing.T) {
+	setupMemoryResolver(t, map[string]string{"/specs/root.yaml": cyclesTestDocument})
+	DisableInfoCache()
+	defer EnableInfoCache()
+	ctx := WithFileCache(gocontext.Background(), NewFileCache())
+	_, err := ResolveReferencesWithCyclesContext(ctx, "/specs/root.yaml", false, func() error {
+		t.Errorf("expected references not to be resolved")
+		return nil
+	})
+	if err == nil || err.Error() != "reference cycle: #/schemas/A -> #/schemas/B -> #/schemas/A\n"+
+		"reference cycle: #/schemas/X -> #/schemas/Y -> #/schemas/Z -> #/schemas/X\n"+
+		"reference cycle: #/schemas/Self -> #/schemas/Self" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
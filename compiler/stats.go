@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"sync"
+)
+
+// Names of the counts that the compiler adds to the StatsSink of a Go context.
+const (
+	// StatReferencesResolved counts the references that ReadReferencedFiles and
+	// ResolveReferences read fragments for, not including those in cycles.
+	StatReferencesResolved = "refs_resolved"
+	// StatRemoteFetches counts the documents that are read from URLs, not including
+	// those that are read from a file cache.
+	StatRemoteFetches = "remote_fetches"
+	// StatExtensionsHandled counts the extensions that extension handlers accept.
+	StatExtensionsHandled = "extensions_handled"
+)
+
+// StatsSink receives counts of the events of compilations, so that callers can report
+// them with their own metrics. Sinks are called from any goroutine that compiles with
+// their Go context, so they must be safe for concurrent use.
+type StatsSink interface {
+	// Add adds delta to the count of name.
+	Add(name string, delta int64)
+}
+
+type statsSinkKey struct{}
+
+// WithStatsSink returns a copy of a Go context in which the compiler adds counts to sink.
+// A nil sink disables counting.
+func WithStatsSink(ctx gocontext.Context, sink StatsSink) gocontext.Context {
+	return gocontext.WithValue(ctx, statsSinkKey{}, sink)
+}
+
+// StatsSinkFromContext returns the sink that was set with WithStatsSink, or nil.
+func StatsSinkFromContext(ctx gocontext.Context) StatsSink {
+	sink, _ := ctx.Value(statsSinkKey{}).(StatsSink)
+	return sink
+}
+
+// AddStat adds delta to the count of name in the StatsSink of a Go context, if it has one.
+func AddStat(ctx gocontext.Context, name string, delta int64) {
+	if sink := StatsSinkFromContext(ctx); sink != nil {
+		sink.Add(name, delta)
+	}
+}
+
+// Stats is a StatsSink that keeps its counts in memory.
+type Stats struct {
+	mutex  sync.Mutex
+	counts map[string]int64
+}
+
+// NewStats returns a Stats with no counts.
+func NewStats() *Stats {
+	return &Stats{counts: make(map[string]int64)}
+}
+
+// Add adds delta to the count of name.
+func (s *Stats) Add(name string, delta int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.counts[name] += delta
+}
+
+// Get returns the count of name, which is zero if nothing was added to it.
+func (s *Stats) Get(name string) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.counts[name]
+}
+
+// Counts returns a copy of the counts.
+func (s *Stats) Counts() map[string]int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	counts := make(map[string]int64, len(s.counts))
+	for name, count := range s.counts {
+		counts[name] = count
+	}
+	return counts
+}
+
+type multiStatsSink []StatsSink
+
+func (sinks multiStatsSink) Add(name string, delta int64) {
+	for _, sink := range sinks {
+		sink.Add(name, delta)
+	}
+}
+
+// MultiStatsSink returns a StatsSink that adds counts to each of sinks that isn't nil.
+func MultiStatsSink(sinks ...StatsSink) StatsSink {
+	nonNil := make(multiStatsSink, 0, len(sinks))
+	for _, sink := range sinks {
+		if sink != nil {
+			nonNil = append(nonNil, sink)
+		}
+	}
+	if len(nonNil) == 1 {
+		return nonNil[0]
+	}
+	return nonNil
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"testing"
+)
+
+func TestStatsSink(t *testing.T) {
+	setupMemoryResolver(t, map[string]string{
+		"/specs/root.yaml": "pet:\n  $ref: 'pet.yaml#/Pet'\nowner:\n  $ref: 'pet.yaml#/Owner'\nloop:\n  $ref: '#/loop'\n",
+		"/specs/pet.yaml":  "Pet:\n  type: object\nOwner:\n  type: string\n",
+	})
+	stats := NewStats()
+	other := NewStats()
+	ctx := WithStatsSink(gocontext.Background(), MultiStatsSink(stats, nil, other))
+	if err := ReadReferencedFilesContext(WithFileCache(ctx, NewFileCache()), "/specs/root.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	// The reference in a cycle isn't counted.
+	for _, s := range []*Stats{stats, other} {
+		if count := s.Get(StatReferencesResolved); count != 2 {
+			t.Errorf("expected 2 resolved references, got %d", count)
+		}
+		if count := s.Get(StatRemoteFetches); count != 0 {
+			t.Errorf("expected no remote fetches, got %d", count)
+		}
+	}
+	// Compilations without sinks aren't counted.
+	AddStat(gocontext.Background(), StatReferencesResolved, 1)
+	if count := stats.Get(StatReferencesResolved); count != 2 {
+		t.Errorf("expected 2 resolved references, got %d", count)
+	}
+}
@@ -0,0 +1,7 @@
+#!/bin/sh
+# A stub extension handler for tests.
+# It records its arguments and replies with the response in response.pb.
+dir=$(dirname "$0")
+cat > /dev/null
+echo "$@" >> "$dir/calls.log"
+cat "$dir/response.pb"
@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Codes of the warnings that the compiler reports.
+const (
+	// WarningInvalidKey reports a key that is neither a field nor an extension; see InvalidKeyError.
+	WarningInvalidKey = "INVALID_KEY"
+	// WarningYAML11Coercion reports a scalar that was read with its YAML 1.1 meaning.
+	WarningYAML11Coercion = "YAML11_COERCION"
+	// WarningYAML11AmbiguousScalar reports a string that YAML 1.1 reads differently.
+	WarningYAML11AmbiguousScalar = "YAML11_AMBIGUOUS_SCALAR"
+	// WarningExtensionHandlerFailed reports an extension handler failure that was
+	// reported as a warning because of ExtensionErrorsWarn.
+	WarningExtensionHandlerFailed = "EXTENSION_HANDLER_FAILED"
+	// WarningExtensionHandler reports a warning of an extension handler about an
+	// extension that it accepted.
+	WarningExtensionHandler = "EXTENSION_HANDLER_WARNING"
+	// WarningSkippedArrayItem reports an item of an array of strings that isn't a string,
+	// which is left out of the array.
+	WarningSkippedArrayItem = "SKIPPED_ARRAY_ITEM"
+)
+
+// Warning reports a problem in a description that doesn't stop its compilation, like a
+// scalar that was coerced or a value that was skipped. Warnings are located like Errors,
+// and their codes identify their kinds.
+type Warning struct {
+	// Code identifies the kind of the warning, like SKIPPED_ARRAY_ITEM.
+	Code string
+	// Context locates the warning in the document.
+	Context *Context
+	// Message describes the warning without its location.
+	Message string
+}
+
+// NewWarning creates a Warning.
+func NewWarning(context *Context, code string, message string) *Warning {
+	return &Warning{Code: code, Context: context, Message: message}
+}
+
+// Error returns the string value of a Warning, which is like that of an Error, so that
+// callers can report warnings as errors.
+func (w *Warning) Error() string {
+	return NewError(w.Context, w.Message).Error()
+}
+
+// Keys returns the names of the contexts of a Warning below the root of the document.
+func (w *Warning) Keys() []string {
+	keys := make([]string, 0)
+	for context := w.Context; context != nil && context.Parent != nil; context = context.Parent {
+		keys = append([]string{context.Name}, keys...)
+	}
+	return keys
+}
+
+type warningKey struct {
+	code    string
+	node    *yaml.Node
+	message string
+}
+
+// AddWarning records a warning of the compilation of a context, which is returned by
+// Warnings.
+func AddWarning(context *Context, warning *Warning) {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	state.addWarningLocked(warning)
+}
+
+// addWarningLocked records a warning, unless it was already recorded by an earlier pass over
+// the document; see CallExtensionsInBatches. It must be called with extensionStatesMutex held.
+func (s *extensionState) addWarningLocked(warning *Warning) {
+	key := warningKey{code: warning.Code, message: warning.Message}
+	if warning.Context != nil {
+		key.node = warning.Context.Node
+	}
+	if s.warningKeys == nil {
+		s.warningKeys = make(map[warningKey]bool)
+	}
+	if s.warningKeys[key] {
+		return
+	}
+	s.warningKeys[key] = true
+	s.allWarnings = append(s.allWarnings, warning)
+}
+
+// Warnings returns the warnings of the compilation of a context in the order that they were
+// reported, including those that are also returned by ExtensionWarnings,
+// ExtensionHandlerWarnings, YAML11Coercions, and YAML11AmbiguousScalars. Invalid keys
+// are returned as errors; see InvalidKeyError.Warning.
+func Warnings(context *Context) []*Warning {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	return append([]*Warning(nil), state.allWarnings...)
+}
+
+// Warning returns an InvalidKeyError as a warning, which is located at the key.
+func (err *InvalidKeyError) Warning() *Warning {
+	context := &Context{Name: err.Key.Value, Parent: err.Context, Node: err.Key}
+	if err.Context != nil {
+		context.ExtensionHandlers = err.Context.ExtensionHandlers
+	}
+	return NewWarning(context, WarningInvalidKey, err.Message())
+}
+
+// StringArrayForSequenceNodeInContext returns the strings of a sequence node of a field named
+// name, like StringArrayForSequenceNode. Items that aren't strings are left out of the
+// array and reported as warnings.
+func StringArrayForSequenceNodeInContext(context *Context, name string, node *yaml.Node) []string {
+	stringArray := make([]string, 0)
+	for i, item := range node.Content {
+		v, ok := StringForScalarNodeInContext(context, name, item)
+		if !ok {
+			itemContext := NewContext(strconv.Itoa(i), item, NewContext(name, node, context))
+			message := fmt.Sprintf("skipped item that isn't a string: %s", describeItem(item))
+			AddWarning(context, NewWarning(itemContext, WarningSkippedArrayItem, message))
+			continue
+		}
+		stringArray = append(stringArray, v)
+	}
+	return stringArray
+}
+
+// describeItem describes a YAML node briefly, like true (bool) or a map.
+func describeItem(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return fmt.Sprintf("%s (%s)", node.Value, strings.TrimPrefix(node.Tag, "!!"))
+	case yaml.MappingNode:
+		return "a map"
+	case yaml.SequenceNode:
+		return "a sequence"
+	default:
+		return Display(node)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestStringArrayWarnings(t *testing.T) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("tags: [pets, 1, true, {name: dogs}, [cats]]\n"), &node); err != nil {
+		t.Fatal(err)
+	}
+	root := node.Content[0]
+	context := NewContextWithCancellation(gocontext.Background(), "$root", root, nil, nil, nil)
+	defer RemoveExtensionConfig(context)
+	tags := MapValueForKey(root, "tags")
+	// Documents can be compiled more than once, but their warnings are reported once.
+	for i := 0; i < 2; i++ {
+		if values := StringArrayForSequenceNodeInContext(context, "tags", tags); !reflect.DeepEqual(values, []string{"pets", "1"}) {
+			t.Errorf("unexpected strings %v", values)
+		}
+	}
+	expected := []string{
+		"[1,17] $root.tags.2 skipped item that isn't a string: true (bool)",
+		"[1,23] $root.tags.3 skipped item that isn't a string: a map",
+		"[1,37] $root.tags.4 skipped item that isn't a string: a sequence",
+	}
+	warnings := Warnings(context)
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, warning := range warnings {
+		if warning.Code != WarningSkippedArrayItem || warning.Error() != expected[i] {
+			t.Errorf("unexpected warning %s %s (expected %s)", warning.Code, warning.Error(), expected[i])
+		}
+	}
+	if keys := strings.Join(warnings[0].Keys(), "."); keys != "tags.2" {
+		t.Errorf("unexpected keys %s", keys)
+	}
+	// Compilations started with NewContext record their own warnings.
+	plain := NewContext("$root", root, nil)
+	StringArrayForSequenceNodeInContext(plain, "tags", tags)
+	if warnings := Warnings(plain); len(warnings) != len(expected) {
+		t.Errorf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	if warnings := Warnings(context); len(warnings) != len(expected) {
+		t.Errorf("expected the warnings of another compilation to be kept apart, got %v", warnings)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+type yaml11CompatKey struct{}
+
+// WithYAML11Compat returns a copy of a Go context in which compilations started with
+// NewContextWithCancellation read scalars of fields with their YAML 1.1 meanings, as if
+// SetYAML11Compat was called with their root contexts.
+func WithYAML11Compat(ctx gocontext.Context) gocontext.Context {
+	return gocontext.WithValue(ctx, yaml11CompatKey{}, true)
+}
+
+// SetYAML11Compat sets whether the compilation of a context reads scalars of fields with
+// their YAML 1.1 meanings. Documents are read with YAML 1.2, in which unquoted yes, no, on,
+// and off are strings and values like 1:30 aren't numbers, but descriptions written for
+// YAML 1.1 tools use them as booleans and base 60 numbers. With this setting, they are read
+// that way where a boolean or number is expected. Each scalar that is read that way is
+// reported by YAML11Coercions, and scalars of string fields that YAML 1.1 reads as booleans
+// or numbers are reported by YAML11AmbiguousScalars.
+func SetYAML11Compat(context *Context, compat bool) {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	state.yaml11Compat = compat
+}
+
+// yaml11Compat returns the state of a compilation that reads scalars with their YAML 1.1
+// meanings, or nil if the compilation uses YAML 1.2.
+func yaml11Compat(context *Context) *extensionState {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	if !state.yaml11Compat {
+		return nil
+	}
+	return state
+}
+
+var (
+	yaml11True  = regexp.MustCompile(`^(y|Y|yes|Yes|YES|on|On|ON)$`)
+	yaml11False = regexp.MustCompile(`^(n|N|no|No|NO|off|Off|OFF)$`)
+	// Base 60 numbers, like 1:30 (90) and 1:30.5 (90.5).
+	yaml11Sexagesimal = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+)
+
+// isYAML11Candidate returns true if node is a scalar that YAML 1.2 reads as a string
+// only because it is written in a form that YAML 1.2 doesn't recognize.
+func isYAML11Candidate(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && node.Style == 0 && node.Tag == "!!str"
+}
+
+// yaml11Bool returns the YAML 1.1 boolean value of a scalar.
+func yaml11Bool(value string) (bool, bool) {
+	if yaml11True.MatchString(value) {
+		return true, true
+	}
+	if yaml11False.MatchString(value) {
+		return false, true
+	}
+	return false, false
+}
+
+// yaml11SexagesimalValue returns the YAML 1.1 value of a base 60 number.
+func yaml11SexagesimalValue(value string) (float64, bool) {
+	if !yaml11Sexagesimal.MatchString(value) {
+		return 0, false
+	}
+	sign := 1.0
+	if value[0] == '-' || value[0] == '+' {
+		if value[0] == '-' {
+			sign = -1
+		}
+		value = value[1:]
+	}
+	result := 0.0
+	for _, part := range strings.Split(strings.ReplaceAll(value, "_", ""), ":") {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, false
+		}
+		result = result*60 + v
+	}
+	return sign * result, true
+}
+
+// yaml11Context returns the context of a field, or context itself if name is empty.
+func yaml11Context(context *Context, name string, node *yaml.Node) *Context {
+	if name == "" {
+		return context
+	}
+	return NewContext(name, node, context)
+}
+
+// addYAML11Coercion records a scalar that was read with its YAML 1.1 meaning.
+func (s *extensionState) addYAML11Coercion(context *Context, name string, node *yaml.Node, meaning string) {
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	if s.yaml11Scalars[node] {
+		// Documents are compiled more than once when extensions are called in batches.
+		return
+	}
+	s.yaml11Scalars[node] = true
+	message := fmt.Sprintf("read %s as the YAML 1.1 %s", node.Value, meaning)
+	s.yaml11Coercions = append(s.yaml11Coercions, NewError(yaml11Context(context, name, node), message))
+	s.addWarningLocked(NewWarning(yaml11Context(context, name, node), WarningYAML11Coercion, message))
+}
+
+// addYAML11Ambiguity records a string scalar that YAML 1.1 reads differently.
+func (s *extensionState) addYAML11Ambiguity(context *Context, name string, node *yaml.Node, meaning string) {
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	if s.yaml11Scalars[node] {
+		return
+	}
+	s.yaml11Scalars[node] = true
+	message := fmt.Sprintf("read %s as a string, but YAML 1.1 reads it as the %s; quote it to keep it a string", node.Value, meaning)
+	s.yaml11Ambiguities = append(s.yaml11Ambiguities, NewError(yaml11Context(context, name, node), message))
+	s.addWarningLocked(NewWarning(yaml11Context(context, name, node), WarningYAML11AmbiguousScalar, message))
+}
+
+// BoolForScalarNodeInContext returns the bool value of the node of a field named name,
+// or of the context itself if name is empty. When the compilation reads scalars with
+// their YAML 1.1 meanings, YAML 1.1 booleans like yes and off are also bools.
+func BoolForScalarNodeInContext(context *Context, name string, node *yaml.Node) (bool, bool) {
+	if v, ok := BoolForScalarNode(node); ok {
+		return v, ok
+	}
+	state := yaml11Compat(context)
+	if state == nil || !isYAML11Candidate(node) {
+		return false, false
+	}
+	v, ok := yaml11Bool(node.Value)
+	if ok {
+		state.addYAML11Coercion(context, name, node, fmt.Sprintf("boolean %t", v))
+	}
+	return v, ok
+}
+
+// IntForScalarNodeInContext returns the integer value of the node of a field named name.
+// When the compilation reads scalars with their YAML 1.1 meanings, whole base 60 numbers
+// like 1:30 are also integers.
+func IntForScalarNodeInContext(context *Context, name string, node *yaml.Node) (int64, bool) {
+	if v, ok := IntForScalarNode(node); ok {
+		return v, ok
+	}
+	state := yaml11Compat(context)
+	if state == nil || !isYAML11Candidate(node) || strings.Contains(node.Value, ".") {
+		return 0, false
+	}
+	v, ok := yaml11SexagesimalValue(node.Value)
+	if ok {
+		state.addYAML11Coercion(context, name, node, fmt.Sprintf("number %d", int64(v)))
+	}
+	return int64(v), ok
+}
+
+// FloatForScalarNodeInContext returns the float value of the node of a field named name.
+// When the compilation reads scalars with their YAML 1.1 meanings, base 60 numbers like
+// 1:30.5 are also floats.
+func FloatForScalarNodeInContext(context *Context, name string, node *yaml.Node) (float64, bool) {
+	if v, ok := FloatForScalarNode(node); ok {
+		return v, ok
+	}
+	state := yaml11Compat(context)
+	if state == nil || !isYAML11Candidate(node) {
+		return 0, false
+	}
+	v, ok := yaml11SexagesimalValue(node.Value)
+	if ok {
+		state.addYAML11Coercion(context, name, node, fmt.Sprintf("number %g", v))
+	}
+	return v, ok
+}
+
+// StringForScalarNodeInContext returns the string value of the node of a field named name.
+// When the compilation reads scalars with their YAML 1.1 meanings, unquoted strings that
+// YAML 1.1 reads as booleans or numbers are still strings, but they are reported.
+func StringForScalarNodeInContext(context *Context, name string, node *yaml.Node) (string, bool) {
+	v, ok := StringForScalarNode(node)
+	if !ok || !isYAML11Candidate(node) {
+		return v, ok
+	}
+	state := yaml11Compat(context)
+	if state == nil {
+		return v, ok
+	}
+	if b, isBool := yaml11Bool(node.Value); isBool {
+		state.addYAML11Ambiguity(context, name, node, fmt.Sprintf("boolean %t", b))
+	} else if n, isNumber := yaml11SexagesimalValue(node.Value); isNumber {
+		state.addYAML11Ambiguity(context, name, node, fmt.Sprintf("number %g", n))
+	}
+	return v, ok
+}
+
+// YAML11Coercions returns the scalars that a compilation read with their YAML 1.1 meanings;
+// see SetYAML11Compat.
+func YAML11Coercions(context *Context) []*Error {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	return append([]*Error(nil), state.yaml11Coercions...)
+}
+
+// YAML11AmbiguousScalars returns the scalars of string fields that a compilation read as
+// strings but that YAML 1.1 reads as booleans or numbers; see SetYAML11Compat.
+func YAML11AmbiguousScalars(context *Context) []*Error {
+	state := extensionStateForContext(context)
+	if state == nil {
+		return nil
+	}
+	extensionStatesMutex.Lock()
+	defer extensionStatesMutex.Unlock()
+	return append([]*Error(nil), state.yaml11Ambiguities...)
+}
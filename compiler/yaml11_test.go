@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	gocontext "context"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// yaml11TestNode returns the scalar of a YAML 1.2 document.
+func yaml11TestNode(t *testing.T, text string) *yaml.Node {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &node); err != nil {
+		t.Fatal(err)
+	}
+	return node.Content[0]
+}
+
+func TestYAML11Scalars(t *testing.T) {
+	for _, test := range []struct {
+		text   string
+		isBool bool
+		b      bool
+		isInt  bool
+		i      int64
+		isNum  bool
+		f      float64
+	}{
+		{text: "yes", isBool: true, b: true},
+		{text: "No", isBool: true, b: false},
+		{text: "ON", isBool: true, b: true},
+		{text: "off", isBool: true, b: false},
+		{text: "y", isBool: true, b: true},
+		{text: "true", isBool: true, b: true},
+		{text: "1:30", isInt: true, i: 90, isNum: true, f: 90},
+		{text: "-1:30:00", isInt: true, i: -5400, isNum: true, f: -5400},
+		{text: "1_0:00", isInt: true, i: 600, isNum: true, f: 600},
+		{text: "1:30.5", isNum: true, f: 90.5},
+		{text: "42", isInt: true, i: 42, isNum: true, f: 42},
+		// Quoted scalars and values that aren't YAML 1.1 booleans or numbers are strings.
+		{text: `"yes"`},
+		{text: "'1:30'"},
+		{text: "yep"},
+		{text: "1:60"},
+		{text: "12:30 pm"},
+	} {
+		ctx := WithYAML11Compat(gocontext.Background())
+		context := NewContextWithCancellation(ctx, "$root", nil, nil, nil, nil)
+		node := yaml11TestNode(t, test.text)
+		if b, ok := BoolForScalarNodeInContext(context, "b", node); ok != test.isBool || b != test.b {
+			t.Errorf("BoolForScalarNodeInContext(%s) = %t, %t", test.text, b, ok)
+		}
+		if i, ok := IntForScalarNodeInContext(context, "i", node); ok != test.isInt || i != test.i {
+			t.Errorf("IntForScalarNodeInContext(%s) = %d, %t", test.text, i, ok)
+		}
+		if f, ok := FloatForScalarNodeInContext(context, "f", node); ok != test.isNum || f != test.f {
+			t.Errorf("FloatForScalarNodeInContext(%s) = %g, %t", test.text, f, ok)
+		}
+		RemoveExtensionConfig(context)
+	}
+}
+
+func TestYAML11Warnings(t *testing.T) {
+	yes := yaml11TestNode(t, "yes")
+	time := yaml11TestNode(t, "1:30")
+	quoted := yaml11TestNode(t, `"no"`)
+
+	// By default, YAML 1.1 booleans and numbers are strings.
+	context := NewContextWithCancellation(gocontext.Background(), "$root", nil, nil, nil, nil)
+	if _, ok := BoolForScalarNodeInContext(context, "required", yes); ok {
+		t.Errorf("expected yes not to be a boolean")
+	}
+	if _, ok := IntForScalarNodeInContext(context, "maximum", time); ok {
+		t.Errorf("expected 1:30 not to be a number")
+	}
+	if s, ok := StringForScalarNodeInContext(context, "version", time); !ok || s != "1:30" {
+		t.Errorf("expected 1:30 to be a string")
+	}
+	if warnings := append(YAML11Coercions(context), YAML11AmbiguousScalars(context)...); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+	RemoveExtensionConfig(context)
+
+	context = NewContextWithCancellation(WithYAML11Compat(gocontext.Background()), "$root", nil, nil, nil, nil)
+	defer RemoveExtensionConfig(context)
+	for i := 0; i < 2; i++ {
+		// Scalars that are read more than once are reported once.
+		BoolForScalarNodeInContext(context, "required", yes)
+		StringForScalarNodeInContext(context, "version", time)
+		StringForScalarNodeInContext(context, "description", quoted)
+	}
+	coercions := YAML11Coercions(context)
+	if len(coercions) != 1 || coercions[0].Error() != "[1,1] $root.required read yes as the YAML 1.1 boolean true" {
+		t.Errorf("unexpected coercions %v", coercions)
+	}
+	ambiguities := YAML11AmbiguousScalars(context)
+	if len(ambiguities) != 1 ||
+		ambiguities[0].Error() != "[1,1] $root.version read 1:30 as a string, but YAML 1.1 reads it as the number 90; quote it to keep it a string" {
+		t.Errorf("unexpected ambiguous scalars %v", ambiguities)
+	}
+}
+
+func TestSetYAML11Compat(t *testing.T) {
+	yes := yaml11TestNode(t, "yes")
+	// Compilations started with NewContext can read YAML 1.1 scalars too.
+	context := NewContext("$root", nil, nil)
+	SetYAML11Compat(context, true)
+	if b, ok := BoolForScalarNodeInContext(NewContext("info", nil, context), "required", yes); !ok || !b {
+		t.Errorf("expected yes to be the boolean true")
+	}
+	if coercions := YAML11Coercions(context); len(coercions) != 1 {
+		t.Errorf("expected 1 coercion, got %v", coercions)
+	}
+	// Other compilations are unaffected.
+	if _, ok := BoolForScalarNodeInContext(NewContext("$root", nil, nil), "required", yes); ok {
+		t.Errorf("expected yes not to be a boolean in another compilation")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// KeyOrder is the order in which the keys of mappings are written as YAML.
+type KeyOrder int
+
+const (
+	// KeyOrderModel writes keys in the order of the fields of the model that the YAML
+	// is produced from, which for OpenAPI documents is the order of the specification.
+	KeyOrderModel KeyOrder = iota
+	// KeyOrderSorted writes the keys of every mapping in sorted order.
+	KeyOrderSorted
+)
+
+// YAMLOptions control the YAML that is written by EncodeYAML and RenderYAML.
+// The zero value writes YAML like yaml.Marshal.
+type YAMLOptions struct {
+	// Indent is the number of spaces of each level of indentation. It is four if it is zero.
+	Indent int
+	// Comment is written at the top of the YAML. Each of its lines is prefixed with "# ".
+	Comment string
+	// KeyOrder is the order of the keys of mappings.
+	KeyOrder KeyOrder
+}
+
+// EncodeYAML writes the YAML representation of a node to w. Aliases are expanded, since
+// several YAML parsers reject them, so every value is written in full.
+func EncodeYAML(w io.Writer, node *yaml.Node, options YAMLOptions) error {
+	node, err := ExpandAliases(node)
+	if err != nil {
+		return err
+	}
+	if options.KeyOrder == KeyOrderSorted {
+		node = sortedKeys(node)
+	}
+	if node.Kind != yaml.DocumentNode {
+		node = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+	}
+	if options.Comment != "" {
+		document := *node
+		document.HeadComment = options.Comment
+		node = &document
+	}
+	encoder := yaml.NewEncoder(w)
+	if options.Indent > 0 {
+		encoder.SetIndent(options.Indent)
+	}
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// RenderYAML returns the YAML representation of a node like EncodeYAML.
+func RenderYAML(node *yaml.Node, options YAMLOptions) ([]byte, error) {
+	var b bytes.Buffer
+	if err := EncodeYAML(&b, node, options); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// sortedKeys copies a node with the keys of its mappings and of the mappings that it
+// contains in sorted order. The node itself isn't changed.
+func sortedKeys(node *yaml.Node) *yaml.Node {
+	if node == nil || len(node.Content) == 0 {
+		return node
+	}
+	copied := *node
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = sortedKeys(child)
+	}
+	if copied.Kind == yaml.MappingNode {
+		pairs := make([][2]*yaml.Node, 0, len(copied.Content)/2)
+		for i := 0; i+1 < len(copied.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{copied.Content[i], copied.Content[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool { return pairs[i][0].Value < pairs[j][0].Value })
+		for i, pair := range pairs {
+			copied.Content[2*i] = pair[0]
+			copied.Content[2*i+1] = pair[1]
+		}
+	}
+	return &copied
+}
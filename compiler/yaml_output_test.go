@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiler
+
+import (
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestRenderYAML(t *testing.T) {
+	var info yaml.Node
+	if err := yaml.Unmarshal([]byte("title: Books\ninfo: &info\n  version: 1.0.0\n  name: books\ncopy: *info\n"), &info); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		options  YAMLOptions
+		expected string
+	}{
+		{
+			YAMLOptions{},
+			"title: Books\ninfo:\n    version: 1.0.0\n    name: books\ncopy:\n    version: 1.0.0\n    name: books\n",
+		},
+		{
+			YAMLOptions{Indent: 2, Comment: "Generated\nby a test"},
+			"# Generated\n# by a test\n\ntitle: Books\ninfo:\n  version: 1.0.0\n  name: books\ncopy:\n  version: 1.0.0\n  name: books\n",
+		},
+		{
+			YAMLOptions{KeyOrder: KeyOrderSorted},
+			"copy:\n    name: books\n    version: 1.0.0\ninfo:\n    name: books\n    version: 1.0.0\ntitle: Books\n",
+		},
+	} {
+		bytes, err := RenderYAML(&info, test.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bytes) != test.expected {
+			t.Errorf("unexpected output for %+v:\n%s\n(expected\n%s)", test.options, bytes, test.expected)
+		}
+	}
+	// The node is unchanged.
+	bytes, err := yaml.Marshal(&info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bytes) != "title: Books\ninfo: &info\n    version: 1.0.0\n    name: books\ncopy: *info\n" {
+		t.Errorf("RenderYAML changed its input:\n%s", bytes)
+	}
+}
+
+func TestRenderYAMLMatchesMarshal(t *testing.T) {
+	// With the zero options, the output is the output of yaml.Marshal.
+	root := NewMappingNode()
+	root.Content = append(root.Content, NewScalarNodeForString("openapi"), NewScalarNodeForString("3.0.0"))
+	expected, err := yaml.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytes, err := RenderYAML(root, YAMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bytes) != string(expected) {
+		t.Errorf("unexpected output:\n%s\n(expected\n%s)", bytes, expected)
+	}
+}
@@ -42,11 +42,7 @@ func NewAnnotations(in *yaml.Node, context *compiler.Context) (*Annotations, err
 	} else {
 		allowedKeys := []string{"required"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated string required = 1;
 		v1 := compiler.MapValueForKey(m, "required")
 		if v1 != nil {
@@ -82,11 +78,7 @@ func NewAuth(in *yaml.Node, context *compiler.Context) (*Auth, error) {
 	} else {
 		allowedKeys := []string{"oauth2"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// Oauth2 oauth2 = 1;
 		v1 := compiler.MapValueForKey(m, "oauth2")
 		if v1 != nil {
@@ -117,84 +109,107 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 		}
 		allowedKeys := []string{"auth", "basePath", "baseUrl", "batchPath", "canonicalName", "description", "discoveryVersion", "documentationLink", "etag", "features", "fullyEncodeReservedExpansion", "icons", "id", "kind", "labels", "methods", "mtlsRootUrl", "name", "ownerDomain", "ownerName", "packagePath", "parameters", "protocol", "resources", "revision", "rootUrl", "schemas", "servicePath", "title", "version", "version_module"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string kind = 1;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v1 := compiler.MapValueForKey(m, "kind")
 		if v1 != nil {
-			x.Kind, ok = compiler.StringForScalarNode(v1)
+			x.Kind, ok = compiler.StringForScalarNodeInContext(context, "kind", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for kind: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string discovery_version = 2;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v2 := compiler.MapValueForKey(m, "discoveryVersion")
 		if v2 != nil {
-			x.DiscoveryVersion, ok = compiler.StringForScalarNode(v2)
+			x.DiscoveryVersion, ok = compiler.StringForScalarNodeInContext(context, "discoveryVersion", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for discoveryVersion: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string id = 3;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v3 := compiler.MapValueForKey(m, "id")
 		if v3 != nil {
-			x.Id, ok = compiler.StringForScalarNode(v3)
+			x.Id, ok = compiler.StringForScalarNodeInContext(context, "id", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for id: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string name = 4;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v4 := compiler.MapValueForKey(m, "name")
 		if v4 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v4)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string version = 5;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v5 := compiler.MapValueForKey(m, "version")
 		if v5 != nil {
-			x.Version, ok = compiler.StringForScalarNode(v5)
+			x.Version, ok = compiler.StringForScalarNodeInContext(context, "version", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for version: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string revision = 6;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v6 := compiler.MapValueForKey(m, "revision")
 		if v6 != nil {
-			x.Revision, ok = compiler.StringForScalarNode(v6)
+			x.Revision, ok = compiler.StringForScalarNodeInContext(context, "revision", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for revision: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string title = 7;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v7 := compiler.MapValueForKey(m, "title")
 		if v7 != nil {
-			x.Title, ok = compiler.StringForScalarNode(v7)
+			x.Title, ok = compiler.StringForScalarNodeInContext(context, "title", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for title: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string description = 8;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v8 := compiler.MapValueForKey(m, "description")
 		if v8 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v8)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// Icons icons = 9;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v9 := compiler.MapValueForKey(m, "icons")
 		if v9 != nil {
 			var err error
@@ -204,15 +219,21 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// string documentation_link = 10;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v10 := compiler.MapValueForKey(m, "documentationLink")
 		if v10 != nil {
-			x.DocumentationLink, ok = compiler.StringForScalarNode(v10)
+			x.DocumentationLink, ok = compiler.StringForScalarNodeInContext(context, "documentationLink", v10)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for documentationLink: %s", compiler.Display(v10))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// repeated string labels = 11;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v11 := compiler.MapValueForKey(m, "labels")
 		if v11 != nil {
 			v, ok := compiler.SequenceNodeForNode(v11)
@@ -224,60 +245,81 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// string protocol = 12;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v12 := compiler.MapValueForKey(m, "protocol")
 		if v12 != nil {
-			x.Protocol, ok = compiler.StringForScalarNode(v12)
+			x.Protocol, ok = compiler.StringForScalarNodeInContext(context, "protocol", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for protocol: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string base_url = 13;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v13 := compiler.MapValueForKey(m, "baseUrl")
 		if v13 != nil {
-			x.BaseUrl, ok = compiler.StringForScalarNode(v13)
+			x.BaseUrl, ok = compiler.StringForScalarNodeInContext(context, "baseUrl", v13)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for baseUrl: %s", compiler.Display(v13))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string base_path = 14;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v14 := compiler.MapValueForKey(m, "basePath")
 		if v14 != nil {
-			x.BasePath, ok = compiler.StringForScalarNode(v14)
+			x.BasePath, ok = compiler.StringForScalarNodeInContext(context, "basePath", v14)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for basePath: %s", compiler.Display(v14))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string root_url = 15;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v15 := compiler.MapValueForKey(m, "rootUrl")
 		if v15 != nil {
-			x.RootUrl, ok = compiler.StringForScalarNode(v15)
+			x.RootUrl, ok = compiler.StringForScalarNodeInContext(context, "rootUrl", v15)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for rootUrl: %s", compiler.Display(v15))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string service_path = 16;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v16 := compiler.MapValueForKey(m, "servicePath")
 		if v16 != nil {
-			x.ServicePath, ok = compiler.StringForScalarNode(v16)
+			x.ServicePath, ok = compiler.StringForScalarNodeInContext(context, "servicePath", v16)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for servicePath: %s", compiler.Display(v16))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string batch_path = 17;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v17 := compiler.MapValueForKey(m, "batchPath")
 		if v17 != nil {
-			x.BatchPath, ok = compiler.StringForScalarNode(v17)
+			x.BatchPath, ok = compiler.StringForScalarNodeInContext(context, "batchPath", v17)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for batchPath: %s", compiler.Display(v17))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// Parameters parameters = 18;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v18 := compiler.MapValueForKey(m, "parameters")
 		if v18 != nil {
 			var err error
@@ -287,6 +329,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Auth auth = 19;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v19 := compiler.MapValueForKey(m, "auth")
 		if v19 != nil {
 			var err error
@@ -296,6 +341,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated string features = 20;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v20 := compiler.MapValueForKey(m, "features")
 		if v20 != nil {
 			v, ok := compiler.SequenceNodeForNode(v20)
@@ -307,6 +355,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Schemas schemas = 21;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v21 := compiler.MapValueForKey(m, "schemas")
 		if v21 != nil {
 			var err error
@@ -316,6 +367,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Methods methods = 22;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v22 := compiler.MapValueForKey(m, "methods")
 		if v22 != nil {
 			var err error
@@ -325,6 +379,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Resources resources = 23;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v23 := compiler.MapValueForKey(m, "resources")
 		if v23 != nil {
 			var err error
@@ -334,72 +391,96 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// string etag = 24;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v24 := compiler.MapValueForKey(m, "etag")
 		if v24 != nil {
-			x.Etag, ok = compiler.StringForScalarNode(v24)
+			x.Etag, ok = compiler.StringForScalarNodeInContext(context, "etag", v24)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for etag: %s", compiler.Display(v24))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string owner_domain = 25;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v25 := compiler.MapValueForKey(m, "ownerDomain")
 		if v25 != nil {
-			x.OwnerDomain, ok = compiler.StringForScalarNode(v25)
+			x.OwnerDomain, ok = compiler.StringForScalarNodeInContext(context, "ownerDomain", v25)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for ownerDomain: %s", compiler.Display(v25))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string owner_name = 26;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v26 := compiler.MapValueForKey(m, "ownerName")
 		if v26 != nil {
-			x.OwnerName, ok = compiler.StringForScalarNode(v26)
+			x.OwnerName, ok = compiler.StringForScalarNodeInContext(context, "ownerName", v26)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for ownerName: %s", compiler.Display(v26))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// bool version_module = 27;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v27 := compiler.MapValueForKey(m, "version_module")
 		if v27 != nil {
-			x.VersionModule, ok = compiler.BoolForScalarNode(v27)
+			x.VersionModule, ok = compiler.BoolForScalarNodeInContext(context, "version_module", v27)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for version_module: %s", compiler.Display(v27))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string canonical_name = 28;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v28 := compiler.MapValueForKey(m, "canonicalName")
 		if v28 != nil {
-			x.CanonicalName, ok = compiler.StringForScalarNode(v28)
+			x.CanonicalName, ok = compiler.StringForScalarNodeInContext(context, "canonicalName", v28)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for canonicalName: %s", compiler.Display(v28))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// bool fully_encode_reserved_expansion = 29;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v29 := compiler.MapValueForKey(m, "fullyEncodeReservedExpansion")
 		if v29 != nil {
-			x.FullyEncodeReservedExpansion, ok = compiler.BoolForScalarNode(v29)
+			x.FullyEncodeReservedExpansion, ok = compiler.BoolForScalarNodeInContext(context, "fullyEncodeReservedExpansion", v29)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for fullyEncodeReservedExpansion: %s", compiler.Display(v29))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string package_path = 30;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v30 := compiler.MapValueForKey(m, "packagePath")
 		if v30 != nil {
-			x.PackagePath, ok = compiler.StringForScalarNode(v30)
+			x.PackagePath, ok = compiler.StringForScalarNodeInContext(context, "packagePath", v30)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for packagePath: %s", compiler.Display(v30))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string mtls_root_url = 31;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v31 := compiler.MapValueForKey(m, "mtlsRootUrl")
 		if v31 != nil {
-			x.MtlsRootUrl, ok = compiler.StringForScalarNode(v31)
+			x.MtlsRootUrl, ok = compiler.StringForScalarNodeInContext(context, "mtlsRootUrl", v31)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for mtlsRootUrl: %s", compiler.Display(v31))
 				errors = append(errors, compiler.NewError(context, message))
@@ -426,15 +507,11 @@ func NewIcons(in *yaml.Node, context *compiler.Context) (*Icons, error) {
 		}
 		allowedKeys := []string{"x16", "x32"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string x16 = 1;
 		v1 := compiler.MapValueForKey(m, "x16")
 		if v1 != nil {
-			x.X16, ok = compiler.StringForScalarNode(v1)
+			x.X16, ok = compiler.StringForScalarNodeInContext(context, "x16", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for x16: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -443,7 +520,7 @@ func NewIcons(in *yaml.Node, context *compiler.Context) (*Icons, error) {
 		// string x32 = 2;
 		v2 := compiler.MapValueForKey(m, "x32")
 		if v2 != nil {
-			x.X32, ok = compiler.StringForScalarNode(v2)
+			x.X32, ok = compiler.StringForScalarNodeInContext(context, "x32", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for x32: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -464,11 +541,7 @@ func NewMediaUpload(in *yaml.Node, context *compiler.Context) (*MediaUpload, err
 	} else {
 		allowedKeys := []string{"accept", "maxSize", "protocols", "supportsSubscription"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated string accept = 1;
 		v1 := compiler.MapValueForKey(m, "accept")
 		if v1 != nil {
@@ -483,7 +556,7 @@ func NewMediaUpload(in *yaml.Node, context *compiler.Context) (*MediaUpload, err
 		// string max_size = 2;
 		v2 := compiler.MapValueForKey(m, "maxSize")
 		if v2 != nil {
-			x.MaxSize, ok = compiler.StringForScalarNode(v2)
+			x.MaxSize, ok = compiler.StringForScalarNodeInContext(context, "maxSize", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for maxSize: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -501,7 +574,7 @@ func NewMediaUpload(in *yaml.Node, context *compiler.Context) (*MediaUpload, err
 		// bool supports_subscription = 4;
 		v4 := compiler.MapValueForKey(m, "supportsSubscription")
 		if v4 != nil {
-			x.SupportsSubscription, ok = compiler.BoolForScalarNode(v4)
+			x.SupportsSubscription, ok = compiler.BoolForScalarNodeInContext(context, "supportsSubscription", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for supportsSubscription: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -522,15 +595,11 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 	} else {
 		allowedKeys := []string{"description", "etagRequired", "flatPath", "httpMethod", "id", "mediaUpload", "parameterOrder", "parameters", "path", "request", "response", "scopes", "streamingType", "supportsMediaDownload", "supportsMediaUpload", "supportsSubscription", "useMediaDownloadService"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string id = 1;
 		v1 := compiler.MapValueForKey(m, "id")
 		if v1 != nil {
-			x.Id, ok = compiler.StringForScalarNode(v1)
+			x.Id, ok = compiler.StringForScalarNodeInContext(context, "id", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for id: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -539,7 +608,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// string path = 2;
 		v2 := compiler.MapValueForKey(m, "path")
 		if v2 != nil {
-			x.Path, ok = compiler.StringForScalarNode(v2)
+			x.Path, ok = compiler.StringForScalarNodeInContext(context, "path", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for path: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -548,7 +617,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// string http_method = 3;
 		v3 := compiler.MapValueForKey(m, "httpMethod")
 		if v3 != nil {
-			x.HttpMethod, ok = compiler.StringForScalarNode(v3)
+			x.HttpMethod, ok = compiler.StringForScalarNodeInContext(context, "httpMethod", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for httpMethod: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -557,7 +626,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// string description = 4;
 		v4 := compiler.MapValueForKey(m, "description")
 		if v4 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v4)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -615,7 +684,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// bool supports_media_download = 10;
 		v10 := compiler.MapValueForKey(m, "supportsMediaDownload")
 		if v10 != nil {
-			x.SupportsMediaDownload, ok = compiler.BoolForScalarNode(v10)
+			x.SupportsMediaDownload, ok = compiler.BoolForScalarNodeInContext(context, "supportsMediaDownload", v10)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for supportsMediaDownload: %s", compiler.Display(v10))
 				errors = append(errors, compiler.NewError(context, message))
@@ -624,7 +693,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// bool supports_media_upload = 11;
 		v11 := compiler.MapValueForKey(m, "supportsMediaUpload")
 		if v11 != nil {
-			x.SupportsMediaUpload, ok = compiler.BoolForScalarNode(v11)
+			x.SupportsMediaUpload, ok = compiler.BoolForScalarNodeInContext(context, "supportsMediaUpload", v11)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for supportsMediaUpload: %s", compiler.Display(v11))
 				errors = append(errors, compiler.NewError(context, message))
@@ -633,7 +702,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// bool use_media_download_service = 12;
 		v12 := compiler.MapValueForKey(m, "useMediaDownloadService")
 		if v12 != nil {
-			x.UseMediaDownloadService, ok = compiler.BoolForScalarNode(v12)
+			x.UseMediaDownloadService, ok = compiler.BoolForScalarNodeInContext(context, "useMediaDownloadService", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for useMediaDownloadService: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
@@ -651,7 +720,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// bool supports_subscription = 14;
 		v14 := compiler.MapValueForKey(m, "supportsSubscription")
 		if v14 != nil {
-			x.SupportsSubscription, ok = compiler.BoolForScalarNode(v14)
+			x.SupportsSubscription, ok = compiler.BoolForScalarNodeInContext(context, "supportsSubscription", v14)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for supportsSubscription: %s", compiler.Display(v14))
 				errors = append(errors, compiler.NewError(context, message))
@@ -660,7 +729,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// string flat_path = 15;
 		v15 := compiler.MapValueForKey(m, "flatPath")
 		if v15 != nil {
-			x.FlatPath, ok = compiler.StringForScalarNode(v15)
+			x.FlatPath, ok = compiler.StringForScalarNodeInContext(context, "flatPath", v15)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for flatPath: %s", compiler.Display(v15))
 				errors = append(errors, compiler.NewError(context, message))
@@ -669,7 +738,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// bool etag_required = 16;
 		v16 := compiler.MapValueForKey(m, "etagRequired")
 		if v16 != nil {
-			x.EtagRequired, ok = compiler.BoolForScalarNode(v16)
+			x.EtagRequired, ok = compiler.BoolForScalarNodeInContext(context, "etagRequired", v16)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for etagRequired: %s", compiler.Display(v16))
 				errors = append(errors, compiler.NewError(context, message))
@@ -678,7 +747,7 @@ func NewMethod(in *yaml.Node, context *compiler.Context) (*Method, error) {
 		// string streaming_type = 17;
 		v17 := compiler.MapValueForKey(m, "streamingType")
 		if v17 != nil {
-			x.StreamingType, ok = compiler.StringForScalarNode(v17)
+			x.StreamingType, ok = compiler.StringForScalarNodeInContext(context, "streamingType", v17)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for streamingType: %s", compiler.Display(v17))
 				errors = append(errors, compiler.NewError(context, message))
@@ -729,15 +798,11 @@ func NewNamedMethod(in *yaml.Node, context *compiler.Context) (*NamedMethod, err
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -767,15 +832,11 @@ func NewNamedParameter(in *yaml.Node, context *compiler.Context) (*NamedParamete
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -805,15 +866,11 @@ func NewNamedResource(in *yaml.Node, context *compiler.Context) (*NamedResource,
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -843,15 +900,11 @@ func NewNamedSchema(in *yaml.Node, context *compiler.Context) (*NamedSchema, err
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -881,15 +934,11 @@ func NewNamedScope(in *yaml.Node, context *compiler.Context) (*NamedScope, error
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -919,11 +968,7 @@ func NewOauth2(in *yaml.Node, context *compiler.Context) (*Oauth2, error) {
 	} else {
 		allowedKeys := []string{"scopes"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// Scopes scopes = 1;
 		v1 := compiler.MapValueForKey(m, "scopes")
 		if v1 != nil {
@@ -948,15 +993,11 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 	} else {
 		allowedKeys := []string{"$ref", "additionalProperties", "annotations", "default", "description", "enum", "enumDescriptions", "format", "id", "items", "location", "maximum", "minimum", "pattern", "properties", "repeated", "required", "type"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string id = 1;
 		v1 := compiler.MapValueForKey(m, "id")
 		if v1 != nil {
-			x.Id, ok = compiler.StringForScalarNode(v1)
+			x.Id, ok = compiler.StringForScalarNodeInContext(context, "id", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for id: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -965,7 +1006,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string type = 2;
 		v2 := compiler.MapValueForKey(m, "type")
 		if v2 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v2)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -974,7 +1015,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string _ref = 3;
 		v3 := compiler.MapValueForKey(m, "$ref")
 		if v3 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v3)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -983,7 +1024,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string description = 4;
 		v4 := compiler.MapValueForKey(m, "description")
 		if v4 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v4)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -992,7 +1033,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string default = 5;
 		v5 := compiler.MapValueForKey(m, "default")
 		if v5 != nil {
-			x.Default, ok = compiler.StringForScalarNode(v5)
+			x.Default, ok = compiler.StringForScalarNodeInContext(context, "default", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for default: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1001,7 +1042,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// bool required = 6;
 		v6 := compiler.MapValueForKey(m, "required")
 		if v6 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v6)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1010,7 +1051,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string format = 7;
 		v7 := compiler.MapValueForKey(m, "format")
 		if v7 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v7)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1019,7 +1060,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string pattern = 8;
 		v8 := compiler.MapValueForKey(m, "pattern")
 		if v8 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v8)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1028,7 +1069,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string minimum = 9;
 		v9 := compiler.MapValueForKey(m, "minimum")
 		if v9 != nil {
-			x.Minimum, ok = compiler.StringForScalarNode(v9)
+			x.Minimum, ok = compiler.StringForScalarNodeInContext(context, "minimum", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for minimum: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1037,7 +1078,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string maximum = 10;
 		v10 := compiler.MapValueForKey(m, "maximum")
 		if v10 != nil {
-			x.Maximum, ok = compiler.StringForScalarNode(v10)
+			x.Maximum, ok = compiler.StringForScalarNodeInContext(context, "maximum", v10)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for maximum: %s", compiler.Display(v10))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1068,7 +1109,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// bool repeated = 13;
 		v13 := compiler.MapValueForKey(m, "repeated")
 		if v13 != nil {
-			x.Repeated, ok = compiler.BoolForScalarNode(v13)
+			x.Repeated, ok = compiler.BoolForScalarNodeInContext(context, "repeated", v13)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for repeated: %s", compiler.Display(v13))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1077,7 +1118,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string location = 14;
 		v14 := compiler.MapValueForKey(m, "location")
 		if v14 != nil {
-			x.Location, ok = compiler.StringForScalarNode(v14)
+			x.Location, ok = compiler.StringForScalarNodeInContext(context, "location", v14)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for location: %s", compiler.Display(v14))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1164,11 +1205,7 @@ func NewProtocols(in *yaml.Node, context *compiler.Context) (*Protocols, error)
 	} else {
 		allowedKeys := []string{"resumable", "simple"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// Simple simple = 1;
 		v1 := compiler.MapValueForKey(m, "simple")
 		if v1 != nil {
@@ -1202,15 +1239,11 @@ func NewRequest(in *yaml.Node, context *compiler.Context) (*Request, error) {
 	} else {
 		allowedKeys := []string{"$ref", "parameterName"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string _ref = 1;
 		v1 := compiler.MapValueForKey(m, "$ref")
 		if v1 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v1)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1219,7 +1252,7 @@ func NewRequest(in *yaml.Node, context *compiler.Context) (*Request, error) {
 		// string parameter_name = 2;
 		v2 := compiler.MapValueForKey(m, "parameterName")
 		if v2 != nil {
-			x.ParameterName, ok = compiler.StringForScalarNode(v2)
+			x.ParameterName, ok = compiler.StringForScalarNodeInContext(context, "parameterName", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for parameterName: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1240,11 +1273,7 @@ func NewResource(in *yaml.Node, context *compiler.Context) (*Resource, error) {
 	} else {
 		allowedKeys := []string{"methods", "resources"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// Methods methods = 1;
 		v1 := compiler.MapValueForKey(m, "methods")
 		if v1 != nil {
@@ -1308,15 +1337,11 @@ func NewResponse(in *yaml.Node, context *compiler.Context) (*Response, error) {
 	} else {
 		allowedKeys := []string{"$ref"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string _ref = 1;
 		v1 := compiler.MapValueForKey(m, "$ref")
 		if v1 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v1)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1337,15 +1362,11 @@ func NewResumable(in *yaml.Node, context *compiler.Context) (*Resumable, error)
 	} else {
 		allowedKeys := []string{"multipart", "path"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// bool multipart = 1;
 		v1 := compiler.MapValueForKey(m, "multipart")
 		if v1 != nil {
-			x.Multipart, ok = compiler.BoolForScalarNode(v1)
+			x.Multipart, ok = compiler.BoolForScalarNodeInContext(context, "multipart", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for multipart: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1354,7 +1375,7 @@ func NewResumable(in *yaml.Node, context *compiler.Context) (*Resumable, error)
 		// string path = 2;
 		v2 := compiler.MapValueForKey(m, "path")
 		if v2 != nil {
-			x.Path, ok = compiler.StringForScalarNode(v2)
+			x.Path, ok = compiler.StringForScalarNodeInContext(context, "path", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for path: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1375,15 +1396,11 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 	} else {
 		allowedKeys := []string{"$ref", "additionalProperties", "annotations", "default", "description", "enum", "enumDescriptions", "format", "id", "items", "location", "maximum", "minimum", "pattern", "properties", "readOnly", "repeated", "required", "type"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string id = 1;
 		v1 := compiler.MapValueForKey(m, "id")
 		if v1 != nil {
-			x.Id, ok = compiler.StringForScalarNode(v1)
+			x.Id, ok = compiler.StringForScalarNodeInContext(context, "id", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for id: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1392,7 +1409,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string type = 2;
 		v2 := compiler.MapValueForKey(m, "type")
 		if v2 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v2)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1401,7 +1418,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1410,7 +1427,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string default = 4;
 		v4 := compiler.MapValueForKey(m, "default")
 		if v4 != nil {
-			x.Default, ok = compiler.StringForScalarNode(v4)
+			x.Default, ok = compiler.StringForScalarNodeInContext(context, "default", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for default: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1419,7 +1436,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool required = 5;
 		v5 := compiler.MapValueForKey(m, "required")
 		if v5 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v5)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1428,7 +1445,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string format = 6;
 		v6 := compiler.MapValueForKey(m, "format")
 		if v6 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v6)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1437,7 +1454,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string pattern = 7;
 		v7 := compiler.MapValueForKey(m, "pattern")
 		if v7 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v7)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1446,7 +1463,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string minimum = 8;
 		v8 := compiler.MapValueForKey(m, "minimum")
 		if v8 != nil {
-			x.Minimum, ok = compiler.StringForScalarNode(v8)
+			x.Minimum, ok = compiler.StringForScalarNodeInContext(context, "minimum", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for minimum: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1455,7 +1472,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string maximum = 9;
 		v9 := compiler.MapValueForKey(m, "maximum")
 		if v9 != nil {
-			x.Maximum, ok = compiler.StringForScalarNode(v9)
+			x.Maximum, ok = compiler.StringForScalarNodeInContext(context, "maximum", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for maximum: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1486,7 +1503,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool repeated = 12;
 		v12 := compiler.MapValueForKey(m, "repeated")
 		if v12 != nil {
-			x.Repeated, ok = compiler.BoolForScalarNode(v12)
+			x.Repeated, ok = compiler.BoolForScalarNodeInContext(context, "repeated", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for repeated: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1495,7 +1512,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string location = 13;
 		v13 := compiler.MapValueForKey(m, "location")
 		if v13 != nil {
-			x.Location, ok = compiler.StringForScalarNode(v13)
+			x.Location, ok = compiler.StringForScalarNodeInContext(context, "location", v13)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for location: %s", compiler.Display(v13))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1531,7 +1548,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string _ref = 17;
 		v17 := compiler.MapValueForKey(m, "$ref")
 		if v17 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v17)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v17)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v17))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1549,7 +1566,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool read_only = 19;
 		v19 := compiler.MapValueForKey(m, "readOnly")
 		if v19 != nil {
-			x.ReadOnly, ok = compiler.BoolForScalarNode(v19)
+			x.ReadOnly, ok = compiler.BoolForScalarNodeInContext(context, "readOnly", v19)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for readOnly: %s", compiler.Display(v19))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1600,15 +1617,11 @@ func NewScope(in *yaml.Node, context *compiler.Context) (*Scope, error) {
 	} else {
 		allowedKeys := []string{"description"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1659,15 +1672,11 @@ func NewSimple(in *yaml.Node, context *compiler.Context) (*Simple, error) {
 	} else {
 		allowedKeys := []string{"multipart", "path"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// bool multipart = 1;
 		v1 := compiler.MapValueForKey(m, "multipart")
 		if v1 != nil {
-			x.Multipart, ok = compiler.BoolForScalarNode(v1)
+			x.Multipart, ok = compiler.BoolForScalarNodeInContext(context, "multipart", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for multipart: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1676,7 +1685,7 @@ func NewSimple(in *yaml.Node, context *compiler.Context) (*Simple, error) {
 		// string path = 2;
 		v2 := compiler.MapValueForKey(m, "path")
 		if v2 != nil {
-			x.Path, ok = compiler.StringForScalarNode(v2)
+			x.Path, ok = compiler.StringForScalarNodeInContext(context, "path", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for path: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -16,6 +16,7 @@ package discovery_v1
 
 import (
 	"io/ioutil"
+	"regexp"
 	"testing"
 )
 
@@ -71,3 +72,41 @@ func TestParseDocument_Empty(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterAPIs(t *testing.T) {
+	list := &List{APIs: []*API{
+		{Name: "pubsub", Version: "v1beta2"},
+		{Name: "pubsub", Version: "v1", Preferred: true},
+		{Name: "pubsublite", Version: "v1", Preferred: true},
+		{Name: "storage", Version: "v1", Preferred: true},
+	}}
+	for _, test := range []struct {
+		name          string
+		nameFilter    *regexp.Regexp
+		preferredOnly bool
+		expected      []string
+	}{
+		{"all", nil, false, []string{"pubsub/v1beta2", "pubsub/v1", "pubsublite/v1", "storage/v1"}},
+		{"preferred", nil, true, []string{"pubsub/v1", "pubsublite/v1", "storage/v1"}},
+		{"name", regexp.MustCompile("^pubsub"), false, []string{"pubsub/v1beta2", "pubsub/v1", "pubsublite/v1"}},
+		{"name and preferred", regexp.MustCompile("^pubsub$"), true, []string{"pubsub/v1"}},
+		{"no match", regexp.MustCompile("compute"), false, []string{}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			apis := list.FilterAPIs(test.nameFilter, test.preferredOnly)
+			names := make([]string, len(apis))
+			for i, api := range apis {
+				names[i] = api.Name + "/" + api.Version
+			}
+			if len(names) != len(test.expected) {
+				t.Fatalf("unexpected APIs %v (expected %v)", names, test.expected)
+			}
+			for i := range names {
+				if names[i] != test.expected[i] {
+					t.Errorf("unexpected APIs %v (expected %v)", names, test.expected)
+					break
+				}
+			}
+		})
+	}
+}
@@ -15,9 +15,12 @@
 package discovery_v1
 
 import (
+	"context"
 	"errors"
+	"io"
 
 	"github.com/google/gnostic/compiler"
+	"go.yaml.in/yaml/v3"
 )
 
 // FetchDocumentBytes downloads the bytes of a discovery document from a URL.
@@ -25,17 +28,57 @@ func FetchDocumentBytes(documentURL string) ([]byte, error) {
 	return compiler.FetchFile(documentURL)
 }
 
+// FetchDocumentBytesContext is like FetchDocumentBytes, but it stops with ctx.Err() when ctx is done.
+func FetchDocumentBytesContext(ctx context.Context, documentURL string) ([]byte, error) {
+	return compiler.FetchFileContext(ctx, documentURL)
+}
+
 // ParseDocument reads a Discovery description from a YAML/JSON representation.
 func ParseDocument(b []byte) (*Document, error) {
-	info, err := compiler.ReadInfoFromBytes("", b)
+	return ParseDocumentContext(context.Background(), b)
+}
+
+// ParseDocumentContext is like ParseDocument, but it stops with ctx.Err() when ctx is done.
+func ParseDocumentContext(ctx context.Context, b []byte) (*Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	info, err := compiler.ReadInfoFromBytesContext(ctx, "", b)
 	if err != nil {
 		return nil, err
 	}
+	return parseInfo(ctx, info)
+}
+
+// ParseDocumentFromReader reads a Discovery description from a YAML/JSON representation,
+// parsing it as it is read from r.
+func ParseDocumentFromReader(r io.Reader) (*Document, error) {
+	return ParseDocumentFromReaderContext(context.Background(), r)
+}
 
+// ParseDocumentFromReaderContext is like ParseDocumentFromReader, but it stops with ctx.Err() when ctx is done.
+func ParseDocumentFromReaderContext(ctx context.Context, r io.Reader) (*Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	info, err := compiler.ReadInfoFromReaderContext(ctx, "", r)
+	if err != nil {
+		return nil, err
+	}
+	return parseInfo(ctx, info)
+}
+
+func parseInfo(ctx context.Context, info *yaml.Node) (*Document, error) {
 	if len(info.Content) < 1 {
 		return nil, errors.New("document has no content")
 	}
 
 	root := info.Content[0]
-	return NewDocument(root, compiler.NewContext("$root", root, nil))
+	rootContext := compiler.NewContextWithCancellation(ctx, "$root", root, nil, nil, nil)
+	defer compiler.RemoveExtensionConfig(rootContext)
+	document, err := NewDocument(root, rootContext)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return document, err
 }
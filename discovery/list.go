@@ -15,8 +15,10 @@
 package discovery_v1
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"regexp"
 	"strings"
 
 	"github.com/google/gnostic/compiler"
@@ -37,9 +39,19 @@ func FetchListBytes() ([]byte, error) {
 	return compiler.FetchFile(APIsListServiceURL)
 }
 
+// FetchListBytesContext is like FetchListBytes, but it stops with ctx.Err() when ctx is done.
+func FetchListBytesContext(ctx context.Context) ([]byte, error) {
+	return compiler.FetchFileContext(ctx, APIsListServiceURL)
+}
+
 // Read the list of APIs from the apis/list service.
 func FetchList() (*List, error) {
-	bytes, err := FetchListBytes()
+	return FetchListContext(context.Background())
+}
+
+// FetchListContext is like FetchList, but it stops with ctx.Err() when ctx is done.
+func FetchListContext(ctx context.Context) (*List, error) {
+	bytes, err := FetchListBytesContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -92,3 +104,20 @@ func (a *List) APIWithNameAndVersion(name string, version string) (*API, error)
 		return api, nil
 	}
 }
+
+// FilterAPIs returns the APIs whose names match nameFilter, in the order of the list.
+// A nil nameFilter matches every name. If preferredOnly is true, only the preferred
+// version of each API is returned.
+func (a *List) FilterAPIs(nameFilter *regexp.Regexp, preferredOnly bool) []*API {
+	apis := make([]*API, 0)
+	for _, item := range a.APIs {
+		if nameFilter != nil && !nameFilter.MatchString(item.Name) {
+			continue
+		}
+		if preferredOnly && !item.Preferred {
+			continue
+		}
+		apis = append(apis, item)
+	}
+	return apis
+}
@@ -0,0 +1,520 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        (unknown)
+// source: extensions/batch.proto
+
+package gnostic_extension_v1
+
+import (
+	extensions "github.com/google/gnostic-models/extensions"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// An encoded ExtensionHandlerBatchRequest is written to the ExtensionHandler's
+// stdin when the compiler has several extensions for a handler. It is also
+// used without wrappers or a batch version to send a single extension with
+// its location.
+//
+// Its first two fields match ExtensionHandlerRequest, so a handler that
+// predates batching reads it as a request for the first extension and replies
+// with an ExtensionHandlerResponse. The compiler detects this and sends the
+// remaining extensions one at a time.
+type ExtensionHandlerBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The first extension to process.
+	Wrapper *extensions.Wrapper `protobuf:"bytes,1,opt,name=wrapper,proto3" json:"wrapper,omitempty"`
+	// The version number of Gnostic.
+	CompilerVersion *extensions.Version `protobuf:"bytes,2,opt,name=compiler_version,json=compilerVersion,proto3" json:"compiler_version,omitempty"`
+	// All of the extensions to process, including the first.
+	Wrappers []*extensions.Wrapper `protobuf:"bytes,3,rep,name=wrappers,proto3" json:"wrappers,omitempty"`
+	// The version of the batch protocol used by the compiler.
+	BatchVersion int32 `protobuf:"varint,4,opt,name=batch_version,json=batchVersion,proto3" json:"batch_version,omitempty"`
+	// The location of the first extension.
+	Location *ExtensionLocation `protobuf:"bytes,5,opt,name=location,proto3" json:"location,omitempty"`
+	// The locations of all of the extensions, in the same order as the wrappers.
+	Locations []*ExtensionLocation `protobuf:"bytes,6,rep,name=locations,proto3" json:"locations,omitempty"`
+	// The protocol version and optional features of the compiler.
+	Capabilities  *Capabilities `protobuf:"bytes,7,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtensionHandlerBatchRequest) Reset() {
+	*x = ExtensionHandlerBatchRequest{}
+	mi := &file_extensions_batch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtensionHandlerBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtensionHandlerBatchRequest) ProtoMessage() {}
+
+func (x *ExtensionHandlerBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_extensions_batch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtensionHandlerBatchRequest.ProtoReflect.Descriptor instead.
+func (*ExtensionHandlerBatchRequest) Descriptor() ([]byte, []int) {
+	return file_extensions_batch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExtensionHandlerBatchRequest) GetWrapper() *extensions.Wrapper {
+	if x != nil {
+		return x.Wrapper
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerBatchRequest) GetCompilerVersion() *extensions.Version {
+	if x != nil {
+		return x.CompilerVersion
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerBatchRequest) GetWrappers() []*extensions.Wrapper {
+	if x != nil {
+		return x.Wrappers
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerBatchRequest) GetBatchVersion() int32 {
+	if x != nil {
+		return x.BatchVersion
+	}
+	return 0
+}
+
+func (x *ExtensionHandlerBatchRequest) GetLocation() *ExtensionLocation {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerBatchRequest) GetLocations() []*ExtensionLocation {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerBatchRequest) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+// A handler writes an encoded ExtensionHandlerSingleResponse to stdout in reply
+// to a request for a single extension.
+//
+// Its first three fields match ExtensionHandlerResponse, so compilers that
+// predate it read it as an ExtensionHandlerResponse.
+type ExtensionHandlerSingleResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// true if the extension is handled by the extension handler; false otherwise
+	Handled bool `protobuf:"varint,1,opt,name=handled,proto3" json:"handled,omitempty"`
+	// Error message(s). If non-empty, the extension handling failed.
+	// See ExtensionHandlerResponse.
+	Errors []string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	// text output
+	Value *anypb.Any `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// The protocol version and optional features of the handler.
+	// Empty if the handler predates capabilities.
+	Capabilities *Capabilities `protobuf:"bytes,7,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	// Warnings about the extension, if the handler accepted it. Field numbers
+	// 4-6 are not used so that this message can't be confused with an
+	// ExtensionHandlerBatchResponse.
+	Warnings      []string `protobuf:"bytes,8,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtensionHandlerSingleResponse) Reset() {
+	*x = ExtensionHandlerSingleResponse{}
+	mi := &file_extensions_batch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtensionHandlerSingleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtensionHandlerSingleResponse) ProtoMessage() {}
+
+func (x *ExtensionHandlerSingleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_extensions_batch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtensionHandlerSingleResponse.ProtoReflect.Descriptor instead.
+func (*ExtensionHandlerSingleResponse) Descriptor() ([]byte, []int) {
+	return file_extensions_batch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExtensionHandlerSingleResponse) GetHandled() bool {
+	if x != nil {
+		return x.Handled
+	}
+	return false
+}
+
+func (x *ExtensionHandlerSingleResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerSingleResponse) GetValue() *anypb.Any {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerSingleResponse) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerSingleResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// A handler that supports batching writes an encoded
+// ExtensionHandlerBatchResponse to stdout in reply to a batch request.
+type ExtensionHandlerBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One response for each of the request's wrappers, in the same order.
+	// Field numbers 1-3 are not used so that this message can't be confused
+	// with an ExtensionHandlerResponse.
+	Responses []*extensions.ExtensionHandlerResponse `protobuf:"bytes,4,rep,name=responses,proto3" json:"responses,omitempty"`
+	// The version of the batch protocol used by the handler.
+	// Zero when the reply was written by a handler that doesn't support batching.
+	BatchVersion int32 `protobuf:"varint,5,opt,name=batch_version,json=batchVersion,proto3" json:"batch_version,omitempty"`
+	// The warnings for each of the responses, in the same order. It may be
+	// shorter than the list of responses if the last responses have none.
+	Warnings []*ExtensionWarnings `protobuf:"bytes,6,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// The protocol version and optional features of the handler.
+	// Empty if the handler predates capabilities.
+	Capabilities  *Capabilities `protobuf:"bytes,7,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtensionHandlerBatchResponse) Reset() {
+	*x = ExtensionHandlerBatchResponse{}
+	mi := &file_extensions_batch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtensionHandlerBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtensionHandlerBatchResponse) ProtoMessage() {}
+
+func (x *ExtensionHandlerBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_extensions_batch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtensionHandlerBatchResponse.ProtoReflect.Descriptor instead.
+func (*ExtensionHandlerBatchResponse) Descriptor() ([]byte, []int) {
+	return file_extensions_batch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExtensionHandlerBatchResponse) GetResponses() []*extensions.ExtensionHandlerResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerBatchResponse) GetBatchVersion() int32 {
+	if x != nil {
+		return x.BatchVersion
+	}
+	return 0
+}
+
+func (x *ExtensionHandlerBatchResponse) GetWarnings() []*ExtensionWarnings {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *ExtensionHandlerBatchResponse) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+// The location of an extension in the document being compiled.
+type ExtensionLocation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path of the extension, in the form used by compiler errors, e.g.
+	// "$root.paths./pets.get.x-rate-limit".
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The kind of object that contains the extension, e.g. "Operation".
+	// Empty if it is not known.
+	ObjectKind    string `protobuf:"bytes,2,opt,name=object_kind,json=objectKind,proto3" json:"object_kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtensionLocation) Reset() {
+	*x = ExtensionLocation{}
+	mi := &file_extensions_batch_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtensionLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtensionLocation) ProtoMessage() {}
+
+func (x *ExtensionLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_extensions_batch_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtensionLocation.ProtoReflect.Descriptor instead.
+func (*ExtensionLocation) Descriptor() ([]byte, []int) {
+	return file_extensions_batch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExtensionLocation) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ExtensionLocation) GetObjectKind() string {
+	if x != nil {
+		return x.ObjectKind
+	}
+	return ""
+}
+
+// Warnings about an extension that a handler accepted, which the compiler
+// reports without failing.
+type ExtensionWarnings struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Warning messages.
+	Warnings      []string `protobuf:"bytes,1,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtensionWarnings) Reset() {
+	*x = ExtensionWarnings{}
+	mi := &file_extensions_batch_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtensionWarnings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtensionWarnings) ProtoMessage() {}
+
+func (x *ExtensionWarnings) ProtoReflect() protoreflect.Message {
+	mi := &file_extensions_batch_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtensionWarnings.ProtoReflect.Descriptor instead.
+func (*ExtensionWarnings) Descriptor() ([]byte, []int) {
+	return file_extensions_batch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ExtensionWarnings) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+var File_extensions_batch_proto protoreflect.FileDescriptor
+
+const file_extensions_batch_proto_rawDesc = "" +
+	"\n" +
+	"\x16extensions/batch.proto\x12\x14gnostic.extension.v1\x1a\x19google/protobuf/any.proto\x1a\x1aextensions/extension.proto\x1a\x1dextensions/capabilities.proto\"\xd5\x03\n" +
+	"\x1cExtensionHandlerBatchRequest\x127\n" +
+	"\awrapper\x18\x01 \x01(\v2\x1d.gnostic.extension.v1.WrapperR\awrapper\x12H\n" +
+	"\x10compiler_version\x18\x02 \x01(\v2\x1d.gnostic.extension.v1.VersionR\x0fcompilerVersion\x129\n" +
+	"\bwrappers\x18\x03 \x03(\v2\x1d.gnostic.extension.v1.WrapperR\bwrappers\x12#\n" +
+	"\rbatch_version\x18\x04 \x01(\x05R\fbatchVersion\x12C\n" +
+	"\blocation\x18\x05 \x01(\v2'.gnostic.extension.v1.ExtensionLocationR\blocation\x12E\n" +
+	"\tlocations\x18\x06 \x03(\v2'.gnostic.extension.v1.ExtensionLocationR\tlocations\x12F\n" +
+	"\fcapabilities\x18\a \x01(\v2\".gnostic.extension.v1.CapabilitiesR\fcapabilities\"\xe2\x01\n" +
+	"\x1eExtensionHandlerSingleResponse\x12\x18\n" +
+	"\ahandled\x18\x01 \x01(\bR\ahandled\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\x12*\n" +
+	"\x05value\x18\x03 \x01(\v2\x14.google.protobuf.AnyR\x05value\x12F\n" +
+	"\fcapabilities\x18\a \x01(\v2\".gnostic.extension.v1.CapabilitiesR\fcapabilities\x12\x1a\n" +
+	"\bwarnings\x18\b \x03(\tR\bwarnings\"\x9f\x02\n" +
+	"\x1dExtensionHandlerBatchResponse\x12L\n" +
+	"\tresponses\x18\x04 \x03(\v2..gnostic.extension.v1.ExtensionHandlerResponseR\tresponses\x12#\n" +
+	"\rbatch_version\x18\x05 \x01(\x05R\fbatchVersion\x12C\n" +
+	"\bwarnings\x18\x06 \x03(\v2'.gnostic.extension.v1.ExtensionWarningsR\bwarnings\x12F\n" +
+	"\fcapabilities\x18\a \x01(\v2\".gnostic.extension.v1.CapabilitiesR\fcapabilities\"H\n" +
+	"\x11ExtensionLocation\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1f\n" +
+	"\vobject_kind\x18\x02 \x01(\tR\n" +
+	"objectKind\"/\n" +
+	"\x11ExtensionWarnings\x12\x1a\n" +
+	"\bwarnings\x18\x01 \x03(\tR\bwarningsBR\n" +
+	"\x0eorg.gnostic.v1B\x15GnosticExtensionBatchP\x01Z!./extensions;gnostic_extension_v1\xa2\x02\x03GNXb\x06proto3"
+
+var (
+	file_extensions_batch_proto_rawDescOnce sync.Once
+	file_extensions_batch_proto_rawDescData []byte
+)
+
+func file_extensions_batch_proto_rawDescGZIP() []byte {
+	file_extensions_batch_proto_rawDescOnce.Do(func() {
+		file_extensions_batch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_extensions_batch_proto_rawDesc), len(file_extensions_batch_proto_rawDesc)))
+	})
+	return file_extensions_batch_proto_rawDescData
+}
+
+var file_extensions_batch_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_extensions_batch_proto_goTypes = []any{
+	(*ExtensionHandlerBatchRequest)(nil),        // 0: gnostic.extension.v1.ExtensionHandlerBatchRequest
+	(*ExtensionHandlerSingleResponse)(nil),      // 1: gnostic.extension.v1.ExtensionHandlerSingleResponse
+	(*ExtensionHandlerBatchResponse)(nil),       // 2: gnostic.extension.v1.ExtensionHandlerBatchResponse
+	(*ExtensionLocation)(nil),                   // 3: gnostic.extension.v1.ExtensionLocation
+	(*ExtensionWarnings)(nil),                   // 4: gnostic.extension.v1.ExtensionWarnings
+	(*extensions.Wrapper)(nil),                  // 5: gnostic.extension.v1.Wrapper
+	(*extensions.Version)(nil),                  // 6: gnostic.extension.v1.Version
+	(*Capabilities)(nil),                        // 7: gnostic.extension.v1.Capabilities
+	(*anypb.Any)(nil),                           // 8: google.protobuf.Any
+	(*extensions.ExtensionHandlerResponse)(nil), // 9: gnostic.extension.v1.ExtensionHandlerResponse
+}
+var file_extensions_batch_proto_depIdxs = []int32{
+	5,  // 0: gnostic.extension.v1.ExtensionHandlerBatchRequest.wrapper:type_name -> gnostic.extension.v1.Wrapper
+	6,  // 1: gnostic.extension.v1.ExtensionHandlerBatchRequest.compiler_version:type_name -> gnostic.extension.v1.Version
+	5,  // 2: gnostic.extension.v1.ExtensionHandlerBatchRequest.wrappers:type_name -> gnostic.extension.v1.Wrapper
+	3,  // 3: gnostic.extension.v1.ExtensionHandlerBatchRequest.location:type_name -> gnostic.extension.v1.ExtensionLocation
+	3,  // 4: gnostic.extension.v1.ExtensionHandlerBatchRequest.locations:type_name -> gnostic.extension.v1.ExtensionLocation
+	7,  // 5: gnostic.extension.v1.ExtensionHandlerBatchRequest.capabilities:type_name -> gnostic.extension.v1.Capabilities
+	8,  // 6: gnostic.extension.v1.ExtensionHandlerSingleResponse.value:type_name -> google.protobuf.Any
+	7,  // 7: gnostic.extension.v1.ExtensionHandlerSingleResponse.capabilities:type_name -> gnostic.extension.v1.Capabilities
+	9,  // 8: gnostic.extension.v1.ExtensionHandlerBatchResponse.responses:type_name -> gnostic.extension.v1.ExtensionHandlerResponse
+	4,  // 9: gnostic.extension.v1.ExtensionHandlerBatchResponse.warnings:type_name -> gnostic.extension.v1.ExtensionWarnings
+	7,  // 10: gnostic.extension.v1.ExtensionHandlerBatchResponse.capabilities:type_name -> gnostic.extension.v1.Capabilities
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_extensions_batch_proto_init() }
+func file_extensions_batch_proto_init() {
+	if File_extensions_batch_proto != nil {
+		return
+	}
+	file_extensions_capabilities_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_extensions_batch_proto_rawDesc), len(file_extensions_batch_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_extensions_batch_proto_goTypes,
+		DependencyIndexes: file_extensions_batch_proto_depIdxs,
+		MessageInfos:      file_extensions_batch_proto_msgTypes,
+	}.Build()
+	File_extensions_batch_proto = out.File
+	file_extensions_batch_proto_goTypes = nil
+	file_extensions_batch_proto_depIdxs = nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnostic_extension_v1
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestBatchRequestRoundTrip(t *testing.T) {
+	wrappers := []*Wrapper{
+		{Version: "unknown", ExtensionName: "x-amazon-apigateway-integration", Yaml: "type: aws\n"},
+		{Version: "unknown", ExtensionName: "x-amazon-apigateway-auth", Yaml: "type: none\n"},
+	}
+	request := &ExtensionHandlerBatchRequest{
+		Wrapper:         wrappers[0],
+		CompilerVersion: &Version{Major: 0, Minor: 1},
+		Wrappers:        wrappers,
+		BatchVersion:    BatchVersion,
+	}
+	data, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A handler that supports batching reads the complete request.
+	batchRequest := &ExtensionHandlerBatchRequest{}
+	if err = proto.Unmarshal(data, batchRequest); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(batchRequest, request) {
+		t.Errorf("batch request changed in round trip: %v", batchRequest)
+	}
+	// A handler that predates batching reads a request for the first extension.
+	singleRequest := &ExtensionHandlerRequest{}
+	if err = proto.Unmarshal(data, singleRequest); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(singleRequest.Wrapper, wrappers[0]) || !proto.Equal(singleRequest.CompilerVersion, request.CompilerVersion) {
+		t.Errorf("unexpected single request %v", singleRequest)
+	}
+	// A single request is not mistaken for a batch.
+	data, err = proto.Marshal(&ExtensionHandlerRequest{Wrapper: wrappers[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchRequest = &ExtensionHandlerBatchRequest{}
+	if err = proto.Unmarshal(data, batchRequest); err != nil {
+		t.Fatal(err)
+	}
+	if batchRequest.BatchVersion != 0 || len(batchRequest.Wrappers) != 0 {
+		t.Errorf("single request was read as a batch: %v", batchRequest)
+	}
+}
+
+func TestBatchResponseRoundTrip(t *testing.T) {
+	value, err := anypb.New(&Version{Major: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := &ExtensionHandlerBatchResponse{
+		Responses: []*ExtensionHandlerResponse{
+			{Handled: true, Value: value},
+			{Handled: false},
+			{Handled: true, Errors: []string{"invalid value"}},
+		},
+		BatchVersion: BatchVersion,
+	}
+	data, err := proto.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchResponse := &ExtensionHandlerBatchResponse{}
+	if err = proto.Unmarshal(data, batchResponse); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(batchResponse, response) {
+		t.Errorf("batch response changed in round trip: %v", batchResponse)
+	}
+	// The reply of a handler that predates batching has no batch version.
+	data, err = proto.Marshal(&ExtensionHandlerResponse{Handled: true, Value: value, Errors: []string{"warning"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchResponse = &ExtensionHandlerBatchResponse{}
+	if err = proto.Unmarshal(data, batchResponse); err != nil {
+		t.Fatal(err)
+	}
+	if batchResponse.BatchVersion != 0 || len(batchResponse.Responses) != 0 {
+		t.Errorf("single response was read as a batch: %v", batchResponse)
+	}
+}
+
+func TestLocationRoundTrip(t *testing.T) {
+	wrapper := &Wrapper{Version: "unknown", ExtensionName: "x-book", Yaml: "title: Dune\n"}
+	location := &ExtensionLocation{Path: "$root.info.x-book", ObjectKind: "Info"}
+	data, err := proto.Marshal(&ExtensionHandlerBatchRequest{Wrapper: wrapper, CompilerVersion: &Version{Minor: 1}, Location: location})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &ExtensionHandlerBatchRequest{}
+	if err = proto.Unmarshal(data, request); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(request.Location, location) || request.BatchVersion != 0 {
+		t.Errorf("unexpected request %v", request)
+	}
+	// A handler that predates locations reads a request for the extension.
+	singleRequest := &ExtensionHandlerRequest{}
+	if err = proto.Unmarshal(data, singleRequest); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(singleRequest.Wrapper, wrapper) {
+		t.Errorf("unexpected single request %v", singleRequest)
+	}
+}
+
+func TestHandlerWarnings(t *testing.T) {
+	handler := func(name string, yamlInput string, location *ExtensionLocation) (bool, proto.Message, error) {
+		return true, &Version{Major: 1}, Warnings{"use lowercase", "deprecated"}
+	}
+	response, warnings := handle(handler, &Wrapper{ExtensionName: "x-book"}, &ExtensionLocation{})
+	if !response.Handled || response.Value == nil || len(response.Errors) != 0 {
+		t.Errorf("expected the extension to be handled without errors, got %v", response)
+	}
+	data, err := proto.Marshal(&ExtensionHandlerSingleResponse{
+		Handled:  response.Handled,
+		Errors:   response.Errors,
+		Value:    response.Value,
+		Warnings: warnings,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := &ExtensionHandlerSingleResponse{}
+	if err = proto.Unmarshal(data, read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Warnings) != 2 || read.Warnings[0] != "use lowercase" || read.Warnings[1] != "deprecated" {
+		t.Errorf("unexpected warnings %v", read.Warnings)
+	}
+	// The reply isn't mistaken for a batch response.
+	batch := &ExtensionHandlerBatchResponse{}
+	if err = proto.Unmarshal(data, batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch.BatchVersion != 0 || len(batch.Responses) != 0 || len(batch.Warnings) != 0 {
+		t.Errorf("unexpected batch response %v", batch)
+	}
+	// Compilers that predate warnings still read the response.
+	single := &ExtensionHandlerResponse{}
+	if err = proto.Unmarshal(data, single); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(single.Value, response.Value) {
+		t.Errorf("unexpected response %v", single)
+	}
+}
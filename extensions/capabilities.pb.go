@@ -0,0 +1,155 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        (unknown)
+// source: extensions/capabilities.proto
+
+package gnostic_extension_v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Capabilities describe the version of the extension protocol and the optional
+// features that are supported by the compiler or by an extension handler.
+//
+// They are sent in the capabilities fields of the compiler's requests and of
+// the handler's responses, which compilers and handlers that predate
+// capabilities ignore. Capabilities that are missing have a protocol version
+// of zero.
+type Capabilities struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The version of the extension protocol.
+	ProtocolVersion int32 `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// The optional features that are supported, e.g. "batch".
+	Features      []string `protobuf:"bytes,2,rep,name=features,proto3" json:"features,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	mi := &file_extensions_capabilities_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_extensions_capabilities_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_extensions_capabilities_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Capabilities) GetProtocolVersion() int32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *Capabilities) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+var File_extensions_capabilities_proto protoreflect.FileDescriptor
+
+const file_extensions_capabilities_proto_rawDesc = "" +
+	"\n" +
+	"\x1dextensions/capabilities.proto\x12\x14gnostic.extension.v1\"U\n" +
+	"\fCapabilities\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\x05R\x0fprotocolVersion\x12\x1a\n" +
+	"\bfeatures\x18\x02 \x03(\tR\bfeaturesBY\n" +
+	"\x0eorg.gnostic.v1B\x1cGnosticExtensionCapabilitiesP\x01Z!./extensions;gnostic_extension_v1\xa2\x02\x03GNXb\x06proto3"
+
+var (
+	file_extensions_capabilities_proto_rawDescOnce sync.Once
+	file_extensions_capabilities_proto_rawDescData []byte
+)
+
+func file_extensions_capabilities_proto_rawDescGZIP() []byte {
+	file_extensions_capabilities_proto_rawDescOnce.Do(func() {
+		file_extensions_capabilities_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_extensions_capabilities_proto_rawDesc), len(file_extensions_capabilities_proto_rawDesc)))
+	})
+	return file_extensions_capabilities_proto_rawDescData
+}
+
+var file_extensions_capabilities_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_extensions_capabilities_proto_goTypes = []any{
+	(*Capabilities)(nil), // 0: gnostic.extension.v1.Capabilities
+}
+var file_extensions_capabilities_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_extensions_capabilities_proto_init() }
+func file_extensions_capabilities_proto_init() {
+	if File_extensions_capabilities_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_extensions_capabilities_proto_rawDesc), len(file_extensions_capabilities_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_extensions_capabilities_proto_goTypes,
+		DependencyIndexes: file_extensions_capabilities_proto_depIdxs,
+		MessageInfos:      file_extensions_capabilities_proto_msgTypes,
+	}.Build()
+	File_extensions_capabilities_proto = out.File
+	file_extensions_capabilities_proto_goTypes = nil
+	file_extensions_capabilities_proto_depIdxs = nil
+}
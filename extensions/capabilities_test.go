@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnostic_extension_v1
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCapabilitiesRoundTrip(t *testing.T) {
+	capabilities := &Capabilities{ProtocolVersion: ProtocolVersion, Features: []string{FeatureBatch}}
+	request := &ExtensionHandlerBatchRequest{
+		Wrapper:      &Wrapper{Version: "unknown", ExtensionName: "x-book", Yaml: "title: Dune\n"},
+		Wrappers:     []*Wrapper{{Version: "unknown", ExtensionName: "x-book", Yaml: "title: Dune\n"}},
+		BatchVersion: BatchVersion,
+		Capabilities: capabilities,
+	}
+	data, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchRequest := &ExtensionHandlerBatchRequest{}
+	if err = proto.Unmarshal(data, batchRequest); err != nil {
+		t.Fatal(err)
+	}
+	if len(batchRequest.Wrappers) != 1 || batchRequest.BatchVersion != BatchVersion {
+		t.Errorf("unexpected batch request %v", batchRequest)
+	}
+	if !proto.Equal(batchRequest.Capabilities, capabilities) {
+		t.Errorf("capabilities changed in round trip: %v", batchRequest.Capabilities)
+	}
+	// Handlers that predate capabilities still read the request.
+	singleRequest := &ExtensionHandlerRequest{}
+	if err = proto.Unmarshal(data, singleRequest); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(singleRequest.Wrapper, request.Wrapper) {
+		t.Errorf("unexpected single request %v", singleRequest)
+	}
+	data, err = proto.Marshal(&ExtensionHandlerBatchResponse{BatchVersion: BatchVersion, Capabilities: capabilities})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchResponse := &ExtensionHandlerBatchResponse{}
+	if err = proto.Unmarshal(data, batchResponse); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(batchResponse.Capabilities, capabilities) {
+		t.Errorf("batch response capabilities changed in round trip: %v", batchResponse.Capabilities)
+	}
+	// Compilers that predate capabilities still read the response.
+	data, err = proto.Marshal(&ExtensionHandlerSingleResponse{
+		Handled:      true,
+		Errors:       []string{"invalid value"},
+		Capabilities: &Capabilities{ProtocolVersion: ProtocolVersion},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := &ExtensionHandlerResponse{}
+	if err = proto.Unmarshal(data, response); err != nil {
+		t.Fatal(err)
+	}
+	if !response.Handled || len(response.Errors) != 1 {
+		t.Errorf("unexpected response %v", response)
+	}
+	singleResponse := &ExtensionHandlerSingleResponse{}
+	if err = proto.Unmarshal(data, singleResponse); err != nil {
+		t.Fatal(err)
+	}
+	if c := singleResponse.Capabilities; c.GetProtocolVersion() != ProtocolVersion || c.HasFeature(FeatureBatch) {
+		t.Errorf("unexpected response capabilities %v", c)
+	}
+	if r := singleResponse.Response(); !r.Handled || len(r.Errors) != 1 {
+		t.Errorf("unexpected single response %v", singleResponse.Response())
+	}
+}
+
+func TestMissingCapabilities(t *testing.T) {
+	// Handlers that predate capabilities reply without them.
+	data, err := proto.Marshal(&ExtensionHandlerResponse{Handled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := &ExtensionHandlerSingleResponse{}
+	if err = proto.Unmarshal(data, response); err != nil {
+		t.Fatal(err)
+	}
+	if c := response.GetCapabilities(); c.GetProtocolVersion() != 0 || len(c.GetFeatures()) != 0 {
+		t.Errorf("expected no capabilities in response, got %v", c)
+	}
+	data, err = proto.Marshal(&ExtensionHandlerBatchResponse{BatchVersion: BatchVersion})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchResponse := &ExtensionHandlerBatchResponse{}
+	if err = proto.Unmarshal(data, batchResponse); err != nil {
+		t.Fatal(err)
+	}
+	if c := batchResponse.GetCapabilities(); c.GetProtocolVersion() != 0 || c.HasFeature(FeatureBatch) {
+		t.Errorf("expected no capabilities in batch response, got %v", c)
+	}
+}
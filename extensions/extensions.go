@@ -15,18 +15,69 @@
 package gnostic_extension_v1
 
 import (
+	"errors"
 	"google.golang.org/protobuf/types/known/anypb"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 
 	"google.golang.org/protobuf/proto"
 )
 
 type extensionHandler func(name string, yamlInput string) (bool, proto.Message, error)
 
+// Warnings can be returned as the error of a handler that handled an extension, to report
+// warnings about the extension without failing.
+type Warnings []string
+
+func (w Warnings) Error() string {
+	return strings.Join(w, "; ")
+}
+
+// LocatedExtensionHandler handles an extension that was found at a location in the document.
+// The location is empty if the compiler didn't send one.
+type LocatedExtensionHandler func(name string, yamlInput string, location *ExtensionLocation) (bool, proto.Message, error)
+
+// BatchVersion is the version of the batch protocol supported by this package.
+const BatchVersion = 1
+
+// ProtocolVersion is the version of the extension protocol supported by this package.
+const ProtocolVersion = 1
+
+// FeatureBatch is the capability of handling ExtensionHandlerBatchRequests.
+const FeatureBatch = "batch"
+
+// HasFeature returns true if the capabilities include a feature.
+func (x *Capabilities) HasFeature(feature string) bool {
+	for _, f := range x.GetFeatures() {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Response returns the response to the extension, without the handler's capabilities and warnings.
+func (x *ExtensionHandlerSingleResponse) Response() *ExtensionHandlerResponse {
+	return &ExtensionHandlerResponse{
+		Handled: x.GetHandled(),
+		Errors:  x.GetErrors(),
+		Value:   x.GetValue(),
+	}
+}
+
 // Main implements the main program of an extension handler.
+// It handles both single and batched requests and reports its capabilities.
 func Main(handler extensionHandler) {
+	MainWithLocation(func(name string, yamlInput string, location *ExtensionLocation) (bool, proto.Message, error) {
+		return handler(name, yamlInput)
+	})
+}
+
+// MainWithLocation implements the main program of an extension handler that uses the
+// locations of the extensions in the document.
+func MainWithLocation(handler LocatedExtensionHandler) {
 	// unpack the request
 	data, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
@@ -37,14 +88,64 @@ func Main(handler extensionHandler) {
 		log.Println("No input data.")
 		os.Exit(1)
 	}
-	request := &ExtensionHandlerRequest{}
+	// a batch request can also be read as a single request, so check for it first
+	request := &ExtensionHandlerBatchRequest{}
 	err = proto.Unmarshal(data, request)
 	if err != nil {
 		log.Println("Input error:", err.Error())
 		os.Exit(1)
 	}
+	capabilities := &Capabilities{
+		ProtocolVersion: ProtocolVersion,
+		Features:        []string{FeatureBatch},
+	}
+	var responseBytes []byte
+	if request.BatchVersion > 0 {
+		response := &ExtensionHandlerBatchResponse{
+			Responses:    make([]*ExtensionHandlerResponse, 0, len(request.Wrappers)),
+			BatchVersion: BatchVersion,
+			Capabilities: capabilities,
+		}
+		warned := false
+		for i, wrapper := range request.Wrappers {
+			location := &ExtensionLocation{}
+			if i < len(request.Locations) {
+				location = request.Locations[i]
+			}
+			extensionResponse, warnings := handle(handler, wrapper, location)
+			response.Responses = append(response.Responses, extensionResponse)
+			response.Warnings = append(response.Warnings, &ExtensionWarnings{Warnings: warnings})
+			warned = warned || len(warnings) > 0
+		}
+		if !warned {
+			response.Warnings = nil
+		}
+		responseBytes, _ = proto.Marshal(response)
+	} else {
+		location := request.Location
+		if location == nil {
+			location = &ExtensionLocation{}
+		}
+		response, warnings := handle(handler, request.Wrapper, location)
+		responseBytes, _ = proto.Marshal(&ExtensionHandlerSingleResponse{
+			Handled:      response.Handled,
+			Errors:       response.Errors,
+			Value:        response.Value,
+			Capabilities: capabilities,
+			Warnings:     warnings,
+		})
+	}
+	os.Stdout.Write(responseBytes)
+}
+
+// handle calls the handler for a single extension and returns its response and warnings.
+func handle(handler LocatedExtensionHandler, wrapper *Wrapper, location *ExtensionLocation) (*ExtensionHandlerResponse, []string) {
 	// call the handler
-	handled, output, err := handler(request.Wrapper.ExtensionName, request.Wrapper.Yaml)
+	handled, output, err := handler(wrapper.GetExtensionName(), wrapper.GetYaml(), location)
+	var warnings Warnings
+	if handled && errors.As(err, &warnings) {
+		err = nil
+	}
 	// respond with the output of the handler
 	response := &ExtensionHandlerResponse{
 		Handled: false, // default assumption
@@ -59,6 +160,5 @@ func Main(handler extensionHandler) {
 			response.Errors = append(response.Errors, err.Error())
 		}
 	}
-	responseBytes, _ := proto.Marshal(response)
-	os.Stdout.Write(responseBytes)
+	return response, warnings
 }
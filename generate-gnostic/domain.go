@@ -34,6 +34,7 @@ type Domain struct {
 	ObjectTypeRequests    map[string]*TypeRequest // anonymous types implied by type instantiation
 	MapTypeRequests       map[string]string       // "NamedObject" types that will be used to implement ordered maps
 	Version               string                  // OpenAPI Version ("v2" or "v3")
+	GenerateEnums         bool                    // if true, enumerated string properties get generated enum types
 }
 
 // NewDomain creates a domain representation.
@@ -116,6 +117,11 @@ func (domain *Domain) arrayItemTypeForSchema(propertyName string, schema *jsonsc
 				itemTypeName = domain.TypeNameForStub(propertyName + "Item")
 				domain.ObjectTypeRequests[itemTypeName] =
 					NewTypeRequest(itemTypeName, propertyName, schema.Items.Schema)
+			} else if schema.Items.Schema.TypeIs("object") {
+				// the items have an "anonymous" object schema, so request a type for them
+				itemTypeName = domain.TypeNameForStub(propertyName + "Item")
+				domain.ObjectTypeRequests[itemTypeName] =
+					NewTypeRequest(itemTypeName, propertyName, schema.Items.Schema)
 			} else if types == nil {
 				// do nothing
 			} else if (types.StringArray != nil) && len(*(types.StringArray)) == 1 {
@@ -509,6 +515,48 @@ func (domain *Domain) buildTypeForDefinitionObject(
 	return typeModel
 }
 
+// enumTypeForProperty returns the name of the enum type generated for an enumerated string
+// property, or "" if the property is represented with a string.
+func (domain *Domain) enumTypeForProperty(typeProperty *TypeProperty) string {
+	if !domain.GenerateEnums || typeProperty.Type != "string" || typeProperty.Repeated || len(typeProperty.StringEnumValues) == 0 {
+		return ""
+	}
+	return typeProperty.FieldName()
+}
+
+// enumValueNamesForProperty returns the names of the enum values that represent the
+// StringEnumValues of a property. The first name is for the default (unspecified) value.
+func (domain *Domain) enumValueNamesForProperty(typeProperty *TypeProperty) []string {
+	prefix := strings.ToUpper(camelCaseToSnakeCase(domain.enumTypeForProperty(typeProperty)))
+	names := []string{prefix + "_UNSPECIFIED"}
+	used := map[string]bool{names[0]: true}
+	for _, value := range typeProperty.StringEnumValues {
+		name := prefix + "_" + enumValueIdentifier(value)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s_%s_%d", prefix, enumValueIdentifier(value), i)
+		}
+		used[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// buildRequestedObjectTypes generates a type for each of the anonymous object types to be instantiated.
+func (domain *Domain) buildRequestedObjectTypes() {
+	// we loop because these implied types could imply other types.
+	// when implied types are instantiated (with buildTypeForDefinitionObject),
+	// new requests might be added to domain.ObjectTypeRequests
+	for len(domain.ObjectTypeRequests) > 0 {
+		typeRequests := domain.ObjectTypeRequests
+		domain.ObjectTypeRequests = make(map[string]*TypeRequest, 0)
+		for typeName, typeRequest := range typeRequests {
+			// this could add to domain.ObjectTypeRequests
+			domain.TypeModels[typeRequest.Name] =
+				domain.buildTypeForDefinitionObject(typeName, typeRequest.PropertyName, typeRequest.Schema)
+		}
+	}
+}
+
 // Build builds a domain model.
 func (domain *Domain) Build() (err error) {
 	if (domain.Schema == nil) || (domain.Schema.Definitions == nil) {
@@ -544,19 +592,7 @@ func (domain *Domain) Build() (err error) {
 		}
 	}
 
-	// iterate over anonymous object types to be instantiated and generate a type for each
-	// we loop because these implied types could imply other types.
-	// when implied types are instantiated (with buildTypeForDefinitionObject),
-	// new requests might be added to domain.ObjectTypeRequests
-	for len(domain.ObjectTypeRequests) > 0 {
-		typeRequests := domain.ObjectTypeRequests
-		domain.ObjectTypeRequests = make(map[string]*TypeRequest, 0)
-		for typeName, typeRequest := range typeRequests {
-			// this could add to domain.ObjectTypeRequests
-			domain.TypeModels[typeRequest.Name] =
-				domain.buildTypeForDefinitionObject(typeName, typeRequest.PropertyName, typeRequest.Schema)
-		}
-	}
+	domain.buildRequestedObjectTypes()
 
 	// iterate over map item types to be instantiated and generate a type for each
 	mapTypeNames := make([]string, 0)
@@ -110,10 +110,16 @@ func (domain *Domain) GenerateCompiler(packageName string, license string, impor
 	for _, typeName := range typeNames {
 		domain.generateToRawInfoMethodForType(code, typeName)
 	}
+	domain.generateRawInfoHelpers(code)
 
 	// generate precompiled regexps for use during parsing
 	domain.generateConstantVariables(code, regexPatterns)
 
+	// generate mappings between enumerated strings and their enum values
+	for _, typeName := range typeNames {
+		domain.generateEnumVariablesForType(code, typeName)
+	}
+
 	return code.String()
 }
 
@@ -258,10 +264,17 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 	} else {
 		oneOfWrapper := typeModel.OneOfWrapper
 
+		if typeName == "SchemaOrReference" && domain.Version == "v3" {
+			// OpenAPI 3.1 schemas can have a $ref and other keywords.
+			code.Print("if x, ok, err := newSchemaWithReference(in, context); ok {")
+			code.Print("  return x, err")
+			code.Print("}")
+		}
 		code.Print("x := &%s{}", typeName)
 
 		if oneOfWrapper {
 			code.Print("matched := false")
+			code.Print("var partialMatch error")
 		}
 
 		unpackAtTop := !oneOfWrapper || len(typeModel.Required) > 0
@@ -327,11 +340,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 				code.Print("var allowedPatterns []*regexp.Regexp")
 
 			}
-			code.Print("invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)")
-			code.Print("if len(invalidKeys) > 0 {")
-			code.Print("  message := fmt.Sprintf(\"has invalid %%s: %%+v\", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, \", \"))")
-			code.Print("  errors = append(errors, compiler.NewError(context, message))")
-			code.Print("}")
+			code.Print("errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)")
 		}
 
 		var fieldNumber = 0
@@ -351,11 +360,22 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 			}
 			displayName = camelCaseToSnakeCase(displayName)
 
-			var line = fmt.Sprintf("%s %s = %d;", propertyType, displayName, fieldNumber)
+			declaredType := propertyType
+			enumType := domain.enumTypeForProperty(propertyModel)
+			if enumType != "" {
+				declaredType = enumType
+			}
+			var line = fmt.Sprintf("%s %s = %d;", declaredType, displayName, fieldNumber)
 			if propertyModel.Repeated {
 				line = "repeated " + line
 			}
 			code.Print("// " + line)
+			if typeName == domain.Prefix+"Document" {
+				// Stop between the top-level sections of canceled compilations.
+				code.Print("if err := compiler.CancellationError(context); err != nil {")
+				code.Print("  return nil, err")
+				code.Print("}")
+			}
 
 			fieldName := strings.Title(snakeCaseToCamelCase(propertyName))
 			if propertyName == "$ref" {
@@ -363,7 +383,19 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 			}
 
 			typeModel, typeFound := domain.TypeModels[propertyType]
-			if typeFound && !typeModel.IsPair {
+			if enumType != "" {
+				code.Print("v%d := compiler.MapValueForKey(m, \"%s\")", fieldNumber, propertyName)
+				code.Print("if (v%d != nil) {", fieldNumber)
+				code.Print("  s, ok := compiler.StringForScalarNode(v%d)", fieldNumber)
+				code.Print("  if ok {")
+				code.Print("    x.%s, ok = %s[s]", fieldName, domain.enumVariableName(parentTypeName, propertyModel, "Values"))
+				code.Print("  }")
+				code.Print("  if !ok {")
+				code.Print("    message := fmt.Sprintf(\"has unexpected value for %s: %%s\", compiler.Display(v%d))", propertyName, fieldNumber)
+				code.Print("    errors = append(errors, compiler.NewError(context, message))")
+				code.Print("  }")
+				code.Print("}")
+			} else if typeFound && !typeModel.IsPair {
 				if propertyModel.Repeated {
 					code.Print("v%d := compiler.MapValueForKey(m, \"%s\")", fieldNumber, propertyName)
 					code.Print("if (v%d != nil) {", fieldNumber)
@@ -392,6 +424,10 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 						code.Print("    if matchingError == nil {")
 						code.Print("      x.Oneof = &%s_%s{%s: t}", parentTypeName, typeModel.Name, typeModel.Name)
 						code.Print("      matched = true")
+						code.Print("    } else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {")
+						code.Print("      // this possibility matches except for invalid keys, which are reported if no possibility matches")
+						code.Print("      x.Oneof = &%s_%s{%s: t}", parentTypeName, typeModel.Name, typeModel.Name)
+						code.Print("      partialMatch = matchingError")
 						code.Print("    } else {")
 						code.Print("      errors = append(errors, matchingError)")
 						code.Print("    }")
@@ -417,7 +453,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 					code.Print("if (v%d != nil) {", fieldNumber)
 					code.Print("  v, ok := compiler.SequenceNodeForNode(v%d)", fieldNumber)
 					code.Print("  if ok {")
-					code.Print("    x.%s = compiler.StringArrayForSequenceNode(v)", fieldName)
+					code.Print("    x.%s = compiler.StringArrayForSequenceNodeInContext(context, \"%s\", v)", fieldName, propertyName)
 					code.Print("  } else {")
 					code.Print("    message := fmt.Sprintf(\"has unexpected value for %s: %%s\", compiler.Display(v%d))", propertyName, fieldNumber)
 					code.Print("    errors = append(errors, compiler.NewError(context, message))")
@@ -445,7 +481,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 				} else {
 					code.Print("v%d := compiler.MapValueForKey(m, \"%s\")", fieldNumber, propertyName)
 					code.Print("if (v%d != nil) {", fieldNumber)
-					code.Print("  x.%s, ok = compiler.StringForScalarNode(v%d)", fieldName, fieldNumber)
+					code.Print("  x.%s, ok = compiler.StringForScalarNodeInContext(context, \"%s\", v%d)", fieldName, propertyName, fieldNumber)
 					code.Print("  if !ok {")
 					code.Print("    message := fmt.Sprintf(\"has unexpected value for %s: %%s\", compiler.Display(v%d))", propertyName, fieldNumber)
 					code.Print("    errors = append(errors, compiler.NewError(context, message))")
@@ -474,7 +510,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 			} else if propertyType == "float" {
 				code.Print("v%d := compiler.MapValueForKey(m, \"%s\")", fieldNumber, propertyName)
 				code.Print("if (v%d != nil) {", fieldNumber)
-				code.Print("  v, ok := compiler.FloatForScalarNode(v%d)", fieldNumber)
+				code.Print("  v, ok := compiler.FloatForScalarNodeInContext(context, \"%s\", v%d)", propertyName, fieldNumber)
 				code.Print("  if ok {")
 				code.Print("    x.%s = v", fieldName)
 				code.Print("  } else {")
@@ -485,7 +521,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 			} else if propertyType == "int64" {
 				code.Print("v%d := compiler.MapValueForKey(m, \"%s\")", fieldNumber, propertyName)
 				code.Print("if (v%d != nil) {", fieldNumber)
-				code.Print("  t, ok := compiler.IntForScalarNode(v%d)", fieldNumber)
+				code.Print("  t, ok := compiler.IntForScalarNodeInContext(context, \"%s\", v%d)", propertyName, fieldNumber)
 				code.Print("  if ok {")
 				code.Print("    x.%s = int64(t)", fieldName)
 				code.Print("  } else {")
@@ -496,7 +532,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 			} else if propertyType == "bool" {
 				if oneOfWrapper {
 					propertyName := "Boolean"
-					code.Print("boolValue, ok := compiler.BoolForScalarNode(in)")
+					code.Print("boolValue, ok := compiler.BoolForScalarNodeInContext(context, \"\", in)")
 					code.Print("if ok {")
 					code.Print("  x.Oneof = &%s_%s{%s: boolValue}", parentTypeName, propertyName, propertyName)
 					code.Print("  matched = true")
@@ -504,7 +540,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 				} else {
 					code.Print("v%d := compiler.MapValueForKey(m, \"%s\")", fieldNumber, propertyName)
 					code.Print("if (v%d != nil) {", fieldNumber)
-					code.Print("  x.%s, ok = compiler.BoolForScalarNode(v%d)", fieldName, fieldNumber)
+					code.Print("  x.%s, ok = compiler.BoolForScalarNodeInContext(context, \"%s\", v%d)", fieldName, propertyName, fieldNumber)
 					code.Print("  if !ok {")
 					code.Print("    message := fmt.Sprintf(\"has unexpected value for %s: %%s\", compiler.Display(v%d))", propertyName, fieldNumber)
 					code.Print("    errors = append(errors, compiler.NewError(context, message))")
@@ -539,7 +575,7 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 						code.Print("pair.Value, _ = compiler.StringForScalarNode(v)")
 					} else if mapTypeName == "Any" {
 						code.Print("result := &Any{}")
-						code.Print("handled, resultFromExt, err := compiler.CallExtension(context, v, k)")
+						code.Print("handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, \"%s\")", typeName)
 						code.Print("if handled {")
 						code.Print("	if err != nil {")
 						code.Print("		errors = append(errors, err)")
@@ -573,6 +609,10 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 				}
 			}
 		}
+		if typeName == "Responses" && domain.Version == "v3" {
+			// the generated type has no field for the position of the default response
+			code.Print("recordDefaultResponsePosition(x, m)")
+		}
 		if unpackAtTop {
 			code.Print("}")
 		}
@@ -580,6 +620,8 @@ func (domain *Domain) generateConstructorForType(code *printer.Code, typeName st
 			code.Print("if matched {")
 			code.Print("    // since the oneof matched one of its possibilities, discard any matching errors")
 			code.Print("	errors = make([]error, 0)")
+			code.Print("} else if partialMatch != nil {")
+			code.Print("    errors = []error{partialMatch}")
 			generateMatchErrors := true // TODO: enable this and update tests for new error messages
 			if generateMatchErrors {
 				code.Print("} else {")
@@ -711,6 +753,9 @@ func (domain *Domain) generateToRawInfoMethodForType(code *printer.Code, typeNam
 	code.Print("func (m *%s) ToRawInfo() *yaml.Node {", typeName)
 	typeModel := domain.TypeModels[typeName]
 	if typeName == "Any" {
+		code.Print("if node := plainStringNode(m.Yaml); node != nil {")
+		code.Print("	return node")
+		code.Print("}")
 		code.Print("var err error")
 		code.Print("var node yaml.Node")
 		code.Print("err = yaml.Unmarshal([]byte(m.Yaml), &node)")
@@ -749,127 +794,262 @@ func (domain *Domain) generateToRawInfoMethodForType(code *printer.Code, typeNam
 		}
 		code.Print("return compiler.NewNullNode()")
 	} else {
-		code.Print("info := compiler.NewMappingNode()")
-		code.Print("if m == nil {return info}")
-		for _, propertyModel := range typeModel.Properties {
-			isRequired := typeModel.IsRequired(propertyModel.Name)
-			switch propertyModel.Type {
-			case "string":
-				propertyName := propertyModel.Name
-				if !propertyModel.Repeated {
-					code.PrintIf(isRequired, "// always include this required field.")
-					code.PrintIf(!isRequired, "if m.%s != \"\" {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(m.%s))", propertyModel.FieldName())
-					code.PrintIf(!isRequired, "}")
+		code.Print("if m == nil {return compiler.NewMappingNode()}")
+		entries := domain.rawInfoEntriesForType(typeName)
+		// Count the pairs and nodes first, so that the nodes of the mapping are allocated together.
+		pairs, size := 0, 1
+		for _, entry := range entries {
+			if entry.condition == "" {
+				pairs += entry.pairs
+				size += entry.size
+			}
+		}
+		code.Print("pairs, size := %d, %d", pairs, size)
+		for _, entry := range entries {
+			if entry.condition != "" {
+				code.Print("if %s {", entry.condition)
+				if pairs := sumExpression(entry.pairs, entry.variablePairs); pairs == "1" {
+					code.Print("pairs++")
 				} else {
-					code.Print("if len(m.%s) != 0 {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewSequenceNodeForStringArray(m.%s))", propertyModel.FieldName())
-					code.Print("}")
+					code.Print("pairs += %s", pairs)
 				}
-			case "bool":
-				propertyName := propertyModel.Name
-				if !propertyModel.Repeated {
-					code.PrintIf(isRequired, "// always include this required field.")
-					code.PrintIf(!isRequired, "if m.%s != false {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForBool(m.%s))", propertyModel.FieldName())
-					code.PrintIf(!isRequired, "}")
+				code.Print("size += %s", sumExpression(entry.size, entry.variableSize))
+				code.Print("}")
+				continue
+			}
+			code.PrintIf(entry.variablePairs != "", "pairs += %s", entry.variablePairs)
+			code.PrintIf(entry.variableSize != "", "size += %s", entry.variableSize)
+		}
+		code.Print("nodes := make([]yaml.Node, 1, size)")
+		code.Print("info := &nodes[0]")
+		code.Print("info.Kind = yaml.MappingNode")
+		code.Print("info.Content = make([]*yaml.Node, 0, 2*pairs)")
+		for _, entry := range entries {
+			code.PrintIf(entry.comment != "", "%s", entry.comment)
+			code.PrintIf(entry.condition != "", "if %s {", entry.condition)
+			for _, line := range entry.code {
+				code.Print("%s", line)
+			}
+			code.PrintIf(entry.condition != "", "}")
+		}
+		code.Print("return info")
+	}
+	code.Print("}\n")
+}
+
+// rawInfoEntry is the code that adds a property to the mapping node of a ToRawInfo method.
+type rawInfoEntry struct {
+	comment string
+	// The property is added if the condition is true, or always if it is empty.
+	condition string
+	// The number of pairs that the property adds to the mapping and of nodes that it
+	// takes from the method's node slice, with expressions for the parts that vary,
+	// like the entries of a map or the items of a string array.
+	pairs         int
+	size          int
+	variablePairs string
+	variableSize  string
+	code          []string
+}
+
+// sumExpression returns an expression for the sum of a number and an optional expression.
+func sumExpression(n int, expression string) string {
+	switch {
+	case expression == "":
+		return fmt.Sprintf("%d", n)
+	case n == 0:
+		return expression
+	}
+	return fmt.Sprintf("%d+%s", n, expression)
+}
+
+// appendPair returns code that appends a key and a value to the mapping node.
+func appendPair(key string, value string) string {
+	return fmt.Sprintf("info.Content = append(info.Content, newScalarNode(&nodes, \"!!str\", %s), %s)", key, value)
+}
+
+// rawInfoEntriesForType returns the code that adds each property of a type to its raw info.
+func (domain *Domain) rawInfoEntriesForType(typeName string) []*rawInfoEntry {
+	typeModel := domain.TypeModels[typeName]
+	entries := make([]*rawInfoEntry, 0, len(typeModel.Properties))
+	for _, propertyModel := range typeModel.Properties {
+		isRequired := typeModel.IsRequired(propertyModel.Name)
+		fieldName := propertyModel.FieldName()
+		key := fmt.Sprintf("%q", propertyModel.Name)
+		entry := &rawInfoEntry{pairs: 1, size: 1}
+		// scalar adds a property with a scalar value, which is omitted if it is zero unless the property is required.
+		scalar := func(zero string, value string) {
+			if isRequired {
+				entry.comment = "// always include this required field."
+			} else {
+				entry.condition = fmt.Sprintf("m.%s != %s", fieldName, zero)
+			}
+			entry.size = 2
+			entry.code = []string{appendPair(key, value)}
+		}
+		// array adds a repeated property, which is omitted if it is empty.
+		array := func(value string) {
+			entry.condition = fmt.Sprintf("len(m.%s) != 0", fieldName)
+			entry.code = []string{appendPair(key, value)}
+		}
+		switch propertyModel.Type {
+		case "string":
+			if domain.enumTypeForProperty(propertyModel) != "" {
+				// the unspecified value has no string representation.
+				entry.condition = fmt.Sprintf("m.%s != %s_%s", fieldName, typeName, domain.enumValueNamesForProperty(propertyModel)[0])
+				entry.size = 2
+				entry.code = []string{appendPair(key, fmt.Sprintf("newScalarNode(&nodes, \"!!str\", %s[m.%s])",
+					domain.enumVariableName(typeName, propertyModel, "Names"), fieldName))}
+			} else if !propertyModel.Repeated {
+				scalar("\"\"", fmt.Sprintf("newScalarNode(&nodes, \"!!str\", m.%s)", fieldName))
+			} else {
+				array(fmt.Sprintf("newSequenceNodeForStrings(&nodes, m.%s)", fieldName))
+				entry.size = 2
+				entry.variableSize = fmt.Sprintf("len(m.%s)", fieldName)
+			}
+		case "bool":
+			if !propertyModel.Repeated {
+				scalar("false", fmt.Sprintf("newScalarNodeForBool(&nodes, m.%s)", fieldName))
+			} else {
+				array(fmt.Sprintf("compiler.NewSequenceNodeForBoolArray(m.%s)", fieldName))
+			}
+		case "int":
+			if !propertyModel.Repeated {
+				scalar("0", fmt.Sprintf("newScalarNodeForInt(&nodes, m.%s)", fieldName))
+			} else {
+				array(fmt.Sprintf("compiler.NewSequenceNodeForIntArray(m.%s)", fieldName))
+			}
+		case "float":
+			if !propertyModel.Repeated {
+				scalar("0.0", fmt.Sprintf("newScalarNodeForFloat(&nodes, m.%s)", fieldName))
+			} else {
+				array(fmt.Sprintf("compiler.NewSequenceNodeForFloatArray(m.%s)", fieldName))
+			}
+		default:
+			if propertyModel.Name == "value" && propertyModel.Type != "Any" {
+				entry.comment = fmt.Sprintf("// %+v", propertyModel)
+				entry.pairs, entry.size = 0, 0
+			} else if !propertyModel.Repeated {
+				if isRequired {
+					entry.comment = "// always include this required field."
 				} else {
-					code.Print("if len(m.%s) != 0 {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewSequenceNodeForBoolArray(m.%s))", propertyModel.FieldName())
-					code.Print("}")
+					entry.condition = fmt.Sprintf("m.%s != nil", fieldName)
 				}
-			case "int":
-				propertyName := propertyModel.Name
-				if !propertyModel.Repeated {
-					code.PrintIf(isRequired, "// always include this required field.")
-					code.PrintIf(!isRequired, "if m.%s != 0 {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForInt(m.%s))", propertyModel.FieldName())
-					code.PrintIf(!isRequired, "}")
+				if propertyModel.Type == "TypeItem" {
+					entry.size = 2
+					entry.variableSize = "len(m.Type.Value)"
+					entry.code = []string{
+						"if len(m.Type.Value) == 1 {",
+						appendPair("\"type\"", "newScalarNode(&nodes, \"!!str\", m.Type.Value[0])"),
+						"} else {",
+						appendPair("\"type\"", "newSequenceNodeForStrings(&nodes, m.Type.Value)"),
+						"}",
+					}
+				} else if propertyModel.Type == "ItemsItem" {
+					schemas := "m.Items.SchemaOrReference"
+					if domain.Version == "v2" {
+						schemas = "m.Items.Schema"
+					}
+					entry.size = 2
+					entry.code = []string{
+						fmt.Sprintf("items := newSequenceNode(&nodes, len(%s))", schemas),
+						fmt.Sprintf("for _, item := range %s {", schemas),
+						"	items.Content = append(items.Content, item.ToRawInfo())",
+						"}",
+						"if len(items.Content) == 1 {items = items.Content[0]}",
+						appendPair("\"items\"", "items"),
+					}
 				} else {
-					code.Print("if len(m.%s) != 0 {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewSequenceNodeForIntArray(m.%s))", propertyModel.FieldName())
-					code.Print("}")
+					entry.code = []string{appendPair(key, fmt.Sprintf("m.%s.ToRawInfo()", fieldName))}
 				}
-			case "float":
-				propertyName := propertyModel.Name
-				if !propertyModel.Repeated {
-					code.PrintIf(isRequired, "// always include this required field.")
-					code.PrintIf(!isRequired, "if m.%s != 0.0 {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForFloat(m.%s))", propertyModel.FieldName())
-					code.PrintIf(!isRequired, "}")
-				} else {
-					code.Print("if len(m.%s) != 0 {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, compiler.NewSequenceNodeForFloatArray(m.%s))", propertyModel.FieldName())
-					code.Print("}")
+			} else if propertyModel.MapType == "string" {
+				entry.pairs, entry.size = 0, 0
+				entry.variablePairs = fmt.Sprintf("len(m.%s)", fieldName)
+				entry.variableSize = "2*" + entry.variablePairs
+				entry.code = []string{
+					fmt.Sprintf("for _, item := range m.%s {", fieldName),
+					appendPair("item.Name", "newScalarNode(&nodes, \"!!str\", item.Value)"),
+					"}",
 				}
-			default:
-				propertyName := propertyModel.Name
-				if propertyName == "value" && propertyModel.Type != "Any" {
-					code.Print("// %+v", propertyModel)
-				} else if !propertyModel.Repeated {
-					code.PrintIf(isRequired, "// always include this required field.")
-					code.PrintIf(!isRequired, "if m.%s != nil {", propertyModel.FieldName())
-					if propertyModel.Type == "TypeItem" {
-						code.Print("if len(m.Type.Value) == 1 {")
-						code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"type\"))")
-						code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(m.Type.Value[0]))")
-						code.Print("} else {")
-						code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"type\"))")
-						code.Print("info.Content = append(info.Content, compiler.NewSequenceNodeForStringArray(m.Type.Value))")
-						code.Print("}")
-					} else if propertyModel.Type == "ItemsItem" {
-						code.Print("items := compiler.NewSequenceNode()")
-						if domain.Version == "v2" {
-							code.Print("for _, item := range m.Items.Schema {")
-						} else {
-							code.Print("for _, item := range m.Items.SchemaOrReference {")
-						}
-						code.Print("	items.Content = append(items.Content, item.ToRawInfo())")
-						code.Print("}")
-						code.Print("if len(items.Content) == 1 {items = items.Content[0]}")
-						code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"items\"))")
-						code.Print("info.Content = append(info.Content, items)")
-					} else {
-						code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-						code.Print("info.Content = append(info.Content, m.%s.ToRawInfo())", propertyModel.FieldName())
-					}
-					code.PrintIf(!isRequired, "}")
-				} else if propertyModel.MapType == "string" {
-					code.Print("if m.%s != nil {", propertyModel.FieldName())
-					code.Print("for _, item := range m.%s {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(item.Name))")
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(item.Value))")
-					code.Print("}")
-					code.Print("}")
-				} else if propertyModel.MapType != "" {
-					code.Print("if m.%s != nil {", propertyModel.FieldName())
-					code.Print("for _, item := range m.%s {", propertyModel.FieldName())
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(item.Name))")
-					code.Print("info.Content = append(info.Content, item.Value.ToRawInfo())")
-					code.Print("}")
-					code.Print("}")
-				} else {
-					code.Print("if len(m.%s) != 0 {", propertyModel.FieldName())
-					code.Print("items := compiler.NewSequenceNode()")
-					code.Print("for _, item := range m.%s {", propertyModel.FieldName())
-					code.Print("items.Content = append(items.Content, item.ToRawInfo())")
-					code.Print("}")
-					code.Print("info.Content = append(info.Content, compiler.NewScalarNodeForString(\"%s\"))", propertyName)
-					code.Print("info.Content = append(info.Content, items)")
-					code.Print("}")
+			} else if propertyModel.MapType != "" {
+				entry.pairs, entry.size = 0, 0
+				entry.variablePairs = fmt.Sprintf("len(m.%s)", fieldName)
+				entry.variableSize = entry.variablePairs
+				entry.code = []string{
+					fmt.Sprintf("for _, item := range m.%s {", fieldName),
+					appendPair("item.Name", "item.Value.ToRawInfo()"),
+					"}",
+				}
+			} else {
+				entry.condition = fmt.Sprintf("len(m.%s) != 0", fieldName)
+				entry.size = 2
+				entry.code = []string{
+					fmt.Sprintf("items := newSequenceNode(&nodes, len(m.%s))", fieldName),
+					fmt.Sprintf("for _, item := range m.%s {", fieldName),
+					"items.Content = append(items.Content, item.ToRawInfo())",
+					"}",
+					appendPair(key, "items"),
 				}
 			}
 		}
-		code.Print("return info")
+		entries = append(entries, entry)
 	}
+	return entries
+}
+
+// generateRawInfoHelpers generates the functions that the ToRawInfo methods use to
+// allocate nodes from a slice with room for all of the nodes of a mapping.
+func (domain *Domain) generateRawInfoHelpers(code *printer.Code) {
+	code.Print("// newScalarNode adds a scalar node to nodes and returns it.")
+	code.Print("func newScalarNode(nodes *[]yaml.Node, tag string, value string) *yaml.Node {")
+	code.Print("*nodes = append(*nodes, yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})")
+	code.Print("return &(*nodes)[len(*nodes)-1]")
+	code.Print("}\n")
+	code.Print("// newScalarNodeForBool adds a scalar node for a bool to nodes and returns it.")
+	code.Print("func newScalarNodeForBool(nodes *[]yaml.Node, b bool) *yaml.Node {")
+	code.Print("return newScalarNode(nodes, \"!!bool\", strconv.FormatBool(b))")
+	code.Print("}\n")
+	code.Print("// newScalarNodeForInt adds a scalar node for an integer to nodes and returns it.")
+	code.Print("func newScalarNodeForInt(nodes *[]yaml.Node, i int64) *yaml.Node {")
+	code.Print("return newScalarNode(nodes, \"!!int\", strconv.FormatInt(i, 10))")
+	code.Print("}\n")
+	code.Print("// newScalarNodeForFloat adds a scalar node for a float to nodes and returns it.")
+	code.Print("func newScalarNodeForFloat(nodes *[]yaml.Node, f float64) *yaml.Node {")
+	code.Print("return newScalarNode(nodes, \"!!float\", strconv.FormatFloat(f, 'g', -1, 64))")
+	code.Print("}\n")
+	code.Print("// newSequenceNode adds a sequence node with room for n items to nodes and returns it.")
+	code.Print("func newSequenceNode(nodes *[]yaml.Node, n int) *yaml.Node {")
+	code.Print("*nodes = append(*nodes, yaml.Node{Kind: yaml.SequenceNode, Content: make([]*yaml.Node, 0, n)})")
+	code.Print("return &(*nodes)[len(*nodes)-1]")
+	code.Print("}\n")
+	code.Print("// plainStringNode returns the node of a yaml document that is a plain string of letters,")
+	code.Print("// digits and the characters \"_-.\" that starts with a letter, like most names and enum values.")
+	code.Print("// These are the only strings that it reads, so that they don't need a yaml parser. It returns")
+	code.Print("// nil for any other document, including the strings that yaml reads as booleans or null.")
+	code.Print("func plainStringNode(document string) *yaml.Node {")
+	code.Print("s := strings.TrimSuffix(document, \"\\n\")")
+	code.Print("if s == \"\" || len(s) == len(document) {")
+	code.Print("	return nil")
+	code.Print("}")
+	code.Print("for i := 0; i < len(s); i++ {")
+	code.Print("	c := s[i]")
+	code.Print("	if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && ('0' <= c && c <= '9' || c == '_' || c == '-' || c == '.')) {")
+	code.Print("		return nil")
+	code.Print("	}")
+	code.Print("}")
+	code.Print("switch s {")
+	code.Print("case \"true\", \"True\", \"TRUE\", \"false\", \"False\", \"FALSE\", \"null\", \"Null\", \"NULL\":")
+	code.Print("	return nil")
+	code.Print("}")
+	code.Print("return &yaml.Node{Kind: yaml.ScalarNode, Tag: \"!!str\", Value: s, Line: 1, Column: 1}")
+	code.Print("}\n")
+	code.Print("// newSequenceNodeForStrings adds a sequence node for an array of strings and its items to nodes and returns it.")
+	code.Print("func newSequenceNodeForStrings(nodes *[]yaml.Node, values []string) *yaml.Node {")
+	code.Print("sequence := newSequenceNode(nodes, len(values))")
+	code.Print("for _, value := range values {")
+	code.Print("sequence.Content = append(sequence.Content, newScalarNode(nodes, \"!!str\", value))")
+	code.Print("}")
+	code.Print("return sequence")
 	code.Print("}\n")
 }
 
@@ -889,3 +1069,31 @@ func (domain *Domain) generateConstantVariables(code *printer.Code, regexPattern
 	}
 	code.Print(")\n")
 }
+
+// enumVariableName returns the name of a variable that maps between the strings and the enum
+// values of an enumerated string property.
+func (domain *Domain) enumVariableName(typeName string, propertyModel *TypeProperty, suffix string) string {
+	return strings.ToLower(typeName[0:1]) + typeName[1:] + domain.enumTypeForProperty(propertyModel) + suffix
+}
+
+func (domain *Domain) generateEnumVariablesForType(code *printer.Code, typeName string) {
+	typeModel := domain.TypeModels[typeName]
+	for _, propertyModel := range typeModel.Properties {
+		enumType := domain.enumTypeForProperty(propertyModel)
+		if enumType == "" {
+			continue
+		}
+		enumTypeName := typeName + "_" + enumType
+		names := domain.enumValueNamesForProperty(propertyModel)
+		code.Print("var %s = map[string]%s{", domain.enumVariableName(typeName, propertyModel, "Values"), enumTypeName)
+		for i, value := range propertyModel.StringEnumValues {
+			code.Print("\"%s\": %s_%s,", value, typeName, names[i+1])
+		}
+		code.Print("}\n")
+		code.Print("var %s = map[%s]string{", domain.enumVariableName(typeName, propertyModel, "Names"), enumTypeName)
+		for i, value := range propertyModel.StringEnumValues {
+			code.Print("%s_%s: \"%s\",", typeName, names[i+1], value)
+		}
+		code.Print("}\n")
+	}
+}
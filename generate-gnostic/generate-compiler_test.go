@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/imports"
+)
+
+// rawInfoProgram writes the yaml of the ToRawInfo nodes of the documents named by its
+// arguments, using the openapi_v3 package that it is generated with.
+const rawInfoProgram = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+
+	openapi_v3 "PACKAGE"
+)
+
+func main() {
+	for _, filename := range os.Args[1:] {
+		fmt.Printf("# %s\n", filename)
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			panic(err)
+		}
+		document, err := openapi_v3.ParseDocument(data)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			continue
+		}
+		output, err := yaml.Marshal(document.ToRawInfo())
+		if err != nil {
+			panic(err)
+		}
+		os.Stdout.Write(output)
+	}
+}
+`
+
+// TestToRawInfoEquivalence checks that the ToRawInfo methods that are generated for OpenAPI v3
+// describe documents like those of the pinned gnostic-models package, which were generated
+// before they were changed to allocate less. The generated methods replace those of a copy
+// of the package, and the yaml of the v3 examples and test documents is compared.
+func TestToRawInfoEquivalence(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds two copies of the OpenAPI v3 models")
+	}
+	output, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/google/gnostic-models").Output()
+	if err != nil {
+		t.Fatalf("error locating gnostic-models: %v", err)
+	}
+	modelsDir := filepath.Join(strings.TrimSpace(string(output)), "openapiv3")
+	cc, err := newOpenAPIDomain("v3", "../openapiv3/openapi-3.1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated := cc.GenerateCompiler("openapi_v3", License, openAPIModelImports)
+	models, err := os.ReadFile(filepath.Join(modelsDir, "OpenAPIv3.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := replaceToRawInfoMethods(models, []byte(generated))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The copy and the programs are built in the module, which provides their dependencies.
+	dir := "rawinfo-test"
+	defer os.RemoveAll(dir)
+	packageDir := filepath.Join(dir, "openapi_v3")
+	if err = os.MkdirAll(packageDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"OpenAPIv3.pb.go", "document.go"} {
+		data, err := os.ReadFile(filepath.Join(modelsDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(packageDir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = os.WriteFile(filepath.Join(packageDir, "OpenAPIv3.go"), code, 0644); err != nil {
+		t.Fatal(err)
+	}
+	programs := map[string]string{
+		"models":    "github.com/google/gnostic-models/openapiv3",
+		"generated": "github.com/google/gnostic/generate-gnostic/" + dir + "/openapi_v3",
+	}
+	binDir := t.TempDir()
+	for name, packagePath := range programs {
+		programDir := filepath.Join(dir, name)
+		if err = os.MkdirAll(programDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		program := strings.Replace(rawInfoProgram, "PACKAGE", packagePath, 1)
+		if err = os.WriteFile(filepath.Join(programDir, "main.go"), []byte(program), 0644); err != nil {
+			t.Fatal(err)
+		}
+		command := exec.Command("go", "build", "-o", filepath.Join(binDir, name), "./"+programDir)
+		if output, err := command.CombinedOutput(); err != nil {
+			t.Fatalf("error building %s: %v %s", name, err, output)
+		}
+	}
+
+	var documents []string
+	for _, pattern := range []string{
+		"../examples/v3.0/yaml/*.yaml",
+		"../examples/v3.0/json/*.json",
+		"../testdata/v3.0/yaml/*.yaml",
+		"../testdata/v3.0/json/*.json",
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		documents = append(documents, matches...)
+	}
+	if len(documents) == 0 {
+		t.Fatal("no documents to compare")
+	}
+	results := make(map[string]map[string]string)
+	for name := range programs {
+		output, err := exec.Command(filepath.Join(binDir, name), documents...).Output()
+		if err != nil {
+			t.Fatalf("error running %s: %v", name, err)
+		}
+		results[name] = splitRawInfoOutput(string(output))
+	}
+	for _, document := range documents {
+		expected, actual := results["models"][document], results["generated"][document]
+		if actual != expected {
+			t.Errorf("%s: generated ToRawInfo methods differ from gnostic-models:\n%s\n(expected)\n%s", document, actual, expected)
+		}
+	}
+}
+
+// replaceToRawInfoMethods returns the source of models with its ToRawInfo methods replaced
+// by those of generated, along with the functions that only generated declares.
+func replaceToRawInfoMethods(models []byte, generated []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	modelsFile, err := parser.ParseFile(fset, "models.go", models, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	generatedFile, err := parser.ParseFile(fset, "generated.go", generated, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	declared := make(map[string]bool)
+	var removed [][2]int
+	for _, decl := range modelsFile.Decls {
+		if f, ok := decl.(*ast.FuncDecl); ok {
+			if isToRawInfoMethod(f) {
+				removed = append(removed, declRange(fset, f))
+			} else if f.Recv == nil {
+				declared[f.Name.Name] = true
+			}
+		}
+	}
+	var code bytes.Buffer
+	start := 0
+	sort.Slice(removed, func(i, j int) bool { return removed[i][0] < removed[j][0] })
+	for _, r := range removed {
+		code.Write(models[start:r[0]])
+		start = r[1]
+	}
+	code.Write(models[start:])
+	for _, decl := range generatedFile.Decls {
+		if f, ok := decl.(*ast.FuncDecl); ok && (isToRawInfoMethod(f) || f.Recv == nil && !declared[f.Name.Name]) {
+			r := declRange(fset, f)
+			code.WriteString("\n")
+			code.Write(generated[r[0]:r[1]])
+			code.WriteString("\n")
+		}
+	}
+	return imports.Process("OpenAPIv3.go", code.Bytes(), nil)
+}
+
+func isToRawInfoMethod(f *ast.FuncDecl) bool {
+	return f.Recv != nil && f.Name.Name == "ToRawInfo"
+}
+
+// declRange returns the offsets of a function declaration and its doc comment.
+func declRange(fset *token.FileSet, f *ast.FuncDecl) [2]int {
+	start := f.Pos()
+	if f.Doc != nil {
+		start = f.Doc.Pos()
+	}
+	return [2]int{fset.Position(start).Offset, fset.Position(f.End()).Offset}
+}
+
+// splitRawInfoOutput returns the output of a raw info program for each document.
+func splitRawInfoOutput(output string) map[string]string {
+	results := make(map[string]string)
+	for _, section := range strings.Split(output, "# ")[1:] {
+		filename, text, _ := strings.Cut(section, "\n")
+		results[filename] = text
+	}
+	return results
+}
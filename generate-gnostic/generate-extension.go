@@ -179,6 +179,7 @@ func generateExtension(schemaFile string, outDir string) error {
 
 	// build a simplified model of the types described by the schema
 	cc := NewDomain(openapiSchema, "v2") // TODO fix for OpenAPI v3
+	cc.GenerateEnums = true
 
 	// create a type for each object defined in the schema
 	extensionNameToMessageName := make(map[string]generatedTypeInfo)
@@ -230,6 +231,8 @@ func generateExtension(schemaFile string, outDir string) error {
 		// error has been reported.
 		return compiler.NewErrorGroupOrNil(schemaErrors)
 	}
+	// create types for nested objects and arrays of objects
+	cc.buildRequestedObjectTypes()
 
 	err = os.MkdirAll(outDir, os.ModePerm)
 	if err != nil {
@@ -271,6 +274,7 @@ func generateExtension(schemaFile string, outDir string) error {
 	compiler := cc.GenerateCompiler(goPackageName, License, []string{
 		"fmt",
 		"regexp",
+		"strconv",
 		"strings",
 		"github.com/google/gnostic/compiler",
 		"go.yaml.in/yaml/v3",
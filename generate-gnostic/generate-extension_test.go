@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -56,3 +57,50 @@ func TestErrorExtensionGeneratorNameCollision(t *testing.T) {
 		os.Remove(outputFile)
 	}
 }
+
+func TestExtensionGeneratorNestedTypes(t *testing.T) {
+	outDir := t.TempDir()
+	output, err := exec.Command(
+		"generate-gnostic",
+		"--extension",
+		"../extensions/sample/x-samplethree.json",
+		"--out_dir="+outDir,
+	).CombinedOutput()
+	if err != nil || len(output) != 0 {
+		t.Fatalf("error executing generate-gnostic: %v %s", err, output)
+	}
+	// The proto has messages for the nested object and array items and an enum for the status.
+	protoFile := filepath.Join(outDir, "gnostic-x-samplethree", "proto", "x-samplethree.proto")
+	err = exec.Command("diff", protoFile, "test/generated/x-samplethree.proto").Run()
+	if err != nil {
+		t.Fatalf("Diff failed: %+v", err)
+	}
+}
+
+func TestExtensionGeneratorNestedTypesCompile(t *testing.T) {
+	for _, tool := range []string{"protoc", "protoc-gen-go"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is required to compile the generated code", tool)
+		}
+	}
+	// The generated handler imports its package from its location in the samples directory.
+	sampleDir := "../extensions/sample"
+	outDir := "generated-test"
+	defer os.RemoveAll(filepath.Join(sampleDir, outDir))
+	command := exec.Command("generate-gnostic", "--extension", "x-samplethree.json", "--out_dir="+outDir)
+	command.Dir = sampleDir
+	if output, err := command.CombinedOutput(); err != nil || len(output) != 0 {
+		t.Fatalf("error executing generate-gnostic: %v %s", err, output)
+	}
+	handlerDir := filepath.Join(sampleDir, outDir, "gnostic-x-samplethree")
+	command = exec.Command("protoc", "--go_out=.", "--go_opt=paths=source_relative", "x-samplethree.proto")
+	command.Dir = filepath.Join(handlerDir, "proto")
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("error executing protoc: %v %s", err, output)
+	}
+	command = exec.Command("go", "build", "-o", filepath.Join(t.TempDir(), "gnostic-x-samplethree"), ".")
+	command.Dir = handlerDir
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("generated handler doesn't compile: %v %s", err, output)
+	}
+}
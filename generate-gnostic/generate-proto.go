@@ -79,6 +79,12 @@ func (domain *Domain) generateProtoMessage(code *printer.Code, typeName string)
 	}
 	code.Print("message %s {", typeName)
 	code.Indent()
+	// generate enum definitions for enumerated string properties
+	for _, propertyModel := range typeModel.Properties {
+		if domain.enumTypeForProperty(propertyModel) != "" {
+			domain.generateProtoEnum(code, propertyModel)
+		}
+	}
 	if typeModel.OneOfWrapper {
 		code.Print("oneof oneof {")
 		code.Indent()
@@ -100,6 +106,9 @@ func (domain *Domain) generateProtoMessage(code *printer.Code, typeName string)
 		if propertyType == "blob" {
 			propertyType = "string"
 		}
+		if enumType := domain.enumTypeForProperty(propertyModel); enumType != "" {
+			propertyType = enumType
+		}
 		// adjust the display name to a valid identifier
 		propertyName := propertyModel.Name
 		var displayName = propertyName
@@ -127,3 +136,16 @@ func (domain *Domain) generateProtoMessage(code *printer.Code, typeName string)
 	code.Print("}")
 	code.Print()
 }
+
+func (domain *Domain) generateProtoEnum(code *printer.Code, propertyModel *TypeProperty) {
+	code.Print("enum %s {", domain.enumTypeForProperty(propertyModel))
+	code.Indent()
+	for i, name := range domain.enumValueNamesForProperty(propertyModel) {
+		if i > 0 {
+			code.Print("// %s", propertyModel.StringEnumValues[i-1])
+		}
+		code.Print("%s = %d;", name, i)
+	}
+	code.Outdent()
+	code.Print("}")
+}
@@ -53,3 +53,20 @@ func snakeCaseToCamelCase(input string) string {
 
 	return out
 }
+
+// Returns an upper-case identifier for an enumerated string value.
+func enumValueIdentifier(value string) string {
+	out := ""
+	for _, runeValue := range strings.ToUpper(value) {
+		if (runeValue >= 'A' && runeValue <= 'Z') || (runeValue >= '0' && runeValue <= '9') {
+			out += string(runeValue)
+		} else if len(out) > 0 && !strings.HasSuffix(out, "_") {
+			out += "_"
+		}
+	}
+	out = strings.TrimSuffix(out, "_")
+	if out == "" {
+		return "VALUE"
+	}
+	return out
+}
@@ -47,6 +47,16 @@ const License = "" +
 	"// See the License for the specific language governing permissions and\n" +
 	"// limitations under the License.\n"
 
+// openAPIModelImports are the packages imported by the support code of the OpenAPI models.
+var openAPIModelImports = []string{
+	"fmt",
+	"go.yaml.in/yaml/v3",
+	"strings",
+	"regexp",
+	"strconv",
+	"github.com/google/gnostic/compiler",
+}
+
 func protoOptions(directoryName string, packageName string) []ProtoOption {
 	return []ProtoOption{
 		{
@@ -120,50 +130,7 @@ func generateOpenAPIModel(version string) error {
 
 	projectRoot := "./"
 
-	baseSchema, err := jsonschema.NewBaseSchema()
-	if err != nil {
-		return err
-	}
-	baseSchema.ResolveRefs()
-	baseSchema.ResolveAllOfs()
-
-	openapiSchema, err := jsonschema.NewSchemaFromFile(projectRoot + directoryName + "/" + input)
-	if err != nil {
-		return err
-	}
-	openapiSchema.ResolveRefs()
-	openapiSchema.ResolveAllOfs()
-
-	// build a simplified model of the types described by the schema
-	cc := NewDomain(openapiSchema, version)
-	// generators will map these patterns to the associated property names
-	// these pattern names are a bit of a hack until we find a more automated way to obtain them
-
-	switch version {
-	case "v2":
-		cc.TypeNameOverrides = map[string]string{
-			"VendorExtension": "Any",
-		}
-		cc.PropertyNameOverrides = map[string]string{
-			"PathItem":      "Path",
-			"ResponseValue": "ResponseCode",
-		}
-	case "v3":
-		cc.TypeNameOverrides = map[string]string{
-			"SpecificationExtension": "Any",
-		}
-		cc.PropertyNameOverrides = map[string]string{
-			"PathItem":      "Path",
-			"ResponseValue": "ResponseCode",
-		}
-	case "discovery":
-		cc.TypeNameOverrides = map[string]string{}
-		cc.PropertyNameOverrides = map[string]string{}
-	default:
-		return fmt.Errorf("Unknown OpenAPI version %s", version)
-	}
-
-	err = cc.Build()
+	cc, err := newOpenAPIDomain(version, projectRoot+directoryName+"/"+input)
 	if err != nil {
 		return err
 	}
@@ -188,16 +155,9 @@ func generateOpenAPIModel(version string) error {
 		return err
 	}
 
-	packageImports := []string{
-		"fmt",
-		"go.yaml.in/yaml/v3",
-		"strings",
-		"regexp",
-		"github.com/google/gnostic/compiler",
-	}
 	// generate the compiler
 	log.Printf("Generating compiler support code")
-	compiler := cc.GenerateCompiler(goPackageName, License, packageImports)
+	compiler := cc.GenerateCompiler(goPackageName, License, openAPIModelImports)
 	goFileName := projectRoot + directoryName + "/" + filename + ".go"
 
 	// format the compiler
@@ -216,6 +176,59 @@ func generateOpenAPIModel(version string) error {
 	return ioutil.WriteFile(goFileName, []byte(data), 0644)
 }
 
+// newOpenAPIDomain builds a simplified model of the types of an OpenAPI version,
+// which are described by the JSON schema in schemaFile.
+func newOpenAPIDomain(version string, schemaFile string) (*Domain, error) {
+	baseSchema, err := jsonschema.NewBaseSchema()
+	if err != nil {
+		return nil, err
+	}
+	baseSchema.ResolveRefs()
+	baseSchema.ResolveAllOfs()
+
+	openapiSchema, err := jsonschema.NewSchemaFromFile(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	openapiSchema.ResolveRefs()
+	openapiSchema.ResolveAllOfs()
+
+	// build a simplified model of the types described by the schema
+	cc := NewDomain(openapiSchema, version)
+	// generators will map these patterns to the associated property names
+	// these pattern names are a bit of a hack until we find a more automated way to obtain them
+
+	switch version {
+	case "v2":
+		cc.TypeNameOverrides = map[string]string{
+			"VendorExtension": "Any",
+		}
+		cc.PropertyNameOverrides = map[string]string{
+			"PathItem":      "Path",
+			"ResponseValue": "ResponseCode",
+		}
+	case "v3":
+		cc.TypeNameOverrides = map[string]string{
+			"SpecificationExtension": "Any",
+		}
+		cc.PropertyNameOverrides = map[string]string{
+			"PathItem":      "Path",
+			"ResponseValue": "ResponseCode",
+		}
+	case "discovery":
+		cc.TypeNameOverrides = map[string]string{}
+		cc.PropertyNameOverrides = map[string]string{}
+	default:
+		return nil, fmt.Errorf("Unknown OpenAPI version %s", version)
+	}
+
+	err = cc.Build()
+	if err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
 func usage() string {
 	return fmt.Sprintf(`
 Usage: %s [OPTIONS]
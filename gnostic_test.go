@@ -15,16 +15,71 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/google/gnostic/compiler"
+	extensions "github.com/google/gnostic/extensions"
 	"github.com/google/gnostic/lib"
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	openapi_v3 "github.com/google/gnostic/openapiv3"
+	plugins "github.com/google/gnostic/plugins"
 )
 
+// When this environment variable is set, the test binary acts as a plugin that writes the
+// parameters of its request to a file named parameters.txt.
+const stubPluginVariable = "GNOSTIC_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stubPluginVariable) != "" {
+		echoParametersPluginMain()
+	}
+	os.Exit(m.Run())
+}
+
+func echoParametersPluginMain() {
+	env, err := plugins.NewEnvironment()
+	env.RespondAndExitIfError(err)
+	var b strings.Builder
+	for _, parameter := range env.Request.Parameters {
+		fmt.Fprintf(&b, "%s=%s\n", parameter.Name, parameter.Value)
+	}
+	if value, ok := env.Parameter("style"); ok {
+		fmt.Fprintf(&b, "style is %s\n", value)
+	}
+	env.Response.Files = append(env.Response.Files, &plugins.File{Name: "parameters.txt", Data: []byte(b.String())})
+	env.RespondAndExit()
+}
+
+// Installs the test binary as a plugin named gnostic-echo.
+func setupStubPlugin(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink(os.Args[0], filepath.Join(dir, "gnostic-echo")); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(stubPluginVariable, "echo")
+}
+
 func isURL(path string) bool {
 	_, err := url.ParseRequestURI(path)
 	if err != nil {
@@ -37,7 +92,7 @@ func isURL(path string) bool {
 	return true
 }
 
-func testCompiler(t *testing.T, inputFile string, referenceFile string, expectErrors bool) {
+func testCompiler(t *testing.T, inputFile string, referenceFile string, expectErrors bool, options ...string) {
 	outputFormat := filepath.Ext(referenceFile)[1:]
 	outputFile := strings.Replace(inputFile, filepath.Ext(inputFile), "."+outputFormat, 1)
 	errorsFile := strings.Replace(inputFile, filepath.Ext(inputFile), ".errors", 1)
@@ -57,7 +112,7 @@ func testCompiler(t *testing.T, inputFile string, referenceFile string, expectEr
 		"--" + outputFormat + "-out=.",
 		"--errors-out=.",
 		"--resolve-refs"}
-	g := lib.NewGnostic(args)
+	g := lib.NewGnostic(append(args, options...))
 	err = g.Main()
 	// verify the output against a reference
 	var testFile string
@@ -81,8 +136,8 @@ func testNormal(t *testing.T, inputFile string, referenceFile string) {
 	testCompiler(t, inputFile, referenceFile, false)
 }
 
-func testErrors(t *testing.T, inputFile string, referenceFile string) {
-	testCompiler(t, inputFile, referenceFile, true)
+func testErrors(t *testing.T, inputFile string, referenceFile string, options ...string) {
+	testCompiler(t, inputFile, referenceFile, true, options...)
 }
 
 func TestPetstoreJSON(t *testing.T) {
@@ -136,7 +191,15 @@ func TestRemoteSeparateJSON(t *testing.T) {
 func TestErrorBadProperties(t *testing.T) {
 	testErrors(t,
 		"examples/errors/petstore-badproperties.yaml",
-		"testdata/errors/petstore-badproperties.errors")
+		"testdata/errors/petstore-badproperties.errors",
+		"--strict-keys")
+}
+
+func TestErrorInvalidKeys(t *testing.T) {
+	testErrors(t,
+		"examples/errors/petstore-invalidkeys.yaml",
+		"testdata/errors/petstore-invalidkeys.errors",
+		"--strict-keys")
 }
 
 func TestErrorUnresolvedRefs(t *testing.T) {
@@ -294,3 +357,995 @@ func TestDiscoveryJSON(t *testing.T) {
 		"examples/discovery/discovery-v1.json",
 		"testdata/discovery/discovery-v1.text")
 }
+
+func TestCheckConflicts(t *testing.T) {
+	inputFile := "testdata/v3.0/yaml/conflicts.yaml"
+	messagesFile := "conflicts.messages.pb"
+	os.Remove(messagesFile)
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--check-conflicts", "--messages-out=" + messagesFile})
+	if err := g.Main(); err != nil {
+		t.Fatalf("Compile failed: %+v", err)
+	}
+	data, err := os.ReadFile(messagesFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	messages := &plugins.Messages{}
+	if err = proto.Unmarshal(data, messages); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	expected := []string{
+		"DUPLICATE_PATH operation get /pets/{petId} differs from get /pets/{id} only by path parameter names (first defined at paths./pets/{id}.get) [paths /pets/{petId} get]",
+		"DUPLICATE_OPERATION_ID duplicate operationId \"getPet\" (first defined at paths./pets/{id}.get.operationId) [paths /shelters get operationId]",
+	}
+	if len(messages.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %d: %+v", len(expected), len(messages.Messages), messages.Messages)
+	}
+	for i, message := range messages.Messages {
+		got := fmt.Sprintf("%s %s %v", message.Code, message.Text, message.Keys)
+		if got != expected[i] {
+			t.Errorf("unexpected message %q (expected %q)", got, expected[i])
+		}
+	}
+	os.Remove(messagesFile)
+}
+
+func TestExtensionHandlerErrors(t *testing.T) {
+	inputFile := "testdata/v3.0/yaml/extensions.yaml"
+	// "false" is a handler that always fails.
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--extension-wildcard=false", "--text-out=-"})
+	err := g.Main()
+	if err == nil {
+		t.Fatalf("expected handler failure to be an error")
+	}
+	for _, text := range []string{"$root.info.x-audit", "extension x-audit", "extension handler false failed"} {
+		if !strings.Contains(err.Error(), text) {
+			t.Errorf("expected %q in error %q", text, err)
+		}
+	}
+	messagesFile := "extensions.messages.pb"
+	os.Remove(messagesFile)
+	g = lib.NewGnostic([]string{"gnostic", inputFile, "--extension-wildcard=false", "--extension-errors=warn", "--messages-out=" + messagesFile})
+	if err = g.Main(); err != nil {
+		t.Fatalf("expected handler failure to be a warning: %+v", err)
+	}
+	data, err := os.ReadFile(messagesFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	messages := &plugins.Messages{}
+	if err = proto.Unmarshal(data, messages); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(messages.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %+v", messages.Messages)
+	}
+	message := messages.Messages[0]
+	if message.Level != plugins.Message_WARNING || message.Code != "EXTENSION_HANDLER_FAILED" ||
+		message.Text != "extension x-audit: extension handler false failed: exit status 1" ||
+		fmt.Sprintf("%v", message.Keys) != "[info x-audit]" {
+		t.Errorf("unexpected message %+v", message)
+	}
+	os.Remove(messagesFile)
+}
+
+func TestStrictExtensions(t *testing.T) {
+	inputFile := "testdata/v3.0/yaml/unhandled-extensions.yaml"
+	// A handler that accepts every extension.
+	dir := t.TempDir()
+	script, err := os.ReadFile("compiler/testdata/stub-extension-handler.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := filepath.Join(dir, "handler.sh")
+	if err = os.WriteFile(handler, script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	response, err := proto.Marshal(&extensions.ExtensionHandlerResponse{
+		Handled: true,
+		Value:   &anypb.Any{TypeUrl: "type.googleapis.com/stub", Value: []byte("handled")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "response.pb"), response, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "default"},
+		{name: "strict-pass", args: []string{"--strict-extensions", "--extension-wildcard=" + handler}},
+		{
+			name: "strict-fail",
+			args: []string{"--strict-extensions"},
+			expected: "[5,12] $root.info.x-audit extension x-audit has no handler (used 2 times)\n" +
+				"[10,19] $root.paths./pets.get.x-internal extension x-internal has no handler",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := lib.NewGnostic(append([]string{"gnostic", inputFile, "--text-out=" + os.DevNull}, tt.args...))
+			err := g.Main()
+			if tt.expected == "" && err != nil {
+				t.Errorf("Compile failed: %+v", err)
+			} else if tt.expected != "" && (err == nil || !strings.HasSuffix(err.Error(), tt.expected)) {
+				t.Errorf("unexpected error %v (expected %q)", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtensionHandlerWarnings(t *testing.T) {
+	inputFile := "testdata/v3.0/yaml/unhandled-extensions.yaml"
+	// A handler that accepts every extension with a warning.
+	dir := t.TempDir()
+	script, err := os.ReadFile("compiler/testdata/stub-extension-handler.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := filepath.Join(dir, "handler.sh")
+	if err = os.WriteFile(handler, script, 0755); err != nil {
+		t.Fatal(err)
+	}
+	response, err := proto.Marshal(&extensions.ExtensionHandlerSingleResponse{
+		Handled:  true,
+		Value:    &anypb.Any{TypeUrl: "type.googleapis.com/stub", Value: []byte("handled")},
+		Warnings: []string{"value should be lowercase"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "response.pb"), response, 0644); err != nil {
+		t.Fatal(err)
+	}
+	messagesFile := filepath.Join(dir, "messages.pb")
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--text-out=" + os.DevNull, "--extension-wildcard=" + handler, "--messages-out=" + messagesFile})
+	if err = g.Main(); err != nil {
+		t.Fatalf("expected handler warnings not to fail: %+v", err)
+	}
+	data, err := os.ReadFile(messagesFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	messages := &plugins.Messages{}
+	if err = proto.Unmarshal(data, messages); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	expected := []string{
+		"[info x-audit] extension x-audit: value should be lowercase",
+		"[paths /pets get x-audit] extension x-audit: value should be lowercase",
+		"[paths /pets get x-internal] extension x-internal: value should be lowercase",
+	}
+	if len(messages.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), messages.Messages)
+	}
+	for i, message := range messages.Messages {
+		if message.Level != plugins.Message_WARNING || message.Code != "EXTENSION_HANDLER_WARNING" ||
+			fmt.Sprintf("%v %s", message.Keys, message.Text) != expected[i] {
+			t.Errorf("unexpected message %+v (expected %q)", message, expected[i])
+		}
+	}
+}
+
+func TestMainContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g := lib.NewGnostic([]string{"gnostic", "examples/v3.0/yaml/petstore.yaml", "--text-out=-"})
+	if err := g.MainContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the compilation to be canceled, got %v", err)
+	}
+}
+
+func TestDocumentLimits(t *testing.T) {
+	for _, test := range []struct {
+		option   string
+		expected string
+	}{
+		{"--max-input-bytes=100", "document exceeds the maximum size of 100 bytes"},
+		{"--max-depth=3", "document exceeds the maximum nesting depth of 3"},
+		{"--max-depth=0", "invalid depth limit"},
+	} {
+		g := lib.NewGnostic([]string{"gnostic", "examples/v3.0/yaml/petstore.yaml", "--text-out=-", test.option})
+		err := g.Main()
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected %q in error %v", test.option, test.expected, err)
+		}
+	}
+	// Limits set with options don't outlast the compilation.
+	if limits := compiler.CurrentLimits(); limits.MaxDepth != compiler.DefaultMaxDepth || limits.MaxInputBytes != compiler.DefaultMaxInputBytes {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+}
+
+func TestConcurrentCompilations(t *testing.T) {
+	inputs := []string{
+		"examples/v2.0/yaml/petstore-separate/spec/swagger.yaml",
+		"examples/v2.0/json/petstore-separate/spec/swagger.json",
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dir := t.TempDir()
+			g := lib.NewGnostic([]string{"gnostic", inputs[i%len(inputs)], "--text-out=" + filepath.Join(dir, "swagger.text"), "--resolve-refs"})
+			if errs[i] = g.Main(); errs[i] != nil {
+				return
+			}
+			errs[i] = exec.Command("diff", filepath.Join(dir, "swagger.text"), "testdata/v2.0/yaml/petstore-separate/spec/swagger.text").Run()
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("compilation %d failed: %v", i, err)
+		}
+	}
+}
+
+func TestInvalidKeyWarnings(t *testing.T) {
+	inputFile := "examples/errors/petstore-invalidkeys.yaml"
+	messagesFile := filepath.Join(t.TempDir(), "messages.pb")
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--text-out=" + os.DevNull, "--messages-out=" + messagesFile})
+	if err := g.Main(); err != nil {
+		t.Fatalf("expected invalid keys not to fail: %+v", err)
+	}
+	data, err := os.ReadFile(messagesFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	messages := &plugins.Messages{}
+	if err = proto.Unmarshal(data, messages); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	expected := []string{
+		"[responces] has invalid property: responces",
+		"[components schemas Pet schema requird] has invalid property: requird",
+	}
+	if len(messages.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), messages.Messages)
+	}
+	for i, message := range messages.Messages {
+		if message.Level != plugins.Message_WARNING || message.Code != "INVALID_KEY" ||
+			fmt.Sprintf("%v %s", message.Keys, message.Text) != expected[i] {
+			t.Errorf("unexpected message %+v (expected %q)", message, expected[i])
+		}
+	}
+}
+
+func TestExtensionValueTypes(t *testing.T) {
+	for _, inputFile := range []string{
+		"testdata/v2.0/yaml/extension-values.yaml",
+		"testdata/v3.0/yaml/extension-values.yaml",
+	} {
+		t.Run(inputFile, func(t *testing.T) {
+			input, err := os.ReadFile(inputFile)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			var expected interface{}
+			if err = yaml.Unmarshal(input, &expected); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			dir := t.TempDir()
+			yamlFile := filepath.Join(dir, "out.yaml")
+			jsonFile := filepath.Join(dir, "out.json")
+			g := lib.NewGnostic([]string{"gnostic", inputFile, "--yaml-out=" + yamlFile, "--json-out=" + jsonFile})
+			if err = g.Main(); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			// Numbers and booleans stay numbers and booleans, and strings that look like
+			// them stay strings, in both YAML and JSON.
+			for _, outputFile := range []string{yamlFile, jsonFile} {
+				output, err := os.ReadFile(outputFile)
+				if err != nil {
+					t.Fatalf("%+v", err)
+				}
+				var actual interface{}
+				if err = yaml.Unmarshal(output, &actual); err != nil {
+					t.Fatalf("%+v", err)
+				}
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("unexpected values in %s:\n%s", filepath.Base(outputFile), output)
+				}
+			}
+		})
+	}
+}
+
+func TestReferenceSiblings(t *testing.T) {
+	for _, test := range []struct {
+		inputFile    string
+		expectedFile string
+	}{
+		// In 3.0, keys of References other than summary and description are ignored.
+		{"testdata/v3.0/yaml/ref-siblings.yaml", "testdata/v3.0/yaml/ref-siblings.out.yaml"},
+		// In 3.1, schemas with a $ref keep their other keywords.
+		{"testdata/v3.1/yaml/ref-siblings.yaml", "testdata/v3.1/yaml/ref-siblings.yaml"},
+	} {
+		t.Run(test.inputFile, func(t *testing.T) {
+			expectedBytes, err := os.ReadFile(test.expectedFile)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			var expected interface{}
+			if err = yaml.Unmarshal(expectedBytes, &expected); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			dir := t.TempDir()
+			once := filepath.Join(dir, "once.yaml")
+			twice := filepath.Join(dir, "twice.yaml")
+			// The output is read again to check that it is read the same way.
+			for _, args := range [][]string{
+				{"gnostic", test.inputFile, "--yaml-out=" + once},
+				{"gnostic", once, "--yaml-out=" + twice},
+			} {
+				if err = lib.NewGnostic(args).Main(); err != nil {
+					t.Fatalf("Compile failed for command %v: %+v", strings.Join(args, " "), err)
+				}
+			}
+			for _, outputFile := range []string{once, twice} {
+				output, err := os.ReadFile(outputFile)
+				if err != nil {
+					t.Fatalf("%+v", err)
+				}
+				var actual interface{}
+				if err = yaml.Unmarshal(output, &actual); err != nil {
+					t.Fatalf("%+v", err)
+				}
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("unexpected document in %s:\n%s", filepath.Base(outputFile), output)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseOrder(t *testing.T) {
+	// The fixture has default responses first, last, and between ranges of status codes.
+	inputFile := "testdata/v3.0/yaml/response-ranges.yaml"
+	expected, err := os.ReadFile(inputFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	dir := t.TempDir()
+	for name, commands := range map[string][][]string{
+		"yaml": {
+			{"gnostic", inputFile, "--yaml-out=" + filepath.Join(dir, "yaml.yaml")},
+		},
+		"pb": {
+			{"gnostic", inputFile, "--pb-out=" + filepath.Join(dir, "pb.pb")},
+			{"gnostic", filepath.Join(dir, "pb.pb"), "--yaml-out=" + filepath.Join(dir, "pb.yaml")},
+		},
+		"json": {
+			{"gnostic", inputFile, "--json-out=" + filepath.Join(dir, "json.json")},
+			{"gnostic", filepath.Join(dir, "json.json"), "--yaml-out=" + filepath.Join(dir, "json.yaml")},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, args := range commands {
+				if err := lib.NewGnostic(args).Main(); err != nil {
+					t.Fatalf("Compile failed for command %v: %+v", strings.Join(args, " "), err)
+				}
+			}
+			output, err := os.ReadFile(filepath.Join(dir, name+".yaml"))
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if !bytes.Equal(output, expected) {
+				t.Errorf("unexpected YAML:\n%s\n(expected\n%s)", output, expected)
+			}
+		})
+	}
+}
+
+func TestRenderYAMLMatchesYAMLOutput(t *testing.T) {
+	for _, test := range []struct {
+		inputFile string
+		render    func([]byte) ([]byte, error)
+	}{
+		{"examples/v2.0/yaml/petstore.yaml", func(b []byte) ([]byte, error) {
+			document, err := openapi_v2.ParseDocument(b)
+			if err != nil {
+				return nil, err
+			}
+			return openapi_v2.RenderYAML(document, openapi_v2.YAMLOptions{})
+		}},
+		{"examples/v3.0/yaml/petstore.yaml", func(b []byte) ([]byte, error) {
+			document, err := openapi_v3.ParseDocument(b)
+			if err != nil {
+				return nil, err
+			}
+			return openapi_v3.RenderYAML(document, openapi_v3.YAMLOptions{})
+		}},
+	} {
+		t.Run(test.inputFile, func(t *testing.T) {
+			yamlFile := filepath.Join(t.TempDir(), "out.yaml")
+			if err := lib.NewGnostic([]string{"gnostic", test.inputFile, "--yaml-out=" + yamlFile}).Main(); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			expected, err := os.ReadFile(yamlFile)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			input, err := os.ReadFile(test.inputFile)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			rendered, err := test.render(input)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if !bytes.Equal(rendered, expected) {
+				t.Errorf("unexpected YAML:\n%s\n(expected\n%s)", rendered, expected)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	dir := t.TempDir()
+	once := filepath.Join(dir, "once.yaml")
+	twice := filepath.Join(dir, "twice.yaml")
+	for _, args := range [][]string{
+		{"gnostic", "normalize", "examples/v3.0/yaml/petstore.yaml", "--yaml-out=" + once},
+		{"gnostic", "normalize", once, "--yaml-out=" + twice},
+	} {
+		if err := lib.NewGnostic(args).Main(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	onceBytes, err := os.ReadFile(once)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	twiceBytes, err := os.ReadFile(twice)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !bytes.Equal(onceBytes, twiceBytes) {
+		t.Errorf("normalizing twice changed the document:\n%s\n%s", onceBytes, twiceBytes)
+	}
+	// Normalization is only defined for OpenAPI v3.
+	g := lib.NewGnostic([]string{"gnostic", "normalize", "examples/v2.0/yaml/petstore.yaml", "--yaml-out=" + os.DevNull})
+	if err := g.Main(); err == nil || !strings.Contains(err.Error(), "requires an OpenAPI v3 description") {
+		t.Errorf("expected an error for an OpenAPI v2 description, got %v", err)
+	}
+}
+
+func TestReferenceCycles(t *testing.T) {
+	for _, test := range []struct {
+		inputFile string
+		cycles    []string
+	}{
+		{
+			inputFile: "testdata/v3.0/yaml/cycles.yaml",
+			cycles: []string{
+				"reference cycle: #/components/schemas/A -> #/components/schemas/B -> #/components/schemas/A",
+				"reference cycle: #/components/schemas/X -> #/components/schemas/Y -> #/components/schemas/Z -> #/components/schemas/X",
+				"reference cycle: #/components/schemas/Self -> #/components/schemas/Self",
+			},
+		},
+		{
+			inputFile: "testdata/v2.0/yaml/cycles.yaml",
+			cycles: []string{
+				"reference cycle: #/definitions/Node -> #/definitions/Node",
+				"reference cycle: #/definitions/Child -> #/definitions/Parent -> #/definitions/Child",
+			},
+		},
+	} {
+		t.Run(test.inputFile, func(t *testing.T) {
+			messagesFile := filepath.Join(t.TempDir(), "messages.pb")
+			g := lib.NewGnostic([]string{"gnostic", test.inputFile, "--resolve-refs", "--text-out=" + os.DevNull, "--messages-out=" + messagesFile})
+			if err := g.Main(); err != nil {
+				t.Fatalf("expected reference cycles not to fail: %+v", err)
+			}
+			data, err := os.ReadFile(messagesFile)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			messages := &plugins.Messages{}
+			if err = proto.Unmarshal(data, messages); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if len(messages.Messages) != len(test.cycles) {
+				t.Fatalf("expected %d messages, got %+v", len(test.cycles), messages.Messages)
+			}
+			for i, message := range messages.Messages {
+				if message.Level != plugins.Message_WARNING || message.Code != "REFERENCE_CYCLE" || message.Text != test.cycles[i] {
+					t.Errorf("unexpected message %+v (expected %q)", message, test.cycles[i])
+				}
+			}
+			// With --strict-refs, the cycles are errors.
+			g = lib.NewGnostic([]string{"gnostic", test.inputFile, "--resolve-refs", "--strict-refs", "--text-out=" + os.DevNull, "--errors-out=" + os.DevNull})
+			err = g.Main()
+			if err == nil || err.Error() != strings.Join(test.cycles, "\n") {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestFillDefaults(t *testing.T) {
+	// Sparse descriptions that omit operationIds, response descriptions, and titles.
+	testCompiler(t, "testdata/v3.0/yaml/sparse.yaml", "testdata/v3.0/sparse-filled.text", false, "--fill-defaults")
+	testCompiler(t, "testdata/v2.0/yaml/sparse.yaml", "testdata/v2.0/sparse-filled.text", false, "--fill-defaults")
+}
+
+func TestYAML11Compat(t *testing.T) {
+	inputFile := "testdata/v3.0/yaml/yaml11.yaml"
+	// By default, YAML 1.1 booleans are strings and can't be booleans.
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--text-out=" + os.DevNull, "--errors-out=" + os.DevNull})
+	if err := g.Main(); err == nil || !strings.Contains(err.Error(), "has unexpected value for deprecated: yes (string)") {
+		t.Errorf("unexpected error %v", err)
+	}
+	dir := t.TempDir()
+	textFile := filepath.Join(dir, "yaml11.text")
+	messagesFile := filepath.Join(dir, "messages.pb")
+	g = lib.NewGnostic([]string{"gnostic", inputFile, "--yaml11-compat", "--text-out=" + textFile, "--messages-out=" + messagesFile})
+	if err := g.Main(); err != nil {
+		t.Fatalf("expected YAML 1.1 booleans to be read: %+v", err)
+	}
+	text, err := os.ReadFile(textFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for _, field := range []string{"required: true", "deprecated: true", "maximum: 90", `version: "1:30"`} {
+		if !strings.Contains(string(text), field) {
+			t.Errorf("expected %s in\n%s", field, text)
+		}
+	}
+	data, err := os.ReadFile(messagesFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	messages := &plugins.Messages{}
+	if err = proto.Unmarshal(data, messages); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	expected := []struct {
+		code string
+		text string
+		keys string
+	}{
+		// Warnings are in the order that they are found, and info is read before paths.
+		{"YAML11_AMBIGUOUS_SCALAR", "read 1:30 as a string, but YAML 1.1 reads it as the number 90; quote it to keep it a string", "info.version"},
+		{"YAML11_COERCION", "read no as the YAML 1.1 boolean false", "paths./pets.get.parameters.parameter.required"},
+		{"YAML11_COERCION", "read on as the YAML 1.1 boolean true", "paths./pets.get.parameters.parameter.deprecated"},
+		{"YAML11_COERCION", "read 1:30 as the YAML 1.1 number 90", "paths./pets.get.parameters.parameter.schema.schema.maximum"},
+		{"YAML11_COERCION", "read Yes as the YAML 1.1 boolean true", "paths./pets.get.parameters.parameter.required"},
+		{"YAML11_COERCION", "read yes as the YAML 1.1 boolean true", "paths./pets.get.deprecated"},
+	}
+	if len(messages.Messages) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), messages.Messages)
+	}
+	for i, message := range messages.Messages {
+		if message.Level != plugins.Message_WARNING || message.Code != expected[i].code || message.Text != expected[i].text ||
+			strings.Join(message.Keys, ".") != expected[i].keys {
+			t.Errorf("unexpected message %+v (expected %+v)", message, expected[i])
+		}
+	}
+}
+
+func TestNestedRelativeReferences(t *testing.T) {
+	// References are relative to the documents that contain them, not to the working directory.
+	inputFile, err := filepath.Abs("testdata/v2.0/yaml/nested-refs/spec/swagger.yaml")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	referenceFile, err := filepath.Abs("testdata/v2.0/yaml/nested-refs/spec/swagger.text")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--resolve-refs", "--text-out=swagger.text"})
+	if err := g.Main(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := exec.Command("diff", filepath.Join(dir, "swagger.text"), referenceFile).Run(); err != nil {
+		t.Errorf("Diff failed: %+v", err)
+	}
+}
+
+func TestGzipInput(t *testing.T) {
+	referenceFile, err := filepath.Abs("testdata/v2.0/yaml/petstore-separate/spec/swagger.text")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	dir := t.TempDir()
+	// The root document is named with .gz, and a referenced file is compressed under its usual name.
+	for _, name := range []string{"spec/swagger.yaml", "spec/Pet.yaml", "spec/NewPet.yaml", "spec/parameters.yaml", "common/Error.yaml"} {
+		data, err := os.ReadFile(filepath.Join("examples/v2.0/yaml/petstore-separate", name))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if name == "spec/swagger.yaml" || name == "spec/Pet.yaml" {
+			var compressed bytes.Buffer
+			zw := gzip.NewWriter(&compressed)
+			zw.Write(data)
+			zw.Close()
+			data = compressed.Bytes()
+		}
+		if name == "spec/swagger.yaml" {
+			name += ".gz"
+		}
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if err := os.WriteFile(filename, data, 0644); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	t.Chdir(filepath.Join(dir, "spec"))
+	// Outputs are named for the decompressed document.
+	g := lib.NewGnostic([]string{"gnostic", "swagger.yaml.gz", "--resolve-refs", "--text-out=."})
+	if err := g.Main(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := exec.Command("diff", filepath.Join(dir, "spec", "swagger.text"), referenceFile).Run(); err != nil {
+		t.Errorf("Diff failed: %+v", err)
+	}
+}
+
+func TestDocumentStreams(t *testing.T) {
+	inputFile := "testdata/v3.0/yaml/stream.yaml"
+	titles := []string{"title: Pets", "title: Owners"}
+	descriptions := []string{"The pets of the first document.", "The owners of the second document."}
+	dir := t.TempDir()
+	// Each document is written to an output named with its index.
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--resolve-refs", "--yaml-out=" + filepath.Join(dir, "out.yaml")})
+	if err := g.Main(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for i := range titles {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("out.%d.yaml", i)))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		// Local references are resolved in the document that contains them.
+		if !strings.Contains(string(data), titles[i]) || !strings.Contains(string(data), descriptions[i]) {
+			t.Errorf("unexpected output for document %d:\n%s", i, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected no unsuffixed output, got %v", err)
+	}
+	// A document can be selected by its index.
+	for i := range titles {
+		outputFile := filepath.Join(dir, fmt.Sprintf("selected-%d.yaml", i))
+		g = lib.NewGnostic([]string{"gnostic", inputFile, fmt.Sprintf("--document-index=%d", i), "--yaml-out=" + outputFile})
+		if err := g.Main(); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if !strings.Contains(string(data), titles[i]) || strings.Contains(string(data), titles[1-i]) {
+			t.Errorf("unexpected output for document %d:\n%s", i, data)
+		}
+	}
+	g = lib.NewGnostic([]string{"gnostic", inputFile, "--document-index=2", "--yaml-out=" + os.DevNull, "--errors-out=" + os.DevNull})
+	if err := g.Main(); err == nil || err.Error() != "document index 2 is out of range for 2 documents" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	sourceFile := filepath.Join(dir, "spec.yaml")
+	responsesFile := filepath.Join(dir, "responses.yaml")
+	outputFile := filepath.Join(dir, "out.yaml")
+	errorsFile := filepath.Join(dir, "errors.txt")
+	writeSource := func(title string) {
+		source := "openapi: 3.0.0\ninfo:\n  title: " + title + "\n  version: 1.0.0\npaths:\n  /pets:\n    get:\n      responses:\n" +
+			"        '200':\n          $ref: 'responses.yaml#/Pets'\n"
+		if err := os.WriteFile(sourceFile, []byte(source), 0644); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	writeResponses := func(name string) {
+		if err := os.WriteFile(responsesFile, []byte(name+":\n  description: Some pets.\n"), 0644); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	// waitFor polls a file until it contains text.
+	waitFor := func(name string, text string) {
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			if data, err := os.ReadFile(name); err == nil && strings.Contains(string(data), text) {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		data, _ := os.ReadFile(name)
+		t.Fatalf("timed out waiting for %q in %s:\n%s", text, name, data)
+	}
+	writeSource("First")
+	writeResponses("Pets")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		g := lib.NewGnostic([]string{"gnostic", sourceFile, "--watch", "--resolve-refs", "--yaml-out=" + outputFile, "--errors-out=" + errorsFile})
+		done <- g.MainContext(ctx)
+	}()
+	waitFor(outputFile, "title: First")
+	// Changes to the source are compiled.
+	writeSource("Second version")
+	waitFor(outputFile, "title: Second version")
+	// Errors are reported without exiting.
+	if err := os.WriteFile(sourceFile, []byte("openapi: 3.0.0\ninfo: [\n"), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	waitFor(errorsFile, "Errors reading "+sourceFile)
+	writeSource("Third version")
+	waitFor(outputFile, "title: Third version")
+	// Changes to referenced files are compiled.
+	if err := os.Remove(errorsFile); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	writeResponses("Dogs")
+	waitFor(errorsFile, "responses.yaml#/Pets")
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected watching to stop with context.Canceled, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("watching didn't stop")
+	}
+}
+
+func TestStats(t *testing.T) {
+	statsFile := filepath.Join(t.TempDir(), "stats.json")
+	// Callers can also collect the counts with their own sinks.
+	sink := compiler.NewStats()
+	ctx := compiler.WithStatsSink(context.Background(), sink)
+	g := lib.NewGnostic([]string{"gnostic", "examples/v2.0/yaml/petstore-separate/spec/swagger.yaml", "--resolve-refs", "--text-out=" + os.DevNull, "--stats=" + statsFile})
+	if err := g.MainContext(ctx); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	data, err := os.ReadFile(statsFile)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !bytes.HasSuffix(data, []byte("}\n")) || bytes.Count(data, []byte("\n")) != 1 {
+		t.Errorf("expected a line of JSON, got %q", data)
+	}
+	stats := make(map[string]int64)
+	if err = json.Unmarshal(data, &stats); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, ok := stats["elapsed_ms"]; !ok {
+		t.Errorf("expected elapsed_ms in %s", data)
+	}
+	expected := map[string]int64{
+		"paths":              2,
+		"operations":         4,
+		"schemas":            0,
+		"parameters":         5,
+		"refs_resolved":      5,
+		"remote_fetches":     0,
+		"extensions_handled": 0,
+		"warnings":           0,
+	}
+	for name, count := range expected {
+		if stats[name] != count {
+			t.Errorf("expected %s to be %d, got %d", name, count, stats[name])
+		}
+		if sink.Get(name) != count {
+			t.Errorf("expected the sink's %s to be %d, got %d", name, count, sink.Get(name))
+		}
+	}
+}
+
+func TestPluginParameters(t *testing.T) {
+	setupStubPlugin(t)
+	for _, test := range []struct {
+		args     []string
+		expected string
+	}{
+		// Plugins that aren't given parameters get none.
+		{[]string{"--echo-out=OUT"}, ""},
+		{[]string{"--echo-out=package=pets,style=short:OUT"}, "package=pets\nstyle=short\nstyle is short\n"},
+		// Options precede the parameters of invocations, which can override them.
+		{[]string{"--echo-out=style=long:OUT", "--echo-opt=package=pets,style=short", "--echo_opt=x=1"}, "package=pets\nstyle=short\nx=1\nstyle=long\nstyle is long\n"},
+		// Options of other plugins aren't passed.
+		{[]string{"--other-opt=style=short", "--echo-out=OUT"}, ""},
+	} {
+		dir := filepath.Join(t.TempDir(), "out")
+		args := []string{"gnostic", "examples/v3.0/yaml/petstore.yaml"}
+		for _, arg := range test.args {
+			args = append(args, strings.Replace(arg, "OUT", dir, 1))
+		}
+		if err := lib.NewGnostic(args).Main(); err != nil {
+			t.Fatalf("%v: %+v", test.args, err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "parameters.txt"))
+		if err != nil {
+			t.Fatalf("%v: %+v", test.args, err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("%v: expected parameters %q, got %q", test.args, test.expected, data)
+		}
+	}
+	// Invalid options are errors.
+	args := []string{"gnostic", "examples/v3.0/yaml/petstore.yaml", "--echo-opt=style", "--echo-out=" + t.TempDir()}
+	if err := lib.NewGnostic(args).Main(); err == nil || !strings.Contains(err.Error(), "Invalid options of gnostic-echo: style") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// countingListener counts the connections that it accepts.
+type countingListener struct {
+	net.Listener
+	mutex       sync.Mutex
+	connections int
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.mutex.Lock()
+		l.connections++
+		l.mutex.Unlock()
+	}
+	return conn, err
+}
+
+func TestPluginServer(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "stream.yaml")
+	var stream strings.Builder
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(&stream, "---\nopenapi: 3.0.0\ninfo:\n  title: API %d\n  version: 1.0.0\npaths: {}\n", i)
+	}
+	if err := os.WriteFile(inputFile, []byte(stream.String()), 0644); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	socket := filepath.Join(dir, "echo.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets are unavailable: %v", err)
+	}
+	counter := &countingListener{Listener: listener}
+	var requests int32
+	go plugins.Serve(counter, func(request *plugins.Request) *plugins.Response {
+		atomic.AddInt32(&requests, 1)
+		document := &openapi_v3.Document{}
+		if err := proto.Unmarshal(request.Models[0].Value, document); err != nil {
+			return &plugins.Response{Errors: []string{err.Error()}}
+		}
+		return &plugins.Response{Messages: []*plugins.Message{{Level: plugins.Message_INFO, Code: "TITLE", Text: document.Info.Title}}}
+	})
+	defer listener.Close()
+	messagesFile := filepath.Join(dir, "messages.pb")
+	g := lib.NewGnostic([]string{"gnostic", inputFile, "--echo", "--echo-plugin-server=" + socket, "--messages-out=" + messagesFile})
+	if err := g.Main(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	for i := 0; i < 3; i++ {
+		messages := readMessages(t, filepath.Join(dir, fmt.Sprintf("messages.%d.pb", i)))
+		if len(messages) != 1 || messages[0].Text != fmt.Sprintf("API %d", i) {
+			t.Errorf("unexpected messages for document %d: %v", i, messages)
+		}
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+	// The documents are sent over one connection, which begins with the only handshake.
+	counter.mutex.Lock()
+	if counter.connections != 1 {
+		t.Errorf("expected one connection, got %d", counter.connections)
+	}
+	counter.mutex.Unlock()
+
+	// Without a server, the plugin is run instead.
+	listener.Close()
+	setupStubPlugin(t)
+	out := filepath.Join(dir, "out")
+	g = lib.NewGnostic([]string{"gnostic", "examples/v3.0/yaml/petstore.yaml", "--echo-out=style=short:" + out, "--echo-plugin-server=" + socket, "--messages-out=" + messagesFile})
+	if err := g.Main(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "parameters.txt")); err != nil {
+		t.Errorf("expected the plugin to run: %v", err)
+	}
+	messages := readMessages(t, messagesFile)
+	if len(messages) != 1 || messages[0].Level != plugins.Message_WARNING || messages[0].Code != "PLUGIN_SERVER_UNREACHABLE" ||
+		!strings.Contains(messages[0].Text, "plugin server at "+socket+" is unreachable") {
+		t.Errorf("unexpected messages %v", messages)
+	}
+	// If neither can be run, the error names both.
+	t.Setenv(stubPluginVariable, "")
+	t.Setenv("PATH", "")
+	g = lib.NewGnostic([]string{"gnostic", "examples/v3.0/yaml/petstore.yaml", "--echo", "--echo-plugin-server=" + socket})
+	if err := g.Main(); err == nil || !strings.Contains(err.Error(), "unreachable") || !strings.Contains(err.Error(), "running gnostic-echo failed") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func readMessages(t *testing.T, filename string) []*plugins.Message {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	messages := &plugins.Messages{}
+	if err = proto.Unmarshal(data, messages); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	return messages.Messages
+}
+
+func TestWarnings(t *testing.T) {
+	inputFile := "testdata/v3.0/yaml/warnings.yaml"
+	codes := []string{"SKIPPED_ARRAY_ITEM", "INVALID_KEY", "REFERENCE_CYCLE"}
+	type problem struct {
+		Code    string
+		Message string
+		Keys    []string
+		Line    int
+	}
+	for _, test := range []struct {
+		warningsAsErrors bool
+		errors           int
+		warnings         int
+	}{
+		{false, 0, 3},
+		// The warnings are reported as errors, which fail the compilation.
+		{true, 3, 0},
+	} {
+		errorsFile := filepath.Join(t.TempDir(), "errors.json")
+		args := []string{"gnostic", inputFile, "--resolve-refs", "--text-out=" + os.DevNull, "--errors-out=" + os.DevNull, "--errors-json=" + errorsFile}
+		if test.warningsAsErrors {
+			args = append(args, "--warnings-as-errors")
+		}
+		err := lib.NewGnostic(args).Main()
+		if failed := err != nil; failed != test.warningsAsErrors {
+			t.Errorf("unexpected error %v with warnings as errors %t", err, test.warningsAsErrors)
+		}
+		data, err := os.ReadFile(errorsFile)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		var problems struct {
+			Errors   []problem
+			Warnings []problem
+		}
+		if err = json.Unmarshal(data, &problems); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if len(problems.Errors) != test.errors || len(problems.Warnings) != test.warnings {
+			t.Fatalf("expected %d errors and %d warnings, got %s", test.errors, test.warnings, data)
+		}
+		for i, problem := range append(problems.Errors, problems.Warnings...) {
+			if problem.Code != codes[i] {
+				t.Errorf("expected code %s, got %+v", codes[i], problem)
+			}
+		}
+		if test.warningsAsErrors {
+			continue
+		}
+		skipped := problems.Warnings[0]
+		if skipped.Message != "skipped item that isn't a string: a map" || strings.Join(skipped.Keys, ".") != "paths./pets.get.tags.1" || skipped.Line != 11 {
+			t.Errorf("unexpected warning %+v", skipped)
+		}
+	}
+	// Without warnings, --errors-json writes empty arrays.
+	errorsFile := filepath.Join(t.TempDir(), "errors.json")
+	if err := lib.NewGnostic([]string{"gnostic", "examples/v3.0/yaml/petstore.yaml", "--warnings-as-errors", "--errors-json=" + errorsFile}).Main(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if data, _ := os.ReadFile(errorsFile); string(data) != "{\n  \"errors\": [],\n  \"warnings\": []\n}\n" {
+		t.Errorf("unexpected errors %q", data)
+	}
+}
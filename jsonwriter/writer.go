@@ -15,10 +15,14 @@
 package jsonwriter
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"go.yaml.in/yaml/v3"
 )
@@ -28,21 +32,32 @@ const (
 	null        = "null"
 )
 
-type writer struct {
-	b bytes.Buffer
+// output is implemented by bytes.Buffer and bufio.Writer.
+type output interface {
+	io.Writer
+	io.StringWriter
+	io.ByteWriter
 }
 
-func (w *writer) bytes() []byte {
-	return w.b.Bytes()
+type writer struct {
+	out output
+	// quoted holds the last quoted string to avoid an allocation for each one.
+	quoted []byte
 }
 
 func (w *writer) writeString(s string) {
-	w.b.Write([]byte(s))
+	w.out.WriteString(s)
 }
 
-func (w *writer) writeMap(node *yaml.Node, indent string) {
+func (w *writer) writeIndent(depth int) {
+	for i := 0; i < depth; i++ {
+		w.out.WriteString(indentation)
+	}
+}
+
+func (w *writer) writeMap(node *yaml.Node, depth int) {
 	if node.Kind == yaml.DocumentNode {
-		w.writeMap(node.Content[0], indent)
+		w.writeMap(node.Content[0], depth)
 		return
 	}
 	if node.Kind != yaml.MappingNode {
@@ -50,38 +65,36 @@ func (w *writer) writeMap(node *yaml.Node, indent string) {
 		return
 	}
 	w.writeString("{\n")
-	innerIndent := indent + indentation
 	for i := 0; i < len(node.Content); i += 2 {
 		// first print the key
-		key := node.Content[i].Value
-		w.writeString(fmt.Sprintf("%s\"%+v\": ", innerIndent, key))
+		w.writeIndent(depth + 1)
+		w.writeQuoted(node.Content[i].Value)
+		w.writeString(": ")
 		// then the value
 		value := node.Content[i+1]
 		switch value.Kind {
 		case yaml.MappingNode:
-			w.writeMap(value, innerIndent)
+			w.writeMap(value, depth+1)
 		case yaml.SequenceNode:
-			w.writeSequence(value, innerIndent)
+			w.writeSequence(value, depth+1)
 		case yaml.ScalarNode:
-			w.writeScalar(value, innerIndent)
+			w.writeScalar(value)
 		}
 		if i < len(node.Content)-2 {
-			w.writeString(",")
+			w.out.WriteByte(',')
 		}
-		w.writeString("\n")
+		w.out.WriteByte('\n')
 	}
-	w.writeString(indent)
-	w.writeString("}")
+	w.writeIndent(depth)
+	w.out.WriteByte('}')
 }
 
-func (w *writer) writeScalar(node *yaml.Node, indent string) {
+func (w *writer) writeScalar(node *yaml.Node) {
 	if node.Kind != yaml.ScalarNode {
 		w.writeString(fmt.Sprintf("invalid node for scalar: %+v", node))
 		return
 	}
 	switch node.Tag {
-	case "!!str":
-		w.writeString(strconv.Quote(node.Value))
 	case "!!int":
 		w.writeString(node.Value)
 	case "!!float":
@@ -90,55 +103,131 @@ func (w *writer) writeScalar(node *yaml.Node, indent string) {
 		w.writeString(node.Value)
 	case "!!null":
 		w.writeString(null)
+	default:
+		// Strings and the scalars that JSON has no type for, like timestamps.
+		w.writeQuoted(node.Value)
+	}
+}
+
+// writeQuoted writes a string as a JSON string.
+func (w *writer) writeQuoted(s string) {
+	w.quoted = appendQuoted(w.quoted[:0], s)
+	w.out.Write(w.quoted)
+}
+
+// appendQuoted appends a string to b as a JSON string. Like strconv.AppendQuote, it
+// escapes the characters that aren't printable, but only with the escapes of JSON.
+// Invalid UTF-8 is replaced with U+FFFD.
+func appendQuoted(b []byte, s string) []byte {
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c >= 0x20 && c != 0x7f:
+				b = append(b, c)
+			case c == '\n':
+				b = append(b, `\n`...)
+			case c == '\r':
+				b = append(b, `\r`...)
+			case c == '\t':
+				b = append(b, `\t`...)
+			case c == '\b':
+				b = append(b, `\b`...)
+			case c == '\f':
+				b = append(b, `\f`...)
+			default:
+				b = appendEscapedRune(b, rune(c))
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = appendEscapedRune(b, utf8.RuneError)
+		case strconv.IsPrint(r):
+			b = append(b, s[i:i+size]...)
+		default:
+			b = appendEscapedRune(b, r)
+		}
+		i += size
 	}
+	return append(b, '"')
 }
 
-func (w *writer) writeSequence(node *yaml.Node, indent string) {
+// appendEscapedRune appends a \u escape of a rune to b, using a surrogate pair for
+// runes outside of the Basic Multilingual Plane.
+func appendEscapedRune(b []byte, r rune) []byte {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		b = appendEscapedRune(b, r1)
+		r = r2
+	}
+	const hex = "0123456789abcdef"
+	return append(b, '\\', 'u', hex[r>>12&0xf], hex[r>>8&0xf], hex[r>>4&0xf], hex[r&0xf])
+}
+
+func (w *writer) writeSequence(node *yaml.Node, depth int) {
 	if node.Kind != yaml.SequenceNode {
 		w.writeString(fmt.Sprintf("invalid node for sequence: %+v", node))
 		return
 	}
 	w.writeString("[\n")
-	innerIndent := indent + indentation
 	for i, value := range node.Content {
-		w.writeString(innerIndent)
+		w.writeIndent(depth + 1)
 		switch value.Kind {
 		case yaml.MappingNode:
-			w.writeMap(value, innerIndent)
+			w.writeMap(value, depth+1)
 		case yaml.SequenceNode:
-			w.writeSequence(value, innerIndent)
+			w.writeSequence(value, depth+1)
 		case yaml.ScalarNode:
-			w.writeScalar(value, innerIndent)
+			w.writeScalar(value)
 		}
 		if i < len(node.Content)-1 {
-			w.writeString(",")
+			w.out.WriteByte(',')
 		}
-		w.writeString("\n")
+		w.out.WriteByte('\n')
 	}
-	w.writeString(indent)
-	w.writeString("]")
+	w.writeIndent(depth)
+	w.out.WriteByte(']')
 }
 
-// Marshal writes a yaml.Node as JSON
-func Marshal(in *yaml.Node) (out []byte, err error) {
-	var w writer
-
+func (w *writer) write(in *yaml.Node) error {
 	switch in.Kind {
 	case yaml.DocumentNode:
-		w.writeMap(in.Content[0], "")
-		w.writeString("\n")
+		w.writeMap(in.Content[0], 0)
 	case yaml.MappingNode:
-		w.writeMap(in, "")
-		w.writeString("\n")
+		w.writeMap(in, 0)
 	case yaml.SequenceNode:
-		w.writeSequence(in, "")
-		w.writeString("\n")
+		w.writeSequence(in, 0)
 	case yaml.ScalarNode:
-		w.writeScalar(in, "")
-		w.writeString("\n")
+		w.writeScalar(in)
 	default:
-		return nil, errors.New("invalid type passed to Marshal")
+		return errors.New("invalid type passed to Marshal")
 	}
+	w.out.WriteByte('\n')
+	return nil
+}
 
-	return w.bytes(), err
+// Marshal writes a yaml.Node as JSON
+func Marshal(in *yaml.Node) (out []byte, err error) {
+	var b bytes.Buffer
+	w := &writer{out: &b}
+	if err = w.write(in); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Encode writes a yaml.Node as JSON to out. It produces the same bytes as Marshal
+// without holding the whole document in memory.
+func Encode(out io.Writer, in *yaml.Node) error {
+	b := bufio.NewWriter(out)
+	w := &writer{out: b}
+	if err := w.write(in); err != nil {
+		return err
+	}
+	return b.Flush()
 }
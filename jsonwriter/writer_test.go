@@ -15,7 +15,11 @@
 package jsonwriter_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/google/gnostic/compiler"
 	"github.com/google/gnostic/jsonwriter"
@@ -39,6 +43,8 @@ func TestMarshal(t *testing.T) {
 		scalarIntTestCase(),
 		scalarStringTestCase(),
 		scalarNullTestCase(),
+		scalarEscapedStringTestCase(),
+		scalarTimestampTestCase(),
 		sequenceStringArrayTestCase(),
 		sequenceBoolArrayTestCase(),
 		sequenceFloatArrayTestCase(),
@@ -46,6 +52,7 @@ func TestMarshal(t *testing.T) {
 		sequenceSequenceStringArrayTestCase(),
 		sequenceMappingNodeTestCase(),
 		mappingNodeTestCase(),
+		mappingNodeEscapedKeyTestCase(),
 		documentNodeTestCase(),
 		aliasNodeTestCase(),
 	}
@@ -62,6 +69,15 @@ func TestMarshal(t *testing.T) {
 			if string(b) != test.Expected {
 				s.Errorf("expected %v to equal %v", string(b), test.Expected)
 			}
+			// Encode writes the same bytes.
+			var buf bytes.Buffer
+			err = jsonwriter.Encode(&buf, test.Node)
+			if err == nil && test.Err {
+				s.Error("expected error from Encode")
+			}
+			if buf.String() != test.Expected {
+				s.Errorf("expected encoded %v to equal %v", buf.String(), test.Expected)
+			}
 		})
 	}
 }
@@ -98,6 +114,22 @@ func scalarStringTestCase() *MarshalTestCase {
 	}
 }
 
+func scalarEscapedStringTestCase() *MarshalTestCase {
+	return &MarshalTestCase{
+		Name:     "scalar escaped string",
+		Node:     compiler.NewScalarNodeForString("line\r\n\ttab \a\x7f \u00a0 é … \U000e0001 \xff"),
+		Expected: "\"line\\r\\n\\ttab \\u0007\\u007f \\u00a0 é … \\udb40\\udc01 \\ufffd\"\n",
+	}
+}
+
+func scalarTimestampTestCase() *MarshalTestCase {
+	return &MarshalTestCase{
+		Name:     "scalar timestamp",
+		Node:     &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: "2021-01-01"},
+		Expected: "\"2021-01-01\"\n",
+	}
+}
+
 func scalarNullTestCase() *MarshalTestCase {
 	return &MarshalTestCase{
 		Name:     "scalar null",
@@ -193,6 +225,43 @@ func mappingNodeTestCase() *MarshalTestCase {
 	}
 }
 
+func mappingNodeEscapedKeyTestCase() *MarshalTestCase {
+	node := compiler.NewMappingNode()
+	node.Content = append(node.Content, compiler.NewScalarNodeForString("say \"hi\"\x00\a"))
+	node.Content = append(node.Content, compiler.NewScalarNodeForInt(1))
+	return &MarshalTestCase{
+		Name:     "Mapping node with escaped key",
+		Node:     node,
+		Expected: "{\n  \"say \\\"hi\\\"\\u0000\\u0007\": 1\n}\n",
+	}
+}
+
+// TestMarshalEscapesAreJSON checks that encoding/json reads back every ASCII character
+// and some others in keys and values.
+func TestMarshalEscapesAreJSON(t *testing.T) {
+	t.Parallel()
+	values := []string{"\u2028\u2029", "\u00a0é…", "\U000e0001"}
+	for c := 0; c < utf8.RuneSelf; c++ {
+		values = append(values, fmt.Sprintf("%c\"%c", c, c))
+	}
+	for _, s := range values {
+		node := compiler.NewMappingNode()
+		node.Content = append(node.Content, compiler.NewScalarNodeForString(s), compiler.NewScalarNodeForString(s))
+		var buf bytes.Buffer
+		if err := jsonwriter.Encode(&buf, node); err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]string
+		if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+			t.Errorf("invalid JSON for %q: %s (%v)", s, buf.String(), err)
+			continue
+		}
+		if len(m) != 1 || m[s] != s {
+			t.Errorf("expected %q as key and value, got %v", s, m)
+		}
+	}
+}
+
 func documentNodeTestCase() *MarshalTestCase {
 	m := compiler.NewMappingNode()
 	m.Content = append(m.Content, compiler.NewScalarNodeForString("version"))
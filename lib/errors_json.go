@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/google/gnostic/compiler"
+)
+
+// A problem that --errors-json writes. Its location is omitted if it is unknown.
+type jsonProblem struct {
+	Code    string   `json:"code,omitempty"`
+	Message string   `json:"message"`
+	Keys    []string `json:"keys,omitempty"`
+	Line    int      `json:"line,omitempty"`
+	Column  int      `json:"column,omitempty"`
+}
+
+// The errors and warnings of a compilation, which --errors-json writes.
+type jsonProblems struct {
+	Errors   []*jsonProblem `json:"errors"`
+	Warnings []*jsonProblem `json:"warnings"`
+}
+
+// Describe a problem that is located with a compiler context.
+func newJSONProblem(code string, context *compiler.Context, message string) *jsonProblem {
+	problem := &jsonProblem{Code: code, Message: message}
+	problem.Keys = compiler.NewWarning(context, code, message).Keys()
+	if context != nil && context.Node != nil {
+		problem.Line = context.Node.Line
+		problem.Column = context.Node.Column
+	}
+	return problem
+}
+
+// Describe the errors in an error returned by the compiler, locating those that it can.
+func jsonErrors(err error) []*jsonProblem {
+	problems := make([]*jsonProblem, 0)
+	var add func(err error)
+	add = func(err error) {
+		switch err := err.(type) {
+		case nil:
+		case *compiler.ErrorGroup:
+			for _, err := range err.Errors {
+				add(err)
+			}
+		case *compiler.Error:
+			if err.Context == nil {
+				problems = append(problems, &jsonProblem{Message: err.Message})
+			} else {
+				problems = append(problems, newJSONProblem("", err.Context, err.Message))
+			}
+		case *compiler.InvalidKeyError:
+			warning := err.Warning()
+			problems = append(problems, newJSONProblem(warning.Code, warning.Context, warning.Message))
+		case *compiler.Warning:
+			problems = append(problems, newJSONProblem(err.Code, err.Context, err.Message))
+		default:
+			problems = append(problems, &jsonProblem{Message: err.Error()})
+		}
+	}
+	add(err)
+	return problems
+}
+
+// Write the errors and warnings of a compilation as JSON.
+func (g *Gnostic) writeErrorsJSON(err error, name string) {
+	problems := &jsonProblems{Errors: jsonErrors(err), Warnings: make([]*jsonProblem, 0)}
+	for _, warning := range g.warnings {
+		problems.Warnings = append(problems.Warnings, newJSONProblem(warning.Code, warning.Context, warning.Message))
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	// Messages quote documents, which are easier to read without escaped <, >, and &.
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(problems)
+	writeFile(g.errorsJSONPath, b.Bytes(), name, "errors.json")
+}
+
+// Write the errors of a compilation, if there are any, and with --errors-json, its errors
+// and warnings.
+func (g *Gnostic) writeErrors(err error, name string) {
+	if err != nil {
+		writeFile(g.errorOutputPath, g.errorBytes(err), name, "errors")
+	}
+	if g.errorsJSONPath != "" {
+		g.writeErrorsJSON(err, name)
+	}
+}
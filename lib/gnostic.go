@@ -16,6 +16,7 @@ package lib
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -25,6 +26,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -83,7 +85,7 @@ func getOpenAPIVersionFromInfo(info *yaml.Node) int {
 	}
 
 	openapi, ok := compiler.StringForScalarNode(compiler.MapValueForKey(m, "openapi"))
-	if ok && strings.HasPrefix(openapi, "3.0") {
+	if ok && (strings.HasPrefix(openapi, "3.0") || strings.HasPrefix(openapi, "3.1")) {
 		return SourceFormatOpenAPI3
 	}
 
@@ -103,6 +105,33 @@ const (
 type pluginCall struct {
 	Name       string
 	Invocation string
+	// Options are the parameters of --PLUGIN-opt options, which are passed to the
+	// plugin before those of its invocation.
+	Options []string
+	// Server is the Unix socket of a plugin server that handles the calls, which is
+	// set with --PLUGIN-plugin-server.
+	Server string
+	// server is the connection to Server, which is kept for later documents.
+	server *plugins.ServerClient
+}
+
+// Plugin parameters are comma-separated key=value pairs.
+// Keys and values must be alphanumeric strings and may contain
+// dashes, underscores, periods, or forward slashes.
+const pluginParametersPattern = `[\w-_\/\.]+=[\w-_\/\.]+(,[\w-_\/\.]+=[\w-_\/\.]+)*`
+
+var pluginOptionsRegex = regexp.MustCompile(`^` + pluginParametersPattern + `$`)
+
+// Parses comma-separated key=value pairs into plugin parameters.
+func pluginParameters(pairs string) []*plugins.Parameter {
+	parameters := make([]*plugins.Parameter, 0)
+	for _, keyvalue := range strings.Split(pairs, ",") {
+		pair := strings.Split(keyvalue, "=")
+		if len(pair) == 2 {
+			parameters = append(parameters, &plugins.Parameter{Name: pair[0], Value: pair[1]})
+		}
+	}
+	return parameters
 }
 
 // Invokes a plugin.
@@ -113,6 +142,13 @@ func (p *pluginCall) perform(document proto.Message, sourceFormat int, sourceNam
 		// Infer the name of the executable by adding the prefix.
 		executableName := pluginPrefix + p.Name
 
+		for _, options := range p.Options {
+			if !pluginOptionsRegex.MatchString(options) {
+				return nil, fmt.Errorf("Invalid options of %s: %s", executableName, options)
+			}
+			request.Parameters = append(request.Parameters, pluginParameters(options)...)
+		}
+
 		// Validate invocation string with regular expression.
 		invocation := p.Invocation
 
@@ -120,11 +156,9 @@ func (p *pluginCall) perform(document proto.Message, sourceFormat int, sourceNam
 		// Plugin invocations must consist of
 		// zero or more comma-separated key=value pairs followed by a path.
 		// If pairs are present, a colon separates them from the path.
-		// Keys and values must be alphanumeric strings and may contain
-		// dashes, underscores, periods, or forward slashes.
 		// A path can contain any characters other than the separators ',', ':', and '='.
 		//
-		invocationRegex := regexp.MustCompile(`^([\w-_\/\.]+=[\w-_\/\.]+(,[\w-_\/\.]+=[\w-_\/\.]+)*:)?[^,:=]+$`)
+		invocationRegex := regexp.MustCompile(`^(` + pluginParametersPattern + `:)?[^,:=]+$`)
 		if !invocationRegex.Match([]byte(p.Invocation)) {
 			return nil, fmt.Errorf("Invalid invocation of %s: %s", executableName, invocation)
 		}
@@ -135,13 +169,7 @@ func (p *pluginCall) perform(document proto.Message, sourceFormat int, sourceNam
 		case 1:
 			outputLocation = invocationParts[0]
 		case 2:
-			parameters := strings.Split(invocationParts[0], ",")
-			for _, keyvalue := range parameters {
-				pair := strings.Split(keyvalue, "=")
-				if len(pair) == 2 {
-					request.Parameters = append(request.Parameters, &plugins.Parameter{Name: pair[0], Value: pair[1]})
-				}
-			}
+			request.Parameters = append(request.Parameters, pluginParameters(invocationParts[0])...)
 			outputLocation = invocationParts[1]
 		default:
 			// badly-formed request
@@ -181,13 +209,8 @@ func (p *pluginCall) perform(document proto.Message, sourceFormat int, sourceNam
 		default:
 		}
 
-		requestBytes, _ := proto.Marshal(request)
-
-		cmd := exec.Command(executableName, "-plugin")
-		cmd.Stdin = bytes.NewReader(requestBytes)
-		cmd.Stderr = os.Stderr
 		pluginStartTime := time.Now()
-		output, err := cmd.Output()
+		response, warning, err := p.call(executableName, request)
 		pluginElapsedTime := time.Since(pluginStartTime)
 		if timePlugins {
 			fmt.Printf("> %s (%s)\n", executableName, pluginElapsedTime)
@@ -195,22 +218,93 @@ func (p *pluginCall) perform(document proto.Message, sourceFormat int, sourceNam
 		if err != nil {
 			return nil, err
 		}
-		response := &plugins.Response{}
-		err = proto.Unmarshal(output, response)
-		if err != nil {
-			// Gnostic expects plugins to only write the
-			// response message to stdout. Be sure that
-			// any logging messages are written to stderr only.
-			return nil, errors.New("invalid plugin response (plugins must write log messages to stderr, not stdout)")
-		}
 
 		err = plugins.HandleResponse(response, outputLocation)
 
+		if warning != nil {
+			return append([]*plugins.Message{warning}, response.Messages...), err
+		}
 		return response.Messages, err
 	}
 	return nil, nil
 }
 
+// Sends a request to the plugin server of the call, or if it has none or the server can't
+// be reached, to a new process of the plugin. Running the process instead of calling the
+// server is reported with a warning message.
+func (p *pluginCall) call(executableName string, request *plugins.Request) (*plugins.Response, *plugins.Message, error) {
+	if p.Server == "" {
+		response, err := runPlugin(executableName, request)
+		return response, nil, err
+	}
+	response, serverErr := p.callServer(request)
+	if serverErr == nil {
+		return response, nil, nil
+	}
+	response, err := runPlugin(executableName, request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("plugin server at %s is unreachable (%v), and running %s failed: %v", p.Server, serverErr, executableName, err)
+	}
+	warning := &plugins.Message{
+		Level: plugins.Message_WARNING,
+		Code:  "PLUGIN_SERVER_UNREACHABLE",
+		Text:  fmt.Sprintf("plugin server at %s is unreachable (%v), so %s was run instead", p.Server, serverErr, executableName),
+	}
+	return response, warning, nil
+}
+
+// Sends a request to the plugin server of the call, connecting to it if necessary.
+func (p *pluginCall) callServer(request *plugins.Request) (*plugins.Response, error) {
+	reused := p.server != nil
+	if !reused {
+		server, err := plugins.DialServer(p.Server)
+		if err != nil {
+			return nil, err
+		}
+		p.server = server
+	}
+	response, err := p.server.Call(request)
+	if err != nil {
+		p.closeServer()
+		// Servers can close idle connections, like those kept between runs of --watch,
+		// so a failed call on a kept connection is retried on a new one.
+		if reused {
+			return p.callServer(request)
+		}
+	}
+	return response, err
+}
+
+// Closes any connection to the plugin server of the call.
+func (p *pluginCall) closeServer() {
+	if p.server != nil {
+		p.server.Close()
+		p.server = nil
+	}
+}
+
+// Runs a plugin in a new process that reads a request from stdin and writes its response.
+func runPlugin(executableName string, request *plugins.Request) (*plugins.Response, error) {
+	requestBytes, _ := proto.Marshal(request)
+
+	cmd := exec.Command(executableName, "-plugin")
+	cmd.Stdin = bytes.NewReader(requestBytes)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	response := &plugins.Response{}
+	err = proto.Unmarshal(output, response)
+	if err != nil {
+		// Gnostic expects plugins to only write the
+		// response message to stdout. Be sure that
+		// any logging messages are written to stderr only.
+		return nil, errors.New("invalid plugin response (plugins must write log messages to stderr, not stdout)")
+	}
+	return response, nil
+}
+
 func isFile(path string) bool {
 	fileInfo, err := os.Stat(path)
 	if err != nil {
@@ -227,6 +321,17 @@ func isDirectory(path string) bool {
 	return fileInfo.IsDir()
 }
 
+// uncompressedName returns the name of a source without the .gz suffix of a gzipped file.
+// Sources are decompressed when they are read, so the name of the document is the rest.
+func uncompressedName(path string) string {
+	return strings.TrimSuffix(path, ".gz")
+}
+
+// sourceExtension returns the lowercased extension of a source, ignoring a .gz suffix.
+func sourceExtension(path string) string {
+	return strings.ToLower(filepath.Ext(uncompressedName(path)))
+}
+
 func isURL(path string) bool {
 	_, err := url.ParseRequestURI(path)
 	if err != nil {
@@ -249,15 +354,25 @@ func isURL(path string) bool {
 // If a directory name is given, the file is written there with
 // a name derived from the source and extension arguments.
 func writeFile(name string, bytes []byte, source string, extension string) {
+	writeOutput(name, source, extension, func(writer io.Writer) error {
+		_, err := writer.Write(bytes)
+		return err
+	})
+}
+
+// Write to a named file with an encoding function, following the conventions
+// of writeFile, so that large outputs can be streamed. The error returned by
+// encode is returned.
+func writeOutput(name string, source string, extension string, encode func(io.Writer) error) error {
 	var writer io.Writer
 	if name == "!" {
-		return
+		return nil
 	} else if name == "-" {
 		writer = os.Stdout
 	} else if name == "=" {
 		writer = os.Stderr
 	} else if isDirectory(name) && !isURL(source) {
-		base := source
+		base := uncompressedName(source)
 		// Remove the original source extension.
 		base = base[0 : len(base)-len(filepath.Ext(base))]
 		// Build the path that puts the result in the passed-in directory.
@@ -272,7 +387,7 @@ func writeFile(name string, bytes []byte, source string, extension string) {
 		defer file.Close()
 		writer = file
 	} else if isDirectory(name) {
-		base := filepath.Base(source)
+		base := filepath.Base(uncompressedName(source))
 		// Remove the original source extension.
 		base = base[0 : len(base)-len(filepath.Ext(base))]
 		// Build the path that puts the result in the passed-in directory.
@@ -285,55 +400,156 @@ func writeFile(name string, bytes []byte, source string, extension string) {
 		defer file.Close()
 		writer = file
 	}
-	writer.Write(bytes)
+	return encode(writer)
 }
 
 // The Gnostic structure holds global state information for gnostic.
 type Gnostic struct {
-	args              []string
-	usage             string
-	sourceName        string
-	binaryOutputPath  string
-	textOutputPath    string
-	yamlOutputPath    string
-	jsonOutputPath    string
-	errorOutputPath   string
-	messageOutputPath string
-	resolveReferences bool
-	pluginCalls       []*pluginCall
-	extensionHandlers []compiler.ExtensionHandler
-	sourceFormat      int
-	timePlugins       bool
-	excludeSurface    bool
+	args                []string
+	usage               string
+	sourceName          string
+	outputName          string
+	documentIndex       int
+	binaryOutputPath    string
+	textOutputPath      string
+	yamlOutputPath      string
+	jsonOutputPath      string
+	errorOutputPath     string
+	messageOutputPath   string
+	statsPath           string
+	resolveReferences   bool
+	pluginCalls         []*pluginCall
+	extensionHandlers   []compiler.ExtensionHandler
+	extensionConfig     *compiler.ExtensionConfig
+	extensionConfigPath string
+	extensionWildcard   string
+	extensionTimeout    time.Duration
+	extensionErrors     string
+	strictExtensions    bool
+	strictKeys          bool
+	invalidKeys         []*compiler.InvalidKeyError
+	strictRefs          bool
+	referenceCycles     []*compiler.ReferenceCycleError
+	yaml11Compat        bool
+	fillDefaults        bool
+	warnings            []*compiler.Warning
+	warningsAsErrors    bool
+	errorsJSONPath      string
+	sourceFormat        int
+	timePlugins         bool
+	excludeSurface      bool
+	checkConflicts      bool
+	watch               bool
+	normalize           bool
+	limits              compiler.Limits
 }
 
 // NewGnostic initializes a structure to store global application state.
 func NewGnostic(args []string) *Gnostic {
-	g := &Gnostic{args: args}
+	g := &Gnostic{args: args, documentIndex: -1}
 	// Option fields initialize to their default values.
 	g.usage = `
 Usage: gnostic SOURCE [OPTIONS]
-  SOURCE is the filename or URL of an API description.
+       gnostic normalize SOURCE [OPTIONS]
+  SOURCE is the filename or URL of an API description. Gzipped sources are
+  decompressed, and a .gz suffix is ignored when naming outputs.
+  normalize rewrites an OpenAPI v3 description in a canonical form before
+  writing it: components and tags are sorted by name, operations are written
+  in a fixed order with lowercase methods, references to SOURCE itself are
+  local, and yaml output is indented by two spaces.
 Options:
   --pb-out=PATH       Write a binary proto to the specified location.
   --text-out=PATH     Write a text proto to the specified location.
   --json-out=PATH     Write a json API description to the specified location.
   --yaml-out=PATH     Write a yaml API description to the specified location.
   --errors-out=PATH   Write compilation errors to the specified location.
+  --errors-json=PATH  Write compilation errors and warnings to the specified
+                      location as JSON, in "errors" and "warnings" arrays
+                      of objects with codes, messages, key paths, and line
+                      and column numbers. It is written even if there are
+                      no errors.
   --messages-out=PATH Write messages generated by plugins to the specified
                       location. Messages from all plugin invocations are
                       written to a single common file.
+  --stats=PATH        Write counts of the paths, operations, schemas, and
+                      parameters of the description, the references that
+                      were resolved, the documents that were fetched, the
+                      extensions that were handled, the warnings, and the
+                      elapsed time in milliseconds to the specified location
+                      as a line of JSON. Use - to write them to stdout.
   --PLUGIN-out=PATH   Run the plugin named gnostic-PLUGIN and write results
-                      to the specified location.
+                      to the specified location. Parameters can be passed
+                      to the plugin by preceding the location with them and
+                      a colon, as in --PLUGIN-out=NAME=VALUE,NAME=VALUE:PATH.
+  --PLUGIN-opt=NAME=VALUE,...
+                      Pass parameters to each invocation of gnostic-PLUGIN,
+                      before any parameters of the invocation.
+  --PLUGIN-plugin-server=PATH
+                      Send the requests of gnostic-PLUGIN to a plugin server
+                      listening on the Unix socket at PATH, which handles
+                      every document over one connection. If the server
+                      can't be reached, gnostic-PLUGIN is run instead.
   --PLUGIN            Run the plugin named gnostic-PLUGIN but don't write any
                       results. Used for plugins that return messages only.
                       PLUGIN must not match any other gnostic option.
   --x-EXTENSION       Use the extension named gnostic-x-EXTENSION
                       to process OpenAPI specification extensions.
-  --resolve-refs      Explicitly resolve $ref references.
-                      This could have problems with recursive definitions.
+  --extension-config=PATH
+                      Read a YAML file that maps extension names and
+                      prefixes (such as x-amazon-*) to handler commands.
+                      Extensions that match no configured pattern are
+                      handled as usual.
+  --extension-wildcard=COMMAND
+                      Send each extension that no other handler accepts
+                      to COMMAND. Equivalent to a configured handler with
+                      the pattern "*".
+  --extension-timeout=DURATION
+                      Kill extension handlers that take longer than
+                      DURATION (such as 30s). The default is 1m.
+  --extension-errors=error|warn
+                      Report extension handler failures as errors (the
+                      default) or as warnings, leaving the extensions
+                      unhandled.
+  --strict-extensions Fail if the description uses extensions that no
+                      extension handler accepts.
+  --strict-keys       Fail if the description has keys that are neither
+                      fields nor extensions, like misspelled field names.
+                      By default they are reported as warnings.
+  --yaml11-compat     Read YAML 1.1 booleans (like yes, no, on, and off) and
+                      base 60 numbers (like 1:30) where booleans and numbers
+                      are expected, reporting each as a warning. Strings that
+                      YAML 1.1 reads as booleans or numbers are also reported.
+                      By default descriptions are read with YAML 1.2.
+  --max-input-bytes=N Reject documents larger than N bytes. The default is
+                      100MB.
+  --max-depth=N       Reject documents with collections nested more than N
+                      levels deep. The default is 1000.
+  --max-alias-expansions=N
+                      Reject YAML documents that expand to more than N
+                      aliases. The default is 100000.
+  --document-index=N  Read only the document at index N (counting from 0) of
+                      a YAML stream of documents separated by "---" lines.
+                      By default every document is read, and the outputs of
+                      each are named with its index, like out.1.yaml.
+  --fill-defaults     Add operationIds made from the methods and paths of
+                      operations that have none, descriptions of responses
+                      that have none, and a title if the description has
+                      none, reporting each value that is added as a message.
+  --resolve-refs      Explicitly resolve $ref references. References in
+                      cycles are left in place and reported as warnings.
+  --strict-refs       Fail if --resolve-refs finds cycles of references.
+  --warnings-as-errors
+                      Fail if there are any warnings, like invalid keys,
+                      skipped array items, or reference cycles, reporting
+                      them as errors. Warnings are otherwise printed to
+                      stderr and written as messages.
   --time-plugins      Report plugin runtimes.
   --no-surface        Exclude surface model from calls to plugins.
+  --check-conflicts   Report duplicate operationIds, duplicate operations on
+                      equivalent paths, and conflicting schema definitions
+                      in an OpenAPI v3 description as messages.
+  --watch             Run again whenever SOURCE or a local file that it
+                      references changes, reporting errors without exiting.
   --help              Print usage information and exit.
 `
 	// Initialize internal structures.
@@ -352,6 +568,14 @@ func (g *Gnostic) readOptions() error {
 	// plugin processing matches patterns of the form "--PLUGIN-out=PATH" and "--PLUGIN_out=PATH"
 	pluginRegex := regexp.MustCompile("--(.+)[-_]out=(.+)")
 
+	// plugin options match patterns of the form "--PLUGIN-opt=OPTIONS" and "--PLUGIN_opt=OPTIONS"
+	pluginOptionRegex := regexp.MustCompile("^--([^=]+)[-_]opt=(.+)")
+	pluginOptions := make(map[string][]string)
+
+	// plugin servers match patterns of the form "--PLUGIN-plugin-server=PATH"
+	pluginServerRegex := regexp.MustCompile("^--([^=]+)-plugin-server=(.+)")
+	pluginServers := make(map[string]string)
+
 	// extension processing matches patterns of the form "--x-EXTENSION"
 	extensionRegex := regexp.MustCompile("--x-(.+)")
 
@@ -360,7 +584,12 @@ func (g *Gnostic) readOptions() error {
 			continue // skip the tool name
 		}
 		var m [][]byte
-		if m = pluginRegex.FindSubmatch([]byte(arg)); m != nil {
+		if m = pluginOptionRegex.FindSubmatch([]byte(arg)); m != nil {
+			pluginName := string(m[1])
+			pluginOptions[pluginName] = append(pluginOptions[pluginName], string(m[2]))
+		} else if m = pluginServerRegex.FindSubmatch([]byte(arg)); m != nil {
+			pluginServers[string(m[1])] = string(m[2])
+		} else if m = pluginRegex.FindSubmatch([]byte(arg)); m != nil {
 			pluginName := string(m[1])
 			invocation := string(m[2])
 			switch pluginName {
@@ -380,27 +609,92 @@ func (g *Gnostic) readOptions() error {
 				p := &pluginCall{Name: pluginName, Invocation: invocation}
 				g.pluginCalls = append(g.pluginCalls, p)
 			}
+		} else if strings.HasPrefix(arg, "--errors-json=") {
+			g.errorsJSONPath = strings.TrimPrefix(arg, "--errors-json=")
+		} else if strings.HasPrefix(arg, "--stats=") {
+			g.statsPath = strings.TrimPrefix(arg, "--stats=")
+		} else if strings.HasPrefix(arg, "--extension-timeout=") {
+			timeout, err := time.ParseDuration(strings.TrimPrefix(arg, "--extension-timeout="))
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("invalid extension timeout: %s", arg)
+			}
+			g.extensionTimeout = timeout
+		} else if strings.HasPrefix(arg, "--extension-errors=") {
+			g.extensionErrors = strings.TrimPrefix(arg, "--extension-errors=")
+			if g.extensionErrors != compiler.ExtensionErrorsFail && g.extensionErrors != compiler.ExtensionErrorsWarn {
+				return fmt.Errorf("invalid extension error mode: %s", arg)
+			}
+		} else if strings.HasPrefix(arg, "--extension-wildcard=") {
+			g.extensionWildcard = strings.TrimPrefix(arg, "--extension-wildcard=")
 		} else if m = extensionRegex.FindSubmatch([]byte(arg)); m != nil {
 			extensionName := string(m[1])
 			extensionHandler := compiler.ExtensionHandler{Name: extensionPrefix + extensionName}
 			g.extensionHandlers = append(g.extensionHandlers, extensionHandler)
+		} else if strings.HasPrefix(arg, "--extension-config=") {
+			g.extensionConfigPath = strings.TrimPrefix(arg, "--extension-config=")
+		} else if strings.HasPrefix(arg, "--max-input-bytes=") {
+			value, err := strconv.ParseInt(strings.TrimPrefix(arg, "--max-input-bytes="), 10, 64)
+			if err != nil || value <= 0 {
+				return fmt.Errorf("invalid input size limit: %s", arg)
+			}
+			g.limits.MaxInputBytes = value
+		} else if strings.HasPrefix(arg, "--max-depth=") {
+			value, err := strconv.Atoi(strings.TrimPrefix(arg, "--max-depth="))
+			if err != nil || value <= 0 {
+				return fmt.Errorf("invalid depth limit: %s", arg)
+			}
+			g.limits.MaxDepth = value
+		} else if strings.HasPrefix(arg, "--document-index=") {
+			value, err := strconv.Atoi(strings.TrimPrefix(arg, "--document-index="))
+			if err != nil || value < 0 {
+				return fmt.Errorf("invalid document index: %s", arg)
+			}
+			g.documentIndex = value
+		} else if strings.HasPrefix(arg, "--max-alias-expansions=") {
+			value, err := strconv.Atoi(strings.TrimPrefix(arg, "--max-alias-expansions="))
+			if err != nil || value <= 0 {
+				return fmt.Errorf("invalid alias expansion limit: %s", arg)
+			}
+			g.limits.MaxAliasExpansions = value
+		} else if arg == "--strict-extensions" {
+			g.strictExtensions = true
+		} else if arg == "--strict-keys" {
+			g.strictKeys = true
+		} else if arg == "--yaml11-compat" {
+			g.yaml11Compat = true
+		} else if arg == "--fill-defaults" {
+			g.fillDefaults = true
 		} else if arg == "--resolve-refs" {
 			g.resolveReferences = true
+		} else if arg == "--warnings-as-errors" {
+			g.warningsAsErrors = true
+		} else if arg == "--strict-refs" {
+			g.strictRefs = true
 		} else if arg == "--time-plugins" {
 			g.timePlugins = true
 		} else if arg == "--no-surface" {
 			g.excludeSurface = true
+		} else if arg == "--check-conflicts" {
+			g.checkConflicts = true
+		} else if arg == "--watch" {
+			g.watch = true
 		} else if len(arg) > 2 && arg[0] == '-' && arg[1] == '-' {
 			// try letting the option specify a plugin with no output files (or unwanted output files)
 			// this is useful for calling plugins like linters that only return messages
 			p := &pluginCall{Name: arg[2:], Invocation: "!"}
 			g.pluginCalls = append(g.pluginCalls, p)
+		} else if i == 1 && arg == "normalize" {
+			g.normalize = true
 		} else if arg[0] == '-' {
 			return NewUsageError(fmt.Sprintf("unknown option: %s", arg))
 		} else {
 			g.sourceName = arg
 		}
 	}
+	for _, p := range g.pluginCalls {
+		p.Options = pluginOptions[p.Name]
+		p.Server = pluginServers[p.Name]
+	}
 	return nil
 }
 
@@ -412,12 +706,16 @@ func (g *Gnostic) validateOptions() error {
 		g.jsonOutputPath == "" &&
 		g.errorOutputPath == "" &&
 		g.messageOutputPath == "" &&
+		g.errorsJSONPath == "" &&
+		g.statsPath == "" &&
+		!g.checkConflicts &&
 		len(g.pluginCalls) == 0 {
 		return NewUsageError("missing output directives")
 	}
 	if g.sourceName == "" {
 		return NewUsageError("no input specified")
 	}
+	g.outputName = uncompressedName(g.sourceName)
 	// If we get here and the error output is unspecified, write errors to stderr.
 	if g.errorOutputPath == "" {
 		g.errorOutputPath = "="
@@ -431,44 +729,84 @@ func (g *Gnostic) errorBytes(err error) []byte {
 }
 
 // Read an OpenAPI description from YAML or JSON.
-func (g *Gnostic) readOpenAPIText(bytes []byte) (message proto.Message, err error) {
-	info, err := compiler.ReadInfoFromBytes(g.sourceName, bytes)
+func (g *Gnostic) readOpenAPIText(ctx context.Context, bytes []byte) (message proto.Message, err error) {
+	info, err := compiler.ReadInfoFromBytesContext(ctx, g.sourceName, bytes)
 	if err != nil {
 		return nil, err
 	}
+	return g.compileOpenAPIInfo(ctx, info)
+}
+
+// Compile a parsed OpenAPI description.
+func (g *Gnostic) compileOpenAPIInfo(ctx context.Context, info *yaml.Node) (message proto.Message, err error) {
 	// Determine the OpenAPI version.
 	g.sourceFormat = getOpenAPIVersionFromInfo(info)
 	if g.sourceFormat == SourceFormatUnknown {
 		return nil, errors.New("unable to identify OpenAPI version")
 	}
-	// Compile to the proto model.
-	if g.sourceFormat == SourceFormatOpenAPI2 {
-		root := info.Content[0]
-		document, err := openapi_v2.NewDocument(root, compiler.NewContextWithExtensions("$root", root, nil, &g.extensionHandlers))
-		if err != nil {
-			return nil, err
+	if g.normalize && g.sourceFormat == SourceFormatOpenAPI3 {
+		openapi_v3.NormalizeMethodKeys(info)
+	}
+	if g.fillDefaults {
+		// Missing values are added as empty values, which FillDefaults replaces.
+		if g.sourceFormat == SourceFormatOpenAPI2 {
+			openapi_v2.FillMissingKeys(info)
+		} else if g.sourceFormat == SourceFormatOpenAPI3 {
+			openapi_v3.FillMissingKeys(info)
 		}
-		message = document
-	} else if g.sourceFormat == SourceFormatOpenAPI3 {
-		root := info.Content[0]
-		document, err := openapi_v3.NewDocument(root, compiler.NewContextWithExtensions("$root", root, nil, &g.extensionHandlers))
-		if err != nil {
-			return nil, err
+	}
+	// Compile to the proto model.
+	root := info.Content[0]
+	context := compiler.NewContextWithCancellation(ctx, "$root", root, nil, &g.extensionHandlers, g.extensionConfig)
+	defer func() {
+		g.warnings = compiler.Warnings(context)
+		for _, invalidKey := range g.invalidKeys {
+			g.warnings = append(g.warnings, invalidKey.Warning())
 		}
-		message = document
+		compiler.RemoveExtensionConfig(context)
+	}()
+	// Find the extensions and their locations, and send them to their handlers in batches.
+	compiler.CallExtensionsInBatchesWithCompiler(context, func() {
+		g.compileOpenAPIDocument(root, context)
+	})
+	message, err = g.compileOpenAPIDocument(root, context)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if !g.strictKeys {
+		// Invalid keys are warnings unless keys are strict.
+		g.invalidKeys, err = compiler.SplitInvalidKeyErrors(err)
 	} else {
-		root := info.Content[0]
-		document, err := discovery_v1.NewDocument(root, compiler.NewContextWithExtensions("$root", root, nil, &g.extensionHandlers))
-		if err != nil {
-			return nil, err
-		}
-		message = document
+		g.invalidKeys = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// In strict mode, fail if any extensions were left unhandled.
+	err = compiler.UnhandledExtensionsError(context)
+	if err != nil {
+		return nil, err
 	}
 	return message, err
 }
 
+// Compile a document to the proto model of its OpenAPI version.
+func (g *Gnostic) compileOpenAPIDocument(root *yaml.Node, context *compiler.Context) (proto.Message, error) {
+	if g.sourceFormat == SourceFormatOpenAPI2 {
+		return openapi_v2.NewDocument(root, context)
+	} else if g.sourceFormat == SourceFormatOpenAPI3 {
+		return openapi_v3.NewDocument(root, context)
+	}
+	return discovery_v1.NewDocument(root, context)
+}
+
 func (g *Gnostic) ReadOpenAPIText(bytes []byte) (message proto.Message, err error) {
-	return g.readOpenAPIText(bytes)
+	return g.readOpenAPIText(context.Background(), bytes)
+}
+
+// ReadOpenAPITextContext is like ReadOpenAPIText, but it stops with ctx.Err() when ctx is done.
+func (g *Gnostic) ReadOpenAPITextContext(ctx context.Context, bytes []byte) (message proto.Message, err error) {
+	return g.readOpenAPIText(ctx, bytes)
 }
 
 // Read an OpenAPI binary file.
@@ -476,7 +814,7 @@ func (g *Gnostic) readOpenAPIBinary(data []byte) (message proto.Message, err err
 	// try to read an OpenAPI v3 document
 	documentV3 := &openapi_v3.Document{}
 	err = proto.Unmarshal(data, documentV3)
-	if err == nil && strings.HasPrefix(documentV3.Openapi, "3.0") {
+	if err == nil && (strings.HasPrefix(documentV3.Openapi, "3.0") || strings.HasPrefix(documentV3.Openapi, "3.1")) {
 		g.sourceFormat = SourceFormatOpenAPI3
 		return documentV3, nil
 	}
@@ -501,9 +839,9 @@ func (g *Gnostic) readOpenAPIBinary(data []byte) (message proto.Message, err err
 func (g *Gnostic) writeBinaryOutput(message proto.Message) error {
 	protoBytes, err := proto.Marshal(message)
 	if err != nil {
-		writeFile(g.errorOutputPath, g.errorBytes(err), g.sourceName, "errors")
+		writeFile(g.errorOutputPath, g.errorBytes(err), g.outputName, "errors")
 	} else {
-		writeFile(g.binaryOutputPath, protoBytes, g.sourceName, "pb")
+		writeFile(g.binaryOutputPath, protoBytes, g.outputName, "pb")
 	}
 	return err
 }
@@ -511,7 +849,7 @@ func (g *Gnostic) writeBinaryOutput(message proto.Message) error {
 // Write a text pb representation.
 func (g *Gnostic) writeTextOutput(message proto.Message) {
 	bytes := []byte(proto.MarshalTextString(message))
-	writeFile(g.textOutputPath, bytes, g.sourceName, "text")
+	writeFile(g.textOutputPath, bytes, g.outputName, "text")
 }
 
 // Write JSON/YAML OpenAPI representations.
@@ -523,11 +861,17 @@ func (g *Gnostic) writeJSONYAMLOutput(message proto.Message) {
 		rawInfo = document.ToRawInfo()
 	} else if g.sourceFormat == SourceFormatOpenAPI3 {
 		document := message.(*openapi_v3.Document)
-		rawInfo = document.ToRawInfo()
+		rawInfo = openapi_v3.RawInfo(document)
 	} else if g.sourceFormat == SourceFormatDiscovery {
 		document := message.(*discovery_v1.Document)
 		rawInfo = document.ToRawInfo()
 	}
+	// Write every value in full, since several YAML parsers reject anchors and aliases.
+	rawInfo, err := compiler.ExpandAliases(rawInfo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating json/yaml output %s\n", err.Error())
+		return
+	}
 	if rawInfo.Kind != yaml.DocumentNode {
 		rawInfo = &yaml.Node{
 			Kind:    yaml.DocumentNode,
@@ -537,12 +881,21 @@ func (g *Gnostic) writeJSONYAMLOutput(message proto.Message) {
 	// Optionally write description in yaml format.
 	if g.yamlOutputPath != "" {
 		if rawInfo != nil {
-			bytes, err := yaml.Marshal(rawInfo)
+			var err error
+			if g.normalize {
+				var bytes []byte
+				bytes, err = openapi_v3.CanonicalYAML(message.(*openapi_v3.Document))
+				writeFile(g.yamlOutputPath, bytes, g.outputName, "yaml")
+			} else {
+				// Encode the description as it is written instead of marshaling it first.
+				err = writeOutput(g.yamlOutputPath, g.outputName, "yaml", func(writer io.Writer) error {
+					return compiler.EncodeYAML(writer, rawInfo, compiler.YAMLOptions{})
+				})
+			}
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error generating yaml output %s\n", err.Error())
 				fmt.Fprintf(os.Stderr, "info %+v", rawInfo)
 			}
-			writeFile(g.yamlOutputPath, bytes, g.sourceName, "yaml")
 		} else {
 			fmt.Fprintf(os.Stderr, "No yaml output available.\n")
 		}
@@ -550,15 +903,12 @@ func (g *Gnostic) writeJSONYAMLOutput(message proto.Message) {
 	// Optionally write description in json format.
 	if g.jsonOutputPath != "" {
 		if rawInfo != nil {
-			rawInfo := &yaml.Node{
-				Kind:    yaml.DocumentNode,
-				Content: []*yaml.Node{rawInfo},
-			}
-			bytes, err := jsonwriter.Marshal(rawInfo)
+			err := writeOutput(g.jsonOutputPath, g.outputName, "json", func(writer io.Writer) error {
+				return jsonwriter.Encode(writer, rawInfo)
+			})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error generating json output %s\n", err.Error())
 			}
-			writeFile(g.jsonOutputPath, bytes, g.sourceName, "json")
 		} else {
 			fmt.Fprintf(os.Stderr, "No json output available.\n")
 		}
@@ -569,28 +919,241 @@ func (g *Gnostic) writeJSONYAMLOutput(message proto.Message) {
 func (g *Gnostic) writeMessagesOutput(message proto.Message) error {
 	protoBytes, err := proto.Marshal(message)
 	if err != nil {
-		writeFile(g.messageOutputPath, g.errorBytes(err), g.sourceName, "errors")
+		writeFile(g.messageOutputPath, g.errorBytes(err), g.outputName, "errors")
 	} else {
-		writeFile(g.messageOutputPath, protoBytes, g.sourceName, "messages.pb")
+		writeFile(g.messageOutputPath, protoBytes, g.outputName, "messages.pb")
 	}
 	return err
 }
 
+// Convert the conflicts in a document to messages.
+func conflictMessages(document *openapi_v3.Document) []*plugins.Message {
+	messages := make([]*plugins.Message, 0)
+	for _, conflict := range openapi_v3.FindConflicts(document) {
+		messages = append(messages, &plugins.Message{
+			Level: plugins.Message_ERROR,
+			Code:  conflict.Code,
+			Text:  fmt.Sprintf("%s (first defined at %s)", conflict.Text, conflict.First),
+			Keys:  conflict.Second.Keys,
+		})
+	}
+	return messages
+}
+
+// Convert compiler warnings, like extension handler failures, to warning messages with their codes.
+func warningMessages(warnings []*compiler.Warning) []*plugins.Message {
+	messages := make([]*plugins.Message, 0)
+	for _, warning := range warnings {
+		messages = append(messages, &plugins.Message{
+			Level: plugins.Message_WARNING,
+			Code:  warning.Code,
+			Text:  warning.Message,
+			Keys:  warning.Keys(),
+		})
+	}
+	return messages
+}
+
+// Describe a value that was added by FillDefaults.
+func filledDefaultMessage(keys []string, value string) *plugins.Message {
+	return &plugins.Message{
+		Level: plugins.Message_INFO,
+		Code:  "FILLED_DEFAULT",
+		Text:  fmt.Sprintf("added %s %q", keys[len(keys)-1], value),
+		Keys:  keys,
+	}
+}
+
+// Convert a reference cycle to a warning, which is located at the first reference of the
+// cycle if it is in the source document.
+func referenceCycleWarning(cycle *compiler.ReferenceCycleError) *compiler.Warning {
+	var context *compiler.Context
+	if ref := cycle.Cycle[0]; strings.HasPrefix(ref, "#") {
+		keys, _ := compiler.SplitJSONPointer(ref[1:])
+		context = compiler.NewContext("$root", nil, nil)
+		for _, key := range keys {
+			context = compiler.NewContext(key, nil, context)
+		}
+	}
+	return compiler.NewWarning(context, "REFERENCE_CYCLE", cycle.Error())
+}
+
+// Print the warnings of a compilation to stderr. Invalid keys are counted, and reference
+// cycles are printed with the options that report them as errors.
+func (g *Gnostic) printWarnings() {
+	invalidKeys := 0
+	for _, warning := range g.warnings {
+		switch warning.Code {
+		case compiler.WarningInvalidKey:
+			invalidKeys++
+		case "REFERENCE_CYCLE":
+			fmt.Fprintf(os.Stderr, "Warning: %s has a %s. Its references were left unresolved. Use --strict-refs to report cycles as errors.\n",
+				g.sourceName, warning.Message)
+		default:
+			fmt.Fprintf(os.Stderr, "Warning: %s %s\n", g.sourceName, warning.Error())
+		}
+	}
+	if invalidKeys > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s has %d invalid %s. Use --strict-keys to report them as errors.\n",
+			g.sourceName, invalidKeys, compiler.PluralProperties(invalidKeys))
+	}
+}
+
+// Read, compile, and perform the actions for each document of a JSON/YAML source, or for
+// the document selected with --document-index.
+func (g *Gnostic) readOpenAPIDocuments(ctx context.Context, bytes []byte) error {
+	documents, err := compiler.ReadDocumentsFromBytesContext(ctx, g.sourceName, bytes)
+	if err != nil {
+		g.writeErrors(err, g.sourceName)
+		return err
+	}
+	if g.documentIndex >= len(documents) {
+		err = fmt.Errorf("document index %d is out of range for %d %s", g.documentIndex, len(documents), pluralDocuments(len(documents)))
+		g.writeErrors(err, g.sourceName)
+		return err
+	}
+	if g.documentIndex >= 0 || len(documents) == 1 {
+		index := g.documentIndex
+		if index < 0 {
+			index = 0
+		}
+		return g.processDocument(ctx, bytes, documents, index)
+	}
+	// The outputs of each document of a stream are named with its index.
+	paths := []*string{&g.binaryOutputPath, &g.textOutputPath, &g.yamlOutputPath, &g.jsonOutputPath, &g.errorOutputPath, &g.errorsJSONPath, &g.messageOutputPath}
+	original := make([]string, len(paths))
+	for i, path := range paths {
+		original[i] = *path
+	}
+	defer func() {
+		for i, path := range paths {
+			*path = original[i]
+		}
+		g.outputName = uncompressedName(g.sourceName)
+	}()
+	for index := range documents {
+		for i, path := range paths {
+			*path = documentOutputPath(original[i], index)
+		}
+		g.outputName = documentOutputPath(uncompressedName(g.sourceName), index)
+		if err = g.processDocument(ctx, bytes, documents, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compile and perform the actions for the document at index of the documents of the source.
+func (g *Gnostic) processDocument(ctx context.Context, bytes []byte, documents []*yaml.Node, index int) error {
+	if len(documents) > 1 {
+		// Files are read again for each document, and local references name its definitions.
+		cache := compiler.NewFileCache()
+		cache.SetBytes(g.sourceName, bytes)
+		cache.SetInfo(g.sourceName, documents[index])
+		ctx = compiler.WithFileCache(ctx, cache)
+	} else if cache, _ := compiler.FileCacheFromContext(ctx); cache != nil {
+		cache.SetInfo(g.sourceName, documents[index])
+	}
+	message, err := g.compileOpenAPIInfo(ctx, documents[index])
+	if err == nil {
+		err = g.performActions(ctx, message)
+	}
+	if err != nil {
+		if len(documents) > 1 {
+			err = fmt.Errorf("document %d: %w", index, err)
+		}
+		g.writeErrors(err, g.outputName)
+		return err
+	}
+	g.writeErrors(nil, g.outputName)
+	return nil
+}
+
+// documentOutputPath returns the name of an output file for the document at index of a
+// stream, like out.1.yaml for out.yaml. Directories and the special names are unchanged.
+func documentOutputPath(path string, index int) string {
+	if path == "" || path == "!" || path == "-" || path == "=" || isDirectory(path) {
+		return path
+	}
+	extension := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, extension), index, extension)
+}
+
+func pluralDocuments(count int) string {
+	if count == 1 {
+		return "document"
+	}
+	return "documents"
+}
+
 // Perform all actions specified in the command-line options.
-func (g *Gnostic) performActions(message proto.Message) (err error) {
+func (g *Gnostic) performActions(ctx context.Context, message proto.Message) (err error) {
 	// Optionally resolve internal references.
 	if g.resolveReferences {
-		if g.sourceFormat == SourceFormatOpenAPI2 {
-			document := message.(*openapi_v2.Document)
-			_, err = document.ResolveReferences(g.sourceName)
-		} else if g.sourceFormat == SourceFormatOpenAPI3 {
-			document := message.(*openapi_v3.Document)
-			_, err = document.ResolveReferences(g.sourceName)
+		resolve := func() (err error) {
+			if g.sourceFormat == SourceFormatOpenAPI2 {
+				document := message.(*openapi_v2.Document)
+				_, err = document.ResolveReferences(g.sourceName)
+			} else if g.sourceFormat == SourceFormatOpenAPI3 {
+				document := message.(*openapi_v3.Document)
+				_, err = document.ResolveReferences(g.sourceName)
+			}
+			return err
+		}
+		// Read the files referenced by YAML and JSON sources with the file resolver.
+		if extension := sourceExtension(g.sourceName); extension == ".json" || extension == ".yaml" {
+			// The OpenAPI v2 models also follow the references in the fragments that they resolve.
+			nested := g.sourceFormat == SourceFormatOpenAPI2
+			g.referenceCycles, err = compiler.ResolveReferencesWithCyclesContext(ctx, g.sourceName, nested, resolve)
+			if err == nil && g.strictRefs && len(g.referenceCycles) > 0 {
+				errors := make([]error, len(g.referenceCycles))
+				for i, cycle := range g.referenceCycles {
+					errors[i] = cycle
+				}
+				err = compiler.NewErrorGroupOrNil(errors)
+			}
+		} else {
+			err = resolve()
 		}
 		if err != nil {
 			return err
 		}
+		for _, cycle := range g.referenceCycles {
+			g.warnings = append(g.warnings, referenceCycleWarning(cycle))
+		}
+	}
+	if g.warningsAsErrors && len(g.warnings) > 0 {
+		errors := make([]error, len(g.warnings))
+		for i, warning := range g.warnings {
+			errors[i] = warning
+		}
+		// The warnings are reported as errors instead.
+		g.warnings = nil
+		return compiler.NewErrorGroupOrNil(errors)
 	}
+	// Optionally add values that the document omits.
+	var filledDefaults []*plugins.Message
+	if g.fillDefaults {
+		if g.sourceFormat == SourceFormatOpenAPI2 {
+			for _, filled := range openapi_v2.FillDefaults(message.(*openapi_v2.Document)) {
+				filledDefaults = append(filledDefaults, filledDefaultMessage(filled.Keys, filled.Value))
+			}
+		} else if g.sourceFormat == SourceFormatOpenAPI3 {
+			for _, filled := range openapi_v3.FillDefaults(message.(*openapi_v3.Document)) {
+				filledDefaults = append(filledDefaults, filledDefaultMessage(filled.Keys, filled.Value))
+			}
+		} else {
+			return errors.New("filling defaults requires an OpenAPI v2 or v3 description")
+		}
+	}
+	// Optionally rewrite the document in canonical form.
+	if g.normalize {
+		if g.sourceFormat != SourceFormatOpenAPI3 {
+			return errors.New("normalization requires an OpenAPI v3 description")
+		}
+		openapi_v3.Normalize(message.(*openapi_v3.Document), g.sourceName)
+	}
+	addDocumentStats(ctx, message)
 	// Optionally write proto in binary format.
 	if g.binaryOutputPath != "" {
 		err = g.writeBinaryOutput(message)
@@ -606,9 +1169,19 @@ func (g *Gnostic) performActions(message proto.Message) (err error) {
 	if g.yamlOutputPath != "" || g.jsonOutputPath != "" {
 		g.writeJSONYAMLOutput(message)
 	}
-	// Call all specified plugins.
-	messages := make([]*plugins.Message, 0)
+	messages := warningMessages(g.warnings)
+	g.printWarnings()
+	messages = append(messages, filledDefaults...)
 	errors := make([]error, 0)
+	// Optionally check for conflicting definitions.
+	if g.checkConflicts {
+		if g.sourceFormat == SourceFormatOpenAPI3 {
+			messages = append(messages, conflictMessages(message.(*openapi_v3.Document))...)
+		} else {
+			errors = append(errors, fmt.Errorf("conflict checking requires an OpenAPI v3 description"))
+		}
+	}
+	// Call all specified plugins.
 	for _, p := range g.pluginCalls {
 		pluginMessages, err := p.perform(message, g.sourceFormat, g.sourceName, g.timePlugins, g.excludeSurface)
 		if err != nil {
@@ -617,6 +1190,7 @@ func (g *Gnostic) performActions(message proto.Message) (err error) {
 		}
 		messages = append(messages, pluginMessages...)
 	}
+	addWarningStats(ctx, messages)
 	if g.messageOutputPath != "" {
 		err = g.writeMessagesOutput(&plugins.Messages{Messages: messages})
 		if err != nil {
@@ -635,6 +1209,13 @@ func (g *Gnostic) performActions(message proto.Message) (err error) {
 
 // Main is the main program for Gnostic.
 func (g *Gnostic) Main() error {
+	return g.MainContext(context.Background())
+}
+
+// MainContext is like Main, but it stops with ctx.Err() when ctx is done. Files are cached
+// in the cache of ctx if it has one (see compiler.WithFileCache) and otherwise in a new
+// cache for the call, so concurrent calls don't share files.
+func (g *Gnostic) MainContext(ctx context.Context) error {
 	// if help is requested, print usage and immediately exit
 	for _, arg := range g.args {
 		if arg == "--help" {
@@ -643,8 +1224,6 @@ func (g *Gnostic) Main() error {
 		}
 	}
 
-	compiler.ClearCaches()
-
 	var err error
 	err = g.readOptions()
 	if err != nil {
@@ -654,38 +1233,106 @@ func (g *Gnostic) Main() error {
 	if err != nil {
 		return err
 	}
+	// Connections to plugin servers are kept for every document that is compiled.
+	defer func() {
+		for _, p := range g.pluginCalls {
+			p.closeServer()
+		}
+	}()
+	if g.watch {
+		return g.watchSource(ctx)
+	}
+	return g.run(ctx)
+}
+
+// Compile the source and perform the actions specified by command options.
+func (g *Gnostic) run(ctx context.Context) error {
+	compiler.ClearCaches()
+	g.warnings = nil
+	// Each compilation caches the files that it reads unless the caller supplied a cache.
+	if _, ok := compiler.FileCacheFromContext(ctx); !ok {
+		ctx = compiler.WithFileCache(ctx, compiler.NewFileCache())
+	}
+	if g.yaml11Compat {
+		ctx = compiler.WithYAML11Compat(ctx)
+	}
+	// Count the events of the run, as well as reporting them to any sink of the caller.
+	if g.statsPath != "" {
+		stats := compiler.NewStats()
+		ctx = compiler.WithStatsSink(ctx, compiler.MultiStatsSink(compiler.StatsSinkFromContext(ctx), stats))
+		start := time.Now()
+		defer func() {
+			g.writeStats(stats, time.Since(start))
+		}()
+	}
+	var err error
+	// Apply the limits that were set with options, keeping the others.
+	if g.limits != (compiler.Limits{}) {
+		previous := compiler.CurrentLimits()
+		limits := previous
+		if g.limits.MaxInputBytes != 0 {
+			limits.MaxInputBytes = g.limits.MaxInputBytes
+		}
+		if g.limits.MaxDepth != 0 {
+			limits.MaxDepth = g.limits.MaxDepth
+		}
+		if g.limits.MaxAliasExpansions != 0 {
+			limits.MaxAliasExpansions = g.limits.MaxAliasExpansions
+		}
+		compiler.SetLimits(limits)
+		defer compiler.SetLimits(previous)
+	}
+	// Read the extension configuration.
+	g.extensionConfig = nil
+	if g.extensionConfigPath != "" {
+		g.extensionConfig, err = compiler.ReadExtensionConfig(g.extensionConfigPath)
+		if err != nil {
+			g.writeErrors(err, g.sourceName)
+			return err
+		}
+	}
+	if g.extensionConfig == nil {
+		g.extensionConfig = &compiler.ExtensionConfig{}
+	}
+	if g.extensionTimeout != 0 {
+		g.extensionConfig.Timeout = g.extensionTimeout
+	}
+	if g.extensionErrors != "" {
+		g.extensionConfig.Errors = g.extensionErrors
+	}
+	if g.strictExtensions {
+		g.extensionConfig.Strict = true
+	}
+	if g.extensionWildcard != "" {
+		// Listed first so that it replaces any wildcard handler in the configuration file.
+		wildcard := &compiler.ConfiguredExtensionHandler{Pattern: compiler.WildcardPattern, Command: g.extensionWildcard}
+		g.extensionConfig.Handlers = append([]*compiler.ConfiguredExtensionHandler{wildcard}, g.extensionConfig.Handlers...)
+	}
 	// Read the OpenAPI source.
-	bytes, err := compiler.ReadBytesForFile(g.sourceName)
+	bytes, err := compiler.ReadBytesForFileContext(ctx, g.sourceName)
 	if err != nil {
-		writeFile(g.errorOutputPath, g.errorBytes(err), g.sourceName, "errors")
+		g.writeErrors(err, g.sourceName)
 		return err
 	}
-	extension := strings.ToLower(filepath.Ext(g.sourceName))
+	extension := sourceExtension(g.sourceName)
 	var message proto.Message
 	if extension == ".json" || extension == ".yaml" {
-		// Try to read the source as JSON/YAML.
-		message, err = g.readOpenAPIText(bytes)
-		if err != nil {
-			writeFile(g.errorOutputPath, g.errorBytes(err), g.sourceName, "errors")
-			return err
-		}
+		// Try to read the source as JSON/YAML, which can be a stream of YAML documents.
+		return g.readOpenAPIDocuments(ctx, bytes)
 	} else if extension == ".pb" {
 		// Try to read the source as a binary protocol buffer.
 		message, err = g.readOpenAPIBinary(bytes)
 		if err != nil {
-			writeFile(g.errorOutputPath, g.errorBytes(err), g.sourceName, "errors")
+			g.writeErrors(err, g.sourceName)
 			return err
 		}
 	} else {
 		err = errors.New("unknown file extension. 'json', 'yaml', and 'pb' are accepted")
-		writeFile(g.errorOutputPath, g.errorBytes(err), g.sourceName, "errors")
+		g.writeErrors(err, g.sourceName)
 		return err
 	}
 	// Perform actions specified by command options.
-	err = g.performActions(message)
-	if err != nil {
-		writeFile(g.errorOutputPath, g.errorBytes(err), g.sourceName, "errors")
-		return err
-	}
-	return nil
+	err = g.performActions(ctx, message)
+	g.writeErrors(err, g.sourceName)
+	return err
 }
@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/google/gnostic/jsonwriter"
+	openapi_v3 "github.com/google/gnostic/openapiv3"
+)
+
+// largeDocument returns an OpenAPI v3 document with a number of resources, each with
+// a path of two operations and a schema of a dozen properties.
+func largeDocument(tb testing.TB, resources int) *openapi_v3.Document {
+	var b strings.Builder
+	b.WriteString("openapi: 3.0.0\ninfo:\n  title: Large\n  version: 1.0.0\npaths:\n")
+	for i := 0; i < resources; i++ {
+		fmt.Fprintf(&b, `  /resources%[1]d/{name}:
+    get:
+      operationId: getResource%[1]d
+      description: "Returns a resource: \"%[1]d\"."
+      parameters:
+        - name: name
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            format: int32
+            maximum: 100.5
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Resource%[1]d'
+    delete:
+      operationId: deleteResource%[1]d
+      deprecated: true
+      responses:
+        default:
+          description: Error
+`, i)
+	}
+	b.WriteString("components:\n  schemas:\n")
+	for i := 0; i < resources; i++ {
+		fmt.Fprintf(&b, "    Resource%d:\n      type: object\n      required: [name]\n      properties:\n", i)
+		for j := 0; j < 12; j++ {
+			fmt.Fprintf(&b, "        field%d:\n          type: array\n          items:\n            type: string\n            enum: [a, b, 'yes']\n", j)
+		}
+	}
+	document, err := openapi_v3.ParseDocument([]byte(b.String()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return document
+}
+
+func TestJSONYAMLOutput(t *testing.T) {
+	document := largeDocument(t, 20)
+	dir := t.TempDir()
+	g := &Gnostic{
+		outputName:     "large.yaml",
+		sourceFormat:   SourceFormatOpenAPI3,
+		yamlOutputPath: filepath.Join(dir, "large.yaml"),
+		jsonOutputPath: filepath.Join(dir, "large.json"),
+	}
+	g.writeJSONYAMLOutput(document)
+	// Streamed outputs match the marshaled documents.
+	rawInfo := document.ToRawInfo()
+	expectedYAML, err := yaml.Marshal(rawInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJSON, err := jsonwriter.Marshal(rawInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string][]byte{g.yamlOutputPath: expectedYAML, g.jsonOutputPath: expectedJSON} {
+		actual, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("unexpected contents of %s:\n%s", filepath.Base(name), actual)
+		}
+	}
+}
+
+func TestYAMLOutputWithoutAnchors(t *testing.T) {
+	document, err := openapi_v3.ParseDocument([]byte(`openapi: 3.0.0
+info:
+  title: Anchors
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Resource:
+      type: object
+      example: &example
+        name: resource
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, normalize := range []bool{false, true} {
+		g := &Gnostic{
+			outputName:     "anchors.yaml",
+			sourceFormat:   SourceFormatOpenAPI3,
+			yamlOutputPath: filepath.Join(dir, "anchors.yaml"),
+			normalize:      normalize,
+		}
+		g.writeJSONYAMLOutput(document)
+		bytes, err := os.ReadFile(g.yamlOutputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(bytes), "&example") || !strings.Contains(string(bytes), "name: resource") {
+			t.Errorf("unexpected output with normalize=%t:\n%s", normalize, bytes)
+		}
+	}
+}
+
+func BenchmarkJSONYAMLOutput(b *testing.B) {
+	document := largeDocument(b, 1000)
+	dir := b.TempDir()
+	for _, format := range []string{"yaml", "json"} {
+		b.Run(format, func(b *testing.B) {
+			g := &Gnostic{outputName: "large.yaml", sourceFormat: SourceFormatOpenAPI3}
+			if format == "yaml" {
+				g.yamlOutputPath = filepath.Join(dir, "large.yaml")
+			} else {
+				g.jsonOutputPath = filepath.Join(dir, "large.json")
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				g.writeJSONYAMLOutput(document)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/gnostic/compiler"
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	openapi_v3 "github.com/google/gnostic/openapiv3"
+	plugins "github.com/google/gnostic/plugins"
+)
+
+// Names of the counts that gnostic adds to the StatsSink of its Go context, in addition
+// to those of the compiler, like compiler.StatReferencesResolved.
+const (
+	// StatPaths counts the paths of the descriptions that are processed.
+	StatPaths = "paths"
+	// StatOperations counts the operations of the paths.
+	StatOperations = "operations"
+	// StatSchemas counts the named schemas, which are definitions in OpenAPI v2.
+	StatSchemas = "schemas"
+	// StatParameters counts the parameters of paths and operations and the named parameters.
+	StatParameters = "parameters"
+	// StatWarnings counts the warning messages.
+	StatWarnings = "warnings"
+	// statElapsed is the time that a run took in milliseconds, which is written with the
+	// other counts by --stats.
+	statElapsed = "elapsed_ms"
+)
+
+// statNames are the counts that --stats writes, including those that are zero.
+var statNames = []string{
+	StatPaths,
+	StatOperations,
+	StatSchemas,
+	StatParameters,
+	compiler.StatReferencesResolved,
+	compiler.StatRemoteFetches,
+	compiler.StatExtensionsHandled,
+	StatWarnings,
+}
+
+// Count the elements of a compiled description.
+func addDocumentStats(ctx context.Context, message proto.Message) {
+	var paths, operations, schemas, parameters int
+	switch document := message.(type) {
+	case *openapi_v2.Document:
+		for _, namedPathItem := range document.GetPaths().GetPath() {
+			pathItem := namedPathItem.Value
+			paths++
+			parameters += len(pathItem.GetParameters())
+			for _, operation := range []*openapi_v2.Operation{
+				pathItem.GetGet(), pathItem.GetPut(), pathItem.GetPost(), pathItem.GetDelete(),
+				pathItem.GetOptions(), pathItem.GetHead(), pathItem.GetPatch(),
+			} {
+				if operation != nil {
+					operations++
+					parameters += len(operation.Parameters)
+				}
+			}
+		}
+		schemas = len(document.GetDefinitions().GetAdditionalProperties())
+		parameters += len(document.GetParameters().GetAdditionalProperties())
+	case *openapi_v3.Document:
+		for _, namedPathItem := range document.GetPaths().GetPath() {
+			pathItem := namedPathItem.Value
+			paths++
+			parameters += len(pathItem.GetParameters())
+			for _, operation := range []*openapi_v3.Operation{
+				pathItem.GetGet(), pathItem.GetPut(), pathItem.GetPost(), pathItem.GetDelete(),
+				pathItem.GetOptions(), pathItem.GetHead(), pathItem.GetPatch(), pathItem.GetTrace(),
+			} {
+				if operation != nil {
+					operations++
+					parameters += len(operation.Parameters)
+				}
+			}
+		}
+		schemas = len(document.GetComponents().GetSchemas().GetAdditionalProperties())
+		parameters += len(document.GetComponents().GetParameters().GetAdditionalProperties())
+	}
+	compiler.AddStat(ctx, StatPaths, int64(paths))
+	compiler.AddStat(ctx, StatOperations, int64(operations))
+	compiler.AddStat(ctx, StatSchemas, int64(schemas))
+	compiler.AddStat(ctx, StatParameters, int64(parameters))
+}
+
+// Count the warnings among messages.
+func addWarningStats(ctx context.Context, messages []*plugins.Message) {
+	warnings := 0
+	for _, message := range messages {
+		if message.Level == plugins.Message_WARNING {
+			warnings++
+		}
+	}
+	compiler.AddStat(ctx, StatWarnings, int64(warnings))
+}
+
+// Write the counts of a run as a line of JSON.
+func (g *Gnostic) writeStats(stats *compiler.Stats, elapsed time.Duration) {
+	counts := stats.Counts()
+	for _, name := range statNames {
+		if _, ok := counts[name]; !ok {
+			counts[name] = 0
+		}
+	}
+	counts[statElapsed] = elapsed.Milliseconds()
+	// Keys are sorted, so each run writes its counts in the same order.
+	bytes, _ := json.Marshal(counts)
+	writeFile(g.statsPath, append(bytes, '\n'), g.outputName, "stats.json")
+}
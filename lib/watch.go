@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/gnostic/compiler"
+)
+
+const (
+	// watchInterval is the time between checks of the watched files.
+	watchInterval = 100 * time.Millisecond
+	// watchQuietPeriod is the time that files must be unchanged before the source is
+	// compiled again, so that editors that save files in several writes trigger one run.
+	watchQuietPeriod = 250 * time.Millisecond
+)
+
+// fileState is what is checked to notice that a file changed.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statFile(name string) fileState {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// Run, and run again whenever the source or a local file that it references changes,
+// until ctx is done. Failed runs are reported by run and don't stop watching.
+func (g *Gnostic) watchSource(ctx context.Context) error {
+	if isURL(g.sourceName) {
+		return errors.New("--watch requires a local source")
+	}
+	files := []string{g.sourceName}
+	for {
+		// Files are checked for changes since the start of the run, so that changes
+		// made while the source is compiled aren't missed.
+		states := make(map[string]fileState)
+		for _, file := range files {
+			states[file] = statFile(file)
+		}
+		// Each run reads the files again.
+		err := g.run(compiler.WithFileCache(ctx, compiler.NewFileCache()))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err == nil {
+			// References may have been added or removed, so the files are found again.
+			files = g.watchedFiles(ctx)
+		}
+		for _, file := range files {
+			if _, ok := states[file]; !ok {
+				states[file] = statFile(file)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Watching %d %s for changes.\n", len(files), pluralFiles(len(files)))
+		if err = waitForChanges(ctx, files, states); err != nil {
+			return err
+		}
+	}
+}
+
+// watchedFiles returns the source and the local files that it references.
+func (g *Gnostic) watchedFiles(ctx context.Context) []string {
+	files := []string{g.sourceName}
+	references, err := compiler.ReferencedFilesContext(compiler.WithFileCache(ctx, compiler.NewFileCache()), g.sourceName)
+	if err != nil {
+		return files
+	}
+	for _, file := range references {
+		if !isURL(file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// waitForChanges returns when any of files differs from its state in states and no files
+// have changed for watchQuietPeriod, or with ctx.Err() when ctx is done.
+func waitForChanges(ctx context.Context, files []string, states map[string]fileState) error {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	var changed time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			for _, file := range files {
+				if state := statFile(file); state != states[file] {
+					states[file] = state
+					changed = now
+				}
+			}
+			if !changed.IsZero() && now.Sub(changed) >= watchQuietPeriod {
+				return nil
+			}
+		}
+	}
+}
+
+func pluralFiles(count int) string {
+	if count == 1 {
+		return "file"
+	}
+	return "files"
+}
@@ -50,3 +50,42 @@ func FindNode(filename string, keys []string, token string) (*yaml.Node, error)
 	keys = append(keys, token)
 	return findNode(node.Content[0], 0, len(keys)-1, keys)
 }
+
+// FindKeyPath returns the node at the end of a key path in a parsed yaml or json document.
+// For mapping entries, the key node is returned so that its position identifies the entry.
+// If the path cannot be followed, nil is returned.
+func FindKeyPath(node *yaml.Node, keys []string) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	result := node
+	for _, key := range keys {
+		if node == nil {
+			return nil
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					result, next = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+			node = next
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[index]
+			result = node
+		default:
+			return nil
+		}
+	}
+	if node == nil {
+		return nil
+	}
+	return result
+}
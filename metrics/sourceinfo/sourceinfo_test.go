@@ -1,7 +1,10 @@
 package sourceinfo
 
 import (
+	"io/ioutil"
 	"testing"
+
+	"go.yaml.in/yaml/v3"
 )
 
 //TestFindLineNumbers runs unit tests on the sourceinfo package
@@ -52,3 +55,40 @@ func TestFindLineNumbersV3(t *testing.T) {
 		t.Errorf("Given token \"petId\", FindYamlLine() returned %d, expected 64", result.Line)
 	}
 }
+
+func TestFindKeyPath(t *testing.T) {
+	data, err := ioutil.ReadFile("../../examples/v3.0/yaml/petstore.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		keys []string
+		line int
+	}{
+		{keys: []string{"paths", "/pets", "get", "parameters", "0", "name"}, line: 18},
+		{keys: []string{"paths", "/pets/{petId}", "get"}, line: 58},
+		{keys: []string{"paths", "/pets", "get", "parameters", "0"}, line: 18},
+		{keys: []string{}, line: 1},
+	}
+	for _, test := range tests {
+		result := FindKeyPath(&node, test.keys)
+		if result == nil {
+			t.Errorf("FindKeyPath(%v) returned nil, expected line %d", test.keys, test.line)
+		} else if result.Line != test.line {
+			t.Errorf("FindKeyPath(%v) returned line %d, expected %d", test.keys, result.Line, test.line)
+		}
+	}
+	for _, keys := range [][]string{
+		{"paths", "/unknown"},
+		{"paths", "/pets", "get", "parameters", "7"},
+		{"openapi", "version"},
+	} {
+		if result := FindKeyPath(&node, keys); result != nil {
+			t.Errorf("FindKeyPath(%v) returned line %d, expected nil", keys, result.Line)
+		}
+	}
+}
@@ -36,6 +36,7 @@ func NewAdditionalPropertiesItem(in *yaml.Node, context *compiler.Context) (*Add
 	errors := make([]error, 0)
 	x := &AdditionalPropertiesItem{}
 	matched := false
+	var partialMatch error
 	// Schema schema = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -45,13 +46,17 @@ func NewAdditionalPropertiesItem(in *yaml.Node, context *compiler.Context) (*Add
 			if matchingError == nil {
 				x.Oneof = &AdditionalPropertiesItem_Schema{Schema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &AdditionalPropertiesItem_Schema{Schema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
 		}
 	}
 	// bool boolean = 2;
-	boolValue, ok := compiler.BoolForScalarNode(in)
+	boolValue, ok := compiler.BoolForScalarNodeInContext(context, "", in)
 	if ok {
 		x.Oneof = &AdditionalPropertiesItem_Boolean{Boolean: boolValue}
 		matched = true
@@ -59,6 +64,8 @@ func NewAdditionalPropertiesItem(in *yaml.Node, context *compiler.Context) (*Add
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid AdditionalPropertiesItem")
 		err := compiler.NewError(context, message)
@@ -93,15 +100,11 @@ func NewApiKeySecurity(in *yaml.Node, context *compiler.Context) (*ApiKeySecurit
 		}
 		allowedKeys := []string{"description", "in", "name", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -116,7 +119,7 @@ func NewApiKeySecurity(in *yaml.Node, context *compiler.Context) (*ApiKeySecurit
 		// string name = 2;
 		v2 := compiler.MapValueForKey(m, "name")
 		if v2 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v2)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -125,7 +128,7 @@ func NewApiKeySecurity(in *yaml.Node, context *compiler.Context) (*ApiKeySecurit
 		// string in = 3;
 		v3 := compiler.MapValueForKey(m, "in")
 		if v3 != nil {
-			x.In, ok = compiler.StringForScalarNode(v3)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -140,7 +143,7 @@ func NewApiKeySecurity(in *yaml.Node, context *compiler.Context) (*ApiKeySecurit
 		// string description = 4;
 		v4 := compiler.MapValueForKey(m, "description")
 		if v4 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v4)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -157,7 +160,7 @@ func NewApiKeySecurity(in *yaml.Node, context *compiler.Context) (*ApiKeySecurit
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "ApiKeySecurity")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -198,15 +201,11 @@ func NewBasicAuthenticationSecurity(in *yaml.Node, context *compiler.Context) (*
 		}
 		allowedKeys := []string{"description", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -221,7 +220,7 @@ func NewBasicAuthenticationSecurity(in *yaml.Node, context *compiler.Context) (*
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -238,7 +237,7 @@ func NewBasicAuthenticationSecurity(in *yaml.Node, context *compiler.Context) (*
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "BasicAuthenticationSecurity")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -279,15 +278,11 @@ func NewBodyParameter(in *yaml.Node, context *compiler.Context) (*BodyParameter,
 		}
 		allowedKeys := []string{"description", "in", "name", "required", "schema"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -296,7 +291,7 @@ func NewBodyParameter(in *yaml.Node, context *compiler.Context) (*BodyParameter,
 		// string name = 2;
 		v2 := compiler.MapValueForKey(m, "name")
 		if v2 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v2)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -305,7 +300,7 @@ func NewBodyParameter(in *yaml.Node, context *compiler.Context) (*BodyParameter,
 		// string in = 3;
 		v3 := compiler.MapValueForKey(m, "in")
 		if v3 != nil {
-			x.In, ok = compiler.StringForScalarNode(v3)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -320,7 +315,7 @@ func NewBodyParameter(in *yaml.Node, context *compiler.Context) (*BodyParameter,
 		// bool required = 4;
 		v4 := compiler.MapValueForKey(m, "required")
 		if v4 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v4)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -346,7 +341,7 @@ func NewBodyParameter(in *yaml.Node, context *compiler.Context) (*BodyParameter,
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "BodyParameter")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -381,15 +376,11 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 	} else {
 		allowedKeys := []string{"email", "name", "url"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -398,7 +389,7 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 		// string url = 2;
 		v2 := compiler.MapValueForKey(m, "url")
 		if v2 != nil {
-			x.Url, ok = compiler.StringForScalarNode(v2)
+			x.Url, ok = compiler.StringForScalarNodeInContext(context, "url", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for url: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -407,7 +398,7 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 		// string email = 3;
 		v3 := compiler.MapValueForKey(m, "email")
 		if v3 != nil {
-			x.Email, ok = compiler.StringForScalarNode(v3)
+			x.Email, ok = compiler.StringForScalarNodeInContext(context, "email", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for email: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -424,7 +415,7 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Contact")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -467,7 +458,7 @@ func NewDefault(in *yaml.Node, context *compiler.Context) (*Default, error) {
 				pair := &NamedAny{}
 				pair.Name = k
 				result := &Any{}
-				handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+				handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Default")
 				if handled {
 					if err != nil {
 						errors = append(errors, err)
@@ -537,15 +528,14 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 		}
 		allowedKeys := []string{"basePath", "consumes", "definitions", "externalDocs", "host", "info", "parameters", "paths", "produces", "responses", "schemes", "security", "securityDefinitions", "swagger", "tags"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string swagger = 1;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v1 := compiler.MapValueForKey(m, "swagger")
 		if v1 != nil {
-			x.Swagger, ok = compiler.StringForScalarNode(v1)
+			x.Swagger, ok = compiler.StringForScalarNodeInContext(context, "swagger", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for swagger: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -558,6 +548,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Info info = 2;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v2 := compiler.MapValueForKey(m, "info")
 		if v2 != nil {
 			var err error
@@ -567,29 +560,38 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// string host = 3;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v3 := compiler.MapValueForKey(m, "host")
 		if v3 != nil {
-			x.Host, ok = compiler.StringForScalarNode(v3)
+			x.Host, ok = compiler.StringForScalarNodeInContext(context, "host", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for host: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// string base_path = 4;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v4 := compiler.MapValueForKey(m, "basePath")
 		if v4 != nil {
-			x.BasePath, ok = compiler.StringForScalarNode(v4)
+			x.BasePath, ok = compiler.StringForScalarNodeInContext(context, "basePath", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for basePath: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// repeated string schemes = 5;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v5 := compiler.MapValueForKey(m, "schemes")
 		if v5 != nil {
 			v, ok := compiler.SequenceNodeForNode(v5)
 			if ok {
-				x.Schemes = compiler.StringArrayForSequenceNode(v)
+				x.Schemes = compiler.StringArrayForSequenceNodeInContext(context, "schemes", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for schemes: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -602,28 +604,37 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated string consumes = 6;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v6 := compiler.MapValueForKey(m, "consumes")
 		if v6 != nil {
 			v, ok := compiler.SequenceNodeForNode(v6)
 			if ok {
-				x.Consumes = compiler.StringArrayForSequenceNode(v)
+				x.Consumes = compiler.StringArrayForSequenceNodeInContext(context, "consumes", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for consumes: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// repeated string produces = 7;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v7 := compiler.MapValueForKey(m, "produces")
 		if v7 != nil {
 			v, ok := compiler.SequenceNodeForNode(v7)
 			if ok {
-				x.Produces = compiler.StringArrayForSequenceNode(v)
+				x.Produces = compiler.StringArrayForSequenceNodeInContext(context, "produces", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for produces: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// Paths paths = 8;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v8 := compiler.MapValueForKey(m, "paths")
 		if v8 != nil {
 			var err error
@@ -633,6 +644,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Definitions definitions = 9;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v9 := compiler.MapValueForKey(m, "definitions")
 		if v9 != nil {
 			var err error
@@ -642,6 +656,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// ParameterDefinitions parameters = 10;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v10 := compiler.MapValueForKey(m, "parameters")
 		if v10 != nil {
 			var err error
@@ -651,6 +668,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// ResponseDefinitions responses = 11;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v11 := compiler.MapValueForKey(m, "responses")
 		if v11 != nil {
 			var err error
@@ -660,6 +680,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated SecurityRequirement security = 12;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v12 := compiler.MapValueForKey(m, "security")
 		if v12 != nil {
 			// repeated SecurityRequirement
@@ -676,6 +699,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// SecurityDefinitions security_definitions = 13;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v13 := compiler.MapValueForKey(m, "securityDefinitions")
 		if v13 != nil {
 			var err error
@@ -685,6 +711,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated Tag tags = 14;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v14 := compiler.MapValueForKey(m, "tags")
 		if v14 != nil {
 			// repeated Tag
@@ -701,6 +730,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// ExternalDocs external_docs = 15;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v15 := compiler.MapValueForKey(m, "externalDocs")
 		if v15 != nil {
 			var err error
@@ -710,6 +742,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated NamedAny vendor_extension = 16;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		// MAP: Any ^x-
 		x.VendorExtension = make([]*NamedAny, 0)
 		for i := 0; i < len(m.Content); i += 2 {
@@ -720,7 +755,7 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Document")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -763,7 +798,7 @@ func NewExamples(in *yaml.Node, context *compiler.Context) (*Examples, error) {
 				pair := &NamedAny{}
 				pair.Name = k
 				result := &Any{}
-				handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+				handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Examples")
 				if handled {
 					if err != nil {
 						errors = append(errors, err)
@@ -803,15 +838,11 @@ func NewExternalDocs(in *yaml.Node, context *compiler.Context) (*ExternalDocs, e
 		}
 		allowedKeys := []string{"description", "url"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -820,7 +851,7 @@ func NewExternalDocs(in *yaml.Node, context *compiler.Context) (*ExternalDocs, e
 		// string url = 2;
 		v2 := compiler.MapValueForKey(m, "url")
 		if v2 != nil {
-			x.Url, ok = compiler.StringForScalarNode(v2)
+			x.Url, ok = compiler.StringForScalarNodeInContext(context, "url", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for url: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -837,7 +868,7 @@ func NewExternalDocs(in *yaml.Node, context *compiler.Context) (*ExternalDocs, e
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "ExternalDocs")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -878,15 +909,11 @@ func NewFileSchema(in *yaml.Node, context *compiler.Context) (*FileSchema, error
 		}
 		allowedKeys := []string{"default", "description", "example", "externalDocs", "format", "readOnly", "required", "title", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string format = 1;
 		v1 := compiler.MapValueForKey(m, "format")
 		if v1 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v1)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -895,7 +922,7 @@ func NewFileSchema(in *yaml.Node, context *compiler.Context) (*FileSchema, error
 		// string title = 2;
 		v2 := compiler.MapValueForKey(m, "title")
 		if v2 != nil {
-			x.Title, ok = compiler.StringForScalarNode(v2)
+			x.Title, ok = compiler.StringForScalarNodeInContext(context, "title", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for title: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -904,7 +931,7 @@ func NewFileSchema(in *yaml.Node, context *compiler.Context) (*FileSchema, error
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -924,7 +951,7 @@ func NewFileSchema(in *yaml.Node, context *compiler.Context) (*FileSchema, error
 		if v5 != nil {
 			v, ok := compiler.SequenceNodeForNode(v5)
 			if ok {
-				x.Required = compiler.StringArrayForSequenceNode(v)
+				x.Required = compiler.StringArrayForSequenceNodeInContext(context, "required", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -933,7 +960,7 @@ func NewFileSchema(in *yaml.Node, context *compiler.Context) (*FileSchema, error
 		// string type = 6;
 		v6 := compiler.MapValueForKey(m, "type")
 		if v6 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v6)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -948,7 +975,7 @@ func NewFileSchema(in *yaml.Node, context *compiler.Context) (*FileSchema, error
 		// bool read_only = 7;
 		v7 := compiler.MapValueForKey(m, "readOnly")
 		if v7 != nil {
-			x.ReadOnly, ok = compiler.BoolForScalarNode(v7)
+			x.ReadOnly, ok = compiler.BoolForScalarNodeInContext(context, "readOnly", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for readOnly: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -983,7 +1010,7 @@ func NewFileSchema(in *yaml.Node, context *compiler.Context) (*FileSchema, error
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "FileSchema")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1018,15 +1045,11 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 	} else {
 		allowedKeys := []string{"allowEmptyValue", "collectionFormat", "default", "description", "enum", "exclusiveMaximum", "exclusiveMinimum", "format", "in", "items", "maxItems", "maxLength", "maximum", "minItems", "minLength", "minimum", "multipleOf", "name", "pattern", "required", "type", "uniqueItems"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// bool required = 1;
 		v1 := compiler.MapValueForKey(m, "required")
 		if v1 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v1)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1035,7 +1058,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// string in = 2;
 		v2 := compiler.MapValueForKey(m, "in")
 		if v2 != nil {
-			x.In, ok = compiler.StringForScalarNode(v2)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1050,7 +1073,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1059,7 +1082,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// string name = 4;
 		v4 := compiler.MapValueForKey(m, "name")
 		if v4 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v4)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1068,7 +1091,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// bool allow_empty_value = 5;
 		v5 := compiler.MapValueForKey(m, "allowEmptyValue")
 		if v5 != nil {
-			x.AllowEmptyValue, ok = compiler.BoolForScalarNode(v5)
+			x.AllowEmptyValue, ok = compiler.BoolForScalarNodeInContext(context, "allowEmptyValue", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for allowEmptyValue: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1077,7 +1100,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// string type = 6;
 		v6 := compiler.MapValueForKey(m, "type")
 		if v6 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v6)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1092,7 +1115,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// string format = 7;
 		v7 := compiler.MapValueForKey(m, "format")
 		if v7 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v7)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1110,7 +1133,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// string collection_format = 9;
 		v9 := compiler.MapValueForKey(m, "collectionFormat")
 		if v9 != nil {
-			x.CollectionFormat, ok = compiler.StringForScalarNode(v9)
+			x.CollectionFormat, ok = compiler.StringForScalarNodeInContext(context, "collectionFormat", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for collectionFormat: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1134,7 +1157,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// float maximum = 11;
 		v11 := compiler.MapValueForKey(m, "maximum")
 		if v11 != nil {
-			v, ok := compiler.FloatForScalarNode(v11)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v11)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -1145,7 +1168,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// bool exclusive_maximum = 12;
 		v12 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v12 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v12)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1154,7 +1177,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// float minimum = 13;
 		v13 := compiler.MapValueForKey(m, "minimum")
 		if v13 != nil {
-			v, ok := compiler.FloatForScalarNode(v13)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v13)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -1165,7 +1188,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// bool exclusive_minimum = 14;
 		v14 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v14 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v14)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v14)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v14))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1174,7 +1197,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// int64 max_length = 15;
 		v15 := compiler.MapValueForKey(m, "maxLength")
 		if v15 != nil {
-			t, ok := compiler.IntForScalarNode(v15)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v15)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -1185,7 +1208,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// int64 min_length = 16;
 		v16 := compiler.MapValueForKey(m, "minLength")
 		if v16 != nil {
-			t, ok := compiler.IntForScalarNode(v16)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v16)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -1196,7 +1219,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// string pattern = 17;
 		v17 := compiler.MapValueForKey(m, "pattern")
 		if v17 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v17)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v17)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v17))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1205,7 +1228,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// int64 max_items = 18;
 		v18 := compiler.MapValueForKey(m, "maxItems")
 		if v18 != nil {
-			t, ok := compiler.IntForScalarNode(v18)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v18)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -1216,7 +1239,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// int64 min_items = 19;
 		v19 := compiler.MapValueForKey(m, "minItems")
 		if v19 != nil {
-			t, ok := compiler.IntForScalarNode(v19)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v19)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -1227,7 +1250,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// bool unique_items = 20;
 		v20 := compiler.MapValueForKey(m, "uniqueItems")
 		if v20 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v20)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v20)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v20))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1252,7 +1275,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 		// float multiple_of = 22;
 		v22 := compiler.MapValueForKey(m, "multipleOf")
 		if v22 != nil {
-			v, ok := compiler.FloatForScalarNode(v22)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v22)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -1271,7 +1294,7 @@ func NewFormDataParameterSubSchema(in *yaml.Node, context *compiler.Context) (*F
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "FormDataParameterSubSchema")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1312,15 +1335,11 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		}
 		allowedKeys := []string{"collectionFormat", "default", "description", "enum", "exclusiveMaximum", "exclusiveMinimum", "format", "items", "maxItems", "maxLength", "maximum", "minItems", "minLength", "minimum", "multipleOf", "pattern", "type", "uniqueItems"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1335,7 +1354,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// string format = 2;
 		v2 := compiler.MapValueForKey(m, "format")
 		if v2 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v2)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1353,7 +1372,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// string collection_format = 4;
 		v4 := compiler.MapValueForKey(m, "collectionFormat")
 		if v4 != nil {
-			x.CollectionFormat, ok = compiler.StringForScalarNode(v4)
+			x.CollectionFormat, ok = compiler.StringForScalarNodeInContext(context, "collectionFormat", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for collectionFormat: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1377,7 +1396,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// float maximum = 6;
 		v6 := compiler.MapValueForKey(m, "maximum")
 		if v6 != nil {
-			v, ok := compiler.FloatForScalarNode(v6)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v6)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -1388,7 +1407,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool exclusive_maximum = 7;
 		v7 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v7 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v7)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1397,7 +1416,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// float minimum = 8;
 		v8 := compiler.MapValueForKey(m, "minimum")
 		if v8 != nil {
-			v, ok := compiler.FloatForScalarNode(v8)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v8)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -1408,7 +1427,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool exclusive_minimum = 9;
 		v9 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v9 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v9)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1417,7 +1436,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// int64 max_length = 10;
 		v10 := compiler.MapValueForKey(m, "maxLength")
 		if v10 != nil {
-			t, ok := compiler.IntForScalarNode(v10)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v10)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -1428,7 +1447,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// int64 min_length = 11;
 		v11 := compiler.MapValueForKey(m, "minLength")
 		if v11 != nil {
-			t, ok := compiler.IntForScalarNode(v11)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v11)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -1439,7 +1458,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// string pattern = 12;
 		v12 := compiler.MapValueForKey(m, "pattern")
 		if v12 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v12)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1448,7 +1467,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// int64 max_items = 13;
 		v13 := compiler.MapValueForKey(m, "maxItems")
 		if v13 != nil {
-			t, ok := compiler.IntForScalarNode(v13)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v13)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -1459,7 +1478,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// int64 min_items = 14;
 		v14 := compiler.MapValueForKey(m, "minItems")
 		if v14 != nil {
-			t, ok := compiler.IntForScalarNode(v14)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v14)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -1470,7 +1489,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool unique_items = 15;
 		v15 := compiler.MapValueForKey(m, "uniqueItems")
 		if v15 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v15)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v15)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v15))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1495,7 +1514,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// float multiple_of = 17;
 		v17 := compiler.MapValueForKey(m, "multipleOf")
 		if v17 != nil {
-			v, ok := compiler.FloatForScalarNode(v17)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v17)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -1506,7 +1525,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// string description = 18;
 		v18 := compiler.MapValueForKey(m, "description")
 		if v18 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v18)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v18)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v18))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1523,7 +1542,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Header")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1558,15 +1577,11 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 	} else {
 		allowedKeys := []string{"collectionFormat", "default", "description", "enum", "exclusiveMaximum", "exclusiveMinimum", "format", "in", "items", "maxItems", "maxLength", "maximum", "minItems", "minLength", "minimum", "multipleOf", "name", "pattern", "required", "type", "uniqueItems"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// bool required = 1;
 		v1 := compiler.MapValueForKey(m, "required")
 		if v1 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v1)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1575,7 +1590,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// string in = 2;
 		v2 := compiler.MapValueForKey(m, "in")
 		if v2 != nil {
-			x.In, ok = compiler.StringForScalarNode(v2)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1590,7 +1605,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1599,7 +1614,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// string name = 4;
 		v4 := compiler.MapValueForKey(m, "name")
 		if v4 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v4)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1608,7 +1623,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// string type = 5;
 		v5 := compiler.MapValueForKey(m, "type")
 		if v5 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v5)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1623,7 +1638,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// string format = 6;
 		v6 := compiler.MapValueForKey(m, "format")
 		if v6 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v6)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1641,7 +1656,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// string collection_format = 8;
 		v8 := compiler.MapValueForKey(m, "collectionFormat")
 		if v8 != nil {
-			x.CollectionFormat, ok = compiler.StringForScalarNode(v8)
+			x.CollectionFormat, ok = compiler.StringForScalarNodeInContext(context, "collectionFormat", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for collectionFormat: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1665,7 +1680,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// float maximum = 10;
 		v10 := compiler.MapValueForKey(m, "maximum")
 		if v10 != nil {
-			v, ok := compiler.FloatForScalarNode(v10)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v10)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -1676,7 +1691,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// bool exclusive_maximum = 11;
 		v11 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v11 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v11)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v11)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v11))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1685,7 +1700,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// float minimum = 12;
 		v12 := compiler.MapValueForKey(m, "minimum")
 		if v12 != nil {
-			v, ok := compiler.FloatForScalarNode(v12)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v12)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -1696,7 +1711,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// bool exclusive_minimum = 13;
 		v13 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v13 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v13)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v13)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v13))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1705,7 +1720,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// int64 max_length = 14;
 		v14 := compiler.MapValueForKey(m, "maxLength")
 		if v14 != nil {
-			t, ok := compiler.IntForScalarNode(v14)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v14)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -1716,7 +1731,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// int64 min_length = 15;
 		v15 := compiler.MapValueForKey(m, "minLength")
 		if v15 != nil {
-			t, ok := compiler.IntForScalarNode(v15)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v15)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -1727,7 +1742,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// string pattern = 16;
 		v16 := compiler.MapValueForKey(m, "pattern")
 		if v16 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v16)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v16)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v16))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1736,7 +1751,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// int64 max_items = 17;
 		v17 := compiler.MapValueForKey(m, "maxItems")
 		if v17 != nil {
-			t, ok := compiler.IntForScalarNode(v17)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v17)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -1747,7 +1762,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// int64 min_items = 18;
 		v18 := compiler.MapValueForKey(m, "minItems")
 		if v18 != nil {
-			t, ok := compiler.IntForScalarNode(v18)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v18)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -1758,7 +1773,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// bool unique_items = 19;
 		v19 := compiler.MapValueForKey(m, "uniqueItems")
 		if v19 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v19)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v19)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v19))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1783,7 +1798,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 		// float multiple_of = 21;
 		v21 := compiler.MapValueForKey(m, "multipleOf")
 		if v21 != nil {
-			v, ok := compiler.FloatForScalarNode(v21)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v21)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -1802,7 +1817,7 @@ func NewHeaderParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Hea
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "HeaderParameterSubSchema")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1873,15 +1888,11 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		}
 		allowedKeys := []string{"contact", "description", "license", "termsOfService", "title", "version"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string title = 1;
 		v1 := compiler.MapValueForKey(m, "title")
 		if v1 != nil {
-			x.Title, ok = compiler.StringForScalarNode(v1)
+			x.Title, ok = compiler.StringForScalarNodeInContext(context, "title", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for title: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1890,7 +1901,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		// string version = 2;
 		v2 := compiler.MapValueForKey(m, "version")
 		if v2 != nil {
-			x.Version, ok = compiler.StringForScalarNode(v2)
+			x.Version, ok = compiler.StringForScalarNodeInContext(context, "version", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for version: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1899,7 +1910,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1908,7 +1919,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		// string terms_of_service = 4;
 		v4 := compiler.MapValueForKey(m, "termsOfService")
 		if v4 != nil {
-			x.TermsOfService, ok = compiler.StringForScalarNode(v4)
+			x.TermsOfService, ok = compiler.StringForScalarNodeInContext(context, "termsOfService", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for termsOfService: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1943,7 +1954,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Info")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2004,7 +2015,7 @@ func NewJsonReference(in *yaml.Node, context *compiler.Context) (*JsonReference,
 		// string _ref = 1;
 		v1 := compiler.MapValueForKey(m, "$ref")
 		if v1 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v1)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2013,7 +2024,7 @@ func NewJsonReference(in *yaml.Node, context *compiler.Context) (*JsonReference,
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2040,15 +2051,11 @@ func NewLicense(in *yaml.Node, context *compiler.Context) (*License, error) {
 		}
 		allowedKeys := []string{"name", "url"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2057,7 +2064,7 @@ func NewLicense(in *yaml.Node, context *compiler.Context) (*License, error) {
 		// string url = 2;
 		v2 := compiler.MapValueForKey(m, "url")
 		if v2 != nil {
-			x.Url, ok = compiler.StringForScalarNode(v2)
+			x.Url, ok = compiler.StringForScalarNodeInContext(context, "url", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for url: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2074,7 +2081,7 @@ func NewLicense(in *yaml.Node, context *compiler.Context) (*License, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "License")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2109,15 +2116,11 @@ func NewNamedAny(in *yaml.Node, context *compiler.Context) (*NamedAny, error) {
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2147,15 +2150,11 @@ func NewNamedHeader(in *yaml.Node, context *compiler.Context) (*NamedHeader, err
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2185,15 +2184,11 @@ func NewNamedParameter(in *yaml.Node, context *compiler.Context) (*NamedParamete
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2223,15 +2218,11 @@ func NewNamedPathItem(in *yaml.Node, context *compiler.Context) (*NamedPathItem,
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2261,15 +2252,11 @@ func NewNamedResponse(in *yaml.Node, context *compiler.Context) (*NamedResponse,
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2299,15 +2286,11 @@ func NewNamedResponseValue(in *yaml.Node, context *compiler.Context) (*NamedResp
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2337,15 +2320,11 @@ func NewNamedSchema(in *yaml.Node, context *compiler.Context) (*NamedSchema, err
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2375,15 +2354,11 @@ func NewNamedSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2413,15 +2388,11 @@ func NewNamedString(in *yaml.Node, context *compiler.Context) (*NamedString, err
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2430,7 +2401,7 @@ func NewNamedString(in *yaml.Node, context *compiler.Context) (*NamedString, err
 		// string value = 2;
 		v2 := compiler.MapValueForKey(m, "value")
 		if v2 != nil {
-			x.Value, ok = compiler.StringForScalarNode(v2)
+			x.Value, ok = compiler.StringForScalarNodeInContext(context, "value", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for value: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2451,15 +2422,11 @@ func NewNamedStringArray(in *yaml.Node, context *compiler.Context) (*NamedString
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2483,6 +2450,7 @@ func NewNonBodyParameter(in *yaml.Node, context *compiler.Context) (*NonBodyPara
 	errors := make([]error, 0)
 	x := &NonBodyParameter{}
 	matched := false
+	var partialMatch error
 	m, ok := compiler.UnpackMap(in)
 	if !ok {
 		message := fmt.Sprintf("has unexpected value: %+v (%T)", in, in)
@@ -2501,6 +2469,10 @@ func NewNonBodyParameter(in *yaml.Node, context *compiler.Context) (*NonBodyPara
 			if matchingError == nil {
 				x.Oneof = &NonBodyParameter_HeaderParameterSubSchema{HeaderParameterSubSchema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &NonBodyParameter_HeaderParameterSubSchema{HeaderParameterSubSchema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -2512,6 +2484,10 @@ func NewNonBodyParameter(in *yaml.Node, context *compiler.Context) (*NonBodyPara
 			if matchingError == nil {
 				x.Oneof = &NonBodyParameter_FormDataParameterSubSchema{FormDataParameterSubSchema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &NonBodyParameter_FormDataParameterSubSchema{FormDataParameterSubSchema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -2523,6 +2499,10 @@ func NewNonBodyParameter(in *yaml.Node, context *compiler.Context) (*NonBodyPara
 			if matchingError == nil {
 				x.Oneof = &NonBodyParameter_QueryParameterSubSchema{QueryParameterSubSchema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &NonBodyParameter_QueryParameterSubSchema{QueryParameterSubSchema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -2534,6 +2514,10 @@ func NewNonBodyParameter(in *yaml.Node, context *compiler.Context) (*NonBodyPara
 			if matchingError == nil {
 				x.Oneof = &NonBodyParameter_PathParameterSubSchema{PathParameterSubSchema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &NonBodyParameter_PathParameterSubSchema{PathParameterSubSchema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -2542,6 +2526,8 @@ func NewNonBodyParameter(in *yaml.Node, context *compiler.Context) (*NonBodyPara
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid NonBodyParameter")
 		err := compiler.NewError(context, message)
@@ -2567,15 +2553,11 @@ func NewOauth2AccessCodeSecurity(in *yaml.Node, context *compiler.Context) (*Oau
 		}
 		allowedKeys := []string{"authorizationUrl", "description", "flow", "scopes", "tokenUrl", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2590,7 +2572,7 @@ func NewOauth2AccessCodeSecurity(in *yaml.Node, context *compiler.Context) (*Oau
 		// string flow = 2;
 		v2 := compiler.MapValueForKey(m, "flow")
 		if v2 != nil {
-			x.Flow, ok = compiler.StringForScalarNode(v2)
+			x.Flow, ok = compiler.StringForScalarNodeInContext(context, "flow", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for flow: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2614,7 +2596,7 @@ func NewOauth2AccessCodeSecurity(in *yaml.Node, context *compiler.Context) (*Oau
 		// string authorization_url = 4;
 		v4 := compiler.MapValueForKey(m, "authorizationUrl")
 		if v4 != nil {
-			x.AuthorizationUrl, ok = compiler.StringForScalarNode(v4)
+			x.AuthorizationUrl, ok = compiler.StringForScalarNodeInContext(context, "authorizationUrl", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for authorizationUrl: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2623,7 +2605,7 @@ func NewOauth2AccessCodeSecurity(in *yaml.Node, context *compiler.Context) (*Oau
 		// string token_url = 5;
 		v5 := compiler.MapValueForKey(m, "tokenUrl")
 		if v5 != nil {
-			x.TokenUrl, ok = compiler.StringForScalarNode(v5)
+			x.TokenUrl, ok = compiler.StringForScalarNodeInContext(context, "tokenUrl", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for tokenUrl: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2632,7 +2614,7 @@ func NewOauth2AccessCodeSecurity(in *yaml.Node, context *compiler.Context) (*Oau
 		// string description = 6;
 		v6 := compiler.MapValueForKey(m, "description")
 		if v6 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v6)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2649,7 +2631,7 @@ func NewOauth2AccessCodeSecurity(in *yaml.Node, context *compiler.Context) (*Oau
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Oauth2AccessCodeSecurity")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2690,15 +2672,11 @@ func NewOauth2ApplicationSecurity(in *yaml.Node, context *compiler.Context) (*Oa
 		}
 		allowedKeys := []string{"description", "flow", "scopes", "tokenUrl", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2713,7 +2691,7 @@ func NewOauth2ApplicationSecurity(in *yaml.Node, context *compiler.Context) (*Oa
 		// string flow = 2;
 		v2 := compiler.MapValueForKey(m, "flow")
 		if v2 != nil {
-			x.Flow, ok = compiler.StringForScalarNode(v2)
+			x.Flow, ok = compiler.StringForScalarNodeInContext(context, "flow", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for flow: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2737,7 +2715,7 @@ func NewOauth2ApplicationSecurity(in *yaml.Node, context *compiler.Context) (*Oa
 		// string token_url = 4;
 		v4 := compiler.MapValueForKey(m, "tokenUrl")
 		if v4 != nil {
-			x.TokenUrl, ok = compiler.StringForScalarNode(v4)
+			x.TokenUrl, ok = compiler.StringForScalarNodeInContext(context, "tokenUrl", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for tokenUrl: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2746,7 +2724,7 @@ func NewOauth2ApplicationSecurity(in *yaml.Node, context *compiler.Context) (*Oa
 		// string description = 5;
 		v5 := compiler.MapValueForKey(m, "description")
 		if v5 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v5)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2763,7 +2741,7 @@ func NewOauth2ApplicationSecurity(in *yaml.Node, context *compiler.Context) (*Oa
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Oauth2ApplicationSecurity")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2804,15 +2782,11 @@ func NewOauth2ImplicitSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		}
 		allowedKeys := []string{"authorizationUrl", "description", "flow", "scopes", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2827,7 +2801,7 @@ func NewOauth2ImplicitSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		// string flow = 2;
 		v2 := compiler.MapValueForKey(m, "flow")
 		if v2 != nil {
-			x.Flow, ok = compiler.StringForScalarNode(v2)
+			x.Flow, ok = compiler.StringForScalarNodeInContext(context, "flow", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for flow: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2851,7 +2825,7 @@ func NewOauth2ImplicitSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		// string authorization_url = 4;
 		v4 := compiler.MapValueForKey(m, "authorizationUrl")
 		if v4 != nil {
-			x.AuthorizationUrl, ok = compiler.StringForScalarNode(v4)
+			x.AuthorizationUrl, ok = compiler.StringForScalarNodeInContext(context, "authorizationUrl", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for authorizationUrl: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2860,7 +2834,7 @@ func NewOauth2ImplicitSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		// string description = 5;
 		v5 := compiler.MapValueForKey(m, "description")
 		if v5 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v5)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2877,7 +2851,7 @@ func NewOauth2ImplicitSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Oauth2ImplicitSecurity")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2918,15 +2892,11 @@ func NewOauth2PasswordSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		}
 		allowedKeys := []string{"description", "flow", "scopes", "tokenUrl", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2941,7 +2911,7 @@ func NewOauth2PasswordSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		// string flow = 2;
 		v2 := compiler.MapValueForKey(m, "flow")
 		if v2 != nil {
-			x.Flow, ok = compiler.StringForScalarNode(v2)
+			x.Flow, ok = compiler.StringForScalarNodeInContext(context, "flow", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for flow: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2965,7 +2935,7 @@ func NewOauth2PasswordSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		// string token_url = 4;
 		v4 := compiler.MapValueForKey(m, "tokenUrl")
 		if v4 != nil {
-			x.TokenUrl, ok = compiler.StringForScalarNode(v4)
+			x.TokenUrl, ok = compiler.StringForScalarNodeInContext(context, "tokenUrl", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for tokenUrl: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2974,7 +2944,7 @@ func NewOauth2PasswordSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 		// string description = 5;
 		v5 := compiler.MapValueForKey(m, "description")
 		if v5 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v5)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2991,7 +2961,7 @@ func NewOauth2PasswordSecurity(in *yaml.Node, context *compiler.Context) (*Oauth
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Oauth2PasswordSecurity")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3058,17 +3028,13 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		}
 		allowedKeys := []string{"consumes", "deprecated", "description", "externalDocs", "operationId", "parameters", "produces", "responses", "schemes", "security", "summary", "tags"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated string tags = 1;
 		v1 := compiler.MapValueForKey(m, "tags")
 		if v1 != nil {
 			v, ok := compiler.SequenceNodeForNode(v1)
 			if ok {
-				x.Tags = compiler.StringArrayForSequenceNode(v)
+				x.Tags = compiler.StringArrayForSequenceNodeInContext(context, "tags", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for tags: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3077,7 +3043,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// string summary = 2;
 		v2 := compiler.MapValueForKey(m, "summary")
 		if v2 != nil {
-			x.Summary, ok = compiler.StringForScalarNode(v2)
+			x.Summary, ok = compiler.StringForScalarNodeInContext(context, "summary", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for summary: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3086,7 +3052,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3104,7 +3070,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// string operation_id = 5;
 		v5 := compiler.MapValueForKey(m, "operationId")
 		if v5 != nil {
-			x.OperationId, ok = compiler.StringForScalarNode(v5)
+			x.OperationId, ok = compiler.StringForScalarNodeInContext(context, "operationId", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for operationId: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3115,7 +3081,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		if v6 != nil {
 			v, ok := compiler.SequenceNodeForNode(v6)
 			if ok {
-				x.Produces = compiler.StringArrayForSequenceNode(v)
+				x.Produces = compiler.StringArrayForSequenceNodeInContext(context, "produces", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for produces: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3126,7 +3092,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		if v7 != nil {
 			v, ok := compiler.SequenceNodeForNode(v7)
 			if ok {
-				x.Consumes = compiler.StringArrayForSequenceNode(v)
+				x.Consumes = compiler.StringArrayForSequenceNodeInContext(context, "consumes", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for consumes: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3162,7 +3128,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		if v10 != nil {
 			v, ok := compiler.SequenceNodeForNode(v10)
 			if ok {
-				x.Schemes = compiler.StringArrayForSequenceNode(v)
+				x.Schemes = compiler.StringArrayForSequenceNodeInContext(context, "schemes", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for schemes: %s", compiler.Display(v10))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3177,7 +3143,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// bool deprecated = 11;
 		v11 := compiler.MapValueForKey(m, "deprecated")
 		if v11 != nil {
-			x.Deprecated, ok = compiler.BoolForScalarNode(v11)
+			x.Deprecated, ok = compiler.BoolForScalarNodeInContext(context, "deprecated", v11)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for deprecated: %s", compiler.Display(v11))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3210,7 +3176,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Operation")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3239,6 +3205,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 	errors := make([]error, 0)
 	x := &Parameter{}
 	matched := false
+	var partialMatch error
 	// BodyParameter body_parameter = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -3248,6 +3215,10 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 			if matchingError == nil {
 				x.Oneof = &Parameter_BodyParameter{BodyParameter: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &Parameter_BodyParameter{BodyParameter: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3262,6 +3233,10 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 			if matchingError == nil {
 				x.Oneof = &Parameter_NonBodyParameter{NonBodyParameter: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &Parameter_NonBodyParameter{NonBodyParameter: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3270,6 +3245,8 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid Parameter")
 		err := compiler.NewError(context, message)
@@ -3313,6 +3290,7 @@ func NewParametersItem(in *yaml.Node, context *compiler.Context) (*ParametersIte
 	errors := make([]error, 0)
 	x := &ParametersItem{}
 	matched := false
+	var partialMatch error
 	// Parameter parameter = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -3322,6 +3300,10 @@ func NewParametersItem(in *yaml.Node, context *compiler.Context) (*ParametersIte
 			if matchingError == nil {
 				x.Oneof = &ParametersItem_Parameter{Parameter: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ParametersItem_Parameter{Parameter: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3336,6 +3318,10 @@ func NewParametersItem(in *yaml.Node, context *compiler.Context) (*ParametersIte
 			if matchingError == nil {
 				x.Oneof = &ParametersItem_JsonReference{JsonReference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ParametersItem_JsonReference{JsonReference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3344,6 +3330,8 @@ func NewParametersItem(in *yaml.Node, context *compiler.Context) (*ParametersIte
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid ParametersItem")
 		err := compiler.NewError(context, message)
@@ -3363,15 +3351,11 @@ func NewPathItem(in *yaml.Node, context *compiler.Context) (*PathItem, error) {
 	} else {
 		allowedKeys := []string{"$ref", "delete", "get", "head", "options", "parameters", "patch", "post", "put"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string _ref = 1;
 		v1 := compiler.MapValueForKey(m, "$ref")
 		if v1 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v1)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3467,7 +3451,7 @@ func NewPathItem(in *yaml.Node, context *compiler.Context) (*PathItem, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "PathItem")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3508,15 +3492,11 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		}
 		allowedKeys := []string{"collectionFormat", "default", "description", "enum", "exclusiveMaximum", "exclusiveMinimum", "format", "in", "items", "maxItems", "maxLength", "maximum", "minItems", "minLength", "minimum", "multipleOf", "name", "pattern", "required", "type", "uniqueItems"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// bool required = 1;
 		v1 := compiler.MapValueForKey(m, "required")
 		if v1 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v1)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3525,7 +3505,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// string in = 2;
 		v2 := compiler.MapValueForKey(m, "in")
 		if v2 != nil {
-			x.In, ok = compiler.StringForScalarNode(v2)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3540,7 +3520,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3549,7 +3529,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// string name = 4;
 		v4 := compiler.MapValueForKey(m, "name")
 		if v4 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v4)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3558,7 +3538,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// string type = 5;
 		v5 := compiler.MapValueForKey(m, "type")
 		if v5 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v5)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3573,7 +3553,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// string format = 6;
 		v6 := compiler.MapValueForKey(m, "format")
 		if v6 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v6)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3591,7 +3571,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// string collection_format = 8;
 		v8 := compiler.MapValueForKey(m, "collectionFormat")
 		if v8 != nil {
-			x.CollectionFormat, ok = compiler.StringForScalarNode(v8)
+			x.CollectionFormat, ok = compiler.StringForScalarNodeInContext(context, "collectionFormat", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for collectionFormat: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3615,7 +3595,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// float maximum = 10;
 		v10 := compiler.MapValueForKey(m, "maximum")
 		if v10 != nil {
-			v, ok := compiler.FloatForScalarNode(v10)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v10)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -3626,7 +3606,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// bool exclusive_maximum = 11;
 		v11 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v11 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v11)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v11)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v11))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3635,7 +3615,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// float minimum = 12;
 		v12 := compiler.MapValueForKey(m, "minimum")
 		if v12 != nil {
-			v, ok := compiler.FloatForScalarNode(v12)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v12)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -3646,7 +3626,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// bool exclusive_minimum = 13;
 		v13 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v13 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v13)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v13)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v13))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3655,7 +3635,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// int64 max_length = 14;
 		v14 := compiler.MapValueForKey(m, "maxLength")
 		if v14 != nil {
-			t, ok := compiler.IntForScalarNode(v14)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v14)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -3666,7 +3646,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// int64 min_length = 15;
 		v15 := compiler.MapValueForKey(m, "minLength")
 		if v15 != nil {
-			t, ok := compiler.IntForScalarNode(v15)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v15)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -3677,7 +3657,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// string pattern = 16;
 		v16 := compiler.MapValueForKey(m, "pattern")
 		if v16 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v16)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v16)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v16))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3686,7 +3666,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// int64 max_items = 17;
 		v17 := compiler.MapValueForKey(m, "maxItems")
 		if v17 != nil {
-			t, ok := compiler.IntForScalarNode(v17)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v17)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -3697,7 +3677,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// int64 min_items = 18;
 		v18 := compiler.MapValueForKey(m, "minItems")
 		if v18 != nil {
-			t, ok := compiler.IntForScalarNode(v18)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v18)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -3708,7 +3688,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// bool unique_items = 19;
 		v19 := compiler.MapValueForKey(m, "uniqueItems")
 		if v19 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v19)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v19)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v19))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3733,7 +3713,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 		// float multiple_of = 21;
 		v21 := compiler.MapValueForKey(m, "multipleOf")
 		if v21 != nil {
-			v, ok := compiler.FloatForScalarNode(v21)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v21)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -3752,7 +3732,7 @@ func NewPathParameterSubSchema(in *yaml.Node, context *compiler.Context) (*PathP
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "PathParameterSubSchema")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3787,11 +3767,7 @@ func NewPaths(in *yaml.Node, context *compiler.Context) (*Paths, error) {
 	} else {
 		allowedKeys := []string{}
 		allowedPatterns := []*regexp.Regexp{pattern0, pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated NamedAny vendor_extension = 1;
 		// MAP: Any ^x-
 		x.VendorExtension = make([]*NamedAny, 0)
@@ -3803,7 +3779,7 @@ func NewPaths(in *yaml.Node, context *compiler.Context) (*Paths, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Paths")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3857,15 +3833,11 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 	} else {
 		allowedKeys := []string{"collectionFormat", "default", "enum", "exclusiveMaximum", "exclusiveMinimum", "format", "items", "maxItems", "maxLength", "maximum", "minItems", "minLength", "minimum", "multipleOf", "pattern", "type", "uniqueItems"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3880,7 +3852,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// string format = 2;
 		v2 := compiler.MapValueForKey(m, "format")
 		if v2 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v2)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3898,7 +3870,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// string collection_format = 4;
 		v4 := compiler.MapValueForKey(m, "collectionFormat")
 		if v4 != nil {
-			x.CollectionFormat, ok = compiler.StringForScalarNode(v4)
+			x.CollectionFormat, ok = compiler.StringForScalarNodeInContext(context, "collectionFormat", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for collectionFormat: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3922,7 +3894,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// float maximum = 6;
 		v6 := compiler.MapValueForKey(m, "maximum")
 		if v6 != nil {
-			v, ok := compiler.FloatForScalarNode(v6)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v6)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -3933,7 +3905,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// bool exclusive_maximum = 7;
 		v7 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v7 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v7)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3942,7 +3914,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// float minimum = 8;
 		v8 := compiler.MapValueForKey(m, "minimum")
 		if v8 != nil {
-			v, ok := compiler.FloatForScalarNode(v8)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v8)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -3953,7 +3925,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// bool exclusive_minimum = 9;
 		v9 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v9 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v9)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3962,7 +3934,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// int64 max_length = 10;
 		v10 := compiler.MapValueForKey(m, "maxLength")
 		if v10 != nil {
-			t, ok := compiler.IntForScalarNode(v10)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v10)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -3973,7 +3945,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// int64 min_length = 11;
 		v11 := compiler.MapValueForKey(m, "minLength")
 		if v11 != nil {
-			t, ok := compiler.IntForScalarNode(v11)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v11)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -3984,7 +3956,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// string pattern = 12;
 		v12 := compiler.MapValueForKey(m, "pattern")
 		if v12 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v12)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3993,7 +3965,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// int64 max_items = 13;
 		v13 := compiler.MapValueForKey(m, "maxItems")
 		if v13 != nil {
-			t, ok := compiler.IntForScalarNode(v13)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v13)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -4004,7 +3976,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// int64 min_items = 14;
 		v14 := compiler.MapValueForKey(m, "minItems")
 		if v14 != nil {
-			t, ok := compiler.IntForScalarNode(v14)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v14)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -4015,7 +3987,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// bool unique_items = 15;
 		v15 := compiler.MapValueForKey(m, "uniqueItems")
 		if v15 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v15)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v15)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v15))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4040,7 +4012,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 		// float multiple_of = 17;
 		v17 := compiler.MapValueForKey(m, "multipleOf")
 		if v17 != nil {
-			v, ok := compiler.FloatForScalarNode(v17)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v17)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -4059,7 +4031,7 @@ func NewPrimitivesItems(in *yaml.Node, context *compiler.Context) (*PrimitivesIt
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "PrimitivesItems")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4124,15 +4096,11 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 	} else {
 		allowedKeys := []string{"allowEmptyValue", "collectionFormat", "default", "description", "enum", "exclusiveMaximum", "exclusiveMinimum", "format", "in", "items", "maxItems", "maxLength", "maximum", "minItems", "minLength", "minimum", "multipleOf", "name", "pattern", "required", "type", "uniqueItems"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// bool required = 1;
 		v1 := compiler.MapValueForKey(m, "required")
 		if v1 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v1)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4141,7 +4109,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// string in = 2;
 		v2 := compiler.MapValueForKey(m, "in")
 		if v2 != nil {
-			x.In, ok = compiler.StringForScalarNode(v2)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4156,7 +4124,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4165,7 +4133,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// string name = 4;
 		v4 := compiler.MapValueForKey(m, "name")
 		if v4 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v4)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4174,7 +4142,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// bool allow_empty_value = 5;
 		v5 := compiler.MapValueForKey(m, "allowEmptyValue")
 		if v5 != nil {
-			x.AllowEmptyValue, ok = compiler.BoolForScalarNode(v5)
+			x.AllowEmptyValue, ok = compiler.BoolForScalarNodeInContext(context, "allowEmptyValue", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for allowEmptyValue: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4183,7 +4151,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// string type = 6;
 		v6 := compiler.MapValueForKey(m, "type")
 		if v6 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v6)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4198,7 +4166,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// string format = 7;
 		v7 := compiler.MapValueForKey(m, "format")
 		if v7 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v7)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4216,7 +4184,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// string collection_format = 9;
 		v9 := compiler.MapValueForKey(m, "collectionFormat")
 		if v9 != nil {
-			x.CollectionFormat, ok = compiler.StringForScalarNode(v9)
+			x.CollectionFormat, ok = compiler.StringForScalarNodeInContext(context, "collectionFormat", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for collectionFormat: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4240,7 +4208,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// float maximum = 11;
 		v11 := compiler.MapValueForKey(m, "maximum")
 		if v11 != nil {
-			v, ok := compiler.FloatForScalarNode(v11)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v11)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -4251,7 +4219,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// bool exclusive_maximum = 12;
 		v12 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v12 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v12)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4260,7 +4228,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// float minimum = 13;
 		v13 := compiler.MapValueForKey(m, "minimum")
 		if v13 != nil {
-			v, ok := compiler.FloatForScalarNode(v13)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v13)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -4271,7 +4239,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// bool exclusive_minimum = 14;
 		v14 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v14 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v14)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v14)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v14))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4280,7 +4248,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// int64 max_length = 15;
 		v15 := compiler.MapValueForKey(m, "maxLength")
 		if v15 != nil {
-			t, ok := compiler.IntForScalarNode(v15)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v15)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -4291,7 +4259,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// int64 min_length = 16;
 		v16 := compiler.MapValueForKey(m, "minLength")
 		if v16 != nil {
-			t, ok := compiler.IntForScalarNode(v16)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v16)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -4302,7 +4270,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// string pattern = 17;
 		v17 := compiler.MapValueForKey(m, "pattern")
 		if v17 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v17)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v17)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v17))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4311,7 +4279,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// int64 max_items = 18;
 		v18 := compiler.MapValueForKey(m, "maxItems")
 		if v18 != nil {
-			t, ok := compiler.IntForScalarNode(v18)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v18)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -4322,7 +4290,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// int64 min_items = 19;
 		v19 := compiler.MapValueForKey(m, "minItems")
 		if v19 != nil {
-			t, ok := compiler.IntForScalarNode(v19)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v19)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -4333,7 +4301,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// bool unique_items = 20;
 		v20 := compiler.MapValueForKey(m, "uniqueItems")
 		if v20 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v20)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v20)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v20))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4358,7 +4326,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 		// float multiple_of = 22;
 		v22 := compiler.MapValueForKey(m, "multipleOf")
 		if v22 != nil {
-			v, ok := compiler.FloatForScalarNode(v22)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v22)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -4377,7 +4345,7 @@ func NewQueryParameterSubSchema(in *yaml.Node, context *compiler.Context) (*Quer
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "QueryParameterSubSchema")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4418,15 +4386,11 @@ func NewResponse(in *yaml.Node, context *compiler.Context) (*Response, error) {
 		}
 		allowedKeys := []string{"description", "examples", "headers", "schema"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4470,7 +4434,7 @@ func NewResponse(in *yaml.Node, context *compiler.Context) (*Response, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Response")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4529,6 +4493,7 @@ func NewResponseValue(in *yaml.Node, context *compiler.Context) (*ResponseValue,
 	errors := make([]error, 0)
 	x := &ResponseValue{}
 	matched := false
+	var partialMatch error
 	// Response response = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -4538,6 +4503,10 @@ func NewResponseValue(in *yaml.Node, context *compiler.Context) (*ResponseValue,
 			if matchingError == nil {
 				x.Oneof = &ResponseValue_Response{Response: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ResponseValue_Response{Response: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -4552,6 +4521,10 @@ func NewResponseValue(in *yaml.Node, context *compiler.Context) (*ResponseValue,
 			if matchingError == nil {
 				x.Oneof = &ResponseValue_JsonReference{JsonReference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ResponseValue_JsonReference{JsonReference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -4560,6 +4533,8 @@ func NewResponseValue(in *yaml.Node, context *compiler.Context) (*ResponseValue,
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid ResponseValue")
 		err := compiler.NewError(context, message)
@@ -4579,11 +4554,7 @@ func NewResponses(in *yaml.Node, context *compiler.Context) (*Responses, error)
 	} else {
 		allowedKeys := []string{}
 		allowedPatterns := []*regexp.Regexp{pattern2, pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated NamedResponseValue response_code = 1;
 		// MAP: ResponseValue ^([0-9]{3})$|^(default)$
 		x.ResponseCode = make([]*NamedResponseValue, 0)
@@ -4614,7 +4585,7 @@ func NewResponses(in *yaml.Node, context *compiler.Context) (*Responses, error)
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Responses")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4649,15 +4620,11 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 	} else {
 		allowedKeys := []string{"$ref", "additionalProperties", "allOf", "default", "description", "discriminator", "enum", "example", "exclusiveMaximum", "exclusiveMinimum", "externalDocs", "format", "items", "maxItems", "maxLength", "maxProperties", "maximum", "minItems", "minLength", "minProperties", "minimum", "multipleOf", "pattern", "properties", "readOnly", "required", "title", "type", "uniqueItems", "xml"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string _ref = 1;
 		v1 := compiler.MapValueForKey(m, "$ref")
 		if v1 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v1)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4666,7 +4633,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string format = 2;
 		v2 := compiler.MapValueForKey(m, "format")
 		if v2 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v2)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4675,7 +4642,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string title = 3;
 		v3 := compiler.MapValueForKey(m, "title")
 		if v3 != nil {
-			x.Title, ok = compiler.StringForScalarNode(v3)
+			x.Title, ok = compiler.StringForScalarNodeInContext(context, "title", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for title: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4684,7 +4651,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string description = 4;
 		v4 := compiler.MapValueForKey(m, "description")
 		if v4 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v4)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4702,7 +4669,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// float multiple_of = 6;
 		v6 := compiler.MapValueForKey(m, "multipleOf")
 		if v6 != nil {
-			v, ok := compiler.FloatForScalarNode(v6)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v6)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -4713,7 +4680,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// float maximum = 7;
 		v7 := compiler.MapValueForKey(m, "maximum")
 		if v7 != nil {
-			v, ok := compiler.FloatForScalarNode(v7)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v7)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -4724,7 +4691,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool exclusive_maximum = 8;
 		v8 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v8 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v8)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4733,7 +4700,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// float minimum = 9;
 		v9 := compiler.MapValueForKey(m, "minimum")
 		if v9 != nil {
-			v, ok := compiler.FloatForScalarNode(v9)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v9)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -4744,7 +4711,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool exclusive_minimum = 10;
 		v10 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v10 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v10)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v10)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v10))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4753,7 +4720,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 max_length = 11;
 		v11 := compiler.MapValueForKey(m, "maxLength")
 		if v11 != nil {
-			t, ok := compiler.IntForScalarNode(v11)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v11)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -4764,7 +4731,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 min_length = 12;
 		v12 := compiler.MapValueForKey(m, "minLength")
 		if v12 != nil {
-			t, ok := compiler.IntForScalarNode(v12)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v12)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -4775,7 +4742,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string pattern = 13;
 		v13 := compiler.MapValueForKey(m, "pattern")
 		if v13 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v13)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v13)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v13))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4784,7 +4751,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 max_items = 14;
 		v14 := compiler.MapValueForKey(m, "maxItems")
 		if v14 != nil {
-			t, ok := compiler.IntForScalarNode(v14)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v14)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -4795,7 +4762,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 min_items = 15;
 		v15 := compiler.MapValueForKey(m, "minItems")
 		if v15 != nil {
-			t, ok := compiler.IntForScalarNode(v15)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v15)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -4806,7 +4773,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool unique_items = 16;
 		v16 := compiler.MapValueForKey(m, "uniqueItems")
 		if v16 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v16)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v16)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v16))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4815,7 +4782,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 max_properties = 17;
 		v17 := compiler.MapValueForKey(m, "maxProperties")
 		if v17 != nil {
-			t, ok := compiler.IntForScalarNode(v17)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxProperties", v17)
 			if ok {
 				x.MaxProperties = int64(t)
 			} else {
@@ -4826,7 +4793,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 min_properties = 18;
 		v18 := compiler.MapValueForKey(m, "minProperties")
 		if v18 != nil {
-			t, ok := compiler.IntForScalarNode(v18)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minProperties", v18)
 			if ok {
 				x.MinProperties = int64(t)
 			} else {
@@ -4839,7 +4806,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		if v19 != nil {
 			v, ok := compiler.SequenceNodeForNode(v19)
 			if ok {
-				x.Required = compiler.StringArrayForSequenceNode(v)
+				x.Required = compiler.StringArrayForSequenceNodeInContext(context, "required", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v19))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4916,7 +4883,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string discriminator = 26;
 		v26 := compiler.MapValueForKey(m, "discriminator")
 		if v26 != nil {
-			x.Discriminator, ok = compiler.StringForScalarNode(v26)
+			x.Discriminator, ok = compiler.StringForScalarNodeInContext(context, "discriminator", v26)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for discriminator: %s", compiler.Display(v26))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4925,7 +4892,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool read_only = 27;
 		v27 := compiler.MapValueForKey(m, "readOnly")
 		if v27 != nil {
-			x.ReadOnly, ok = compiler.BoolForScalarNode(v27)
+			x.ReadOnly, ok = compiler.BoolForScalarNodeInContext(context, "readOnly", v27)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for readOnly: %s", compiler.Display(v27))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4969,7 +4936,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Schema")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4998,6 +4965,7 @@ func NewSchemaItem(in *yaml.Node, context *compiler.Context) (*SchemaItem, error
 	errors := make([]error, 0)
 	x := &SchemaItem{}
 	matched := false
+	var partialMatch error
 	// Schema schema = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -5007,6 +4975,10 @@ func NewSchemaItem(in *yaml.Node, context *compiler.Context) (*SchemaItem, error
 			if matchingError == nil {
 				x.Oneof = &SchemaItem_Schema{Schema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SchemaItem_Schema{Schema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5021,6 +4993,10 @@ func NewSchemaItem(in *yaml.Node, context *compiler.Context) (*SchemaItem, error
 			if matchingError == nil {
 				x.Oneof = &SchemaItem_FileSchema{FileSchema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SchemaItem_FileSchema{FileSchema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5029,6 +5005,8 @@ func NewSchemaItem(in *yaml.Node, context *compiler.Context) (*SchemaItem, error
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid SchemaItem")
 		err := compiler.NewError(context, message)
@@ -5072,6 +5050,7 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 	errors := make([]error, 0)
 	x := &SecurityDefinitionsItem{}
 	matched := false
+	var partialMatch error
 	// BasicAuthenticationSecurity basic_authentication_security = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -5081,6 +5060,10 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 			if matchingError == nil {
 				x.Oneof = &SecurityDefinitionsItem_BasicAuthenticationSecurity{BasicAuthenticationSecurity: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecurityDefinitionsItem_BasicAuthenticationSecurity{BasicAuthenticationSecurity: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5095,6 +5078,10 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 			if matchingError == nil {
 				x.Oneof = &SecurityDefinitionsItem_ApiKeySecurity{ApiKeySecurity: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecurityDefinitionsItem_ApiKeySecurity{ApiKeySecurity: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5109,6 +5096,10 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 			if matchingError == nil {
 				x.Oneof = &SecurityDefinitionsItem_Oauth2ImplicitSecurity{Oauth2ImplicitSecurity: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecurityDefinitionsItem_Oauth2ImplicitSecurity{Oauth2ImplicitSecurity: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5123,6 +5114,10 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 			if matchingError == nil {
 				x.Oneof = &SecurityDefinitionsItem_Oauth2PasswordSecurity{Oauth2PasswordSecurity: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecurityDefinitionsItem_Oauth2PasswordSecurity{Oauth2PasswordSecurity: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5137,6 +5132,10 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 			if matchingError == nil {
 				x.Oneof = &SecurityDefinitionsItem_Oauth2ApplicationSecurity{Oauth2ApplicationSecurity: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecurityDefinitionsItem_Oauth2ApplicationSecurity{Oauth2ApplicationSecurity: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5151,6 +5150,10 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 			if matchingError == nil {
 				x.Oneof = &SecurityDefinitionsItem_Oauth2AccessCodeSecurity{Oauth2AccessCodeSecurity: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecurityDefinitionsItem_Oauth2AccessCodeSecurity{Oauth2AccessCodeSecurity: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -5159,6 +5162,8 @@ func NewSecurityDefinitionsItem(in *yaml.Node, context *compiler.Context) (*Secu
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid SecurityDefinitionsItem")
 		err := compiler.NewError(context, message)
@@ -5226,15 +5231,11 @@ func NewTag(in *yaml.Node, context *compiler.Context) (*Tag, error) {
 		}
 		allowedKeys := []string{"description", "externalDocs", "name"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5243,7 +5244,7 @@ func NewTag(in *yaml.Node, context *compiler.Context) (*Tag, error) {
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5269,7 +5270,7 @@ func NewTag(in *yaml.Node, context *compiler.Context) (*Tag, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Tag")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -5340,7 +5341,7 @@ func NewVendorExtension(in *yaml.Node, context *compiler.Context) (*VendorExtens
 				pair := &NamedAny{}
 				pair.Name = k
 				result := &Any{}
-				handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+				handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "VendorExtension")
 				if handled {
 					if err != nil {
 						errors = append(errors, err)
@@ -5374,15 +5375,11 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 	} else {
 		allowedKeys := []string{"attribute", "name", "namespace", "prefix", "wrapped"}
 		allowedPatterns := []*regexp.Regexp{pattern0}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5391,7 +5388,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// string namespace = 2;
 		v2 := compiler.MapValueForKey(m, "namespace")
 		if v2 != nil {
-			x.Namespace, ok = compiler.StringForScalarNode(v2)
+			x.Namespace, ok = compiler.StringForScalarNodeInContext(context, "namespace", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for namespace: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5400,7 +5397,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// string prefix = 3;
 		v3 := compiler.MapValueForKey(m, "prefix")
 		if v3 != nil {
-			x.Prefix, ok = compiler.StringForScalarNode(v3)
+			x.Prefix, ok = compiler.StringForScalarNodeInContext(context, "prefix", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for prefix: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5409,7 +5406,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// bool attribute = 4;
 		v4 := compiler.MapValueForKey(m, "attribute")
 		if v4 != nil {
-			x.Attribute, ok = compiler.BoolForScalarNode(v4)
+			x.Attribute, ok = compiler.BoolForScalarNodeInContext(context, "attribute", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for attribute: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5418,7 +5415,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// bool wrapped = 5;
 		v5 := compiler.MapValueForKey(m, "wrapped")
 		if v5 != nil {
-			x.Wrapped, ok = compiler.BoolForScalarNode(v5)
+			x.Wrapped, ok = compiler.BoolForScalarNodeInContext(context, "wrapped", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for wrapped: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5435,7 +5432,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Xml")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -15,22 +15,76 @@
 package openapi_v2
 
 import (
+	"context"
 	"errors"
+	"io"
 
 	"github.com/google/gnostic/compiler"
+	"go.yaml.in/yaml/v3"
 )
 
 // ParseDocument reads an OpenAPI v2 description from a YAML/JSON representation.
 func ParseDocument(b []byte) (*Document, error) {
-	info, err := compiler.ReadInfoFromBytes("", b)
+	return ParseDocumentContext(context.Background(), b)
+}
+
+// ParseDocumentContext is like ParseDocument, but it stops with ctx.Err() when ctx is done.
+func ParseDocumentContext(ctx context.Context, b []byte) (*Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	info, err := compiler.ReadInfoFromBytesContext(ctx, "", b)
+	if err != nil {
+		return nil, err
+	}
+	return parseInfo(ctx, info)
+}
+
+// ParseDocumentFromReader reads an OpenAPI v2 description from a YAML/JSON representation,
+// parsing it as it is read from r.
+func ParseDocumentFromReader(r io.Reader) (*Document, error) {
+	return ParseDocumentFromReaderContext(context.Background(), r)
+}
+
+// ParseDocumentFromReaderContext is like ParseDocumentFromReader, but it stops with ctx.Err() when ctx is done.
+func ParseDocumentFromReaderContext(ctx context.Context, r io.Reader) (*Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	info, err := compiler.ReadInfoFromReaderContext(ctx, "", r)
 	if err != nil {
 		return nil, err
 	}
+	return parseInfo(ctx, info)
+}
 
+func parseInfo(ctx context.Context, info *yaml.Node) (*Document, error) {
 	if len(info.Content) < 1 {
 		return nil, errors.New("document has no content")
 	}
 
 	root := info.Content[0]
-	return NewDocument(root, compiler.NewContextWithExtensions("$root", root, nil, nil))
+	rootContext := compiler.NewContextWithCancellation(ctx, "$root", root, nil, nil, nil)
+	defer compiler.RemoveExtensionConfig(rootContext)
+	document, err := NewDocument(root, rootContext)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return document, err
+}
+
+// YAMLOptions control the YAML representation of documents that is returned by RenderYAML.
+// The keys of mappings are written in the order of the specification unless KeyOrder is
+// compiler.KeyOrderSorted.
+type YAMLOptions = compiler.YAMLOptions
+
+// RenderYAML returns the YAML representation of a document. With the zero YAMLOptions, it is
+// the YAML that gnostic writes with --yaml-out.
+func RenderYAML(document *Document, options YAMLOptions) ([]byte, error) {
+	return compiler.RenderYAML(document.ToRawInfo(), options)
+}
+
+// EncodeYAML writes the YAML representation of a document to w like RenderYAML.
+func EncodeYAML(w io.Writer, document *Document, options YAMLOptions) error {
+	return compiler.EncodeYAML(w, document.ToRawInfo(), options)
 }
@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v2
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultTitle is the title that FillDefaults gives documents without one.
+const DefaultTitle = "Untitled API"
+
+// FilledDefault describes a value that FillDefaults added to a document.
+type FilledDefault struct {
+	// Keys is the key path of the value within the document.
+	Keys []string
+	// Value is the value that was added.
+	Value string
+}
+
+// String describes the value and its location.
+func (f *FilledDefault) String() string {
+	return fmt.Sprintf("%s: %q", strings.Join(f.Keys, "."), f.Value)
+}
+
+// methods are the HTTP methods of path items in their canonical order.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+type methodOperation struct {
+	method string
+	value  *Operation
+}
+
+// pathItemOperations returns the operations of a path item in the order of methods.
+func pathItemOperations(pathItem *PathItem) []methodOperation {
+	operations := make([]methodOperation, 0)
+	if pathItem == nil {
+		return operations
+	}
+	for _, operation := range []methodOperation{
+		{"get", pathItem.Get},
+		{"put", pathItem.Put},
+		{"post", pathItem.Post},
+		{"delete", pathItem.Delete},
+		{"options", pathItem.Options},
+		{"head", pathItem.Head},
+		{"patch", pathItem.Patch},
+	} {
+		if operation.value != nil {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// FillDefaults adds values that code generators need and that many descriptions omit.
+// Operations without an operationId get one made from their method and path, like
+// getPetsPetId, that is unique in the document. Responses with empty descriptions,
+// which are required, get the text of their status code, and an empty title becomes
+// DefaultTitle. It returns the values that it added, in document order.
+func FillDefaults(document *Document) []*FilledDefault {
+	filled := make([]*FilledDefault, 0)
+	if document.Info == nil {
+		document.Info = &Info{}
+	}
+	if document.Info.Title == "" {
+		document.Info.Title = DefaultTitle
+		filled = append(filled, &FilledDefault{Keys: []string{"info", "title"}, Value: DefaultTitle})
+	}
+	// Existing operationIds are kept, so they are reserved before any are made.
+	operationIDs := make(map[string]bool)
+	for _, namedPathItem := range document.GetPaths().GetPath() {
+		for _, operation := range pathItemOperations(namedPathItem.Value) {
+			operationIDs[operation.value.OperationId] = true
+		}
+	}
+	for _, namedPathItem := range document.GetPaths().GetPath() {
+		for _, operation := range pathItemOperations(namedPathItem.Value) {
+			keys := []string{"paths", namedPathItem.Name, operation.method}
+			if operation.value.OperationId == "" {
+				operationID := uniqueOperationID(operationIDForPath(operation.method, namedPathItem.Name), operationIDs)
+				operation.value.OperationId = operationID
+				filled = append(filled, &FilledDefault{Keys: append(keys, "operationId"), Value: operationID})
+			}
+			for _, namedResponse := range operation.value.GetResponses().GetResponseCode() {
+				filled = fillResponseDescription(namedResponse.GetValue().GetResponse(), append(keys, "responses", namedResponse.Name), filled)
+			}
+		}
+	}
+	for _, namedResponse := range document.GetResponses().GetAdditionalProperties() {
+		filled = fillResponseDescription(namedResponse.Value, []string{"responses", namedResponse.Name}, filled)
+	}
+	return filled
+}
+
+func fillResponseDescription(response *Response, keys []string, filled []*FilledDefault) []*FilledDefault {
+	if response != nil && response.Description == "" {
+		response.Description = responseDescription(keys[len(keys)-1])
+		filled = append(filled, &FilledDefault{Keys: append(append([]string{}, keys...), "description"), Value: response.Description})
+	}
+	return filled
+}
+
+// responseDescription returns a description for a response with a status code like 404.
+func responseDescription(code string) string {
+	if status, err := strconv.Atoi(code); err == nil && http.StatusText(status) != "" {
+		return http.StatusText(status)
+	}
+	if code == "default" {
+		return "Default response"
+	}
+	return "Response"
+}
+
+var operationIDWordRegex = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+// operationIDForPath returns an operationId for an operation, like getPetsPetId
+// for GET /pets/{petId}.
+func operationIDForPath(method string, path string) string {
+	operationID := strings.ToLower(method)
+	for _, word := range operationIDWordRegex.FindAllString(path, -1) {
+		operationID += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return operationID
+}
+
+// uniqueOperationID returns operationID, or if it is already used, operationID followed
+// by the first number from 2 that makes it unique, and reserves it.
+func uniqueOperationID(operationID string, used map[string]bool) string {
+	unique := operationID
+	for i := 2; used[unique]; i++ {
+		unique = operationID + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// FillMissingKeys adds empty values for the info title and response descriptions that a
+// parsed document omits, which are otherwise errors, so that it compiles and FillDefaults
+// can fill them. Call it before compiling the document.
+func FillMissingKeys(info *yaml.Node) {
+	if info.Kind == yaml.DocumentNode && len(info.Content) > 0 {
+		info = info.Content[0]
+	}
+	if info.Kind != yaml.MappingNode {
+		return
+	}
+	addMissingKey(mapValue(info, "info"), "title")
+	paths := mapValue(info, "paths")
+	if paths != nil && paths.Kind == yaml.MappingNode {
+		for i := 1; i < len(paths.Content); i += 2 {
+			for _, method := range methods {
+				addMissingDescriptions(mapValue(mapValue(paths.Content[i], method), "responses"))
+			}
+		}
+	}
+	addMissingDescriptions(mapValue(info, "responses"))
+}
+
+// addMissingDescriptions adds empty descriptions to the responses in a map that have none.
+func addMissingDescriptions(responses *yaml.Node) {
+	if responses == nil || responses.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(responses.Content); i += 2 {
+		if response := responses.Content[i+1]; !strings.HasPrefix(responses.Content[i].Value, "x-") && mapValue(response, "$ref") == nil {
+			addMissingKey(response, "description")
+		}
+	}
+}
+
+// addMissingKey adds an empty string value for a key to a map that doesn't have it.
+func addMissingKey(m *yaml.Node, key string) {
+	if m == nil || m.Kind != yaml.MappingNode || mapValue(m, key) != nil {
+		return
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""})
+}
+
+func mapValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
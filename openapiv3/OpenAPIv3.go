@@ -36,6 +36,7 @@ func NewAdditionalPropertiesItem(in *yaml.Node, context *compiler.Context) (*Add
 	errors := make([]error, 0)
 	x := &AdditionalPropertiesItem{}
 	matched := false
+	var partialMatch error
 	// SchemaOrReference schema_or_reference = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -45,13 +46,17 @@ func NewAdditionalPropertiesItem(in *yaml.Node, context *compiler.Context) (*Add
 			if matchingError == nil {
 				x.Oneof = &AdditionalPropertiesItem_SchemaOrReference{SchemaOrReference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &AdditionalPropertiesItem_SchemaOrReference{SchemaOrReference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
 		}
 	}
 	// bool boolean = 2;
-	boolValue, ok := compiler.BoolForScalarNode(in)
+	boolValue, ok := compiler.BoolForScalarNodeInContext(context, "", in)
 	if ok {
 		x.Oneof = &AdditionalPropertiesItem_Boolean{Boolean: boolValue}
 		matched = true
@@ -59,6 +64,8 @@ func NewAdditionalPropertiesItem(in *yaml.Node, context *compiler.Context) (*Add
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid AdditionalPropertiesItem"
 		err := compiler.NewError(context, message)
@@ -81,6 +88,7 @@ func NewAnyOrExpression(in *yaml.Node, context *compiler.Context) (*AnyOrExpress
 	errors := make([]error, 0)
 	x := &AnyOrExpression{}
 	matched := false
+	var partialMatch error
 	// Any any = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -90,6 +98,10 @@ func NewAnyOrExpression(in *yaml.Node, context *compiler.Context) (*AnyOrExpress
 			if matchingError == nil {
 				x.Oneof = &AnyOrExpression_Any{Any: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &AnyOrExpression_Any{Any: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -104,6 +116,10 @@ func NewAnyOrExpression(in *yaml.Node, context *compiler.Context) (*AnyOrExpress
 			if matchingError == nil {
 				x.Oneof = &AnyOrExpression_Expression{Expression: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &AnyOrExpression_Expression{Expression: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -112,6 +128,8 @@ func NewAnyOrExpression(in *yaml.Node, context *compiler.Context) (*AnyOrExpress
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid AnyOrExpression"
 		err := compiler.NewError(context, message)
@@ -131,11 +149,7 @@ func NewCallback(in *yaml.Node, context *compiler.Context) (*Callback, error) {
 	} else {
 		allowedKeys := []string{}
 		allowedPatterns := []*regexp.Regexp{pattern0, pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated NamedPathItem path = 1;
 		// MAP: PathItem ^
 		x.Path = make([]*NamedPathItem, 0)
@@ -166,7 +180,7 @@ func NewCallback(in *yaml.Node, context *compiler.Context) (*Callback, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Callback")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -195,6 +209,7 @@ func NewCallbackOrReference(in *yaml.Node, context *compiler.Context) (*Callback
 	errors := make([]error, 0)
 	x := &CallbackOrReference{}
 	matched := false
+	var partialMatch error
 	// Callback callback = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -204,6 +219,10 @@ func NewCallbackOrReference(in *yaml.Node, context *compiler.Context) (*Callback
 			if matchingError == nil {
 				x.Oneof = &CallbackOrReference_Callback{Callback: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &CallbackOrReference_Callback{Callback: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -218,6 +237,10 @@ func NewCallbackOrReference(in *yaml.Node, context *compiler.Context) (*Callback
 			if matchingError == nil {
 				x.Oneof = &CallbackOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &CallbackOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -226,6 +249,8 @@ func NewCallbackOrReference(in *yaml.Node, context *compiler.Context) (*Callback
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid CallbackOrReference")
 		err := compiler.NewError(context, message)
@@ -275,11 +300,7 @@ func NewComponents(in *yaml.Node, context *compiler.Context) (*Components, error
 	} else {
 		allowedKeys := []string{"callbacks", "examples", "headers", "links", "parameters", "requestBodies", "responses", "schemas", "securitySchemes"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// SchemasOrReferences schemas = 1;
 		v1 := compiler.MapValueForKey(m, "schemas")
 		if v1 != nil {
@@ -372,7 +393,7 @@ func NewComponents(in *yaml.Node, context *compiler.Context) (*Components, error
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Components")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -407,15 +428,11 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 	} else {
 		allowedKeys := []string{"email", "name", "url"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -424,7 +441,7 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 		// string url = 2;
 		v2 := compiler.MapValueForKey(m, "url")
 		if v2 != nil {
-			x.Url, ok = compiler.StringForScalarNode(v2)
+			x.Url, ok = compiler.StringForScalarNodeInContext(context, "url", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for url: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -433,7 +450,7 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 		// string email = 3;
 		v3 := compiler.MapValueForKey(m, "email")
 		if v3 != nil {
-			x.Email, ok = compiler.StringForScalarNode(v3)
+			x.Email, ok = compiler.StringForScalarNodeInContext(context, "email", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for email: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -450,7 +467,7 @@ func NewContact(in *yaml.Node, context *compiler.Context) (*Contact, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Contact")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -521,15 +538,11 @@ func NewDiscriminator(in *yaml.Node, context *compiler.Context) (*Discriminator,
 		}
 		allowedKeys := []string{"mapping", "propertyName"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string property_name = 1;
 		v1 := compiler.MapValueForKey(m, "propertyName")
 		if v1 != nil {
-			x.PropertyName, ok = compiler.StringForScalarNode(v1)
+			x.PropertyName, ok = compiler.StringForScalarNodeInContext(context, "propertyName", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for propertyName: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -555,7 +568,7 @@ func NewDiscriminator(in *yaml.Node, context *compiler.Context) (*Discriminator,
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Discriminator")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -596,21 +609,23 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 		}
 		allowedKeys := []string{"components", "externalDocs", "info", "openapi", "paths", "security", "servers", "tags"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string openapi = 1;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v1 := compiler.MapValueForKey(m, "openapi")
 		if v1 != nil {
-			x.Openapi, ok = compiler.StringForScalarNode(v1)
+			x.Openapi, ok = compiler.StringForScalarNodeInContext(context, "openapi", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for openapi: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
 			}
 		}
 		// Info info = 2;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v2 := compiler.MapValueForKey(m, "info")
 		if v2 != nil {
 			var err error
@@ -620,6 +635,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated Server servers = 3;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v3 := compiler.MapValueForKey(m, "servers")
 		if v3 != nil {
 			// repeated Server
@@ -636,6 +654,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Paths paths = 4;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v4 := compiler.MapValueForKey(m, "paths")
 		if v4 != nil {
 			var err error
@@ -645,6 +666,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// Components components = 5;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v5 := compiler.MapValueForKey(m, "components")
 		if v5 != nil {
 			var err error
@@ -654,6 +678,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated SecurityRequirement security = 6;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v6 := compiler.MapValueForKey(m, "security")
 		if v6 != nil {
 			// repeated SecurityRequirement
@@ -670,6 +697,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated Tag tags = 7;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v7 := compiler.MapValueForKey(m, "tags")
 		if v7 != nil {
 			// repeated Tag
@@ -686,6 +716,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// ExternalDocs external_docs = 8;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		v8 := compiler.MapValueForKey(m, "externalDocs")
 		if v8 != nil {
 			var err error
@@ -695,6 +728,9 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 			}
 		}
 		// repeated NamedAny specification_extension = 9;
+		if err := compiler.CancellationError(context); err != nil {
+			return nil, err
+		}
 		// MAP: Any ^x-
 		x.SpecificationExtension = make([]*NamedAny, 0)
 		for i := 0; i < len(m.Content); i += 2 {
@@ -705,7 +741,7 @@ func NewDocument(in *yaml.Node, context *compiler.Context) (*Document, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Document")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -740,15 +776,11 @@ func NewEncoding(in *yaml.Node, context *compiler.Context) (*Encoding, error) {
 	} else {
 		allowedKeys := []string{"allowReserved", "contentType", "explode", "headers", "style"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string content_type = 1;
 		v1 := compiler.MapValueForKey(m, "contentType")
 		if v1 != nil {
-			x.ContentType, ok = compiler.StringForScalarNode(v1)
+			x.ContentType, ok = compiler.StringForScalarNodeInContext(context, "contentType", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for contentType: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -766,7 +798,7 @@ func NewEncoding(in *yaml.Node, context *compiler.Context) (*Encoding, error) {
 		// string style = 3;
 		v3 := compiler.MapValueForKey(m, "style")
 		if v3 != nil {
-			x.Style, ok = compiler.StringForScalarNode(v3)
+			x.Style, ok = compiler.StringForScalarNodeInContext(context, "style", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for style: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -775,7 +807,7 @@ func NewEncoding(in *yaml.Node, context *compiler.Context) (*Encoding, error) {
 		// bool explode = 4;
 		v4 := compiler.MapValueForKey(m, "explode")
 		if v4 != nil {
-			x.Explode, ok = compiler.BoolForScalarNode(v4)
+			x.Explode, ok = compiler.BoolForScalarNodeInContext(context, "explode", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for explode: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -784,7 +816,7 @@ func NewEncoding(in *yaml.Node, context *compiler.Context) (*Encoding, error) {
 		// bool allow_reserved = 5;
 		v5 := compiler.MapValueForKey(m, "allowReserved")
 		if v5 != nil {
-			x.AllowReserved, ok = compiler.BoolForScalarNode(v5)
+			x.AllowReserved, ok = compiler.BoolForScalarNodeInContext(context, "allowReserved", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for allowReserved: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -801,7 +833,7 @@ func NewEncoding(in *yaml.Node, context *compiler.Context) (*Encoding, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Encoding")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -866,15 +898,11 @@ func NewExample(in *yaml.Node, context *compiler.Context) (*Example, error) {
 	} else {
 		allowedKeys := []string{"description", "externalValue", "summary", "value"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string summary = 1;
 		v1 := compiler.MapValueForKey(m, "summary")
 		if v1 != nil {
-			x.Summary, ok = compiler.StringForScalarNode(v1)
+			x.Summary, ok = compiler.StringForScalarNodeInContext(context, "summary", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for summary: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -883,7 +911,7 @@ func NewExample(in *yaml.Node, context *compiler.Context) (*Example, error) {
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -901,7 +929,7 @@ func NewExample(in *yaml.Node, context *compiler.Context) (*Example, error) {
 		// string external_value = 4;
 		v4 := compiler.MapValueForKey(m, "externalValue")
 		if v4 != nil {
-			x.ExternalValue, ok = compiler.StringForScalarNode(v4)
+			x.ExternalValue, ok = compiler.StringForScalarNodeInContext(context, "externalValue", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for externalValue: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -918,7 +946,7 @@ func NewExample(in *yaml.Node, context *compiler.Context) (*Example, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Example")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -947,6 +975,7 @@ func NewExampleOrReference(in *yaml.Node, context *compiler.Context) (*ExampleOr
 	errors := make([]error, 0)
 	x := &ExampleOrReference{}
 	matched := false
+	var partialMatch error
 	// Example example = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -956,6 +985,10 @@ func NewExampleOrReference(in *yaml.Node, context *compiler.Context) (*ExampleOr
 			if matchingError == nil {
 				x.Oneof = &ExampleOrReference_Example{Example: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ExampleOrReference_Example{Example: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -970,6 +1003,10 @@ func NewExampleOrReference(in *yaml.Node, context *compiler.Context) (*ExampleOr
 			if matchingError == nil {
 				x.Oneof = &ExampleOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ExampleOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -978,6 +1015,8 @@ func NewExampleOrReference(in *yaml.Node, context *compiler.Context) (*ExampleOr
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid ExampleOrReference")
 		err := compiler.NewError(context, message)
@@ -1035,7 +1074,7 @@ func NewExpression(in *yaml.Node, context *compiler.Context) (*Expression, error
 				pair := &NamedAny{}
 				pair.Name = k
 				result := &Any{}
-				handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+				handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Expression")
 				if handled {
 					if err != nil {
 						errors = append(errors, err)
@@ -1075,15 +1114,11 @@ func NewExternalDocs(in *yaml.Node, context *compiler.Context) (*ExternalDocs, e
 		}
 		allowedKeys := []string{"description", "url"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1092,7 +1127,7 @@ func NewExternalDocs(in *yaml.Node, context *compiler.Context) (*ExternalDocs, e
 		// string url = 2;
 		v2 := compiler.MapValueForKey(m, "url")
 		if v2 != nil {
-			x.Url, ok = compiler.StringForScalarNode(v2)
+			x.Url, ok = compiler.StringForScalarNodeInContext(context, "url", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for url: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1109,7 +1144,7 @@ func NewExternalDocs(in *yaml.Node, context *compiler.Context) (*ExternalDocs, e
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "ExternalDocs")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1144,15 +1179,11 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 	} else {
 		allowedKeys := []string{"allowEmptyValue", "allowReserved", "content", "deprecated", "description", "example", "examples", "explode", "required", "schema", "style"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1161,7 +1192,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool required = 2;
 		v2 := compiler.MapValueForKey(m, "required")
 		if v2 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v2)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1170,7 +1201,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool deprecated = 3;
 		v3 := compiler.MapValueForKey(m, "deprecated")
 		if v3 != nil {
-			x.Deprecated, ok = compiler.BoolForScalarNode(v3)
+			x.Deprecated, ok = compiler.BoolForScalarNodeInContext(context, "deprecated", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for deprecated: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1179,7 +1210,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool allow_empty_value = 4;
 		v4 := compiler.MapValueForKey(m, "allowEmptyValue")
 		if v4 != nil {
-			x.AllowEmptyValue, ok = compiler.BoolForScalarNode(v4)
+			x.AllowEmptyValue, ok = compiler.BoolForScalarNodeInContext(context, "allowEmptyValue", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for allowEmptyValue: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1188,7 +1219,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// string style = 5;
 		v5 := compiler.MapValueForKey(m, "style")
 		if v5 != nil {
-			x.Style, ok = compiler.StringForScalarNode(v5)
+			x.Style, ok = compiler.StringForScalarNodeInContext(context, "style", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for style: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1197,7 +1228,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool explode = 6;
 		v6 := compiler.MapValueForKey(m, "explode")
 		if v6 != nil {
-			x.Explode, ok = compiler.BoolForScalarNode(v6)
+			x.Explode, ok = compiler.BoolForScalarNodeInContext(context, "explode", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for explode: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1206,7 +1237,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 		// bool allow_reserved = 7;
 		v7 := compiler.MapValueForKey(m, "allowReserved")
 		if v7 != nil {
-			x.AllowReserved, ok = compiler.BoolForScalarNode(v7)
+			x.AllowReserved, ok = compiler.BoolForScalarNodeInContext(context, "allowReserved", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for allowReserved: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1259,7 +1290,7 @@ func NewHeader(in *yaml.Node, context *compiler.Context) (*Header, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Header")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1288,6 +1319,7 @@ func NewHeaderOrReference(in *yaml.Node, context *compiler.Context) (*HeaderOrRe
 	errors := make([]error, 0)
 	x := &HeaderOrReference{}
 	matched := false
+	var partialMatch error
 	// Header header = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -1297,6 +1329,10 @@ func NewHeaderOrReference(in *yaml.Node, context *compiler.Context) (*HeaderOrRe
 			if matchingError == nil {
 				x.Oneof = &HeaderOrReference_Header{Header: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &HeaderOrReference_Header{Header: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -1311,6 +1347,10 @@ func NewHeaderOrReference(in *yaml.Node, context *compiler.Context) (*HeaderOrRe
 			if matchingError == nil {
 				x.Oneof = &HeaderOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &HeaderOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -1319,6 +1359,8 @@ func NewHeaderOrReference(in *yaml.Node, context *compiler.Context) (*HeaderOrRe
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := fmt.Sprintf("contains an invalid HeaderOrReference")
 		err := compiler.NewError(context, message)
@@ -1374,15 +1416,11 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		}
 		allowedKeys := []string{"contact", "description", "license", "summary", "termsOfService", "title", "version"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string title = 1;
 		v1 := compiler.MapValueForKey(m, "title")
 		if v1 != nil {
-			x.Title, ok = compiler.StringForScalarNode(v1)
+			x.Title, ok = compiler.StringForScalarNodeInContext(context, "title", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for title: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1391,7 +1429,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1400,7 +1438,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		// string terms_of_service = 3;
 		v3 := compiler.MapValueForKey(m, "termsOfService")
 		if v3 != nil {
-			x.TermsOfService, ok = compiler.StringForScalarNode(v3)
+			x.TermsOfService, ok = compiler.StringForScalarNodeInContext(context, "termsOfService", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for termsOfService: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1427,7 +1465,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		// string version = 6;
 		v6 := compiler.MapValueForKey(m, "version")
 		if v6 != nil {
-			x.Version, ok = compiler.StringForScalarNode(v6)
+			x.Version, ok = compiler.StringForScalarNodeInContext(context, "version", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for version: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1436,7 +1474,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 		// string summary = 7;
 		v7 := compiler.MapValueForKey(m, "summary")
 		if v7 != nil {
-			x.Summary, ok = compiler.StringForScalarNode(v7)
+			x.Summary, ok = compiler.StringForScalarNodeInContext(context, "summary", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for summary: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1453,7 +1491,7 @@ func NewInfo(in *yaml.Node, context *compiler.Context) (*Info, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Info")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1513,15 +1551,11 @@ func NewLicense(in *yaml.Node, context *compiler.Context) (*License, error) {
 		}
 		allowedKeys := []string{"name", "url"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1530,7 +1564,7 @@ func NewLicense(in *yaml.Node, context *compiler.Context) (*License, error) {
 		// string url = 2;
 		v2 := compiler.MapValueForKey(m, "url")
 		if v2 != nil {
-			x.Url, ok = compiler.StringForScalarNode(v2)
+			x.Url, ok = compiler.StringForScalarNodeInContext(context, "url", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for url: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1547,7 +1581,7 @@ func NewLicense(in *yaml.Node, context *compiler.Context) (*License, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "License")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1582,15 +1616,11 @@ func NewLink(in *yaml.Node, context *compiler.Context) (*Link, error) {
 	} else {
 		allowedKeys := []string{"description", "operationId", "operationRef", "parameters", "requestBody", "server"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string operation_ref = 1;
 		v1 := compiler.MapValueForKey(m, "operationRef")
 		if v1 != nil {
-			x.OperationRef, ok = compiler.StringForScalarNode(v1)
+			x.OperationRef, ok = compiler.StringForScalarNodeInContext(context, "operationRef", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for operationRef: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1599,7 +1629,7 @@ func NewLink(in *yaml.Node, context *compiler.Context) (*Link, error) {
 		// string operation_id = 2;
 		v2 := compiler.MapValueForKey(m, "operationId")
 		if v2 != nil {
-			x.OperationId, ok = compiler.StringForScalarNode(v2)
+			x.OperationId, ok = compiler.StringForScalarNodeInContext(context, "operationId", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for operationId: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1626,7 +1656,7 @@ func NewLink(in *yaml.Node, context *compiler.Context) (*Link, error) {
 		// string description = 5;
 		v5 := compiler.MapValueForKey(m, "description")
 		if v5 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v5)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1652,7 +1682,7 @@ func NewLink(in *yaml.Node, context *compiler.Context) (*Link, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Link")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1681,6 +1711,7 @@ func NewLinkOrReference(in *yaml.Node, context *compiler.Context) (*LinkOrRefere
 	errors := make([]error, 0)
 	x := &LinkOrReference{}
 	matched := false
+	var partialMatch error
 	// Link link = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -1690,6 +1721,10 @@ func NewLinkOrReference(in *yaml.Node, context *compiler.Context) (*LinkOrRefere
 			if matchingError == nil {
 				x.Oneof = &LinkOrReference_Link{Link: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &LinkOrReference_Link{Link: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -1704,6 +1739,10 @@ func NewLinkOrReference(in *yaml.Node, context *compiler.Context) (*LinkOrRefere
 			if matchingError == nil {
 				x.Oneof = &LinkOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &LinkOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -1712,6 +1751,8 @@ func NewLinkOrReference(in *yaml.Node, context *compiler.Context) (*LinkOrRefere
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid LinkOrReference"
 		err := compiler.NewError(context, message)
@@ -1761,11 +1802,7 @@ func NewMediaType(in *yaml.Node, context *compiler.Context) (*MediaType, error)
 	} else {
 		allowedKeys := []string{"encoding", "example", "examples", "schema"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// SchemaOrReference schema = 1;
 		v1 := compiler.MapValueForKey(m, "schema")
 		if v1 != nil {
@@ -1813,7 +1850,7 @@ func NewMediaType(in *yaml.Node, context *compiler.Context) (*MediaType, error)
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "MediaType")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -1878,15 +1915,11 @@ func NewNamedAny(in *yaml.Node, context *compiler.Context) (*NamedAny, error) {
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1916,15 +1949,11 @@ func NewNamedCallbackOrReference(in *yaml.Node, context *compiler.Context) (*Nam
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1954,15 +1983,11 @@ func NewNamedEncoding(in *yaml.Node, context *compiler.Context) (*NamedEncoding,
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -1992,15 +2017,11 @@ func NewNamedExampleOrReference(in *yaml.Node, context *compiler.Context) (*Name
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2030,15 +2051,11 @@ func NewNamedHeaderOrReference(in *yaml.Node, context *compiler.Context) (*Named
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2068,15 +2085,11 @@ func NewNamedLinkOrReference(in *yaml.Node, context *compiler.Context) (*NamedLi
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2106,15 +2119,11 @@ func NewNamedMediaType(in *yaml.Node, context *compiler.Context) (*NamedMediaTyp
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2144,15 +2153,11 @@ func NewNamedParameterOrReference(in *yaml.Node, context *compiler.Context) (*Na
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2182,15 +2187,11 @@ func NewNamedPathItem(in *yaml.Node, context *compiler.Context) (*NamedPathItem,
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2220,15 +2221,11 @@ func NewNamedRequestBodyOrReference(in *yaml.Node, context *compiler.Context) (*
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2258,15 +2255,11 @@ func NewNamedResponseOrReference(in *yaml.Node, context *compiler.Context) (*Nam
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2296,15 +2289,11 @@ func NewNamedSchemaOrReference(in *yaml.Node, context *compiler.Context) (*Named
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2334,15 +2323,11 @@ func NewNamedSecuritySchemeOrReference(in *yaml.Node, context *compiler.Context)
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2372,15 +2357,11 @@ func NewNamedServerVariable(in *yaml.Node, context *compiler.Context) (*NamedSer
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2410,15 +2391,11 @@ func NewNamedString(in *yaml.Node, context *compiler.Context) (*NamedString, err
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2427,7 +2404,7 @@ func NewNamedString(in *yaml.Node, context *compiler.Context) (*NamedString, err
 		// string value = 2;
 		v2 := compiler.MapValueForKey(m, "value")
 		if v2 != nil {
-			x.Value, ok = compiler.StringForScalarNode(v2)
+			x.Value, ok = compiler.StringForScalarNodeInContext(context, "value", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for value: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2448,15 +2425,11 @@ func NewNamedStringArray(in *yaml.Node, context *compiler.Context) (*NamedString
 	} else {
 		allowedKeys := []string{"name", "value"}
 		var allowedPatterns []*regexp.Regexp
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2486,15 +2459,11 @@ func NewOauthFlow(in *yaml.Node, context *compiler.Context) (*OauthFlow, error)
 	} else {
 		allowedKeys := []string{"authorizationUrl", "refreshUrl", "scopes", "tokenUrl"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string authorization_url = 1;
 		v1 := compiler.MapValueForKey(m, "authorizationUrl")
 		if v1 != nil {
-			x.AuthorizationUrl, ok = compiler.StringForScalarNode(v1)
+			x.AuthorizationUrl, ok = compiler.StringForScalarNodeInContext(context, "authorizationUrl", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for authorizationUrl: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2503,7 +2472,7 @@ func NewOauthFlow(in *yaml.Node, context *compiler.Context) (*OauthFlow, error)
 		// string token_url = 2;
 		v2 := compiler.MapValueForKey(m, "tokenUrl")
 		if v2 != nil {
-			x.TokenUrl, ok = compiler.StringForScalarNode(v2)
+			x.TokenUrl, ok = compiler.StringForScalarNodeInContext(context, "tokenUrl", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for tokenUrl: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2512,7 +2481,7 @@ func NewOauthFlow(in *yaml.Node, context *compiler.Context) (*OauthFlow, error)
 		// string refresh_url = 3;
 		v3 := compiler.MapValueForKey(m, "refreshUrl")
 		if v3 != nil {
-			x.RefreshUrl, ok = compiler.StringForScalarNode(v3)
+			x.RefreshUrl, ok = compiler.StringForScalarNodeInContext(context, "refreshUrl", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for refreshUrl: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2538,7 +2507,7 @@ func NewOauthFlow(in *yaml.Node, context *compiler.Context) (*OauthFlow, error)
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "OauthFlow")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2573,11 +2542,7 @@ func NewOauthFlows(in *yaml.Node, context *compiler.Context) (*OauthFlows, error
 	} else {
 		allowedKeys := []string{"authorizationCode", "clientCredentials", "implicit", "password"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// OauthFlow implicit = 1;
 		v1 := compiler.MapValueForKey(m, "implicit")
 		if v1 != nil {
@@ -2625,7 +2590,7 @@ func NewOauthFlows(in *yaml.Node, context *compiler.Context) (*OauthFlows, error
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "OauthFlows")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2668,7 +2633,7 @@ func NewObject(in *yaml.Node, context *compiler.Context) (*Object, error) {
 				pair := &NamedAny{}
 				pair.Name = k
 				result := &Any{}
-				handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+				handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Object")
 				if handled {
 					if err != nil {
 						errors = append(errors, err)
@@ -2708,17 +2673,13 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		}
 		allowedKeys := []string{"callbacks", "deprecated", "description", "externalDocs", "operationId", "parameters", "requestBody", "responses", "security", "servers", "summary", "tags"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated string tags = 1;
 		v1 := compiler.MapValueForKey(m, "tags")
 		if v1 != nil {
 			v, ok := compiler.SequenceNodeForNode(v1)
 			if ok {
-				x.Tags = compiler.StringArrayForSequenceNode(v)
+				x.Tags = compiler.StringArrayForSequenceNodeInContext(context, "tags", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for tags: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2727,7 +2688,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// string summary = 2;
 		v2 := compiler.MapValueForKey(m, "summary")
 		if v2 != nil {
-			x.Summary, ok = compiler.StringForScalarNode(v2)
+			x.Summary, ok = compiler.StringForScalarNodeInContext(context, "summary", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for summary: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2736,7 +2697,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2754,7 +2715,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// string operation_id = 5;
 		v5 := compiler.MapValueForKey(m, "operationId")
 		if v5 != nil {
-			x.OperationId, ok = compiler.StringForScalarNode(v5)
+			x.OperationId, ok = compiler.StringForScalarNodeInContext(context, "operationId", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for operationId: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2806,7 +2767,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 		// bool deprecated = 10;
 		v10 := compiler.MapValueForKey(m, "deprecated")
 		if v10 != nil {
-			x.Deprecated, ok = compiler.BoolForScalarNode(v10)
+			x.Deprecated, ok = compiler.BoolForScalarNodeInContext(context, "deprecated", v10)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for deprecated: %s", compiler.Display(v10))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2855,7 +2816,7 @@ func NewOperation(in *yaml.Node, context *compiler.Context) (*Operation, error)
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Operation")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -2896,15 +2857,11 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		}
 		allowedKeys := []string{"allowEmptyValue", "allowReserved", "content", "deprecated", "description", "example", "examples", "explode", "in", "name", "required", "schema", "style"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2913,7 +2870,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string in = 2;
 		v2 := compiler.MapValueForKey(m, "in")
 		if v2 != nil {
-			x.In, ok = compiler.StringForScalarNode(v2)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2922,7 +2879,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2931,7 +2888,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// bool required = 4;
 		v4 := compiler.MapValueForKey(m, "required")
 		if v4 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v4)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2940,7 +2897,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// bool deprecated = 5;
 		v5 := compiler.MapValueForKey(m, "deprecated")
 		if v5 != nil {
-			x.Deprecated, ok = compiler.BoolForScalarNode(v5)
+			x.Deprecated, ok = compiler.BoolForScalarNodeInContext(context, "deprecated", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for deprecated: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2949,7 +2906,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// bool allow_empty_value = 6;
 		v6 := compiler.MapValueForKey(m, "allowEmptyValue")
 		if v6 != nil {
-			x.AllowEmptyValue, ok = compiler.BoolForScalarNode(v6)
+			x.AllowEmptyValue, ok = compiler.BoolForScalarNodeInContext(context, "allowEmptyValue", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for allowEmptyValue: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2958,7 +2915,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// string style = 7;
 		v7 := compiler.MapValueForKey(m, "style")
 		if v7 != nil {
-			x.Style, ok = compiler.StringForScalarNode(v7)
+			x.Style, ok = compiler.StringForScalarNodeInContext(context, "style", v7)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for style: %s", compiler.Display(v7))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2967,7 +2924,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// bool explode = 8;
 		v8 := compiler.MapValueForKey(m, "explode")
 		if v8 != nil {
-			x.Explode, ok = compiler.BoolForScalarNode(v8)
+			x.Explode, ok = compiler.BoolForScalarNodeInContext(context, "explode", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for explode: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -2976,7 +2933,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 		// bool allow_reserved = 9;
 		v9 := compiler.MapValueForKey(m, "allowReserved")
 		if v9 != nil {
-			x.AllowReserved, ok = compiler.BoolForScalarNode(v9)
+			x.AllowReserved, ok = compiler.BoolForScalarNodeInContext(context, "allowReserved", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for allowReserved: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3029,7 +2986,7 @@ func NewParameter(in *yaml.Node, context *compiler.Context) (*Parameter, error)
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Parameter")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3058,6 +3015,7 @@ func NewParameterOrReference(in *yaml.Node, context *compiler.Context) (*Paramet
 	errors := make([]error, 0)
 	x := &ParameterOrReference{}
 	matched := false
+	var partialMatch error
 	// Parameter parameter = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -3067,6 +3025,10 @@ func NewParameterOrReference(in *yaml.Node, context *compiler.Context) (*Paramet
 			if matchingError == nil {
 				x.Oneof = &ParameterOrReference_Parameter{Parameter: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ParameterOrReference_Parameter{Parameter: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3081,6 +3043,10 @@ func NewParameterOrReference(in *yaml.Node, context *compiler.Context) (*Paramet
 			if matchingError == nil {
 				x.Oneof = &ParameterOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ParameterOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3089,6 +3055,8 @@ func NewParameterOrReference(in *yaml.Node, context *compiler.Context) (*Paramet
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid ParameterOrReference"
 		err := compiler.NewError(context, message)
@@ -3138,15 +3106,11 @@ func NewPathItem(in *yaml.Node, context *compiler.Context) (*PathItem, error) {
 	} else {
 		allowedKeys := []string{"$ref", "delete", "description", "get", "head", "options", "parameters", "patch", "post", "put", "servers", "summary", "trace"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string _ref = 1;
 		v1 := compiler.MapValueForKey(m, "$ref")
 		if v1 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v1)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3155,7 +3119,7 @@ func NewPathItem(in *yaml.Node, context *compiler.Context) (*PathItem, error) {
 		// string summary = 2;
 		v2 := compiler.MapValueForKey(m, "summary")
 		if v2 != nil {
-			x.Summary, ok = compiler.StringForScalarNode(v2)
+			x.Summary, ok = compiler.StringForScalarNodeInContext(context, "summary", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for summary: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3164,7 +3128,7 @@ func NewPathItem(in *yaml.Node, context *compiler.Context) (*PathItem, error) {
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3285,7 +3249,7 @@ func NewPathItem(in *yaml.Node, context *compiler.Context) (*PathItem, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "PathItem")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3320,11 +3284,7 @@ func NewPaths(in *yaml.Node, context *compiler.Context) (*Paths, error) {
 	} else {
 		allowedKeys := []string{}
 		allowedPatterns := []*regexp.Regexp{pattern2, pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated NamedPathItem path = 1;
 		// MAP: PathItem ^/
 		x.Path = make([]*NamedPathItem, 0)
@@ -3355,7 +3315,7 @@ func NewPaths(in *yaml.Node, context *compiler.Context) (*Paths, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Paths")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3427,7 +3387,7 @@ func NewReference(in *yaml.Node, context *compiler.Context) (*Reference, error)
 		// string _ref = 1;
 		v1 := compiler.MapValueForKey(m, "$ref")
 		if v1 != nil {
-			x.XRef, ok = compiler.StringForScalarNode(v1)
+			x.XRef, ok = compiler.StringForScalarNodeInContext(context, "$ref", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3436,7 +3396,7 @@ func NewReference(in *yaml.Node, context *compiler.Context) (*Reference, error)
 		// string summary = 2;
 		v2 := compiler.MapValueForKey(m, "summary")
 		if v2 != nil {
-			x.Summary, ok = compiler.StringForScalarNode(v2)
+			x.Summary, ok = compiler.StringForScalarNodeInContext(context, "summary", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for summary: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3445,7 +3405,7 @@ func NewReference(in *yaml.Node, context *compiler.Context) (*Reference, error)
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3502,15 +3462,11 @@ func NewRequestBody(in *yaml.Node, context *compiler.Context) (*RequestBody, err
 		}
 		allowedKeys := []string{"content", "description", "required"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3528,7 +3484,7 @@ func NewRequestBody(in *yaml.Node, context *compiler.Context) (*RequestBody, err
 		// bool required = 3;
 		v3 := compiler.MapValueForKey(m, "required")
 		if v3 != nil {
-			x.Required, ok = compiler.BoolForScalarNode(v3)
+			x.Required, ok = compiler.BoolForScalarNodeInContext(context, "required", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3545,7 +3501,7 @@ func NewRequestBody(in *yaml.Node, context *compiler.Context) (*RequestBody, err
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "RequestBody")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3574,6 +3530,7 @@ func NewRequestBodyOrReference(in *yaml.Node, context *compiler.Context) (*Reque
 	errors := make([]error, 0)
 	x := &RequestBodyOrReference{}
 	matched := false
+	var partialMatch error
 	// RequestBody request_body = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -3583,6 +3540,10 @@ func NewRequestBodyOrReference(in *yaml.Node, context *compiler.Context) (*Reque
 			if matchingError == nil {
 				x.Oneof = &RequestBodyOrReference_RequestBody{RequestBody: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &RequestBodyOrReference_RequestBody{RequestBody: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3597,6 +3558,10 @@ func NewRequestBodyOrReference(in *yaml.Node, context *compiler.Context) (*Reque
 			if matchingError == nil {
 				x.Oneof = &RequestBodyOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &RequestBodyOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3605,6 +3570,8 @@ func NewRequestBodyOrReference(in *yaml.Node, context *compiler.Context) (*Reque
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid RequestBodyOrReference"
 		err := compiler.NewError(context, message)
@@ -3630,15 +3597,11 @@ func NewResponse(in *yaml.Node, context *compiler.Context) (*Response, error) {
 		}
 		allowedKeys := []string{"content", "description", "headers", "links"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string description = 1;
 		v1 := compiler.MapValueForKey(m, "description")
 		if v1 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v1)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3682,7 +3645,7 @@ func NewResponse(in *yaml.Node, context *compiler.Context) (*Response, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Response")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3711,6 +3674,7 @@ func NewResponseOrReference(in *yaml.Node, context *compiler.Context) (*Response
 	errors := make([]error, 0)
 	x := &ResponseOrReference{}
 	matched := false
+	var partialMatch error
 	// Response response = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -3720,6 +3684,10 @@ func NewResponseOrReference(in *yaml.Node, context *compiler.Context) (*Response
 			if matchingError == nil {
 				x.Oneof = &ResponseOrReference_Response{Response: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ResponseOrReference_Response{Response: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3734,6 +3702,10 @@ func NewResponseOrReference(in *yaml.Node, context *compiler.Context) (*Response
 			if matchingError == nil {
 				x.Oneof = &ResponseOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &ResponseOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -3742,6 +3714,8 @@ func NewResponseOrReference(in *yaml.Node, context *compiler.Context) (*Response
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid ResponseOrReference"
 		err := compiler.NewError(context, message)
@@ -3761,11 +3735,7 @@ func NewResponses(in *yaml.Node, context *compiler.Context) (*Responses, error)
 	} else {
 		allowedKeys := []string{"default"}
 		allowedPatterns := []*regexp.Regexp{pattern3, pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// ResponseOrReference default = 1;
 		v1 := compiler.MapValueForKey(m, "default")
 		if v1 != nil {
@@ -3805,7 +3775,7 @@ func NewResponses(in *yaml.Node, context *compiler.Context) (*Responses, error)
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Responses")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -3825,6 +3795,7 @@ func NewResponses(in *yaml.Node, context *compiler.Context) (*Responses, error)
 				}
 			}
 		}
+		recordDefaultResponsePosition(x, m)
 	}
 	return x, compiler.NewErrorGroupOrNil(errors)
 }
@@ -3870,15 +3841,11 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 	} else {
 		allowedKeys := []string{"additionalProperties", "allOf", "anyOf", "default", "deprecated", "description", "discriminator", "enum", "example", "exclusiveMaximum", "exclusiveMinimum", "externalDocs", "format", "items", "maxItems", "maxLength", "maxProperties", "maximum", "minItems", "minLength", "minProperties", "minimum", "multipleOf", "not", "nullable", "oneOf", "pattern", "properties", "readOnly", "required", "title", "type", "uniqueItems", "writeOnly", "xml"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// bool nullable = 1;
 		v1 := compiler.MapValueForKey(m, "nullable")
 		if v1 != nil {
-			x.Nullable, ok = compiler.BoolForScalarNode(v1)
+			x.Nullable, ok = compiler.BoolForScalarNodeInContext(context, "nullable", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for nullable: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3896,7 +3863,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool read_only = 3;
 		v3 := compiler.MapValueForKey(m, "readOnly")
 		if v3 != nil {
-			x.ReadOnly, ok = compiler.BoolForScalarNode(v3)
+			x.ReadOnly, ok = compiler.BoolForScalarNodeInContext(context, "readOnly", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for readOnly: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3905,7 +3872,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool write_only = 4;
 		v4 := compiler.MapValueForKey(m, "writeOnly")
 		if v4 != nil {
-			x.WriteOnly, ok = compiler.BoolForScalarNode(v4)
+			x.WriteOnly, ok = compiler.BoolForScalarNodeInContext(context, "writeOnly", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for writeOnly: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3941,7 +3908,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool deprecated = 8;
 		v8 := compiler.MapValueForKey(m, "deprecated")
 		if v8 != nil {
-			x.Deprecated, ok = compiler.BoolForScalarNode(v8)
+			x.Deprecated, ok = compiler.BoolForScalarNodeInContext(context, "deprecated", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for deprecated: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3950,7 +3917,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string title = 9;
 		v9 := compiler.MapValueForKey(m, "title")
 		if v9 != nil {
-			x.Title, ok = compiler.StringForScalarNode(v9)
+			x.Title, ok = compiler.StringForScalarNodeInContext(context, "title", v9)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for title: %s", compiler.Display(v9))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3959,7 +3926,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// float multiple_of = 10;
 		v10 := compiler.MapValueForKey(m, "multipleOf")
 		if v10 != nil {
-			v, ok := compiler.FloatForScalarNode(v10)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "multipleOf", v10)
 			if ok {
 				x.MultipleOf = v
 			} else {
@@ -3970,7 +3937,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// float maximum = 11;
 		v11 := compiler.MapValueForKey(m, "maximum")
 		if v11 != nil {
-			v, ok := compiler.FloatForScalarNode(v11)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "maximum", v11)
 			if ok {
 				x.Maximum = v
 			} else {
@@ -3981,7 +3948,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool exclusive_maximum = 12;
 		v12 := compiler.MapValueForKey(m, "exclusiveMaximum")
 		if v12 != nil {
-			x.ExclusiveMaximum, ok = compiler.BoolForScalarNode(v12)
+			x.ExclusiveMaximum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMaximum", v12)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMaximum: %s", compiler.Display(v12))
 				errors = append(errors, compiler.NewError(context, message))
@@ -3990,7 +3957,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// float minimum = 13;
 		v13 := compiler.MapValueForKey(m, "minimum")
 		if v13 != nil {
-			v, ok := compiler.FloatForScalarNode(v13)
+			v, ok := compiler.FloatForScalarNodeInContext(context, "minimum", v13)
 			if ok {
 				x.Minimum = v
 			} else {
@@ -4001,7 +3968,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool exclusive_minimum = 14;
 		v14 := compiler.MapValueForKey(m, "exclusiveMinimum")
 		if v14 != nil {
-			x.ExclusiveMinimum, ok = compiler.BoolForScalarNode(v14)
+			x.ExclusiveMinimum, ok = compiler.BoolForScalarNodeInContext(context, "exclusiveMinimum", v14)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for exclusiveMinimum: %s", compiler.Display(v14))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4010,7 +3977,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 max_length = 15;
 		v15 := compiler.MapValueForKey(m, "maxLength")
 		if v15 != nil {
-			t, ok := compiler.IntForScalarNode(v15)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxLength", v15)
 			if ok {
 				x.MaxLength = int64(t)
 			} else {
@@ -4021,7 +3988,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 min_length = 16;
 		v16 := compiler.MapValueForKey(m, "minLength")
 		if v16 != nil {
-			t, ok := compiler.IntForScalarNode(v16)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minLength", v16)
 			if ok {
 				x.MinLength = int64(t)
 			} else {
@@ -4032,7 +3999,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string pattern = 17;
 		v17 := compiler.MapValueForKey(m, "pattern")
 		if v17 != nil {
-			x.Pattern, ok = compiler.StringForScalarNode(v17)
+			x.Pattern, ok = compiler.StringForScalarNodeInContext(context, "pattern", v17)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for pattern: %s", compiler.Display(v17))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4041,7 +4008,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 max_items = 18;
 		v18 := compiler.MapValueForKey(m, "maxItems")
 		if v18 != nil {
-			t, ok := compiler.IntForScalarNode(v18)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxItems", v18)
 			if ok {
 				x.MaxItems = int64(t)
 			} else {
@@ -4052,7 +4019,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 min_items = 19;
 		v19 := compiler.MapValueForKey(m, "minItems")
 		if v19 != nil {
-			t, ok := compiler.IntForScalarNode(v19)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minItems", v19)
 			if ok {
 				x.MinItems = int64(t)
 			} else {
@@ -4063,7 +4030,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// bool unique_items = 20;
 		v20 := compiler.MapValueForKey(m, "uniqueItems")
 		if v20 != nil {
-			x.UniqueItems, ok = compiler.BoolForScalarNode(v20)
+			x.UniqueItems, ok = compiler.BoolForScalarNodeInContext(context, "uniqueItems", v20)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for uniqueItems: %s", compiler.Display(v20))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4072,7 +4039,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 max_properties = 21;
 		v21 := compiler.MapValueForKey(m, "maxProperties")
 		if v21 != nil {
-			t, ok := compiler.IntForScalarNode(v21)
+			t, ok := compiler.IntForScalarNodeInContext(context, "maxProperties", v21)
 			if ok {
 				x.MaxProperties = int64(t)
 			} else {
@@ -4083,7 +4050,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// int64 min_properties = 22;
 		v22 := compiler.MapValueForKey(m, "minProperties")
 		if v22 != nil {
-			t, ok := compiler.IntForScalarNode(v22)
+			t, ok := compiler.IntForScalarNodeInContext(context, "minProperties", v22)
 			if ok {
 				x.MinProperties = int64(t)
 			} else {
@@ -4096,7 +4063,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		if v23 != nil {
 			v, ok := compiler.SequenceNodeForNode(v23)
 			if ok {
-				x.Required = compiler.StringArrayForSequenceNode(v)
+				x.Required = compiler.StringArrayForSequenceNodeInContext(context, "required", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for required: %s", compiler.Display(v23))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4121,7 +4088,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string type = 25;
 		v25 := compiler.MapValueForKey(m, "type")
 		if v25 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v25)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v25)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v25))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4223,7 +4190,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string description = 34;
 		v34 := compiler.MapValueForKey(m, "description")
 		if v34 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v34)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v34)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v34))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4232,7 +4199,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 		// string format = 35;
 		v35 := compiler.MapValueForKey(m, "format")
 		if v35 != nil {
-			x.Format, ok = compiler.StringForScalarNode(v35)
+			x.Format, ok = compiler.StringForScalarNodeInContext(context, "format", v35)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for format: %s", compiler.Display(v35))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4249,7 +4216,7 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Schema")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4276,8 +4243,12 @@ func NewSchema(in *yaml.Node, context *compiler.Context) (*Schema, error) {
 // NewSchemaOrReference creates an object of type SchemaOrReference if possible, returning an error if not.
 func NewSchemaOrReference(in *yaml.Node, context *compiler.Context) (*SchemaOrReference, error) {
 	errors := make([]error, 0)
+	if x, ok, err := newSchemaWithReference(in, context); ok {
+		return x, err
+	}
 	x := &SchemaOrReference{}
 	matched := false
+	var partialMatch error
 	// Schema schema = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -4287,6 +4258,10 @@ func NewSchemaOrReference(in *yaml.Node, context *compiler.Context) (*SchemaOrRe
 			if matchingError == nil {
 				x.Oneof = &SchemaOrReference_Schema{Schema: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SchemaOrReference_Schema{Schema: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -4301,6 +4276,10 @@ func NewSchemaOrReference(in *yaml.Node, context *compiler.Context) (*SchemaOrRe
 			if matchingError == nil {
 				x.Oneof = &SchemaOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SchemaOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -4309,6 +4288,8 @@ func NewSchemaOrReference(in *yaml.Node, context *compiler.Context) (*SchemaOrRe
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid SchemaOrReference"
 		err := compiler.NewError(context, message)
@@ -4394,15 +4375,11 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 		}
 		allowedKeys := []string{"bearerFormat", "description", "flows", "in", "name", "openIdConnectUrl", "scheme", "type"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string type = 1;
 		v1 := compiler.MapValueForKey(m, "type")
 		if v1 != nil {
-			x.Type, ok = compiler.StringForScalarNode(v1)
+			x.Type, ok = compiler.StringForScalarNodeInContext(context, "type", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for type: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4411,7 +4388,7 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4420,7 +4397,7 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 		// string name = 3;
 		v3 := compiler.MapValueForKey(m, "name")
 		if v3 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v3)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4429,7 +4406,7 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 		// string in = 4;
 		v4 := compiler.MapValueForKey(m, "in")
 		if v4 != nil {
-			x.In, ok = compiler.StringForScalarNode(v4)
+			x.In, ok = compiler.StringForScalarNodeInContext(context, "in", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for in: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4438,7 +4415,7 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 		// string scheme = 5;
 		v5 := compiler.MapValueForKey(m, "scheme")
 		if v5 != nil {
-			x.Scheme, ok = compiler.StringForScalarNode(v5)
+			x.Scheme, ok = compiler.StringForScalarNodeInContext(context, "scheme", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for scheme: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4447,7 +4424,7 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 		// string bearer_format = 6;
 		v6 := compiler.MapValueForKey(m, "bearerFormat")
 		if v6 != nil {
-			x.BearerFormat, ok = compiler.StringForScalarNode(v6)
+			x.BearerFormat, ok = compiler.StringForScalarNodeInContext(context, "bearerFormat", v6)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for bearerFormat: %s", compiler.Display(v6))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4465,7 +4442,7 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 		// string open_id_connect_url = 8;
 		v8 := compiler.MapValueForKey(m, "openIdConnectUrl")
 		if v8 != nil {
-			x.OpenIdConnectUrl, ok = compiler.StringForScalarNode(v8)
+			x.OpenIdConnectUrl, ok = compiler.StringForScalarNodeInContext(context, "openIdConnectUrl", v8)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for openIdConnectUrl: %s", compiler.Display(v8))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4482,7 +4459,7 @@ func NewSecurityScheme(in *yaml.Node, context *compiler.Context) (*SecuritySchem
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "SecurityScheme")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4511,6 +4488,7 @@ func NewSecuritySchemeOrReference(in *yaml.Node, context *compiler.Context) (*Se
 	errors := make([]error, 0)
 	x := &SecuritySchemeOrReference{}
 	matched := false
+	var partialMatch error
 	// SecurityScheme security_scheme = 1;
 	{
 		m, ok := compiler.UnpackMap(in)
@@ -4520,6 +4498,10 @@ func NewSecuritySchemeOrReference(in *yaml.Node, context *compiler.Context) (*Se
 			if matchingError == nil {
 				x.Oneof = &SecuritySchemeOrReference_SecurityScheme{SecurityScheme: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecuritySchemeOrReference_SecurityScheme{SecurityScheme: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -4534,6 +4516,10 @@ func NewSecuritySchemeOrReference(in *yaml.Node, context *compiler.Context) (*Se
 			if matchingError == nil {
 				x.Oneof = &SecuritySchemeOrReference_Reference{Reference: t}
 				matched = true
+			} else if !matched && compiler.IsBetterPartialMatch(matchingError, partialMatch) {
+				// this possibility matches except for invalid keys, which are reported if no possibility matches
+				x.Oneof = &SecuritySchemeOrReference_Reference{Reference: t}
+				partialMatch = matchingError
 			} else {
 				errors = append(errors, matchingError)
 			}
@@ -4542,6 +4528,8 @@ func NewSecuritySchemeOrReference(in *yaml.Node, context *compiler.Context) (*Se
 	if matched {
 		// since the oneof matched one of its possibilities, discard any matching errors
 		errors = make([]error, 0)
+	} else if partialMatch != nil {
+		errors = []error{partialMatch}
 	} else {
 		message := "contains an invalid SecuritySchemeOrReference"
 		err := compiler.NewError(context, message)
@@ -4597,15 +4585,11 @@ func NewServer(in *yaml.Node, context *compiler.Context) (*Server, error) {
 		}
 		allowedKeys := []string{"description", "url", "variables"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string url = 1;
 		v1 := compiler.MapValueForKey(m, "url")
 		if v1 != nil {
-			x.Url, ok = compiler.StringForScalarNode(v1)
+			x.Url, ok = compiler.StringForScalarNodeInContext(context, "url", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for url: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4614,7 +4598,7 @@ func NewServer(in *yaml.Node, context *compiler.Context) (*Server, error) {
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4640,7 +4624,7 @@ func NewServer(in *yaml.Node, context *compiler.Context) (*Server, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Server")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4681,17 +4665,13 @@ func NewServerVariable(in *yaml.Node, context *compiler.Context) (*ServerVariabl
 		}
 		allowedKeys := []string{"default", "description", "enum"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// repeated string enum = 1;
 		v1 := compiler.MapValueForKey(m, "enum")
 		if v1 != nil {
 			v, ok := compiler.SequenceNodeForNode(v1)
 			if ok {
-				x.Enum = compiler.StringArrayForSequenceNode(v)
+				x.Enum = compiler.StringArrayForSequenceNodeInContext(context, "enum", v)
 			} else {
 				message := fmt.Sprintf("has unexpected value for enum: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4700,7 +4680,7 @@ func NewServerVariable(in *yaml.Node, context *compiler.Context) (*ServerVariabl
 		// string default = 2;
 		v2 := compiler.MapValueForKey(m, "default")
 		if v2 != nil {
-			x.Default, ok = compiler.StringForScalarNode(v2)
+			x.Default, ok = compiler.StringForScalarNodeInContext(context, "default", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for default: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4709,7 +4689,7 @@ func NewServerVariable(in *yaml.Node, context *compiler.Context) (*ServerVariabl
 		// string description = 3;
 		v3 := compiler.MapValueForKey(m, "description")
 		if v3 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v3)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4726,7 +4706,7 @@ func NewServerVariable(in *yaml.Node, context *compiler.Context) (*ServerVariabl
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "ServerVariable")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4865,15 +4845,11 @@ func NewTag(in *yaml.Node, context *compiler.Context) (*Tag, error) {
 		}
 		allowedKeys := []string{"description", "externalDocs", "name"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4882,7 +4858,7 @@ func NewTag(in *yaml.Node, context *compiler.Context) (*Tag, error) {
 		// string description = 2;
 		v2 := compiler.MapValueForKey(m, "description")
 		if v2 != nil {
-			x.Description, ok = compiler.StringForScalarNode(v2)
+			x.Description, ok = compiler.StringForScalarNodeInContext(context, "description", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for description: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4908,7 +4884,7 @@ func NewTag(in *yaml.Node, context *compiler.Context) (*Tag, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Tag")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -4943,15 +4919,11 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 	} else {
 		allowedKeys := []string{"attribute", "name", "namespace", "prefix", "wrapped"}
 		allowedPatterns := []*regexp.Regexp{pattern1}
-		invalidKeys := compiler.InvalidKeysInMap(m, allowedKeys, allowedPatterns)
-		if len(invalidKeys) > 0 {
-			message := fmt.Sprintf("has invalid %s: %+v", compiler.PluralProperties(len(invalidKeys)), strings.Join(invalidKeys, ", "))
-			errors = append(errors, compiler.NewError(context, message))
-		}
+		errors = append(errors, compiler.InvalidKeyErrors(context, m, allowedKeys, allowedPatterns)...)
 		// string name = 1;
 		v1 := compiler.MapValueForKey(m, "name")
 		if v1 != nil {
-			x.Name, ok = compiler.StringForScalarNode(v1)
+			x.Name, ok = compiler.StringForScalarNodeInContext(context, "name", v1)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for name: %s", compiler.Display(v1))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4960,7 +4932,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// string namespace = 2;
 		v2 := compiler.MapValueForKey(m, "namespace")
 		if v2 != nil {
-			x.Namespace, ok = compiler.StringForScalarNode(v2)
+			x.Namespace, ok = compiler.StringForScalarNodeInContext(context, "namespace", v2)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for namespace: %s", compiler.Display(v2))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4969,7 +4941,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// string prefix = 3;
 		v3 := compiler.MapValueForKey(m, "prefix")
 		if v3 != nil {
-			x.Prefix, ok = compiler.StringForScalarNode(v3)
+			x.Prefix, ok = compiler.StringForScalarNodeInContext(context, "prefix", v3)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for prefix: %s", compiler.Display(v3))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4978,7 +4950,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// bool attribute = 4;
 		v4 := compiler.MapValueForKey(m, "attribute")
 		if v4 != nil {
-			x.Attribute, ok = compiler.BoolForScalarNode(v4)
+			x.Attribute, ok = compiler.BoolForScalarNodeInContext(context, "attribute", v4)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for attribute: %s", compiler.Display(v4))
 				errors = append(errors, compiler.NewError(context, message))
@@ -4987,7 +4959,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 		// bool wrapped = 5;
 		v5 := compiler.MapValueForKey(m, "wrapped")
 		if v5 != nil {
-			x.Wrapped, ok = compiler.BoolForScalarNode(v5)
+			x.Wrapped, ok = compiler.BoolForScalarNodeInContext(context, "wrapped", v5)
 			if !ok {
 				message := fmt.Sprintf("has unexpected value for wrapped: %s", compiler.Display(v5))
 				errors = append(errors, compiler.NewError(context, message))
@@ -5004,7 +4976,7 @@ func NewXml(in *yaml.Node, context *compiler.Context) (*Xml, error) {
 					pair := &NamedAny{}
 					pair.Name = k
 					result := &Any{}
-					handled, resultFromExt, err := compiler.CallExtension(context, v, k)
+					handled, resultFromExt, err := compiler.CallExtensionForObject(context, v, k, "Xml")
 					if handled {
 						if err != nil {
 							errors = append(errors, err)
@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Conflict codes reported by FindConflicts.
+const (
+	ConflictOperationID = "DUPLICATE_OPERATION_ID"
+	ConflictPath        = "DUPLICATE_PATH"
+	ConflictSchema      = "CONFLICTING_SCHEMA"
+)
+
+// ConflictLocation identifies an element of one of the documents passed to FindConflicts.
+type ConflictLocation struct {
+	// Document is the index of the document in the list that was checked.
+	Document int
+	// Keys is the key path of the element within the document.
+	Keys []string
+}
+
+// String returns the key path of the location.
+func (l ConflictLocation) String() string {
+	return strings.Join(l.Keys, ".")
+}
+
+// Conflict describes two elements that can't coexist in a merged document.
+type Conflict struct {
+	Code   string
+	Text   string
+	First  ConflictLocation
+	Second ConflictLocation
+}
+
+// Error returns a description of the conflict that includes both locations.
+func (c *Conflict) Error() string {
+	return fmt.Sprintf("%s: %s (%s and %s)", c.Code, c.Text, c.First, c.Second)
+}
+
+var pathParameterRegex = regexp.MustCompile(`{[^}]*}`)
+
+// FindConflicts checks one or more documents for elements that conflict when the
+// documents are merged or used together. It reports duplicate operationIds,
+// operations with the same method on paths that differ only in the names of
+// their template parameters, and schemas with the same name but different contents.
+func FindConflicts(documents ...*Document) []*Conflict {
+	conflicts := make([]*Conflict, 0)
+	operationIDs := make(map[string]ConflictLocation)
+	operations := make(map[string]ConflictLocation)
+	operationPaths := make(map[string]string)
+	schemas := make(map[string]ConflictLocation)
+	schemaValues := make(map[string]*SchemaOrReference)
+	for d, document := range documents {
+		if document.GetPaths() != nil {
+			for _, namedPathItem := range document.Paths.Path {
+				for _, operation := range pathItemOperations(namedPathItem.Value) {
+					location := ConflictLocation{Document: d, Keys: []string{"paths", namedPathItem.Name, operation.method}}
+					// Check for operations on equivalent paths.
+					key := operation.method + " " + pathParameterRegex.ReplaceAllString(namedPathItem.Name, "{}")
+					if first, ok := operations[key]; ok {
+						text := fmt.Sprintf("duplicate operation %s %s", operation.method, namedPathItem.Name)
+						if operationPaths[key] != namedPathItem.Name {
+							text = fmt.Sprintf("operation %s %s differs from %s %s only by path parameter names",
+								operation.method, namedPathItem.Name, operation.method, operationPaths[key])
+						}
+						conflicts = append(conflicts, &Conflict{Code: ConflictPath, Text: text, First: first, Second: location})
+					} else {
+						operations[key] = location
+						operationPaths[key] = namedPathItem.Name
+					}
+					// Check for duplicate operationIds.
+					operationID := operation.value.GetOperationId()
+					if operationID == "" {
+						continue
+					}
+					location.Keys = append(location.Keys, "operationId")
+					if first, ok := operationIDs[operationID]; ok {
+						text := fmt.Sprintf("duplicate operationId %q", operationID)
+						conflicts = append(conflicts, &Conflict{Code: ConflictOperationID, Text: text, First: first, Second: location})
+					} else {
+						operationIDs[operationID] = location
+					}
+				}
+			}
+		}
+		if document.GetComponents().GetSchemas() != nil {
+			for _, namedSchema := range document.Components.Schemas.AdditionalProperties {
+				location := ConflictLocation{Document: d, Keys: []string{"components", "schemas", namedSchema.Name}}
+				if first, ok := schemas[namedSchema.Name]; ok {
+					if !proto.Equal(schemaValues[namedSchema.Name], namedSchema.Value) {
+						text := fmt.Sprintf("schema %q has conflicting definitions", namedSchema.Name)
+						conflicts = append(conflicts, &Conflict{Code: ConflictSchema, Text: text, First: first, Second: location})
+					}
+				} else {
+					schemas[namedSchema.Name] = location
+					schemaValues[namedSchema.Name] = namedSchema.Value
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+type methodOperation struct {
+	method string
+	value  *Operation
+}
+
+// pathItemOperations returns the operations of a path item in the order they appear in the specification.
+func pathItemOperations(pathItem *PathItem) []methodOperation {
+	operations := make([]methodOperation, 0)
+	if pathItem == nil {
+		return operations
+	}
+	for _, operation := range []methodOperation{
+		{"get", pathItem.Get},
+		{"put", pathItem.Put},
+		{"post", pathItem.Post},
+		{"delete", pathItem.Delete},
+		{"options", pathItem.Options},
+		{"head", pathItem.Head},
+		{"patch", pathItem.Patch},
+		{"trace", pathItem.Trace},
+	} {
+		if operation.value != nil {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
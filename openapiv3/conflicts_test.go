@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func parseConflictTestDocument(t *testing.T, text string) *Document {
+	d, err := ParseDocument([]byte(text))
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return d
+}
+
+const petsDocument = `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      responses:
+        default:
+          description: a pet
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+`
+
+func TestFindConflictsNone(t *testing.T) {
+	d := parseConflictTestDocument(t, petsDocument)
+	if conflicts := FindConflicts(d); len(conflicts) != 0 {
+		t.Errorf("unexpected conflicts in a single document: %v", conflicts)
+	}
+	// Merging identical definitions is not a conflict for schemas,
+	// but repeats the operation and its operationId.
+	conflicts := FindConflicts(d, d)
+	codes := make([]string, 0)
+	for _, c := range conflicts {
+		codes = append(codes, c.Code)
+	}
+	if !reflect.DeepEqual(codes, []string{ConflictPath, ConflictOperationID}) {
+		t.Errorf("unexpected conflicts: %v", conflicts)
+	}
+}
+
+func TestFindConflictsOperationID(t *testing.T) {
+	other := parseConflictTestDocument(t, `
+openapi: 3.0.0
+info:
+  title: Shelters
+  version: 1.0.0
+paths:
+  /shelters/{id}:
+    get:
+      operationId: getPet
+      responses:
+        default:
+          description: a shelter
+`)
+	conflicts := FindConflicts(parseConflictTestDocument(t, petsDocument), other)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected one conflict, got %v", conflicts)
+	}
+	c := conflicts[0]
+	if c.Code != ConflictOperationID {
+		t.Errorf("unexpected code %s", c.Code)
+	}
+	first := ConflictLocation{Document: 0, Keys: []string{"paths", "/pets/{id}", "get", "operationId"}}
+	second := ConflictLocation{Document: 1, Keys: []string{"paths", "/shelters/{id}", "get", "operationId"}}
+	if !reflect.DeepEqual(c.First, first) || !reflect.DeepEqual(c.Second, second) {
+		t.Errorf("unexpected locations %v and %v", c.First, c.Second)
+	}
+	expected := `DUPLICATE_OPERATION_ID: duplicate operationId "getPet" (paths./pets/{id}.get.operationId and paths./shelters/{id}.get.operationId)`
+	if c.Error() != expected {
+		t.Errorf("unexpected description %q (expected %q)", c.Error(), expected)
+	}
+}
+
+func TestFindConflictsPathParameterNames(t *testing.T) {
+	d := parseConflictTestDocument(t, `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      responses:
+        default:
+          description: a pet
+  /pets/{petId}:
+    get:
+      operationId: showPetById
+      responses:
+        default:
+          description: a pet
+    delete:
+      operationId: deletePet
+      responses:
+        default:
+          description: deleted
+`)
+	conflicts := FindConflicts(d)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected one conflict, got %v", conflicts)
+	}
+	c := conflicts[0]
+	if c.Code != ConflictPath {
+		t.Errorf("unexpected code %s", c.Code)
+	}
+	first := ConflictLocation{Document: 0, Keys: []string{"paths", "/pets/{id}", "get"}}
+	second := ConflictLocation{Document: 0, Keys: []string{"paths", "/pets/{petId}", "get"}}
+	if !reflect.DeepEqual(c.First, first) || !reflect.DeepEqual(c.Second, second) {
+		t.Errorf("unexpected locations %v and %v", c.First, c.Second)
+	}
+	expected := "operation get /pets/{petId} differs from get /pets/{id} only by path parameter names"
+	if c.Text != expected {
+		t.Errorf("unexpected text %q (expected %q)", c.Text, expected)
+	}
+}
+
+func TestFindConflictsSchema(t *testing.T) {
+	other := parseConflictTestDocument(t, `
+openapi: 3.0.0
+info:
+  title: Shelters
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: integer
+`)
+	conflicts := FindConflicts(parseConflictTestDocument(t, petsDocument), other)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected one conflict, got %v", conflicts)
+	}
+	c := conflicts[0]
+	if c.Code != ConflictSchema {
+		t.Errorf("unexpected code %s", c.Code)
+	}
+	first := ConflictLocation{Document: 0, Keys: []string{"components", "schemas", "Pet"}}
+	second := ConflictLocation{Document: 1, Keys: []string{"components", "schemas", "Pet"}}
+	if !reflect.DeepEqual(c.First, first) || !reflect.DeepEqual(c.Second, second) {
+		t.Errorf("unexpected locations %v and %v", c.First, c.Second)
+	}
+}
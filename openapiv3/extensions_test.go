@@ -0,0 +1,130 @@
+// Copyright 2020 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/google/gnostic/compiler"
+	extensions "github.com/google/gnostic/extensions"
+)
+
+// When this environment variable is set, the test binary acts as an extension handler
+// that appends the location of each extension to the named file.
+const locationLogVariable = "GNOSTIC_TEST_EXTENSION_LOCATION_LOG"
+
+func TestMain(m *testing.M) {
+	if log := os.Getenv(locationLogVariable); log != "" {
+		extensions.MainWithLocation(func(name string, yamlInput string, location *extensions.ExtensionLocation) (bool, proto.Message, error) {
+			f, err := os.OpenFile(log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err != nil {
+				return false, nil, err
+			}
+			defer f.Close()
+			fmt.Fprintf(f, "%s %s\n", location.ObjectKind, location.Path)
+			return true, &emptypb.Empty{}, nil
+		})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestExtensionLocations(t *testing.T) {
+	data, err := os.ReadFile("../testdata/v3.0/yaml/extension-locations.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		t.Fatal(err)
+	}
+	root := node.Content[0]
+	// Paths are the context chains used in compiler errors.
+	expected := []string{
+		"Info $root.info.x-level",
+		"Operation $root.paths./pets.get.x-level",
+		"Schema $root.components.schemas.Pet.schema.properties.name.schema.x-level",
+	}
+	for _, batched := range []bool{false, true} {
+		t.Run(fmt.Sprintf("batched=%t", batched), func(t *testing.T) {
+			log := filepath.Join(t.TempDir(), "locations.log")
+			t.Setenv(locationLogVariable, log)
+			handlers := []compiler.ExtensionHandler{{Name: os.Args[0]}}
+			context := compiler.NewContextWithExtensions("$root", root, nil, &handlers)
+			defer compiler.RemoveExtensionConfig(context)
+			if batched {
+				compiler.CallExtensionsInBatchesWithCompiler(context, func() {
+					NewDocument(root, context)
+				})
+			}
+			if _, err := NewDocument(root, context); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			locations := strings.Split(strings.TrimSpace(string(data)), "\n")
+			sort.Strings(locations)
+			if strings.Join(locations, "\n") != strings.Join(expected, "\n") {
+				t.Errorf("unexpected extension locations:\n%s\nexpected:\n%s", strings.Join(locations, "\n"), strings.Join(expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestRegisteredExtensionHandler(t *testing.T) {
+	compiler.RegisterExtensionHandler("x-level", func(name string, yamlValue string) (proto.Message, bool, error) {
+		return wrapperspb.String(strings.TrimSpace(yamlValue)), true, nil
+	})
+	defer compiler.UnregisterExtensionHandler("x-level")
+	data, err := os.ReadFile("../testdata/v3.0/yaml/extension-locations.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	document, err := ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels := map[string][]*NamedAny{
+		"info":      document.Info.SpecificationExtension,
+		"operation": document.Paths.Path[0].Value.Get.SpecificationExtension,
+	}
+	for level, named := range levels {
+		if len(named) != 1 || named[0].Name != "x-level" {
+			t.Fatalf("unexpected %s extensions %v", level, named)
+		}
+		value := &wrapperspb.StringValue{}
+		if err := named[0].Value.Value.UnmarshalTo(value); err != nil {
+			t.Fatalf("%s: %v", level, err)
+		}
+		if value.Value != level {
+			t.Errorf("unexpected value %q for the %s extension", value.Value, level)
+		}
+		if named[0].Value.Yaml != level+"\n" {
+			t.Errorf("unexpected yaml %q for the %s extension", named[0].Value.Yaml, level)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/gnostic/compiler"
+)
+
+func TestFillDefaults(t *testing.T) {
+	filename := "../testdata/v3.0/yaml/sparse.yaml"
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	info, err := compiler.ReadInfoFromBytes(filename, b)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	// Without their missing keys, sparse descriptions don't compile.
+	if _, err = ParseDocument(b); err == nil {
+		t.Fatalf("expected %s not to compile", filename)
+	}
+	FillMissingKeys(info)
+	document, err := parseInfo(context.Background(), info)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	expected := []string{
+		`info.title: "Untitled API"`,
+		`paths./pets.get.operationId: "getPets"`,
+		`paths./pets.get.responses.default.description: "Default response"`,
+		`paths./pets.post.responses.201.description: "Created"`,
+		// getPetsPetId is the operationId of POST /pets.
+		`paths./pets/{petId}.get.operationId: "getPetsPetId2"`,
+		`paths./pets/{petId}.get.responses.200.description: "OK"`,
+		`paths./pets/{petId}.get.responses.404.description: "Not Found"`,
+		`paths./pets/{pet_id}.get.operationId: "getPetsPetId3"`,
+		`paths./pets/{pet_id}.get.responses.2XX.description: "Response"`,
+		`paths./.head.operationId: "head"`,
+		`paths./.head.responses.204.description: "No Content"`,
+		`components.responses.Pets.description: "Response"`,
+	}
+	filled := FillDefaults(document)
+	if len(filled) != len(expected) {
+		t.Fatalf("expected %d values, got %v", len(expected), filled)
+	}
+	for i, value := range filled {
+		if value.String() != expected[i] {
+			t.Errorf("unexpected value %s (expected %s)", value, expected[i])
+		}
+	}
+	// Filling a filled document changes nothing.
+	if filled = FillDefaults(document); len(filled) != 0 {
+		t.Errorf("expected no values, got %v", filled)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// methods are the HTTP methods of path items in their canonical order.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// NormalizeMethodKeys lowercases the keys of the operations of the path items in a parsed
+// document, like GET, which are otherwise invalid keys. Call it before compiling the document.
+func NormalizeMethodKeys(info *yaml.Node) {
+	if info.Kind == yaml.DocumentNode && len(info.Content) > 0 {
+		info = info.Content[0]
+	}
+	paths := mapValue(info, "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 1; i < len(paths.Content); i += 2 {
+		pathItem := paths.Content[i]
+		if pathItem.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j < len(pathItem.Content); j += 2 {
+			key := pathItem.Content[j]
+			lower := strings.ToLower(key.Value)
+			if lower != key.Value && isMethod(lower) && mapValue(pathItem, lower) == nil {
+				key.Value = lower
+			}
+		}
+	}
+}
+
+func isMethod(key string) bool {
+	for _, method := range methods {
+		if key == method {
+			return true
+		}
+	}
+	return false
+}
+
+func mapValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Normalize rewrites a document in a canonical form without changing its meaning, so that
+// equivalent documents are stored identically. Components of each kind and tags are sorted
+// by name, and references that name the document's own file are rewritten as local
+// references like #/components/schemas/Pet. Default responses are written before the
+// responses with status codes. filename is the name of the document's file, or empty if it
+// is unknown. Operations are written in their canonical order by ToRawInfo and CanonicalYAML.
+func Normalize(document *Document, filename string) {
+	if components := document.Components; components != nil {
+		if components.Schemas != nil {
+			sortNamed(components.Schemas.AdditionalProperties, func(i int) string { return components.Schemas.AdditionalProperties[i].Name })
+		}
+		if components.Responses != nil {
+			sortNamed(components.Responses.AdditionalProperties, func(i int) string { return components.Responses.AdditionalProperties[i].Name })
+		}
+		if components.Parameters != nil {
+			sortNamed(components.Parameters.AdditionalProperties, func(i int) string { return components.Parameters.AdditionalProperties[i].Name })
+		}
+		if components.Examples != nil {
+			sortNamed(components.Examples.AdditionalProperties, func(i int) string { return components.Examples.AdditionalProperties[i].Name })
+		}
+		if components.RequestBodies != nil {
+			sortNamed(components.RequestBodies.AdditionalProperties, func(i int) string { return components.RequestBodies.AdditionalProperties[i].Name })
+		}
+		if components.Headers != nil {
+			sortNamed(components.Headers.AdditionalProperties, func(i int) string { return components.Headers.AdditionalProperties[i].Name })
+		}
+		if components.SecuritySchemes != nil {
+			sortNamed(components.SecuritySchemes.AdditionalProperties, func(i int) string { return components.SecuritySchemes.AdditionalProperties[i].Name })
+		}
+		if components.Links != nil {
+			sortNamed(components.Links.AdditionalProperties, func(i int) string { return components.Links.AdditionalProperties[i].Name })
+		}
+		if components.Callbacks != nil {
+			sortNamed(components.Callbacks.AdditionalProperties, func(i int) string { return components.Callbacks.AdditionalProperties[i].Name })
+		}
+	}
+	sortNamed(document.Tags, func(i int) string { return document.Tags[i].Name })
+	normalizeMessage(document.ProtoReflect(), filename)
+}
+
+// sortNamed sorts a slice of named elements by name, keeping the order of elements with the same name.
+func sortNamed(slice interface{}, name func(i int) string) {
+	sort.SliceStable(slice, func(i, j int) bool { return name(i) < name(j) })
+}
+
+// normalizeMessage rewrites the references in a message and the messages that it contains,
+// and moves their default responses first.
+func normalizeMessage(m protoreflect.Message, filename string) {
+	if name := m.Descriptor().Name(); name == "Reference" || name == "PathItem" {
+		field := m.Descriptor().Fields().ByName("_ref")
+		if ref := m.Get(field).String(); ref != "" {
+			m.Set(field, protoreflect.ValueOfString(normalizeReference(ref, filename)))
+		}
+	}
+	if schema, ok := m.Interface().(*Schema); ok {
+		if ref := SchemaReference(schema); ref != "" {
+			setSchemaReference(schema, normalizeReference(ref, filename))
+		}
+	}
+	if responses, ok := m.Interface().(*Responses); ok {
+		forgetDefaultResponsePosition(responses)
+	}
+	m.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if field.Message() == nil {
+			return true
+		}
+		if field.IsList() {
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				normalizeMessage(list.Get(i).Message(), filename)
+			}
+		} else if !field.IsMap() {
+			normalizeMessage(value.Message(), filename)
+		}
+		return true
+	})
+}
+
+// normalizeReference rewrites a reference to the document in the file named filename as a local reference.
+func normalizeReference(ref string, filename string) string {
+	parts := strings.SplitN(ref, "#", 2)
+	if len(parts) != 2 || parts[0] == "" || filename == "" {
+		return ref
+	}
+	if u, err := url.Parse(parts[0]); err != nil || u.Scheme != "" {
+		return ref
+	}
+	if filepath.Clean(filepath.Join(filepath.Dir(filename), parts[0])) != filepath.Clean(filename) {
+		return ref
+	}
+	return "#" + parts[1]
+}
+
+// CanonicalYAML returns the YAML representation of a document with two-space indentation.
+// Normalized documents that are read from their canonical YAML have the same canonical YAML.
+func CanonicalYAML(document *Document) ([]byte, error) {
+	return RenderYAML(document, YAMLOptions{Indent: 2})
+}
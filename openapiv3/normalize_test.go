@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/gnostic/compiler"
+)
+
+func normalizeTestDocument(t *testing.T, b []byte, filename string) []byte {
+	info, err := compiler.ReadInfoFromBytes(filename, b)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	NormalizeMethodKeys(info)
+	d, err := parseInfo(context.Background(), info)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	Normalize(d, filename)
+	out, err := CanonicalYAML(d)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+	}
+	return out
+}
+
+const unsortedDocument = `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+tags:
+  - name: zebras
+  - name: ants
+paths:
+  /pets:
+    POST:
+      operationId: addPet
+      responses:
+        "201":
+          description: created
+        default:
+          description: added
+    GET:
+      operationId: listPets
+      responses:
+        default:
+          description: pets
+          content:
+            application/json:
+              schema:
+                $ref: 'pets.yaml#/components/schemas/Pets'
+components:
+  schemas:
+    Pets:
+      type: array
+      items:
+        $ref: '#/components/schemas/Pet'
+    Pet:
+      $ref: 'other.yaml#/components/schemas/Pet'
+`
+
+func TestNormalize(t *testing.T) {
+	out := string(normalizeTestDocument(t, []byte(unsortedDocument), "testdata/pets.yaml"))
+	for _, ordered := range [][]string{
+		{"name: ants", "name: zebras"},
+		{"    get:", "    post:"},
+		{"    Pet:", "    Pets:"},
+		{"description: added", "description: created"},
+	} {
+		first, second := strings.Index(out, ordered[0]), strings.Index(out, ordered[1])
+		if first < 0 || second < 0 || first > second {
+			t.Errorf("expected %q before %q in\n%s", ordered[0], ordered[1], out)
+		}
+	}
+	if strings.Contains(out, "GET") || strings.Contains(out, "POST") {
+		t.Errorf("expected lowercase methods in\n%s", out)
+	}
+	if !strings.Contains(out, "$ref: '#/components/schemas/Pets'") {
+		t.Errorf("expected a local reference to Pets in\n%s", out)
+	}
+	if !strings.Contains(out, "$ref: other.yaml#/components/schemas/Pet") {
+		t.Errorf("expected the reference to other.yaml to be unchanged in\n%s", out)
+	}
+}
+
+func TestNormalizeIsIdempotent(t *testing.T) {
+	for _, filename := range []string{
+		"../examples/v3.0/yaml/petstore.yaml",
+		"../testdata/v3.0/yaml/conflicts.yaml",
+		"../testdata/v3.0/yaml/extensions.yaml",
+		"../testdata/v3.0/yaml/extension-locations.yaml",
+		"../testdata/v3.0/yaml/response-ranges.yaml",
+	} {
+		t.Run(filename, func(t *testing.T) {
+			b, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("%s", err.Error())
+			}
+			once := normalizeTestDocument(t, b, filename)
+			twice := normalizeTestDocument(t, once, filename)
+			if !bytes.Equal(once, twice) {
+				t.Errorf("normalizing twice changed the document:\n%s\n%s", once, twice)
+			}
+			for i, line := range strings.Split(string(once), "\n") {
+				if strings.TrimRight(line, " \t") != line {
+					t.Errorf("line %d has trailing whitespace: %q", i+1, line)
+				}
+			}
+		})
+	}
+}
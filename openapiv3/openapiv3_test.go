@@ -15,8 +15,15 @@
 package openapi_v3
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"strings"
 	"testing"
+
+	"github.com/google/gnostic/compiler"
 )
 
 func TestParseDocument(t *testing.T) {
@@ -37,6 +44,19 @@ func TestParseDocument(t *testing.T) {
 	}
 }
 
+func TestParseDocumentContext_Canceled(t *testing.T) {
+	b, err := ioutil.ReadFile("../examples/v3.0/yaml/petstore.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d, err := ParseDocumentContext(ctx, b)
+	if !errors.Is(err, context.Canceled) || d != nil {
+		t.Errorf("expected the parse to be canceled, got %v %v", d, err)
+	}
+}
+
 func TestParseDocument_Empty(t *testing.T) {
 	for _, test := range []struct {
 		name string
@@ -61,3 +81,287 @@ func TestParseDocument_Empty(t *testing.T) {
 		})
 	}
 }
+
+// memoryResolver serves documents from a map and records the locations that are requested.
+type memoryResolver struct {
+	files     map[string]string
+	requested []string
+}
+
+func (r *memoryResolver) Resolve(location string) ([]byte, error) {
+	r.requested = append(r.requested, location)
+	if file, ok := r.files[location]; ok {
+		return []byte(file), nil
+	}
+	return nil, fmt.Errorf("%s not found", location)
+}
+
+func TestResolveReferencesWithFileResolver(t *testing.T) {
+	// None of these files exist, so they can only be read with the resolver.
+	resolver := &memoryResolver{files: map[string]string{
+		"/specs/openapi.yaml": `openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    $ref: 'paths/pets.yaml#/pets'
+`,
+		"/specs/paths/pets.yaml": `pets:
+  get:
+    operationId: listPets
+    responses:
+      '200':
+        $ref: 'https://schemas.example.invalid/responses.yaml#/Pets'
+`,
+		"https://schemas.example.invalid/responses.yaml": `Pets:
+  description: A list of pets
+`,
+	}}
+	compiler.SetFileResolver(resolver)
+	defer compiler.SetFileResolver(nil)
+
+	b, err := compiler.ReadBytesForFile("/specs/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ParseDocument(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = compiler.ReadReferencedFiles("/specs/openapi.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.ResolveReferences("/specs/openapi.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	pets := d.Paths.Path[0].Value
+	if pets.Get == nil || pets.Get.OperationId != "listPets" {
+		t.Errorf("expected the /pets path to be read from paths/pets.yaml, got %v", pets)
+	}
+	if len(resolver.requested) != len(resolver.files) {
+		t.Errorf("unexpected requests %v", resolver.requested)
+	}
+}
+
+// errDisconnected is returned by disconnectingReader after the simulated disconnect.
+var errDisconnected = errors.New("connection reset")
+
+// disconnectingReader reads a document until a simulated disconnect at an offset.
+// Offsets past the end of the document don't disconnect.
+type disconnectingReader struct {
+	t            *testing.T
+	data         string
+	disconnectAt int
+	offset       int
+	disconnected bool
+}
+
+func (r *disconnectingReader) Read(p []byte) (int, error) {
+	if r.disconnected {
+		r.t.Errorf("Read called after the disconnect")
+		return 0, errDisconnected
+	}
+	if r.offset == len(r.data) {
+		return 0, io.EOF
+	}
+	if r.offset >= r.disconnectAt {
+		r.disconnected = true
+		return 0, errDisconnected
+	}
+	end := len(r.data)
+	if r.disconnectAt < end {
+		end = r.disconnectAt
+	}
+	n := copy(p, r.data[r.offset:end])
+	r.offset += n
+	return n, nil
+}
+
+// generatedDocument returns a document with many paths.
+func generatedDocument(paths int) string {
+	var b strings.Builder
+	b.WriteString("openapi: 3.0.0\ninfo:\n  title: Generated\n  version: 1.0.0\npaths:\n")
+	for i := 0; i < paths; i++ {
+		fmt.Fprintf(&b, "  /items/%d:\n    get:\n      operationId: getItem%d\n      responses:\n        '200':\n          description: item %d\n", i, i, i)
+	}
+	return b.String()
+}
+
+func TestParseDocumentFromReader(t *testing.T) {
+	document := generatedDocument(20000)
+	d, err := ParseDocumentFromReader(&disconnectingReader{t: t, data: document, disconnectAt: len(document) + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Paths.Path) != 20000 {
+		t.Errorf("expected 20000 paths, got %d", len(d.Paths.Path))
+	}
+	reader := &disconnectingReader{t: t, data: document, disconnectAt: len(document) / 2}
+	if _, err = ParseDocumentFromReader(reader); !errors.Is(err, errDisconnected) {
+		t.Errorf("expected the read error, got %v", err)
+	}
+	if !reader.disconnected {
+		t.Errorf("expected the reader to be disconnected")
+	}
+}
+
+const examplesDocument = `openapi: 3.0.0
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    post:
+      operationId: createBook
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Book'
+            examples:
+              novel:
+                summary: A novel
+                value:
+                  title: Middlemarch
+                  pages: 880
+                  tags:
+                    - fiction
+              empty:
+                value: {}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              examples:
+                created:
+                  description: The created book
+                  value:
+                    name: books/1
+                    title: Middlemarch
+components:
+  schemas:
+    Book:
+      type: object
+`
+
+func TestExamplesRoundTrip(t *testing.T) {
+	d, err := ParseDocument([]byte(examplesDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	examples := d.Paths.Path[0].Value.Post.RequestBody.GetRequestBody().Content.AdditionalProperties[0].Value.Examples
+	if len(examples.AdditionalProperties) != 2 || examples.AdditionalProperties[0].Value.GetExample().GetValue() == nil {
+		t.Fatalf("expected two examples with values, got %v", examples)
+	}
+	b, err := CanonicalYAML(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != examplesDocument {
+		t.Errorf("unexpected document\n%s\n(expected\n%s)", b, examplesDocument)
+	}
+}
+
+const parametersDocument = `openapi: 3.0.0
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      operationId: listBooks
+      parameters:
+        - name: filter
+          in: query
+          allowReserved: true
+          schema:
+            type: string
+        - name: author
+          in: query
+          allowEmptyValue: true
+          schema:
+            type: string
+        - name: page_size
+          in: query
+          allowEmptyValue: false
+          allowReserved: false
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: OK
+`
+
+func TestParametersRoundTrip(t *testing.T) {
+	d, err := ParseDocument([]byte(parametersDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flags []string
+	for _, parameter := range d.Paths.Path[0].Value.Get.Parameters {
+		flags = append(flags, fmt.Sprintf("%s %t %t", parameter.GetParameter().Name, parameter.GetParameter().AllowEmptyValue, parameter.GetParameter().AllowReserved))
+	}
+	if strings.Join(flags, ", ") != "filter false true, author true false, page_size false false" {
+		t.Errorf("unexpected parameters %v", flags)
+	}
+	b, err := CanonicalYAML(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// False flags are the defaults, so they are left out.
+	expected := strings.Replace(parametersDocument, "          allowEmptyValue: false\n          allowReserved: false\n", "", 1)
+	if string(b) != expected {
+		t.Errorf("unexpected document\n%s\n(expected\n%s)", b, expected)
+	}
+}
+
+const referenceSiblingsDocument = `openapi: %s
+info:
+  title: Books
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Author:
+      type: object
+    Book:
+      type: object
+      properties:
+        author:
+          $ref: "#/components/schemas/Author"
+          description: The author of the book.
+        editor:
+          $ref: "#/components/schemas/Author"
+          nullable: true
+`
+
+func TestReferenceSiblings(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		editor  string
+	}{
+		{"3.0.3", "reference #/components/schemas/Author"},
+		{"3.1.0", "schema #/components/schemas/Author nullable=true"},
+	} {
+		d, err := ParseDocument([]byte(fmt.Sprintf(referenceSiblingsDocument, test.version)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		properties := d.Components.Schemas.AdditionalProperties[1].Value.GetSchema().Properties.AdditionalProperties
+		// References with only a description are References in both versions.
+		if author := properties[0].Value.GetReference(); author.GetXRef() != "#/components/schemas/Author" || author.GetDescription() != "The author of the book." {
+			t.Errorf("%s: unexpected author %v", test.version, properties[0].Value)
+		}
+		var editor string
+		if reference := properties[1].Value.GetReference(); reference != nil {
+			editor = "reference " + reference.XRef
+		} else if schema := properties[1].Value.GetSchema(); schema != nil {
+			editor = fmt.Sprintf("schema %s nullable=%t", SchemaReference(schema), schema.Nullable)
+		}
+		if editor != test.editor {
+			t.Errorf("%s: unexpected editor %q (expected %q)", test.version, editor, test.editor)
+		}
+	}
+}
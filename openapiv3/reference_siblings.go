@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/google/gnostic/compiler"
+)
+
+// In OpenAPI 3.0, schemas with a $ref are Reference Objects, and their other keys are ignored.
+// In OpenAPI 3.1, schemas are JSON Schema 2020-12 schemas, in which $ref is a keyword like any
+// other, so a schema can have a $ref and also, for example, be nullable or read-only.
+//
+// References with only a summary and a description are read as References in both versions.
+// In documents that declare 3.1, schemas with a $ref and other keywords are read as Schemas,
+// and their $ref is kept with their extensions, where SchemaReference finds it. It is written
+// with the other keywords when the document is written, after them.
+
+// referenceKey is the name of the extension that keeps the $ref of a schema with other keywords.
+const referenceKey = "$ref"
+
+// SchemaReference returns the $ref of a schema that has other keywords, or "" if it has none.
+// Schemas like this are only read from documents that declare OpenAPI 3.1.
+func SchemaReference(schema *Schema) string {
+	for _, extension := range schema.GetSpecificationExtension() {
+		if extension.Name == referenceKey {
+			var ref string
+			if err := yaml.Unmarshal([]byte(extension.GetValue().GetYaml()), &ref); err == nil {
+				return ref
+			}
+		}
+	}
+	return ""
+}
+
+// setSchemaReference replaces the $ref of a schema that has other keywords.
+func setSchemaReference(schema *Schema, ref string) {
+	for _, extension := range schema.GetSpecificationExtension() {
+		if extension.Name == referenceKey {
+			extension.Value = &Any{Yaml: string(compiler.Marshal(compiler.NewScalarNodeForString(ref)))}
+		}
+	}
+}
+
+// declaresOpenAPI31 returns true if the document that contains a context declares OpenAPI 3.1.
+func declaresOpenAPI31(context *compiler.Context) bool {
+	for context != nil && context.Parent != nil {
+		context = context.Parent
+	}
+	if context == nil {
+		return false
+	}
+	root, ok := compiler.UnpackMap(context.Node)
+	if !ok {
+		return false
+	}
+	version, _ := compiler.StringForScalarNode(compiler.MapValueForKey(root, "openapi"))
+	return strings.HasPrefix(version, "3.1")
+}
+
+// hasSchemaKeywords returns true if a map with a $ref has keys that References don't have.
+func hasSchemaKeywords(m *yaml.Node) bool {
+	for i := 0; i < len(m.Content); i += 2 {
+		switch m.Content[i].Value {
+		case "$ref", "summary", "description":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// newSchemaWithReference reads a schema with a $ref and other keywords from a document that
+// declares OpenAPI 3.1. It returns false if in should be read as a Schema or a Reference.
+func newSchemaWithReference(in *yaml.Node, context *compiler.Context) (*SchemaOrReference, bool, error) {
+	m, ok := compiler.UnpackMap(in)
+	if !ok {
+		return nil, false, nil
+	}
+	v := compiler.MapValueForKey(m, "$ref")
+	if v == nil || !hasSchemaKeywords(m) || !declaresOpenAPI31(context) {
+		return nil, false, nil
+	}
+	errors := make([]error, 0)
+	// The other keywords are read as a schema, in a map that has the location of the original.
+	keywords := &yaml.Node{Kind: yaml.MappingNode, Tag: m.Tag, Line: m.Line, Column: m.Column}
+	for i := 0; i < len(m.Content); i += 2 {
+		if m.Content[i].Value != "$ref" {
+			keywords.Content = append(keywords.Content, m.Content[i], m.Content[i+1])
+		}
+	}
+	schemaContext := compiler.NewContext("schema", keywords, context)
+	schema, err := NewSchema(keywords, schemaContext)
+	if err != nil {
+		errors = append(errors, err)
+	}
+	if _, ok := compiler.StringForScalarNodeInContext(schemaContext, "$ref", v); ok {
+		ref, err := NewAny(v, compiler.NewContext("$ref", v, schemaContext))
+		if err != nil {
+			errors = append(errors, err)
+		}
+		schema.SpecificationExtension = append(schema.SpecificationExtension, &NamedAny{Name: referenceKey, Value: ref})
+	} else {
+		message := fmt.Sprintf("has unexpected value for $ref: %s", compiler.Display(v))
+		errors = append(errors, compiler.NewError(schemaContext, message))
+	}
+	return &SchemaOrReference{Oneof: &SchemaOrReference_Schema{Schema: schema}}, true, compiler.NewErrorGroupOrNil(errors)
+}
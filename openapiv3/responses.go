@@ -0,0 +1,180 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"regexp"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/google/gnostic/compiler"
+)
+
+// The default response of a Responses Object is kept apart from the responses with status
+// codes, and ToRawInfo writes it first. The generated Responses type has no field for the
+// position of the default response, so NewResponses keeps the positions that aren't first
+// with the extensions of the Responses, where they are kept by the binary and text formats
+// and by copies of documents. RawInfo writes the responses of documents in the order in
+// which they were read, without the extension, and with status codes and ranges like 2XX
+// quoted, so that they are always read as strings.
+
+// defaultPositionKey is the name of the extension that keeps the number of responses with
+// status codes that precede the default response.
+const defaultPositionKey = "x-gnostic-default-position"
+
+// statusCodePattern matches the keys of the responses with status codes or ranges of them.
+var statusCodePattern = regexp.MustCompile("^([0-9X]{3})$")
+
+// defaultResponsePosition returns the number of responses with status codes that precede
+// the default response in a map of responses.
+func defaultResponsePosition(m *yaml.Node) int {
+	position := 0
+	for i := 0; i < len(m.Content); i += 2 {
+		k, ok := compiler.StringForScalarNode(m.Content[i])
+		if !ok {
+			continue
+		}
+		if k == "default" {
+			return position
+		}
+		if statusCodePattern.MatchString(k) {
+			position++
+		}
+	}
+	return 0
+}
+
+// recordDefaultResponsePosition records the position of the default response of responses
+// that were read from a map of responses. Maps that were written by ToRawInfo have their
+// default response first, so a position that they already record is kept.
+func recordDefaultResponsePosition(responses *Responses, m *yaml.Node) {
+	position := defaultResponsePosition(m)
+	if position == 0 {
+		return
+	}
+	forgetDefaultResponsePosition(responses)
+	value := &Any{Yaml: string(compiler.Marshal(compiler.NewScalarNodeForInt(int64(position))))}
+	responses.SpecificationExtension = append(responses.SpecificationExtension, &NamedAny{Name: defaultPositionKey, Value: value})
+}
+
+// recordedDefaultResponsePosition returns the position of the default response of responses,
+// or 0 if it comes first.
+func recordedDefaultResponsePosition(responses *Responses) int {
+	for _, extension := range responses.GetSpecificationExtension() {
+		if extension.Name == defaultPositionKey {
+			var position int
+			if err := yaml.Unmarshal([]byte(extension.GetValue().GetYaml()), &position); err == nil {
+				return position
+			}
+		}
+	}
+	return 0
+}
+
+// forgetDefaultResponsePosition writes the default response of responses first again.
+func forgetDefaultResponsePosition(responses *Responses) {
+	extensions := responses.SpecificationExtension[:0]
+	for _, extension := range responses.SpecificationExtension {
+		if extension.Name != defaultPositionKey {
+			extensions = append(extensions, extension)
+		}
+	}
+	responses.SpecificationExtension = extensions
+}
+
+// RawInfo returns the YAML representation of a document like its ToRawInfo method, but with
+// the responses of its operations in the order in which they were read and their status codes
+// quoted.
+func RawInfo(document *Document) *yaml.Node {
+	info := document.ToRawInfo()
+	if document == nil {
+		return info
+	}
+	orderPaths(document.Paths.GetPath(), mapValue(info, "paths"))
+	if components := mapValue(info, "components"); components != nil {
+		orderCallbacks(document.Components.GetCallbacks(), mapValue(components, "callbacks"))
+	}
+	return info
+}
+
+// orderPaths orders the responses of the operations of path items.
+func orderPaths(paths []*NamedPathItem, info *yaml.Node) {
+	if info == nil {
+		return
+	}
+	for _, path := range paths {
+		item := mapValue(info, path.Name)
+		if item == nil {
+			continue
+		}
+		for method, operation := range map[string]*Operation{
+			"get":     path.Value.GetGet(),
+			"put":     path.Value.GetPut(),
+			"post":    path.Value.GetPost(),
+			"delete":  path.Value.GetDelete(),
+			"options": path.Value.GetOptions(),
+			"head":    path.Value.GetHead(),
+			"patch":   path.Value.GetPatch(),
+			"trace":   path.Value.GetTrace(),
+		} {
+			if operation == nil {
+				continue
+			}
+			if info := mapValue(item, method); info != nil {
+				orderResponses(operation.Responses, mapValue(info, "responses"))
+				orderCallbacks(operation.Callbacks, mapValue(info, "callbacks"))
+			}
+		}
+	}
+}
+
+// orderCallbacks orders the responses of the operations of callbacks.
+func orderCallbacks(callbacks *CallbacksOrReferences, info *yaml.Node) {
+	if info == nil {
+		return
+	}
+	for _, callback := range callbacks.GetAdditionalProperties() {
+		orderPaths(callback.Value.GetCallback().GetPath(), mapValue(info, callback.Name))
+	}
+}
+
+// orderResponses moves the default response of a map of responses to its position among the
+// responses with status codes, removes the extension that records the position, and quotes
+// the status codes.
+func orderResponses(responses *Responses, info *yaml.Node) {
+	if responses == nil || info == nil {
+		return
+	}
+	var defaultPair, statusPairs, otherPairs []*yaml.Node
+	for i := 0; i+1 < len(info.Content); i += 2 {
+		key := info.Content[i]
+		switch {
+		case key.Value == "default":
+			defaultPair = info.Content[i : i+2]
+		case key.Value == defaultPositionKey:
+		case statusCodePattern.MatchString(key.Value):
+			key.Style = yaml.DoubleQuotedStyle
+			statusPairs = append(statusPairs, info.Content[i:i+2]...)
+		default:
+			otherPairs = append(otherPairs, info.Content[i:i+2]...)
+		}
+	}
+	position := min(max(recordedDefaultResponsePosition(responses), 0), len(statusPairs)/2)
+	content := make([]*yaml.Node, 0, len(info.Content))
+	content = append(content, statusPairs[:2*position]...)
+	content = append(content, defaultPair...)
+	content = append(content, statusPairs[2*position:]...)
+	info.Content = append(content, otherPairs...)
+}
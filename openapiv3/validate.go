@@ -0,0 +1,242 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Violation codes reported by Validate.
+const (
+	ViolationInfoVersion   = "MISSING_INFO_VERSION"
+	ViolationOperationID   = "DUPLICATE_OPERATION_ID"
+	ViolationPathParameter = "UNDECLARED_PATH_PARAMETER"
+	ViolationReference     = "UNRESOLVED_REFERENCE"
+)
+
+// Violation describes an element of a document that breaks one of the rules of Validate.
+type Violation struct {
+	Code string
+	Text string
+	// Keys is the key path of the element within the document.
+	Keys []string
+}
+
+// Error returns a description of the violation that includes its location.
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", v.Code, v.Text, strings.Join(v.Keys, "."))
+}
+
+// A Check reports the violations of one rule in a document.
+type Check func(document *Document) []*Violation
+
+// Checks are the checks that Validate runs, in the order of their reports.
+var Checks = []Check{
+	CheckInfoVersion,
+	CheckOperationIDs,
+	CheckPathParameters,
+	CheckReferences,
+}
+
+// Validate runs Checks against a document and returns their violations. Documents
+// that are read with ParseDocument are valid against the OpenAPI schema, but may still
+// be unusable by tools, for example because two operations have the same operationId.
+func Validate(document *Document) []*Violation {
+	violations := make([]*Violation, 0)
+	for _, check := range Checks {
+		violations = append(violations, check(document)...)
+	}
+	return violations
+}
+
+// CheckInfoVersion reports a document whose info.version is empty.
+func CheckInfoVersion(document *Document) []*Violation {
+	if document.GetInfo().GetVersion() != "" {
+		return nil
+	}
+	return []*Violation{{Code: ViolationInfoVersion, Text: "info.version is empty", Keys: []string{"info", "version"}}}
+}
+
+// CheckOperationIDs reports operations whose operationId is used by an earlier operation.
+func CheckOperationIDs(document *Document) []*Violation {
+	violations := make([]*Violation, 0)
+	operationIDs := make(map[string]string)
+	for _, namedPathItem := range document.GetPaths().GetPath() {
+		for _, operation := range pathItemOperations(namedPathItem.Value) {
+			operationID := operation.value.GetOperationId()
+			if operationID == "" {
+				continue
+			}
+			location := operation.method + " " + namedPathItem.Name
+			if first, ok := operationIDs[operationID]; ok {
+				violations = append(violations, &Violation{
+					Code: ViolationOperationID,
+					Text: fmt.Sprintf("operationId %q is also used by %s", operationID, first),
+					Keys: []string{"paths", namedPathItem.Name, operation.method, "operationId"},
+				})
+			} else {
+				operationIDs[operationID] = location
+			}
+		}
+	}
+	return violations
+}
+
+// CheckPathParameters reports operations that don't declare a parameter of their path
+// template, in the operation itself or in its path item.
+func CheckPathParameters(document *Document) []*Violation {
+	violations := make([]*Violation, 0)
+	for _, namedPathItem := range document.GetPaths().GetPath() {
+		names := pathParameterRegex.FindAllString(namedPathItem.Name, -1)
+		if len(names) == 0 {
+			continue
+		}
+		pathItemParameters := pathParameterNames(document, namedPathItem.Value.GetParameters())
+		for _, operation := range pathItemOperations(namedPathItem.Value) {
+			declared := pathParameterNames(document, operation.value.GetParameters())
+			for _, name := range names {
+				name = strings.Trim(name, "{}")
+				if !declared[name] && !pathItemParameters[name] {
+					violations = append(violations, &Violation{
+						Code: ViolationPathParameter,
+						Text: fmt.Sprintf("path parameter %q isn't declared", name),
+						Keys: []string{"paths", namedPathItem.Name, operation.method, "parameters"},
+					})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// pathParameterNames returns the names of the path parameters in a list of parameters,
+// including the ones that refer to components.parameters.
+func pathParameterNames(document *Document, parameters []*ParameterOrReference) map[string]bool {
+	names := make(map[string]bool)
+	for _, parameterOrReference := range parameters {
+		parameter := parameterOrReference.GetParameter()
+		if ref := parameterOrReference.GetReference().GetXRef(); ref != "" {
+			name := strings.TrimPrefix(ref, "#/components/parameters/")
+			for _, namedParameter := range document.GetComponents().GetParameters().GetAdditionalProperties() {
+				if namedParameter.Name == name {
+					parameter = namedParameter.Value.GetParameter()
+				}
+			}
+		}
+		if parameter.GetIn() == "path" {
+			names[parameter.GetName()] = true
+		}
+	}
+	return names
+}
+
+// CheckReferences reports local references, like #/components/schemas/Pet, to elements
+// that the document doesn't have. References to other documents aren't checked.
+func CheckReferences(document *Document) []*Violation {
+	root := document.ToRawInfo()
+	return unresolvedReferences(root, root, []string{}, make([]*Violation, 0))
+}
+
+// unresolvedReferences appends the local references in a node that don't resolve in the
+// document root to violations. Values that are data rather than descriptions, like
+// examples and extensions, aren't checked.
+func unresolvedReferences(root, node *yaml.Node, keys []string, violations []*Violation) []*Violation {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			switch {
+			case key == "$ref" && value.Kind == yaml.ScalarNode:
+				if strings.HasPrefix(value.Value, "#") && resolvePointer(root, value.Value[1:]) == nil {
+					violations = append(violations, &Violation{
+						Code: ViolationReference,
+						Text: fmt.Sprintf("%s doesn't resolve", value.Value),
+						Keys: append(append([]string{}, keys...), key),
+					})
+				}
+			case isDataKey(key) && !isNameMap(keys):
+			default:
+				violations = unresolvedReferences(root, value, append(keys, key), violations)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, value := range node.Content {
+			violations = unresolvedReferences(root, value, append(keys, strconv.Itoa(i)), violations)
+		}
+	}
+	return violations
+}
+
+// isDataKey returns true for the keys of values that are data, like examples, and of extensions.
+func isDataKey(key string) bool {
+	switch key {
+	case "example", "default", "enum", "value":
+		return true
+	}
+	return strings.HasPrefix(key, "x-")
+}
+
+// isNameMap returns true if the mapping at the end of a key path maps names to elements,
+// like properties, so that its keys are names rather than fields.
+func isNameMap(keys []string) bool {
+	if len(keys) == 0 {
+		return false
+	}
+	switch keys[len(keys)-1] {
+	case "paths", "properties", "responses", "schemas", "parameters", "examples", "requestBodies",
+		"headers", "securitySchemes", "links", "callbacks", "content", "encoding", "variables":
+		return true
+	}
+	return false
+}
+
+// resolvePointer returns the node that a JSON pointer, like /components/schemas/Pet,
+// refers to in a document root, or nil if there is none.
+func resolvePointer(root *yaml.Node, pointer string) *yaml.Node {
+	if pointer == "" {
+		return root
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil
+	}
+	node := root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = mapValue(node, token)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[i]
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
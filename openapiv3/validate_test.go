@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_v3
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateNone(t *testing.T) {
+	d := parseConflictTestDocument(t, `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    parameters:
+      - $ref: '#/components/parameters/id'
+    get:
+      operationId: getPet
+      responses:
+        "200":
+          description: a pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /owners/{owner}/pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: owner
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        default:
+          $ref: 'errors.yaml#/components/responses/Error'
+components:
+  parameters:
+    id:
+      name: id
+      in: path
+      required: true
+      schema:
+        type: string
+  schemas:
+    Pet:
+      type: object
+      example:
+        $ref: '#/not/a/reference'
+      properties:
+        owner:
+          $ref: '#/paths/~1owners~1{owner}~1pets/get/parameters/0/schema'
+`)
+	if violations := Validate(d); len(violations) != 0 {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	d := parseConflictTestDocument(t, `
+openapi: 3.0.0
+info:
+  title: Pets
+  version: ""
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      responses:
+        "200":
+          description: a pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+    delete:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        default:
+          $ref: '#/components/responses/Error'
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        value:
+          $ref: '#/components/schemas/Value'
+`)
+	expected := []string{
+		"MISSING_INFO_VERSION: info.version is empty (info.version)",
+		`DUPLICATE_OPERATION_ID: operationId "getPet" is also used by get /pets/{id} (paths./pets/{id}.delete.operationId)`,
+		`UNDECLARED_PATH_PARAMETER: path parameter "id" isn't declared (paths./pets/{id}.get.parameters)`,
+		"UNRESOLVED_REFERENCE: #/components/responses/Error doesn't resolve (paths./pets/{id}.delete.responses.default.$ref)",
+		"UNRESOLVED_REFERENCE: #/components/schemas/Value doesn't resolve (components.schemas.Pet.properties.value.$ref)",
+	}
+	actual := make([]string, 0)
+	for _, violation := range Validate(d) {
+		actual = append(actual, violation.Error())
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected violations:\n%s", strings.Join(actual, "\n"))
+	}
+}
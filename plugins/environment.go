@@ -40,6 +40,7 @@ func NewEnvironment() (env *Environment, err error) {
 	output := flag.String("output", "-", "Output file or directory")
 	plugin := flag.Bool("plugin", false, "Run as a gnostic plugin (other flags are ignored).")
 	verbose := flag.Bool("verbose", false, "Write details to stderr.")
+	parameters := flag.String("parameters", "", "Comma-separated name=value parameters, like those of plugin invocations.")
 	flag.Parse()
 
 	env.RunningAsPlugin = *plugin
@@ -101,6 +102,16 @@ When the -plugin option is specified, these flags are ignored.`)
 		env.Request = &Request{}
 		env.Request.OutputPath = *output
 		env.Request.SourceName = path.Base(*input)
+		if *parameters != "" {
+			for _, keyvalue := range strings.Split(*parameters, ",") {
+				pair := strings.SplitN(keyvalue, "=", 2)
+				if len(pair) != 2 {
+					env.RespondAndExitIfError(fmt.Errorf("invalid parameter %q (parameters must be name=value pairs)", keyvalue))
+				}
+				env.Request.Parameters = append(env.Request.Parameters, &Parameter{Name: pair[0], Value: pair[1]})
+				env.Invocation += " " + pair[0] + "=" + pair[1]
+			}
+		}
 
 		// First try to unmarshal OpenAPI v2.
 		documentv2 := &openapiv2.Document{}
@@ -142,6 +153,20 @@ When the -plugin option is specified, these flags are ignored.`)
 	return env, err
 }
 
+// Parameter returns the value of a parameter of the plugin invocation and whether it was
+// specified. Parameters are specified with --PLUGIN-opt=NAME=VALUE options and in
+// invocations like --PLUGIN-out=NAME=VALUE:PATH. Values in the invocation follow those of
+// options, and the last value of a parameter that is specified more than once is returned.
+func (env *Environment) Parameter(name string) (string, bool) {
+	value, ok := "", false
+	for _, parameter := range env.Request.GetParameters() {
+		if parameter.Name == name {
+			value, ok = parameter.Value, true
+		}
+	}
+	return value, ok
+}
+
 // RespondAndExitIfError checks an error and if it is non-nil, records it and serializes and returns the response and then exits.
 func (env *Environment) RespondAndExitIfError(err error) {
 	if err != nil {
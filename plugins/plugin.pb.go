@@ -232,7 +232,8 @@ type Request struct {
 	SourceName string `protobuf:"bytes,1,opt,name=source_name,json=sourceName,proto3" json:"source_name,omitempty"`
 	// Output path specified in the plugin invocation.
 	OutputPath string `protobuf:"bytes,2,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`
-	// Plugin parameters parsed from the invocation string.
+	// Plugin parameters parsed from --PLUGIN-opt options and then from the
+	// invocation string, as in --PLUGIN-out=NAME=VALUE:PATH.
 	Parameters []*Parameter `protobuf:"bytes,3,rep,name=parameters,proto3" json:"parameters,omitempty"`
 	// The version number of gnostic.
 	CompilerVersion *Version `protobuf:"bytes,4,opt,name=compiler_version,json=compilerVersion,proto3" json:"compiler_version,omitempty"`
@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnostic_plugin_v1
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Plugin servers are long-running plugins that handle the requests for many documents,
+// which gnostic sends them when it is run with --PLUGIN-plugin-server=PATH. Gnostic
+// connects to the Unix socket at PATH and both sides write ServerHandshake. Then, for each
+// document, gnostic writes a Request and the server replies with a Response. Each message
+// is preceded by its length, as a 4-byte big-endian unsigned integer.
+//
+// Servers can also speak this protocol over their stdin and stdout with ServeConn.
+
+// ServerHandshake begins each connection to a plugin server.
+const ServerHandshake = "gnostic-plugin-server 1\n"
+
+// MaxFrameSize is the size of the largest message that WriteFrame and ReadFrame accept.
+const MaxFrameSize = 1 << 30
+
+// ServerDialTimeout limits the time that DialServer waits to connect and handshake.
+var ServerDialTimeout = 5 * time.Second
+
+// WriteFrame writes a message preceded by its length.
+func WriteFrame(w io.Writer, message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxFrameSize {
+		return fmt.Errorf("message of %d bytes exceeds the maximum frame size of %d bytes", len(data), MaxFrameSize)
+	}
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+	_, err = w.Write(frame)
+	return err
+}
+
+// ReadFrame reads a message that was written with WriteFrame. It returns io.EOF if r
+// ends before the message begins.
+func ReadFrame(r io.Reader, message proto.Message) error {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds the maximum frame size of %d bytes", size, MaxFrameSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return proto.Unmarshal(data, message)
+}
+
+// readHandshake reads ServerHandshake, returning an error if something else is read.
+func readHandshake(r io.Reader) error {
+	handshake := make([]byte, len(ServerHandshake))
+	if _, err := io.ReadFull(r, handshake); err != nil {
+		return err
+	}
+	if string(handshake) != ServerHandshake {
+		return fmt.Errorf("unexpected handshake %q (expected %q)", handshake, ServerHandshake)
+	}
+	return nil
+}
+
+// ServeConn handles the requests of a connection, returning nil when the client closes it.
+// Handlers return responses with errors for requests that they can't handle.
+func ServeConn(conn io.ReadWriter, handler func(*Request) *Response) error {
+	reader := bufio.NewReader(conn)
+	if err := readHandshake(reader); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(conn, ServerHandshake); err != nil {
+		return err
+	}
+	for {
+		request := &Request{}
+		if err := ReadFrame(reader, request); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		response := handler(request)
+		if response == nil {
+			response = &Response{}
+		}
+		if err := WriteFrame(conn, response); err != nil {
+			return err
+		}
+	}
+}
+
+// Serve handles the connections of a listener, each in its own goroutine, until the
+// listener is closed. Handlers must be safe for concurrent use.
+func Serve(listener net.Listener, handler func(*Request) *Response) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			ServeConn(conn, handler)
+		}()
+	}
+}
+
+// ServerClient is a connection to a plugin server.
+type ServerClient struct {
+	mutex  sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// DialServer connects to the plugin server listening on the Unix socket at path.
+func DialServer(path string) (*ServerClient, error) {
+	conn, err := net.DialTimeout("unix", path, ServerDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	client := &ServerClient{conn: conn, reader: bufio.NewReader(conn)}
+	conn.SetDeadline(time.Now().Add(ServerDialTimeout))
+	if _, err = io.WriteString(conn, ServerHandshake); err == nil {
+		err = readHandshake(client.reader)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("plugin server at %s failed the handshake: %v", path, err)
+	}
+	conn.SetDeadline(time.Time{})
+	return client, nil
+}
+
+// Call sends a request to the server and returns its response. After an error, the
+// connection can't be used for more requests.
+func (c *ServerClient) Call(request *Request) (*Response, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := WriteFrame(c.conn, request); err != nil {
+		return nil, err
+	}
+	response := &Response{}
+	if err := ReadFrame(c.reader, response); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// Close closes the connection to the server.
+func (c *ServerClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sarif provides support for writing SARIF 2.1.0 logs.
+//
+// Only the subset of the format needed to report linter findings is
+// modeled here: a single run with a tool driver, its rules, and a list of
+// results with physical and logical locations.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	// Version is the SARIF version written by this package.
+	Version = "2.1.0"
+	// SchemaURI identifies the JSON schema of the logs written by this package.
+	SchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Result levels defined by SARIF.
+const (
+	LevelNone    = "none"
+	LevelNote    = "note"
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+// Log is the top-level SARIF object.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []*Run `json:"runs"`
+}
+
+// Run describes a single invocation of an analysis tool.
+type Run struct {
+	Tool    *Tool     `json:"tool"`
+	Results []*Result `json:"results"`
+}
+
+// Tool describes the analysis tool that produced a run.
+type Tool struct {
+	Driver *Driver `json:"driver"`
+}
+
+// Driver describes the tool component that contains the rules.
+type Driver struct {
+	Name           string  `json:"name"`
+	Version        string  `json:"version,omitempty"`
+	InformationURI string  `json:"informationUri,omitempty"`
+	Rules          []*Rule `json:"rules,omitempty"`
+}
+
+// Rule describes a single rule reported by a tool.
+type Rule struct {
+	ID               string   `json:"id"`
+	ShortDescription *Message `json:"shortDescription,omitempty"`
+}
+
+// Message is a SARIF message string.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result is a single finding.
+type Result struct {
+	RuleID    string      `json:"ruleId,omitempty"`
+	RuleIndex *int        `json:"ruleIndex,omitempty"`
+	Level     string      `json:"level,omitempty"`
+	Message   *Message    `json:"message"`
+	Locations []*Location `json:"locations,omitempty"`
+}
+
+// Location is the place where a result was found.
+type Location struct {
+	PhysicalLocation *PhysicalLocation  `json:"physicalLocation,omitempty"`
+	LogicalLocations []*LogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// PhysicalLocation identifies a file and an optional region within it.
+type PhysicalLocation struct {
+	ArtifactLocation *ArtifactLocation `json:"artifactLocation"`
+	Region           *Region           `json:"region,omitempty"`
+}
+
+// ArtifactLocation identifies a file.
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a range of text in a file. Lines and columns are 1-based.
+type Region struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// LogicalLocation identifies a named element of a document,
+// such as a key path in an API description.
+type LogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// NewLog creates a log containing a single run for the named tool.
+func NewLog(toolName string) *Log {
+	return &Log{
+		Schema:  SchemaURI,
+		Version: Version,
+		Runs: []*Run{
+			{
+				Tool:    &Tool{Driver: &Driver{Name: toolName}},
+				Results: []*Result{},
+			},
+		},
+	}
+}
+
+// AddResult appends a result to the first run of the log. If the result has
+// a rule id, the rule is registered with the driver and referenced by index.
+func (l *Log) AddResult(result *Result) {
+	run := l.Runs[0]
+	if result.RuleID != "" {
+		index := run.Tool.Driver.ruleIndex(result.RuleID)
+		result.RuleIndex = &index
+	}
+	run.Results = append(run.Results, result)
+}
+
+// ruleIndex returns the index of the rule with the given id, adding it if needed.
+func (d *Driver) ruleIndex(id string) int {
+	for i, rule := range d.Rules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	d.Rules = append(d.Rules, &Rule{ID: id})
+	return len(d.Rules) - 1
+}
+
+// Write writes the log as indented JSON.
+func (l *Log) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(l)
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/flowstack/go-jsonschema"
+)
+
+func validate(t *testing.T, log *Log) []byte {
+	schema, err := os.ReadFile("testdata/sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := jsonschema.New(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err = log.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = validator.Validate(b.Bytes()); err != nil {
+		t.Fatalf("log does not conform to the SARIF schema: %s\n%s", err, b.String())
+	}
+	return b.Bytes()
+}
+
+func TestEmptyLogConforms(t *testing.T) {
+	validate(t, NewLog("gnostic"))
+}
+
+func TestLogWithResultsConforms(t *testing.T) {
+	log := NewLog("gnostic")
+	log.AddResult(&Result{
+		RuleID:  "operation-id",
+		Level:   LevelError,
+		Message: &Message{Text: "missing operationId"},
+		Locations: []*Location{{
+			PhysicalLocation: &PhysicalLocation{
+				ArtifactLocation: &ArtifactLocation{URI: "petstore.yaml"},
+				Region:           &Region{StartLine: 12, StartColumn: 5},
+			},
+			LogicalLocations: []*LogicalLocation{{
+				FullyQualifiedName: "paths./pets.get",
+				Kind:               "member",
+			}},
+		}},
+	})
+	log.AddResult(&Result{
+		RuleID:  "description",
+		Level:   LevelNote,
+		Message: &Message{Text: "missing description"},
+	})
+	log.AddResult(&Result{
+		RuleID:  "operation-id",
+		Level:   LevelWarning,
+		Message: &Message{Text: "operationId is not camel case"},
+	})
+	log.AddResult(&Result{
+		Level:   LevelNone,
+		Message: &Message{Text: "no rule"},
+	})
+	data := validate(t, log)
+
+	var decoded struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleIndex *int `json:"ruleIndex"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version != Version {
+		t.Errorf("expected version %s, got %s", Version, decoded.Version)
+	}
+	rules := decoded.Runs[0].Tool.Driver.Rules
+	if len(rules) != 2 || rules[0].ID != "operation-id" || rules[1].ID != "description" {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+	results := decoded.Runs[0].Results
+	expected := []int{0, 1, 0}
+	for i, index := range expected {
+		if results[i].RuleIndex == nil || *results[i].RuleIndex != index {
+			t.Errorf("result %d: expected rule index %d", i, index)
+		}
+	}
+	if results[3].RuleIndex != nil {
+		t.Errorf("result 3: expected no rule index, got %d", *results[3].RuleIndex)
+	}
+}
+
+func TestInvalidLevelDoesNotConform(t *testing.T) {
+	schema, err := os.ReadFile("testdata/sarif-schema-2.1.0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := jsonschema.New(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewLog("gnostic")
+	log.AddResult(&Result{Level: "fatal", Message: &Message{Text: "bad level"}})
+	var b bytes.Buffer
+	if err = log.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = validator.Validate(b.Bytes()); err == nil {
+		t.Errorf("expected schema validation to reject level %q", "fatal")
+	}
+}
@@ -128,8 +128,11 @@ func (b *OpenAPI2Builder) buildFromResponseDefinitions(responses *openapiv2.Resp
 func (b *OpenAPI2Builder) buildSymbolicReferences(document *openapiv2.Document, sourceName string) (err error) {
 	cache := compiler.GetInfoCache()
 	if len(cache) == 0 && sourceName != "" {
-		// Fills the compiler cache with all kind of references.
-		_, err = document.ResolveReferences(sourceName)
+		// Fills the compiler cache with all kind of references, leaving cycles unresolved.
+		err = compiler.ResolveReferences(sourceName, func() error {
+			_, err := document.ResolveReferences(sourceName)
+			return err
+		})
 		if err != nil {
 			return err
 		}
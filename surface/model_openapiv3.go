@@ -110,8 +110,11 @@ func (b *OpenAPI3Builder) buildFromPaths(paths *openapiv3.Paths) {
 func (b *OpenAPI3Builder) buildSymbolicReferences(document *openapiv3.Document, sourceName string) (err error) {
 	cache := compiler.GetInfoCache()
 	if len(cache) == 0 && sourceName != "" {
-		// Fills the compiler cache with all kind of references.
-		_, err = document.ResolveReferences(sourceName)
+		// Fills the compiler cache with all kind of references, leaving cycles unresolved.
+		err = compiler.ResolveReferences(sourceName, func() error {
+			_, err := document.ResolveReferences(sourceName)
+			return err
+		})
 		if err != nil {
 			return err
 		}